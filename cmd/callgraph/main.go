@@ -31,6 +31,7 @@ import (
 
 	"github.com/tinygo-org/tinygo/x-tools/go/callgraph"
 	"github.com/tinygo-org/tinygo/x-tools/go/callgraph/cha"
+	"github.com/tinygo-org/tinygo/x-tools/go/callgraph/pta"
 	"github.com/tinygo-org/tinygo/x-tools/go/callgraph/rta"
 	"github.com/tinygo-org/tinygo/x-tools/go/callgraph/static"
 	"github.com/tinygo-org/tinygo/x-tools/go/callgraph/vta"
@@ -42,7 +43,7 @@ import (
 // flags
 var (
 	algoFlag = flag.String("algo", "rta",
-		`Call graph construction algorithm (static, cha, rta, vta)`)
+		`Call graph construction algorithm (static, cha, rta, vta, pta)`)
 
 	testFlag = flag.Bool("test", false,
 		"Loads test code (*_test.go) for imported packages")
@@ -58,7 +59,7 @@ const Usage = `callgraph: display the call graph of a Go program.
 
 Usage:
 
-  callgraph [-algo=static|cha|rta|vta] [-test] [-format=...] package...
+  callgraph [-algo=static|cha|rta|vta|pta] [-test] [-format=...] package...
 
 Flags:
 
@@ -68,10 +69,11 @@ Flags:
             cha         Class Hierarchy Analysis
             rta         Rapid Type Analysis
             vta         Variable Type Analysis
+            pta         inclusion-based pointer analysis
 
            The algorithms are ordered by increasing precision in their
            treatment of dynamic calls (and thus also computational cost).
-           RTA requires a whole program (main or test), and
+           RTA and PTA require a whole program (main or test), and
            include only functions reachable from main.
 
 -test      Include the package's tests in the analysis.
@@ -206,7 +208,15 @@ func doCallgraph(dir, gopath, algo, format string, tests bool, args []string) er
 		cg = cha.CallGraph(prog)
 
 	case "pta":
-		return fmt.Errorf("pointer analysis is no longer supported (see Go issue #59676)")
+		mains, err := mainPackages(pkgs)
+		if err != nil {
+			return err
+		}
+		var roots []*ssa.Function
+		for _, main := range mains {
+			roots = append(roots, main.Func("init"), main.Func("main"))
+		}
+		cg = pta.Analyze(roots).CallGraph
 
 	case "rta":
 		mains, err := mainPackages(pkgs)
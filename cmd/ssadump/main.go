@@ -34,6 +34,13 @@ R	disable [R]ecover() from panic; show interpreter crash instead.
 T	[T]race execution of the program.  Best for single-threaded programs!
 `)
 
+	schedFlag = flag.String("sched", "", `Run goroutines under a deterministic scheduler following the
+schedule SEED[:CHOICES], as printed by -explore.
+Deadlocks are reported with the stacks of all goroutines.`)
+
+	exploreFlag = flag.Int("explore", -1, `Explore interleavings of goroutines, preempting at most N times per run,
+until a run deadlocks, panics, or exits nonzero; then replay it.`)
+
 	cpuprofile = flag.String("cpuprofile", "", "write cpu profile to file")
 
 	args stringListValue
@@ -47,13 +54,15 @@ func init() {
 }
 
 const usage = `SSA builder and interpreter.
-Usage: ssadump [-build=[DBCSNFLG]] [-test] [-run] [-interp=[TR]] [-arg=...] package...
+Usage: ssadump [-build=[DBCSNFLG]] [-test] [-run] [-interp=[TR]] [-sched=SPEC | -explore=N] [-arg=...] package...
 Use -help flag to display options.
 
 Examples:
 % ssadump -build=F hello.go              # dump SSA form of a single package
 % ssadump -build=F -test fmt             # dump SSA form of a package and its tests
 % ssadump -run -interp=T hello.go        # interpret a program, with tracing
+% ssadump -run -explore=2 racy.go        # search for a failing interleaving
+% ssadump -run -sched=0:0,1 racy.go      # replay a schedule found by -explore
 
 The -run flag causes ssadump to build the code in a runnable form and run the first
 package named main.
@@ -105,6 +114,17 @@ func doMain() error {
 		}
 	}
 
+	var sched *interp.Schedule
+	if *schedFlag != "" {
+		if *exploreFlag >= 0 {
+			return fmt.Errorf("-sched and -explore are mutually exclusive")
+		}
+		var err error
+		if sched, err = interp.ParseSchedule(*schedFlag); err != nil {
+			return err
+		}
+	}
+
 	// Profiling support.
 	if *cpuprofile != "" {
 		f, err := os.Create(*cpuprofile)
@@ -176,7 +196,20 @@ func doMain() error {
 
 		// Run first main package.
 		for _, main := range ssautil.MainPackages(pkgs) {
+			if *exploreFlag >= 0 {
+				fmt.Fprintf(os.Stderr, "Exploring: %s\n", main.Pkg.Path())
+				runs, failed, _ := interp.Explore(main, interpMode, sizes, main.Pkg.Path(), args, *exploreFlag)
+				if failed == nil {
+					fmt.Fprintf(os.Stderr, "No failures in %d runs.\n", runs)
+					os.Exit(0)
+				}
+				fmt.Fprintf(os.Stderr, "Failure after %d runs; replaying with -sched=%v\n", runs, failed)
+				sched = failed
+			}
 			fmt.Fprintf(os.Stderr, "Running: %s\n", main.Pkg.Path())
+			if sched != nil {
+				os.Exit(interp.InterpretSchedule(main, interpMode, sizes, main.Pkg.Path(), args, sched))
+			}
 			os.Exit(interp.Interpret(main, interpMode, sizes, main.Pkg.Path(), args))
 		}
 		return fmt.Errorf("no main package")
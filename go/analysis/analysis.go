@@ -74,6 +74,22 @@ type Analyzer struct {
 	// FactTypes establishes a "vertical" dependency between
 	// analysis passes (same analyzer, different packages).
 	FactTypes []Fact
+
+	// RunProgram, if non-nil, applies the analyzer to a whole
+	// program, after Run has been applied to each of its packages.
+	// It receives the results of Run on each package, and the
+	// facts exported by all of them, allowing it to report
+	// findings that no single package can establish, such as an
+	// exported function that is never called.
+	// It returns an error if the analyzer failed.
+	//
+	// RunProgram is called only by drivers that analyze a whole
+	// program at once, such as those based on the checker package,
+	// and gopls, which applies it to the workspace packages; see
+	// [ProgramPass]. Unitchecker (as invoked by 'go vet') analyzes
+	// each package separately and never calls RunProgram, so its
+	// findings are not reported there.
+	RunProgram func(*ProgramPass) error
 }
 
 func (a *Analyzer) String() string { return a.Name }
@@ -0,0 +1,447 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file defines the persistent cache of analysis outcomes.
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+	"github.com/tinygo-org/tinygo/x-tools/go/types/objectpath"
+)
+
+// A Cache is a persistent store of the outcomes of analysis actions,
+// keyed by a hash of their inputs. See [Options.Cache].
+//
+// Its methods must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored under key,
+	// or false if there is none.
+	Get(key [sha256.Size]byte) ([]byte, bool)
+
+	// Set stores value under key. The cache may discard it.
+	Set(key [sha256.Size]byte, value []byte) error
+}
+
+// NewFileCache returns a Cache that stores each value in a file within
+// the specified directory, which is created as needed.
+//
+// The cache is never trimmed; remove the directory to reclaim space.
+func NewFileCache(dir string) Cache { return fileCache{dir} }
+
+type fileCache struct{ dir string }
+
+func (c fileCache) filename(key [sha256.Size]byte) string {
+	hex := hex.EncodeToString(key[:])
+	return filepath.Join(c.dir, hex[:2], hex)
+}
+
+func (c fileCache) Get(key [sha256.Size]byte) ([]byte, bool) {
+	data, err := os.ReadFile(c.filename(key))
+	return data, err == nil
+}
+
+func (c fileCache) Set(key [sha256.Size]byte, value []byte) error {
+	filename := c.filename(key)
+	if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+		return err
+	}
+	// Write a temporary file and rename it, so that
+	// readers never observe an incomplete value.
+	f, err := os.CreateTemp(filepath.Dir(filename), "tmp-*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(value)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(f.Name()) // ignore error
+	}
+	return err
+}
+
+// cacheVersion identifies the encoding of cache keys and values.
+// Change it whenever either changes.
+const cacheVersion = "analysis-cache-v1"
+
+// executableHash returns a hash of the running executable, which
+// identifies the version of all analyzers linked into it.
+var executableHash = sync.OnceValues(func() ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	exe, err := os.Executable()
+	if err != nil {
+		return hash, err
+	}
+	f, err := os.Open(exe)
+	if err != nil {
+		return hash, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return hash, err
+	}
+	h.Sum(hash[:0])
+	return hash, nil
+})
+
+// prepareCache computes the cache key of each action in the graph
+// and looks up its outcome in the cache.
+//
+// An action whose outcome is cached is not executed unless its result
+// is needed by a dependent action that is executed, or by the
+// RunProgram function of its analyzer: a cache records only the
+// diagnostics and facts of an action, not its result.
+func prepareCache(roots []*Action, cache Cache) {
+	exe, err := executableHash()
+	if err != nil {
+		return // can't identify the analyzers; don't cache
+	}
+
+	programs := programAnalyzers(roots)
+	var postorder []*Action
+	pkgKeys := make(map[*packages.Package]*[sha256.Size]byte)
+	forEach(roots, func(act *Action) error {
+		postorder = append(postorder, act)
+
+		// Results of whole-program analyzers are needed by RunProgram.
+		if act.Analyzer.ResultType != nil && slices.Contains(programs, act.Analyzer) {
+			act.resultNeeded = true
+		}
+
+		pkgKey := packageKey(act.Package, pkgKeys)
+		if pkgKey == nil {
+			return nil // not cacheable
+		}
+		h := sha256.New()
+		fmt.Fprintf(h, "%s\n%x\n%s\n", cacheVersion, exe, act.Analyzer.Name)
+		act.Analyzer.Flags.VisitAll(func(f *flag.Flag) {
+			fmt.Fprintf(h, "flag %s=%s\n", f.Name, f.Value)
+		})
+		fmt.Fprintf(h, "package %x\n", *pkgKey)
+		for _, dep := range act.Deps {
+			if dep.cacheKey == nil {
+				return nil // dependency is not cacheable
+			}
+			fmt.Fprintf(h, "dep %x\n", *dep.cacheKey)
+		}
+		act.cacheKey = new([sha256.Size]byte)
+		h.Sum(act.cacheKey[:0])
+		if data, ok := cache.Get(*act.cacheKey); ok {
+			act.cached = act.decodeOutcome(data) // nil if invalid
+		}
+		return nil
+	})
+
+	// Visit dependents before their dependencies,
+	// marking the actions whose results are needed.
+	for i := len(postorder) - 1; i >= 0; i-- {
+		act := postorder[i]
+		if act.cached == nil || act.resultNeeded {
+			for _, dep := range act.Deps {
+				if dep.Package == act.Package {
+					dep.resultNeeded = true
+				}
+			}
+		}
+	}
+}
+
+// packageKey returns a hash of the inputs to an analysis of the
+// package, namely its metadata and source files and (transitively)
+// those of its dependencies, or nil if it is not cacheable.
+func packageKey(pkg *packages.Package, keys map[*packages.Package]*[sha256.Size]byte) *[sha256.Size]byte {
+	if key, ok := keys[pkg]; ok {
+		return key
+	}
+	keys[pkg] = nil // (in case of cycles)
+	if pkg.IllTyped || len(pkg.Errors) > 0 || len(pkg.TypeErrors) > 0 {
+		return nil // outcome may depend on the errors
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n%#v\n", pkg.ID, pkg.PkgPath, pkg.Name, pkg.TypesSizes)
+	if mod := pkg.Module; mod != nil {
+		fmt.Fprintf(h, "module %s %s %s\n", mod.Path, mod.Version, mod.GoVersion)
+	}
+	for _, files := range [][]string{pkg.CompiledGoFiles, pkg.OtherFiles, pkg.IgnoredFiles} {
+		for _, filename := range files {
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return nil
+			}
+			fmt.Fprintf(h, "file %s %x\n", filename, sha256.Sum256(data))
+		}
+		fmt.Fprintf(h, "\n")
+	}
+	paths := make([]string, 0, len(pkg.Imports))
+	for path := range pkg.Imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		depKey := packageKey(pkg.Imports[path], keys)
+		if depKey == nil {
+			return nil
+		}
+		fmt.Fprintf(h, "import %s %x\n", path, *depKey)
+	}
+
+	key := new([sha256.Size]byte)
+	h.Sum(key[:0])
+	keys[pkg] = key
+	return key
+}
+
+// A cacheEntry is the Gob encoding of the outcome of an action:
+// its diagnostics and the facts it exported.
+type cacheEntry struct {
+	Diagnostics  []cachedDiagnostic
+	ObjectFacts  []cachedObjectFact
+	PackageFacts []analysis.Fact
+}
+
+type cachedObjectFact struct {
+	Object objectpath.Path
+	Fact   analysis.Fact
+}
+
+type cachedDiagnostic struct {
+	Pos, End       cachedPos
+	Category       string
+	Message        string
+	URL            string
+	SuggestedFixes []cachedFix
+	Related        []cachedRelated
+}
+
+type cachedFix struct {
+	Message   string
+	TextEdits []cachedEdit
+}
+
+type cachedEdit struct {
+	Pos, End cachedPos
+	NewText  []byte
+}
+
+type cachedRelated struct {
+	Pos, End cachedPos
+	Message  string
+}
+
+// A cachedPos is a token.Pos, recorded as a file offset.
+type cachedPos struct {
+	File   string // "" => token.NoPos
+	Offset int
+}
+
+// saveToCache records the outcome of the action in the cache, if it
+// is cacheable.
+func (act *Action) saveToCache() {
+	if act.cacheKey == nil || act.Err != nil {
+		return
+	}
+	fset := act.Package.Fset
+	encodePos := func(pos token.Pos) cachedPos {
+		if file := fset.File(pos); file != nil {
+			return cachedPos{file.Name(), file.Offset(pos)}
+		}
+		return cachedPos{}
+	}
+
+	var entry cacheEntry
+	for _, diag := range act.Diagnostics {
+		cdiag := cachedDiagnostic{
+			Pos:      encodePos(diag.Pos),
+			End:      encodePos(diag.End),
+			Category: diag.Category,
+			Message:  diag.Message,
+			URL:      diag.URL,
+		}
+		for _, fix := range diag.SuggestedFixes {
+			cfix := cachedFix{Message: fix.Message}
+			for _, edit := range fix.TextEdits {
+				cfix.TextEdits = append(cfix.TextEdits, cachedEdit{encodePos(edit.Pos), encodePos(edit.End), edit.NewText})
+			}
+			cdiag.SuggestedFixes = append(cdiag.SuggestedFixes, cfix)
+		}
+		for _, rel := range diag.Related {
+			cdiag.Related = append(cdiag.Related, cachedRelated{encodePos(rel.Pos), encodePos(rel.End), rel.Message})
+		}
+		entry.Diagnostics = append(entry.Diagnostics, cdiag)
+	}
+
+	// Record the facts exported by this action: those about
+	// this package and its objects. Facts about objects that
+	// are not addressable from the package API are of no use
+	// to dependents, and are discarded.
+	var encoder objectpath.Encoder
+	for key, fact := range act.objectFacts {
+		if key.obj.Pkg() != act.Package.Types {
+			continue // inherited
+		}
+		if path, err := encoder.For(key.obj); err == nil {
+			entry.ObjectFacts = append(entry.ObjectFacts, cachedObjectFact{path, fact})
+		}
+	}
+	sort.Slice(entry.ObjectFacts, func(i, j int) bool {
+		x, y := entry.ObjectFacts[i], entry.ObjectFacts[j]
+		if x.Object != y.Object {
+			return x.Object < y.Object
+		}
+		return factType(x.Fact).String() < factType(y.Fact).String()
+	})
+	for key, fact := range act.packageFacts {
+		if key.pkg == act.Package.Types {
+			entry.PackageFacts = append(entry.PackageFacts, fact)
+		}
+	}
+	sort.Slice(entry.PackageFacts, func(i, j int) bool {
+		return factType(entry.PackageFacts[i]).String() < factType(entry.PackageFacts[j]).String()
+	})
+
+	registerFactTypes(act.Analyzer)
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return // e.g. a fact type that cannot be encoded
+	}
+	act.opts.Cache.Set(*act.cacheKey, buf.Bytes()) // ignore error
+}
+
+// A cachedOutcome is the decoded outcome of an action.
+type cachedOutcome struct {
+	diagnostics  []analysis.Diagnostic
+	objectFacts  map[objectFactKey]analysis.Fact
+	packageFacts map[packageFactKey]analysis.Fact
+}
+
+// decodeOutcome decodes the cached outcome of the action,
+// or returns nil if it is invalid.
+func (act *Action) decodeOutcome(data []byte) *cachedOutcome {
+	registerFactTypes(act.Analyzer)
+	var entry cacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil {
+		return nil // corrupt entry
+	}
+
+	// Find the files of each cached position. They are usually
+	// files of the package, but may be files of its dependencies,
+	// or files added by an analyzer, such as assembly files.
+	fset := act.Package.Fset
+	files := make(map[string]*token.File)
+	fset.Iterate(func(f *token.File) bool {
+		files[f.Name()] = f
+		return true
+	})
+	ok := true
+	decodePos := func(posn cachedPos) token.Pos {
+		if posn.File == "" {
+			return token.NoPos
+		}
+		file, found := files[posn.File]
+		if !found {
+			content, err := os.ReadFile(posn.File)
+			if err != nil {
+				ok = false
+				return token.NoPos
+			}
+			file = fset.AddFile(posn.File, -1, len(content))
+			file.SetLinesForContent(content)
+			files[posn.File] = file
+		}
+		if posn.Offset > file.Size() {
+			ok = false
+			return token.NoPos
+		}
+		return file.Pos(posn.Offset)
+	}
+
+	outcome := &cachedOutcome{
+		objectFacts:  make(map[objectFactKey]analysis.Fact),
+		packageFacts: make(map[packageFactKey]analysis.Fact),
+	}
+	for _, cdiag := range entry.Diagnostics {
+		diag := analysis.Diagnostic{
+			Pos:      decodePos(cdiag.Pos),
+			End:      decodePos(cdiag.End),
+			Category: cdiag.Category,
+			Message:  cdiag.Message,
+			URL:      cdiag.URL,
+		}
+		for _, cfix := range cdiag.SuggestedFixes {
+			fix := analysis.SuggestedFix{Message: cfix.Message}
+			for _, cedit := range cfix.TextEdits {
+				fix.TextEdits = append(fix.TextEdits, analysis.TextEdit{
+					Pos:     decodePos(cedit.Pos),
+					End:     decodePos(cedit.End),
+					NewText: cedit.NewText,
+				})
+			}
+			diag.SuggestedFixes = append(diag.SuggestedFixes, fix)
+		}
+		for _, crel := range cdiag.Related {
+			diag.Related = append(diag.Related, analysis.RelatedInformation{
+				Pos:     decodePos(crel.Pos),
+				End:     decodePos(crel.End),
+				Message: crel.Message,
+			})
+		}
+		outcome.diagnostics = append(outcome.diagnostics, diag)
+	}
+	if !ok {
+		return nil
+	}
+	for _, f := range entry.ObjectFacts {
+		obj, err := objectpath.Object(act.Package.Types, f.Object)
+		if err != nil {
+			return nil
+		}
+		outcome.objectFacts[objectFactKey{obj, factType(f.Fact)}] = f.Fact
+	}
+	for _, fact := range entry.PackageFacts {
+		outcome.packageFacts[packageFactKey{act.Package.Types, factType(fact)}] = fact
+	}
+	return outcome
+}
+
+// loadFromCache restores the outcome of the action from the cache,
+// adding its facts to those inherited from its dependencies.
+func (act *Action) loadFromCache() {
+	act.Diagnostics = act.cached.diagnostics
+	for key, fact := range act.cached.objectFacts {
+		act.objectFacts[key] = fact
+	}
+	for key, fact := range act.cached.packageFacts {
+		act.packageFacts[key] = fact
+	}
+}
+
+// registerFactTypes registers the analyzer's fact types with Gob,
+// so that they may be encoded within a cacheEntry.
+func registerFactTypes(a *analysis.Analyzer) {
+	for _, f := range a.FactTypes {
+		gob.Register(f)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/checker"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/inspect"
+	"github.com/tinygo-org/tinygo/x-tools/go/ast/inspector"
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+	"github.com/tinygo-org/tinygo/x-tools/internal/testenv"
+)
+
+func TestCache(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := t.TempDir()
+	writeFiles := func(files map[string]string) {
+		for name, content := range files {
+			filename := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	writeFiles(map[string]string{
+		"go.mod": "module example.com\ngo 1.22\n",
+		"a/a.go": "package a\n\nfunc F() {}\n",
+		"b/b.go": "package b\n\nimport \"example.com/a\"\n\nfunc G() { a.F() }\n",
+		"c/c.go": "package c\n\nimport \"example.com/b\"\n\nfunc I() { b.G() }\n",
+	})
+
+	cache := &memCache{m: make(map[[sha256.Size]byte][]byte)}
+	runs := make(map[string]int) // number of runs of calls analyzer, by package
+	var mu sync.Mutex
+	calls := &analysis.Analyzer{
+		Name:      "calls",
+		Doc:       "reports calls to functions with a fact",
+		Requires:  []*analysis.Analyzer{inspect.Analyzer},
+		FactTypes: []analysis.Fact{new(declFact)},
+		Run: func(pass *analysis.Pass) (any, error) {
+			mu.Lock()
+			runs[pass.Pkg.Path()]++
+			mu.Unlock()
+			inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+			inspect.Preorder([]ast.Node{(*ast.FuncDecl)(nil), (*ast.CallExpr)(nil)}, func(n ast.Node) {
+				switch n := n.(type) {
+				case *ast.FuncDecl:
+					obj := pass.TypesInfo.Defs[n.Name]
+					pass.ExportObjectFact(obj, &declFact{Pkg: pass.Pkg.Path()})
+				case *ast.CallExpr:
+					if id, ok := ast.Unparen(n.Fun).(*ast.SelectorExpr); ok {
+						var fact declFact
+						if obj, ok := pass.TypesInfo.Uses[id.Sel].(*types.Func); ok && pass.ImportObjectFact(obj, &fact) {
+							pass.Reportf(n.Pos(), "call of %s declared in %s", obj.Name(), fact.Pkg)
+						}
+					}
+				}
+			})
+			pass.ExportPackageFact(&declFact{Pkg: pass.Pkg.Path()})
+			return nil, nil
+		},
+	}
+
+	// analyze analyzes package c, and returns its diagnostics,
+	// and those of its dependencies, and its package facts.
+	analyze := func() string {
+		t.Helper()
+		cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: dir}
+		initial, err := packages.Load(cfg, "example.com/c")
+		if err != nil {
+			t.Fatal(err)
+		}
+		graph, err := checker.Analyze([]*analysis.Analyzer{calls}, initial, &checker.Options{Cache: cache})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out strings.Builder
+		for act := range graph.All() {
+			if act.Err != nil {
+				t.Fatalf("%s: %v", act, act.Err)
+			}
+			if act.Analyzer != calls {
+				continue
+			}
+			for _, diag := range act.Diagnostics {
+				posn := act.Package.Fset.Position(diag.Pos)
+				fmt.Fprintf(&out, "%s:%d:%d: %s\n", filepath.Base(posn.Filename), posn.Line, posn.Column, diag.Message)
+			}
+			var fact declFact
+			if act.PackageFact(act.Package.Types, &fact) {
+				fmt.Fprintf(&out, "%s: package fact %s\n", act.Package.PkgPath, fact.Pkg)
+			}
+		}
+		return out.String()
+	}
+
+	check := func(got, want string, wantRuns map[string]int) {
+		t.Helper()
+		if got != want {
+			t.Errorf("got diagnostics:\n%s\nwant:\n%s", got, want)
+		}
+		if fmt.Sprint(runs) != fmt.Sprint(wantRuns) {
+			t.Errorf("got runs %v, want %v", runs, wantRuns)
+		}
+		clear(runs)
+	}
+
+	// The first analysis populates the cache.
+	want := `example.com/a: package fact example.com/a
+b.go:5:12: call of F declared in example.com/a
+example.com/b: package fact example.com/b
+c.go:5:12: call of G declared in example.com/b
+example.com/c: package fact example.com/c
+`
+	check(analyze(), want, map[string]int{"example.com/a": 1, "example.com/b": 1, "example.com/c": 1})
+
+	// The second analysis is entirely cached.
+	check(analyze(), want, map[string]int{})
+
+	// A change to b causes b and its dependents to be reanalyzed.
+	writeFiles(map[string]string{
+		"b/b.go": "package b\n\nimport \"example.com/a\"\n\n// G calls F.\nfunc G() { a.F() }\n",
+	})
+	want = strings.Replace(want, "b.go:5:12", "b.go:6:12", 1)
+	check(analyze(), want, map[string]int{"example.com/b": 1, "example.com/c": 1})
+}
+
+type declFact struct{ Pkg string }
+
+func (*declFact) AFact() {}
+
+// memCache is a Cache in memory.
+type memCache struct {
+	mu sync.Mutex
+	m  map[[sha256.Size]byte][]byte
+}
+
+func (c *memCache) Get(key [sha256.Size]byte) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, ok := c.m[key]
+	return data, ok
+}
+
+func (c *memCache) Set(key [sha256.Size]byte, value []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.m[key] = value
+	return nil
+}
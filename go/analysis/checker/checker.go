@@ -30,6 +30,7 @@ package checker
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/gob"
 	"fmt"
 	"github.com/tinygo-org/tinygo/alt_go/types"
@@ -56,6 +57,19 @@ type Options struct {
 	SanityCheck bool      // check fact encoding is ok and deterministic
 	FactLog     io.Writer // if non-nil, log each exported fact to it
 
+	// Cache, if non-nil, is a persistent store of the diagnostics
+	// and facts of each action, keyed by the analyzer (including
+	// the executable that defines it, and its flags) and the
+	// content of the package and its dependencies. An action whose
+	// outcome is cached is not executed, and its Result is nil,
+	// unless its result is needed by another action that is
+	// executed. Packages with errors are not cached.
+	//
+	// The key does not include the content of files other than
+	// those listed by the package, such as those read by an
+	// analyzer using Pass.ReadFile.
+	Cache Cache
+
 	// TODO(adonovan): expose ReadFile so that an Overlay specified
 	// in the [packages.Config] can be communicated via
 	// Pass.ReadFile to each Analyzer.
@@ -135,6 +149,11 @@ type Action struct {
 	objectFacts  map[objectFactKey]analysis.Fact
 	packageFacts map[packageFactKey]analysis.Fact
 	inputs       map[*analysis.Analyzer]any
+
+	// cache state (if opts.Cache != nil)
+	cacheKey     *[sha256.Size]byte // nil => not cacheable
+	cached       *cachedOutcome     // outcome obtained from the cache, if any
+	resultNeeded bool               // result is needed by an executed action
 }
 
 func (act *Action) String() string {
@@ -151,6 +170,11 @@ func (act *Action) String() string {
 // On success, it returns a Graph of actions whose Roots hold one
 // item per (a, p) in the cross-product of analyzers and pkgs.
 //
+// Once the Run function of each analyzer has been applied to all the
+// packages, the RunProgram function of each analyzer that has one is
+// applied to the whole program; its diagnostics are added to the root
+// action of the package in which they are reported.
+//
 // If opts is nil, it is equivalent to new(Options).
 func Analyze(analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Options) (*Graph, error) {
 	if opts == nil {
@@ -213,9 +237,17 @@ func Analyze(analyzers []*analysis.Analyzer, pkgs []*packages.Package, opts *Opt
 		}
 	}
 
+	// Look up the outcomes of actions in the cache.
+	if opts.Cache != nil {
+		prepareCache(roots, opts.Cache)
+	}
+
 	// Execute the graph in parallel.
 	execAll(roots)
 
+	// Apply whole-program analyzers.
+	runPrograms(roots)
+
 	// Ensure that only root Results are visible to caller.
 	// (The others are considered temporary intermediaries.)
 	// TODO(adonovan): opt: clear them earlier, so we can
@@ -309,6 +341,12 @@ func (act *Action) execOnce() {
 		}
 	}
 
+	// Use the cached outcome if the result is not needed.
+	if act.cached != nil && !act.resultNeeded {
+		act.loadFromCache()
+		return
+	}
+
 	// Quick (nonexhaustive) check that the correct go/packages mode bits were used.
 	// (If there were errors, all bets are off.)
 	if pkg := act.Package; pkg.Errors == nil {
@@ -390,6 +428,10 @@ func (act *Action) execOnce() {
 	// Help detect (disallowed) calls after Run.
 	pass.ExportObjectFact = nil
 	pass.ExportPackageFact = nil
+
+	if act.opts.Cache != nil && act.cached == nil {
+		act.saveToCache()
+	}
 }
 
 // inheritFacts populates act.facts with
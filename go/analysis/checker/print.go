@@ -86,3 +86,24 @@ func writeJSONDiagnostics(w io.Writer, roots []*Action) error {
 	})
 	return tree.Print(w)
 }
+
+// PrintSARIF emits diagnostics in SARIF 2.1.0 form to w,
+// as the results of a run of the named tool.
+// The URIs of files within the root directory, if nonempty,
+// are relative to it.
+// As with PrintJSON, diagnostics are shown only for the root
+// nodes, but errors (if any) are shown for all dependencies.
+func (g *Graph) PrintSARIF(w io.Writer, tool, root string) error {
+	return writeSARIFDiagnostics(w, g.Roots, tool, root)
+}
+
+func writeSARIFDiagnostics(w io.Writer, roots []*Action, tool, root string) error {
+	sarif := analysisflags.NewSARIFLog(tool, root)
+	forEach(roots, func(act *Action) error {
+		if act.Err != nil || act.IsRoot {
+			sarif.Add(act.Package.Fset, act.Analyzer, act.Diagnostics, act.Err)
+		}
+		return nil
+	})
+	return sarif.Print(w)
+}
@@ -0,0 +1,156 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file defines the whole-program phase of analysis, in which the
+// RunProgram function of each analyzer is applied to all the packages
+// to which its Run function was applied.
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"os"
+	"slices"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/internal/analysisflags"
+	"github.com/tinygo-org/tinygo/x-tools/internal/analysisinternal"
+)
+
+// programAnalyzers returns the analyzers of the root actions that
+// have a RunProgram function, in order of first appearance.
+func programAnalyzers(roots []*Action) []*analysis.Analyzer {
+	var analyzers []*analysis.Analyzer
+	for _, act := range roots {
+		if act.Analyzer.RunProgram != nil && !slices.Contains(analyzers, act.Analyzer) {
+			analyzers = append(analyzers, act.Analyzer)
+		}
+	}
+	return analyzers
+}
+
+// runPrograms applies the RunProgram function of each analyzer of the
+// root actions that has one. It must be called after all the actions
+// have been executed, and before their results are discarded.
+//
+// A whole-program analysis is skipped if any of the actions of its
+// analyzer failed. If it fails, its error is recorded in the first root
+// action of its analyzer.
+func runPrograms(roots []*Action) {
+	for _, a := range programAnalyzers(roots) {
+		var (
+			actions []*Action // actions of a, in dependency order
+			failed  bool
+		)
+		forEach(roots, func(act *Action) error {
+			if act.Analyzer == a {
+				actions = append(actions, act)
+				failed = failed || act.Err != nil
+			}
+			return nil
+		})
+		if !failed {
+			runProgram(a, actions)
+		}
+	}
+}
+
+// runProgram applies the RunProgram function of analyzer a to the
+// packages of its actions. Each diagnostic is added to the root action
+// whose package contains its position; others are discarded.
+func runProgram(a *analysis.Analyzer, actions []*Action) {
+	fset := actions[0].Package.Fset
+	opts := actions[0].opts
+
+	var first *Action // first root action
+	rootOf := make(map[*token.File]*Action)
+	readable := make(map[string]bool)
+	for _, act := range actions {
+		pkg := act.Package
+		for _, f := range pkg.Syntax {
+			tokFile := fset.File(f.FileStart)
+			if act.IsRoot && rootOf[tokFile] == nil {
+				rootOf[tokFile] = act
+			}
+			readable[tokFile.Name()] = true
+		}
+		for _, filename := range slices.Concat(pkg.OtherFiles, pkg.IgnoredFiles) {
+			readable[filename] = true
+		}
+		if act.IsRoot && first == nil {
+			first = act
+		}
+	}
+
+	readFile := os.ReadFile
+	if opts.readFile != nil {
+		readFile = opts.readFile
+	}
+	pass := &analysis.ProgramPass{
+		Analyzer: a,
+		Fset:     fset,
+		Report: func(d analysis.Diagnostic) {
+			// Assert that SuggestedFixes are well formed.
+			if err := analysisinternal.ValidateFixes(fset, a, d.SuggestedFixes); err != nil {
+				panic(err)
+			}
+			act := rootOf[fset.File(d.Pos)]
+			if act == nil {
+				return // not in a root package
+			}
+			url, err := analysisflags.ResolveURL(a, d)
+			if err != nil {
+				panic(err)
+			}
+			d.URL = url
+			act.Diagnostics = append(act.Diagnostics, d)
+		},
+		ReadFile: func(filename string) ([]byte, error) {
+			if !readable[filename] {
+				return nil, fmt.Errorf("ProgramPass.ReadFile: %s is not among the files of the program", filename)
+			}
+			return readFile(filename)
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			seen := make(map[objectFactKey]bool)
+			var facts []analysis.ObjectFact
+			for _, act := range actions {
+				for k, fact := range act.objectFacts {
+					if !seen[k] {
+						seen[k] = true
+						facts = append(facts, analysis.ObjectFact{Object: k.obj, Fact: fact})
+					}
+				}
+			}
+			return facts
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			seen := make(map[packageFactKey]bool)
+			var facts []analysis.PackageFact
+			for _, act := range actions {
+				for k, fact := range act.packageFacts {
+					if !seen[k] {
+						seen[k] = true
+						facts = append(facts, analysis.PackageFact{Package: k.pkg, Fact: fact})
+					}
+				}
+			}
+			return facts
+		},
+	}
+	for _, act := range actions {
+		pass.Packages = append(pass.Packages, &analysis.PackageResult{
+			Pkg:       act.Package.Types,
+			Files:     act.Package.Syntax,
+			TypesInfo: act.Package.TypesInfo,
+			Root:      act.IsRoot,
+			Result:    act.Result,
+		})
+	}
+
+	if err := a.RunProgram(pass); err != nil {
+		first.Err = fmt.Errorf("whole-program analysis failed: %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker_test
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/checker"
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+	"github.com/tinygo-org/tinygo/x-tools/go/types/typeutil"
+	"github.com/tinygo-org/tinygo/x-tools/internal/testenv"
+)
+
+func TestRunProgram(t *testing.T) {
+	testenv.NeedsGoPackages(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"go.mod": "module example.com\ngo 1.22\n",
+		"a/a.go": "package a\n\nfunc F() {}\n\nfunc G() {}\n",
+		"b/b.go": "package b\n\nimport \"example.com/a\"\n\nfunc H() { a.F(); i() }\n\nfunc I() {}\n\nfunc i() {}\n",
+	} {
+		filename := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(filename), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The uncalled analyzer reports exported functions that are
+	// not called anywhere in the program. Its Run function returns
+	// the set of functions called by each package, and exports a
+	// package fact recording the package's exported functions.
+	var packageFacts []string
+	uncalled := &analysis.Analyzer{
+		Name:       "uncalled",
+		Doc:        "reports exported functions that are never called",
+		ResultType: reflect.TypeOf(map[*types.Func]bool(nil)),
+		FactTypes:  []analysis.Fact{new(funcsFact)},
+		Run: func(pass *analysis.Pass) (any, error) {
+			called := make(map[*types.Func]bool)
+			fact := new(funcsFact)
+			for _, file := range pass.Files {
+				ast.Inspect(file, func(n ast.Node) bool {
+					switch n := n.(type) {
+					case *ast.FuncDecl:
+						if n.Name.IsExported() {
+							fact.Funcs = append(fact.Funcs, n.Name.Name)
+						}
+					case *ast.CallExpr:
+						if fn, ok := typeutil.Callee(pass.TypesInfo, n).(*types.Func); ok {
+							called[fn] = true
+						}
+					}
+					return true
+				})
+			}
+			pass.ExportPackageFact(fact)
+			return called, nil
+		},
+		RunProgram: func(pass *analysis.ProgramPass) error {
+			called := make(map[*types.Func]bool)
+			for _, pkg := range pass.Packages {
+				for fn := range pkg.Result.(map[*types.Func]bool) {
+					called[fn] = true
+				}
+			}
+			for _, pkg := range pass.Packages {
+				for _, file := range pkg.Files {
+					for _, decl := range file.Decls {
+						if decl, ok := decl.(*ast.FuncDecl); ok && decl.Name.IsExported() {
+							if fn := pkg.TypesInfo.Defs[decl.Name].(*types.Func); !called[fn] {
+								pass.Reportf(decl.Name.Pos(), "%s is never called", fn.FullName())
+							}
+						}
+					}
+				}
+			}
+			packageFacts = nil
+			for _, f := range pass.AllPackageFacts() {
+				packageFacts = append(packageFacts, fmt.Sprintf("%s: %v", f.Package.Path(), f.Fact.(*funcsFact).Funcs))
+			}
+			return nil
+		},
+	}
+
+	cache := &memCache{m: make(map[[sha256.Size]byte][]byte)}
+	for i := range 2 { // the second analysis uses the cache
+		cfg := &packages.Config{Mode: packages.LoadAllSyntax, Dir: dir}
+		initial, err := packages.Load(cfg, "example.com/b")
+		if err != nil {
+			t.Fatal(err)
+		}
+		graph, err := checker.Analyze([]*analysis.Analyzer{uncalled}, initial, &checker.Options{Cache: cache})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Only the diagnostics in the root package b are reported.
+		var got strings.Builder
+		for _, act := range graph.Roots {
+			if act.Err != nil {
+				t.Fatalf("%s: %v", act, act.Err)
+			}
+			for _, diag := range act.Diagnostics {
+				posn := act.Package.Fset.Position(diag.Pos)
+				fmt.Fprintf(&got, "%s:%d:%d: %s\n", filepath.Base(posn.Filename), posn.Line, posn.Column, diag.Message)
+			}
+		}
+		want := "b.go:5:6: example.com/b.H is never called\n" +
+			"b.go:7:6: example.com/b.I is never called\n"
+		if got.String() != want {
+			t.Errorf("analysis %d: got diagnostics:\n%s\nwant:\n%s", i, got.String(), want)
+		}
+
+		if got, want := strings.Join(slices.Sorted(slices.Values(packageFacts)), "; "), "example.com/a: [F G]; example.com/b: [H I]"; got != want {
+			t.Errorf("analysis %d: got package facts %q, want %q", i, got, want)
+		}
+	}
+}
+
+type funcsFact struct{ Funcs []string }
+
+func (*funcsFact) AFact() {}
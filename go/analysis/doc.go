@@ -279,6 +279,27 @@ this fact is built in to the analyzer so that it correctly checks
 calls to log.Printf even when run in a driver that does not apply
 it to standard packages. We would like to remove this limitation in future.
 
+# Whole-program analysis
+
+Facts flow only from a package to the packages that import it, so no
+single pass can establish a property of the whole program, such as
+that an exported function is never called. An Analyzer may therefore
+provide a RunProgram function, which a driver calls once, after Run
+has been applied to every package, with a ProgramPass:
+
+	type ProgramPass struct {
+		Analyzer *Analyzer
+		Fset     *token.FileSet
+		Packages []*PackageResult // Run's result on each package
+		Report   func(Diagnostic)
+		...
+	}
+
+Only drivers that analyze a whole program, such as those based on the
+checker package, and gopls, which treats the workspace packages as the
+program, call RunProgram; unitchecker ignores it, so an analyzer should
+not rely on it for its per-package findings.
+
 # Testing an Analyzer
 
 The analysistest subpackage provides utilities for testing an Analyzer.
@@ -0,0 +1,326 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags
+
+// This file defines the analyzer configuration file (-config).
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+)
+
+// A Config is an analyzer configuration file, which records the
+// settings of a project in a form that can be checked into its
+// repository. It is a JSON object such as:
+//
+//	{
+//		"analyzers": {"fieldalignment": false},
+//		"flags": {"printf": {"funcs": "Logf,Warnf"}},
+//		"overrides": [
+//			{
+//				"packages": ["example.com/strict/..."],
+//				"flags": {"printf": {"funcs": "Logf,Warnf,Debugf"}}
+//			},
+//			{
+//				"files": ["*.pb.go", "internal/gen/*.go"],
+//				"analyzers": {"printf": false}
+//			}
+//		]
+//	}
+//
+// The "analyzers" object enables or disables analyzers, as if by the
+// -NAME flags of a multichecker, and the "flags" object sets the
+// flags of each analyzer, as if by its -NAME.FLAG flags. Settings on
+// the command line take precedence: if any -NAME flag is set, the
+// configuration does not enable or disable any analyzer, and a flag
+// set on the command line is not set by the configuration.
+//
+// Each element of "overrides" changes these settings for the packages
+// whose path matches one of its "packages" patterns (as used by the go
+// command, such as "example.com/a/..."), or, if "files" is specified,
+// for the diagnostics in files that match one of its globs (as used by
+// [path.Match], relative to the directory of the configuration file,
+// or, for globs without a slash, the base name of the file). Later
+// overrides take precedence over earlier ones. An override of files
+// may only disable analyzers.
+type Config struct {
+	Analyzers map[string]bool           `json:"analyzers,omitempty"`
+	Flags     map[string]map[string]any `json:"flags,omitempty"`
+	Overrides []ConfigOverride          `json:"overrides,omitempty"`
+
+	dir             string          // directory of the file
+	multi           bool            // analyzers are named in flags (-NAME.FLAG)
+	enabled         map[string]bool // names of analyzers enabled for all packages
+	commandLine     map[string]bool // names of flags set on the command line
+	ignoreAnalyzers bool            // analyzers were enabled on the command line
+}
+
+// A ConfigOverride is an element of the "overrides" of a [Config].
+type ConfigOverride struct {
+	Packages  []string                  `json:"packages,omitempty"`
+	Files     []string                  `json:"files,omitempty"`
+	Analyzers map[string]bool           `json:"analyzers,omitempty"`
+	Flags     map[string]map[string]any `json:"flags,omitempty"`
+
+	packages []*regexp.Regexp
+}
+
+// ReadConfig reads the named configuration file, and checks that
+// it mentions only the specified analyzers and their flags.
+// If multi, flags are named -NAME.FLAG, as in a multichecker.
+func ReadConfig(filename string, analyzers []*analysis.Analyzer, multi bool) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	cfg := new(Config)
+	if err := dec.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %v", filename, err)
+	}
+	dir, err := filepath.Abs(filepath.Dir(filename))
+	if err != nil {
+		return nil, err
+	}
+	cfg.dir = dir
+	cfg.multi = multi
+	if err := cfg.check(analyzers); err != nil {
+		return nil, fmt.Errorf("invalid configuration file %s: %v", filename, err)
+	}
+	return cfg, nil
+}
+
+// check checks the names of analyzers and flags in the configuration,
+// and compiles its package patterns.
+func (cfg *Config) check(analyzers []*analysis.Analyzer) error {
+	byName := make(map[string]*analysis.Analyzer)
+	for _, a := range analyzers {
+		byName[a.Name] = a
+	}
+	checkAnalyzers := func(enabled map[string]bool) error {
+		for name := range enabled {
+			if byName[name] == nil {
+				return fmt.Errorf("unknown analyzer %q", name)
+			}
+		}
+		return nil
+	}
+	checkFlags := func(flags map[string]map[string]any) error {
+		for name, values := range flags {
+			a := byName[name]
+			if a == nil {
+				return fmt.Errorf("unknown analyzer %q", name)
+			}
+			for flagName, value := range values {
+				if a.Flags.Lookup(flagName) == nil {
+					return fmt.Errorf("analyzer %q has no flag %q", name, flagName)
+				}
+				switch value.(type) {
+				case string, bool, float64:
+				default:
+					return fmt.Errorf("flag %q of analyzer %q: value %v is not a string, boolean, or number", flagName, name, value)
+				}
+			}
+		}
+		return nil
+	}
+
+	if err := checkAnalyzers(cfg.Analyzers); err != nil {
+		return err
+	}
+	if err := checkFlags(cfg.Flags); err != nil {
+		return err
+	}
+	for i := range cfg.Overrides {
+		o := &cfg.Overrides[i]
+		if err := checkAnalyzers(o.Analyzers); err != nil {
+			return err
+		}
+		if err := checkFlags(o.Flags); err != nil {
+			return err
+		}
+		if o.Files != nil {
+			if o.Flags != nil {
+				return fmt.Errorf("override %d: flags cannot be set for files", i)
+			}
+			for name, on := range o.Analyzers {
+				if on {
+					return fmt.Errorf("override %d: analyzer %q cannot be enabled for files", i, name)
+				}
+			}
+			for _, glob := range o.Files {
+				if _, err := path.Match(glob, ""); err != nil {
+					return fmt.Errorf("override %d: invalid glob %q", i, glob)
+				}
+			}
+		}
+		for _, pattern := range o.Packages {
+			o.packages = append(o.packages, packagePatternRegexp(pattern))
+		}
+	}
+	return nil
+}
+
+// packagePatternRegexp returns a regular expression for the package
+// pattern, in which "..." matches any string, and a final "/..."
+// also matches the empty string.
+func packagePatternRegexp(pattern string) *regexp.Regexp {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.ReplaceAll(re, `\.\.\.`, `.*`)
+	if rest, ok := strings.CutSuffix(re, `/.*`); ok {
+		re = rest + `(/.*)?`
+	}
+	return regexp.MustCompile(`^(` + re + `)$`)
+}
+
+// flagName returns the command-line name of the analyzer's flag.
+func (cfg *Config) flagName(analyzer, name string) string {
+	if cfg.multi {
+		return analyzer + "." + name
+	}
+	return name
+}
+
+// flagValue returns the command-line form of a flag value.
+func flagValue(value any) string {
+	return fmt.Sprint(value) // string, bool, or float64
+}
+
+// apply applies the settings of the configuration for all packages
+// to the command-line flags that were not set on the command line.
+func (cfg *Config) apply(enabled map[*analysis.Analyzer]*triState) error {
+	cfg.commandLine = make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { cfg.commandLine[f.Name] = true })
+
+	for a := range enabled {
+		if cfg.commandLine[a.Name] {
+			cfg.ignoreAnalyzers = true
+		}
+	}
+	if !cfg.ignoreAnalyzers {
+		for a, state := range enabled {
+			if on, ok := cfg.Analyzers[a.Name]; ok {
+				state.Set(fmt.Sprint(on))
+			}
+		}
+	}
+	for name, values := range cfg.Flags {
+		for flagName, value := range values {
+			if name := cfg.flagName(name, flagName); !cfg.commandLine[name] {
+				if err := flag.Set(name, flagValue(value)); err != nil {
+					return fmt.Errorf("invalid value for -%s in configuration file: %v", name, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// enables reports whether any override enables the named analyzer.
+func (cfg *Config) enables(name string) bool {
+	if cfg.ignoreAnalyzers {
+		return false
+	}
+	for _, o := range cfg.Overrides {
+		if o.Analyzers[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesPackage reports whether the override applies to the package.
+func (o *ConfigOverride) matchesPackage(pkgPath string) bool {
+	if o.packages == nil {
+		return true
+	}
+	return slices.ContainsFunc(o.packages, func(rx *regexp.Regexp) bool { return rx.MatchString(pkgPath) })
+}
+
+// matchesFile reports whether the override applies to the file.
+func (o *ConfigOverride) matchesFile(dir, filename string) bool {
+	names := []string{filepath.Base(filename)}
+	if rel, err := filepath.Rel(dir, filename); err == nil && filepath.IsLocal(rel) {
+		names = append(names, filepath.ToSlash(rel))
+	}
+	for _, glob := range o.Files {
+		for _, name := range names {
+			if strings.Contains(glob, "/") != strings.Contains(name, "/") {
+				continue // match globs with a slash against relative names only
+			}
+			if ok, _ := path.Match(glob, name); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Enabled reports whether the named analyzer is enabled for the
+// package with the given path, and, if filename is nonempty, for the
+// diagnostics in the named file.
+func (cfg *Config) Enabled(analyzer, pkgPath, filename string) bool {
+	enabled := cfg.enabled[analyzer]
+	if cfg.ignoreAnalyzers {
+		return enabled // the command line takes precedence
+	}
+	for _, o := range cfg.Overrides {
+		on, ok := o.Analyzers[analyzer]
+		if !ok || !o.matchesPackage(pkgPath) {
+			continue
+		}
+		if o.Files != nil && (filename == "" || !o.matchesFile(cfg.dir, filename)) {
+			continue
+		}
+		enabled = on
+	}
+	return enabled
+}
+
+// PackageFlags returns the values of the flags set by the overrides
+// that apply to the package with the given path, by command-line
+// name. Flags set on the command line are not included.
+func (cfg *Config) PackageFlags(pkgPath string) map[string]string {
+	flags := make(map[string]string)
+	for _, o := range cfg.Overrides {
+		if o.Files != nil || !o.matchesPackage(pkgPath) {
+			continue
+		}
+		for name, values := range o.Flags {
+			for flagName, value := range values {
+				if name := cfg.flagName(name, flagName); !cfg.commandLine[name] {
+					flags[name] = flagValue(value)
+				}
+			}
+		}
+	}
+	return flags
+}
+
+// OverriddenFlags returns the command-line names of all the flags set
+// by overrides.
+func (cfg *Config) OverriddenFlags() []string {
+	var names []string
+	for _, o := range cfg.Overrides {
+		for name, values := range o.Flags {
+			for flagName := range values {
+				names = append(names, cfg.flagName(name, flagName))
+			}
+		}
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
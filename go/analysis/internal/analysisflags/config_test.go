@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/internal/analysisflags"
+)
+
+func TestReadConfig(t *testing.T) {
+	a := &analysis.Analyzer{Name: "a", Doc: "a"}
+	a.Flags.String("funcs", "", "funcs")
+	b := &analysis.Analyzer{Name: "b", Doc: "b"}
+	analyzers := []*analysis.Analyzer{a, b}
+
+	dir := t.TempDir()
+	for _, test := range []struct {
+		config string
+		want   string // error should contain want; "" => no error
+	}{
+		{`{"analyzers": {"a": false}, "flags": {"a": {"funcs": "F"}}}`, ""},
+		{`{"overrides": [{"packages": ["x/..."], "analyzers": {"b": true}, "flags": {"a": {"funcs": "F"}}}]}`, ""},
+		{`{"overrides": [{"files": ["*.pb.go"], "analyzers": {"a": false}}]}`, ""},
+		{`{"analyzer": {}}`, `unknown field "analyzer"`},
+		{`{"analyzers": {"c": true}}`, `unknown analyzer "c"`},
+		{`{"flags": {"b": {"funcs": "F"}}}`, `analyzer "b" has no flag "funcs"`},
+		{`{"flags": {"a": {"funcs": ["F"]}}}`, `is not a string, boolean, or number`},
+		{`{"overrides": [{"files": ["*.go"], "analyzers": {"a": true}}]}`, `analyzer "a" cannot be enabled for files`},
+		{`{"overrides": [{"files": ["*.go"], "flags": {"a": {"funcs": "F"}}}]}`, `flags cannot be set for files`},
+		{`{"overrides": [{"files": ["["], "analyzers": {"a": false}}]}`, `invalid glob "["`},
+	} {
+		filename := filepath.Join(dir, "config.json")
+		if err := os.WriteFile(filename, []byte(test.config), 0666); err != nil {
+			t.Fatal(err)
+		}
+		_, err := analysisflags.ReadConfig(filename, analyzers, true)
+		if test.want == "" {
+			if err != nil {
+				t.Errorf("ReadConfig(%s): unexpected error: %v", test.config, err)
+			}
+		} else if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("ReadConfig(%s) = %v, want error containing %q", test.config, err, test.want)
+		}
+	}
+}
+
+func TestConfigOverrides(t *testing.T) {
+	a := &analysis.Analyzer{Name: "a", Doc: "a"}
+	a.Flags.String("funcs", "", "funcs")
+	b := &analysis.Analyzer{Name: "b", Doc: "b"}
+
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(filename, []byte(`{
+		"overrides": [
+			{"packages": ["example.com/x/..."], "analyzers": {"b": true}, "flags": {"a": {"funcs": "F"}}},
+			{"packages": ["example.com/x/y"], "flags": {"a": {"funcs": "G"}}},
+			{"files": ["*.pb.go", "gen/*.go"], "analyzers": {"a": false}}
+		]
+	}`), 0666); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := analysisflags.ReadConfig(filename, []*analysis.Analyzer{a, b}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Analyzers enabled by overrides are enabled only for the
+	// matching packages. (Analyzers enabled for all packages are
+	// determined by Parse.)
+	for _, test := range []struct {
+		analyzer, pkgPath, filename string
+		want                        bool
+	}{
+		{"b", "example.com/x", "", true},
+		{"b", "example.com/x/y", "", true},
+		{"b", "example.com/xy", "", false},
+		{"b", "example.com/z", "", false},
+	} {
+		if got := cfg.Enabled(test.analyzer, test.pkgPath, test.filename); got != test.want {
+			t.Errorf("Enabled(%q, %q, %q) = %t, want %t", test.analyzer, test.pkgPath, test.filename, got, test.want)
+		}
+	}
+
+	for pkgPath, want := range map[string]string{
+		"example.com/x":   "map[a.funcs:F]",
+		"example.com/x/y": "map[a.funcs:G]",
+		"example.com/z":   "map[]",
+	} {
+		if got := fmt.Sprint(cfg.PackageFlags(pkgPath)); got != want {
+			t.Errorf("PackageFlags(%q) = %s, want %s", pkgPath, got, want)
+		}
+	}
+	if got := strings.Join(cfg.OverriddenFlags(), " "); got != "a.funcs" {
+		t.Errorf("OverriddenFlags() = %s, want a.funcs", got)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"io"
 	"log"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -25,9 +26,18 @@ import (
 // flags common to all {single,multi,unit}checkers.
 var (
 	JSON    = false // -json
+	SARIF   = false // -sarif
 	Context = -1    // -c=N: if N>0, display offending line plus N lines of context
 )
 
+// flags of {single,multi}checkers.
+var (
+	ConfigFile = "" // -config=FILE
+
+	// Configuration is the configuration read from ConfigFile, if any.
+	Configuration *Config
+)
+
 // Parse creates a flag for each of the analyzer's flags,
 // including (in multi mode) a flag named after the analyzer,
 // parses the flags, then filters and returns the list of
@@ -41,6 +51,8 @@ var (
 // only reachable from dropped analyzers.
 // This is not a particularly elegant API, but this is an internal package.
 func Parse(analyzers []*analysis.Analyzer, multi bool) []*analysis.Analyzer {
+	all := analyzers
+
 	// Connect each analysis flag to the command line as -analysis.flag.
 	enabled := make(map[*analysis.Analyzer]*triState)
 	for _, a := range analyzers {
@@ -73,7 +85,9 @@ func Parse(analyzers []*analysis.Analyzer, multi bool) []*analysis.Analyzer {
 
 	// flags common to all checkers
 	flag.BoolVar(&JSON, "json", JSON, "emit JSON output")
+	flag.BoolVar(&SARIF, "sarif", SARIF, "emit SARIF 2.1.0 output (under go vet, one log per package; run the analyzers' standalone command for a single log)")
 	flag.IntVar(&Context, "c", Context, `display offending line with this many lines of context`)
+	flag.StringVar(&ConfigFile, "config", ConfigFile, "read analyzer settings from this JSON configuration file")
 
 	// Add shims for legacy vet flags to enable existing
 	// scripts that run vet to continue to work.
@@ -90,12 +104,28 @@ func Parse(analyzers []*analysis.Analyzer, multi bool) []*analysis.Analyzer {
 
 	flag.Parse() // (ExitOnError)
 
+	if JSON && SARIF {
+		log.Fatalf("-json and -sarif are mutually exclusive")
+	}
+
 	// -flags: print flags so that go vet knows which ones are legitimate.
 	if *printflags {
 		printFlags()
 		os.Exit(0)
 	}
 
+	// -config: apply the settings of the configuration file.
+	if ConfigFile != "" {
+		cfg, err := ReadConfig(ConfigFile, analyzers, multi)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := cfg.apply(enabled); err != nil {
+			log.Fatal(err)
+		}
+		Configuration = cfg
+	}
+
 	everything := expand(analyzers)
 
 	// If any -NAME flag is true,  run only those analyzers. Otherwise,
@@ -129,6 +159,18 @@ func Parse(analyzers []*analysis.Analyzer, multi bool) []*analysis.Analyzer {
 		}
 	}
 
+	// Keep the analyzers that the configuration enables for some
+	// packages, and record those enabled for all of them.
+	if cfg := Configuration; cfg != nil {
+		cfg.enabled = make(map[string]bool)
+		for _, a := range analyzers {
+			cfg.enabled[a.Name] = true
+		}
+		analyzers = slices.DeleteFunc(slices.Clone(all), func(a *analysis.Analyzer) bool {
+			return !cfg.enabled[a.Name] && !cfg.enables(a.Name)
+		})
+	}
+
 	// Register fact types of skipped analyzers
 	// in case we encounter them in imported files.
 	kept := expand(analyzers)
@@ -167,10 +209,13 @@ func printFlags() {
 	var flags []jsonFlag = nil
 	flag.VisitAll(func(f *flag.Flag) {
 		// Don't report {single,multi}checker debugging
-		// flags or fix as these have no effect on unitchecker
-		// (as invoked by 'go vet').
+		// flags, fix flags, baselines, //nolint suppression, the
+		// cache, or the configuration file as these have no
+		// effect on unitchecker (as invoked by 'go vet').
 		switch f.Name {
-		case "debug", "cpuprofile", "memprofile", "trace", "fix":
+		case "debug", "cpuprofile", "memprofile", "trace", "fix", "config",
+			"fixanalyzers", "fixmessage", "fixfiles", "interactive",
+			"baseline", "writebaseline", "nolint", "cache":
 			return
 		}
 
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
 	"strings"
 	"testing"
@@ -40,6 +41,21 @@ func TestExec(t *testing.T) {
 		panic("unreachable")
 	}
 
+	// Configuration files.
+	dir := t.TempDir()
+	writeConfig := func(name, content string) string {
+		filename := filepath.Join(dir, name)
+		if err := os.WriteFile(filename, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return filename
+	}
+	disable := writeConfig("disable.json", `{"analyzers": {"a2": false}}`)
+	override := writeConfig("override.json", `{
+		"analyzers": {"a1": true},
+		"overrides": [{"packages": ["example.com/..."], "analyzers": {"a3": true}}]
+	}`)
+
 	for _, test := range []struct {
 		flags string
 		want  string // output should contain want
@@ -51,6 +67,9 @@ func TestExec(t *testing.T) {
 		{"-a1=1 -a3=1", "[a1 a3]"},
 		{"-a1=1 -a3=0", "[a1]"},
 		{"-V=full", "analysisflags.test version devel"},
+		{"-config=" + disable, "[a1 a3]"},
+		{"-config=" + override, "[a1 a3]"},
+		{"-config=" + override + " -a2", "[a2]"},
 	} {
 		cmd := exec.Command(progname, "-test.run=TestExec")
 		cmd.Env = append(os.Environ(), "ANALYSISFLAGS_CHILD=1", "FLAGS="+test.flags)
@@ -0,0 +1,368 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags
+
+// This file defines the SARIF output of analysis drivers.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifSrcRoot = "%SRCROOT%" // base ID of URIs relative to the root directory
+)
+
+// A SARIFLog accumulates the results of analyses, as a SARIF 2.1.0
+// log of a single run of an analysis tool.
+//
+// Each analyzer is a rule, described by its Name, Doc, and URL. Each
+// diagnostic is a result, whose locations are regions of files, given
+// both by line and column (in Unicode code points) and by byte offset.
+// Related information is reported as related locations, and suggested
+// fixes as fix objects. Analysis errors are reported as notifications.
+type SARIFLog struct {
+	tool  string // name of the tool
+	root  string // directory of files with relative URIs ("" => none)
+	rules []sarifRule
+	index map[*analysis.Analyzer]int // index of the analyzer's rule
+
+	results       []sarifResult
+	seen          map[sarifKey]bool
+	notifications []sarifNotification
+
+	content map[string][]byte // file content, for column computations (nil if unreadable)
+}
+
+// sarifKey identifies a result, to avoid double-reporting in source
+// files that belong to multiple packages, such as foo and foo.test.
+type sarifKey struct {
+	rule     int
+	pos, end token.Position
+	message  string
+}
+
+// NewSARIFLog returns a new, empty SARIF log for the named tool.
+// The URIs of files within the root directory, if nonempty, are
+// relative to the root.
+func NewSARIFLog(tool, root string) *SARIFLog {
+	return &SARIFLog{
+		tool:    tool,
+		root:    root,
+		index:   make(map[*analysis.Analyzer]int),
+		seen:    make(map[sarifKey]bool),
+		content: make(map[string][]byte),
+	}
+}
+
+// Add adds the result of analyzer a, which is either a list of
+// diagnostics or an error.
+func (l *SARIFLog) Add(fset *token.FileSet, a *analysis.Analyzer, diags []analysis.Diagnostic, err error) {
+	rule, ok := l.index[a]
+	if !ok {
+		rule = len(l.rules)
+		l.index[a] = rule
+		l.rules = append(l.rules, sarifRule{
+			ID:               a.Name,
+			ShortDescription: sarifMessage{Text: strings.Split(a.Doc, "\n\n")[0]},
+			FullDescription:  sarifMessage{Text: a.Doc},
+			HelpURI:          a.URL,
+		})
+	}
+	if err != nil {
+		l.notifications = append(l.notifications, sarifNotification{
+			Level:   "error",
+			Message: sarifMessage{Text: fmt.Sprintf("%s: %v", a.Name, err)},
+		})
+		return
+	}
+
+	for _, diag := range diags {
+		k := sarifKey{rule, fset.Position(diag.Pos), fset.Position(diag.End), diag.Message}
+		if l.seen[k] {
+			continue // duplicate
+		}
+		l.seen[k] = true
+
+		result := sarifResult{
+			RuleID:    a.Name,
+			RuleIndex: rule,
+			Level:     "warning",
+			Message:   sarifMessage{Text: diag.Message},
+			Locations: []sarifLocation{},
+		}
+		if loc := l.location(fset, diag.Pos, diag.End, ""); loc.PhysicalLocation != nil {
+			result.Locations = append(result.Locations, loc)
+		}
+		for i, r := range diag.Related {
+			loc := l.location(fset, r.Pos, r.End, r.Message)
+			loc.ID = i + 1
+			result.RelatedLocations = append(result.RelatedLocations, loc)
+		}
+		for _, fix := range diag.SuggestedFixes {
+			sfix := sarifFix{Description: sarifMessage{Text: fix.Message}}
+			changes := make(map[string]int) // index of file's change
+			for _, edit := range fix.TextEdits {
+				start, end := fset.Position(edit.Pos), fset.Position(edit.End)
+				if !start.IsValid() {
+					continue // rejected by fix validation
+				}
+				if !end.IsValid() {
+					end = start
+				}
+				i, ok := changes[start.Filename]
+				if !ok {
+					i = len(sfix.ArtifactChanges)
+					changes[start.Filename] = i
+					sfix.ArtifactChanges = append(sfix.ArtifactChanges, sarifArtifactChange{
+						ArtifactLocation: l.artifactLocation(start.Filename),
+					})
+				}
+				change := &sfix.ArtifactChanges[i]
+				change.Replacements = append(change.Replacements, sarifReplacement{
+					DeletedRegion:   sarifRegion{ByteOffset: start.Offset, ByteLength: end.Offset - start.Offset},
+					InsertedContent: &sarifContent{Text: string(edit.NewText)},
+				})
+			}
+			if len(sfix.ArtifactChanges) > 0 {
+				result.Fixes = append(result.Fixes, sfix)
+			}
+		}
+		l.results = append(l.results, result)
+	}
+}
+
+// location returns the SARIF location of the interval [pos, end).
+// It has no physical location if pos is invalid.
+func (l *SARIFLog) location(fset *token.FileSet, pos, end token.Pos, message string) sarifLocation {
+	var loc sarifLocation
+	if message != "" {
+		loc.Message = &sarifMessage{Text: message}
+	}
+	start := fset.Position(pos)
+	if !start.IsValid() {
+		return loc
+	}
+	endPosn := fset.Position(end)
+	if !endPosn.IsValid() {
+		endPosn = start
+	}
+	loc.PhysicalLocation = &sarifPhysicalLocation{
+		ArtifactLocation: l.artifactLocation(start.Filename),
+		Region: sarifRegion{
+			StartLine:   start.Line,
+			StartColumn: l.column(start),
+			EndLine:     endPosn.Line,
+			EndColumn:   l.column(endPosn),
+			ByteOffset:  start.Offset,
+			ByteLength:  endPosn.Offset - start.Offset,
+		},
+	}
+	return loc
+}
+
+// column returns the 1-based column of posn in Unicode code points,
+// or its byte column if the file cannot be read.
+func (l *SARIFLog) column(posn token.Position) int {
+	content, ok := l.content[posn.Filename]
+	if !ok {
+		content, _ = os.ReadFile(posn.Filename)
+		l.content[posn.Filename] = content
+	}
+	lineStart := posn.Offset - (posn.Column - 1)
+	if content == nil || lineStart < 0 || posn.Offset > len(content) {
+		return posn.Column
+	}
+	return utf8.RuneCount(content[lineStart:posn.Offset]) + 1
+}
+
+// artifactLocation returns the location of the named file: a URI
+// relative to the root directory, if the file is within it, or else
+// an absolute file URI.
+func (l *SARIFLog) artifactLocation(filename string) sarifArtifactLocation {
+	if l.root != "" {
+		roots := []string{l.root}
+		// The file name may be relative to the root with symbolic links resolved.
+		if real, err := filepath.EvalSymlinks(l.root); err == nil && real != l.root {
+			roots = append(roots, real)
+		}
+		for _, root := range roots {
+			if rel, err := filepath.Rel(root, filename); err == nil && filepath.IsLocal(rel) {
+				u := url.URL{Path: filepath.ToSlash(rel)}
+				return sarifArtifactLocation{URI: u.String(), URIBaseID: sarifSrcRoot}
+			}
+		}
+	}
+	return sarifArtifactLocation{URI: fileURI(filename)}
+}
+
+// fileURI returns the file URI of the named file.
+func fileURI(filename string) string {
+	path := filepath.ToSlash(filename)
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path // e.g. C:/dir on Windows
+	}
+	u := url.URL{Scheme: "file", Path: path}
+	return u.String()
+}
+
+// Print prints the log in JSON form.
+func (l *SARIFLog) Print(out io.Writer) error {
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           l.tool,
+			InformationURI: "https://pkg.go.dev/golang.org/x/tools/go/analysis",
+			Rules:          nonNil(l.rules),
+		}},
+		Results:    nonNil(l.results),
+		ColumnKind: "unicodeCodePoints",
+		Invocations: []sarifInvocation{{
+			ExecutionSuccessful:        len(l.notifications) == 0,
+			ToolExecutionNotifications: l.notifications,
+		}},
+	}
+	if l.root != "" {
+		dir := fileURI(l.root)
+		if !strings.HasSuffix(dir, "/") {
+			dir += "/"
+		}
+		run.OriginalURIBaseIDs = map[string]sarifArtifactLocation{sarifSrcRoot: {URI: dir}}
+	}
+	data, err := json.MarshalIndent(sarifLogJSON{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}, "", "\t")
+	if err != nil {
+		log.Panicf("internal error: JSON marshaling failed: %v", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", data)
+	return err
+}
+
+// nonNil returns s, or an empty slice if s is nil,
+// so that it is encoded as [] rather than null.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		s = []T{}
+	}
+	return s
+}
+
+// The types below define the subset of the SARIF schema that we use.
+
+type sarifLogJSON struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	Invocations        []sarifInvocation                `json:"invocations"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	ColumnKind         string                           `json:"columnKind"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+	FullDescription  sarifMessage `json:"fullDescription"`
+	HelpURI          string       `json:"helpUri,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level   string       `json:"level"`
+	Message sarifMessage `json:"message"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               int                    `json:"id,omitempty"`
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+	Message          *sarifMessage          `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// A sarifRegion is a region of a file, given by line and column (if
+// StartLine is nonzero) and by byte offset.
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+	ByteOffset  int `json:"byteOffset"`
+	ByteLength  int `json:"byteLength"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifRegion   `json:"deletedRegion"`
+	InsertedContent *sarifContent `json:"insertedContent,omitempty"`
+}
+
+type sarifContent struct {
+	Text string `json:"text"`
+}
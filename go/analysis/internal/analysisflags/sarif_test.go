@@ -0,0 +1,199 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysisflags_test
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/internal/analysisflags"
+)
+
+func TestSARIFLog(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "a.go")
+	const src = "package a\n\nvar é, x = 1, 2\n"
+	if err := os.WriteFile(filename, []byte(src), 0666); err != nil {
+		t.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	file := fset.AddFile(filename, -1, len(src))
+	file.SetLinesForContent([]byte(src))
+	pos := func(substr string) token.Pos {
+		return file.Pos(strings.Index(src, substr))
+	}
+
+	a := &analysis.Analyzer{
+		Name: "a",
+		Doc:  "report x\n\nThe a analyzer reports x.",
+		URL:  "https://example.com/a",
+	}
+	b := &analysis.Analyzer{Name: "b", Doc: "fail"}
+	diag := analysis.Diagnostic{
+		Pos:     pos("x ="),
+		End:     pos("x =") + 1,
+		Message: "x is bad",
+		Related: []analysis.RelatedInformation{{Pos: pos("é"), Message: "é is good"}},
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "rename x",
+			TextEdits: []analysis.TextEdit{{Pos: pos("x ="), End: pos("x =") + 1, NewText: []byte("y")}},
+		}},
+	}
+
+	log := analysisflags.NewSARIFLog("tool", dir)
+	log.Add(fset, a, []analysis.Diagnostic{diag}, nil)
+	log.Add(fset, a, []analysis.Diagnostic{diag}, nil) // duplicate, as in foo and foo.test
+	log.Add(fset, b, nil, errors.New("oops"))
+	var out strings.Builder
+	if err := log.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	// Decode the parts of the log that we care about.
+	type region struct {
+		StartLine, StartColumn, EndLine, EndColumn, ByteOffset, ByteLength int
+	}
+	type location struct {
+		ID               int
+		PhysicalLocation struct {
+			ArtifactLocation struct{ URI, URIBaseID string }
+			Region           region
+		}
+		Message struct{ Text string }
+	}
+	var got struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID               string
+						ShortDescription struct{ Text string }
+						HelpURI          string
+					}
+				}
+			}
+			Invocations []struct {
+				ExecutionSuccessful        bool
+				ToolExecutionNotifications []struct {
+					Message struct{ Text string }
+				}
+			}
+			Results []struct {
+				RuleID           string
+				RuleIndex        int
+				Message          struct{ Text string }
+				Locations        []location
+				RelatedLocations []location
+				Fixes            []struct {
+					ArtifactChanges []struct {
+						Replacements []struct {
+							DeletedRegion   region
+							InsertedContent struct{ Text string }
+						}
+					}
+				}
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("got version %q and %d runs, want 2.1.0 and 1", got.Version, len(got.Runs))
+	}
+	run := got.Runs[0]
+
+	if rules := run.Tool.Driver.Rules; len(rules) != 2 ||
+		rules[0].ID != "a" || rules[0].ShortDescription.Text != "report x" || rules[0].HelpURI != a.URL ||
+		rules[1].ID != "b" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+	if inv := run.Invocations; len(inv) != 1 || inv[0].ExecutionSuccessful ||
+		len(inv[0].ToolExecutionNotifications) != 1 || inv[0].ToolExecutionNotifications[0].Message.Text != "b: oops" {
+		t.Errorf("unexpected invocations: %+v", inv)
+	}
+
+	if len(run.Results) != 1 {
+		t.Fatalf("got %d results, want 1", len(run.Results))
+	}
+	result := run.Results[0]
+	if result.RuleID != "a" || result.RuleIndex != 0 || result.Message.Text != "x is bad" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	loc := result.Locations[0].PhysicalLocation
+	if loc.ArtifactLocation.URI != "a.go" || loc.ArtifactLocation.URIBaseID != "%SRCROOT%" {
+		t.Errorf("got artifact location %+v, want a.go relative to %%SRCROOT%%", loc.ArtifactLocation)
+	}
+	// The column of x is in code points, not bytes.
+	if want := (region{3, 8, 3, 9, 19, 1}); loc.Region != want {
+		t.Errorf("got region %+v, want %+v", loc.Region, want)
+	}
+	if rel := result.RelatedLocations; len(rel) != 1 || rel[0].ID != 1 || rel[0].Message.Text != "é is good" ||
+		rel[0].PhysicalLocation.Region.StartColumn != 5 {
+		t.Errorf("unexpected related locations: %+v", rel)
+	}
+	if fixes := result.Fixes; len(fixes) != 1 || len(fixes[0].ArtifactChanges) != 1 ||
+		len(fixes[0].ArtifactChanges[0].Replacements) != 1 {
+		t.Errorf("unexpected fixes: %+v", fixes)
+	} else if r := fixes[0].ArtifactChanges[0].Replacements[0]; r.DeletedRegion.ByteOffset != 19 ||
+		r.DeletedRegion.ByteLength != 1 || r.InsertedContent.Text != "y" {
+		t.Errorf("unexpected replacement: %+v", r)
+	}
+}
+
+// TestSARIFLogNoPos checks that diagnostics, related information and
+// edits without a valid position have no physical location.
+func TestSARIFLogNoPos(t *testing.T) {
+	fset := token.NewFileSet()
+	a := &analysis.Analyzer{Name: "a", Doc: "report"}
+	diag := analysis.Diagnostic{
+		Pos:     token.NoPos,
+		Message: "global problem",
+		Related: []analysis.RelatedInformation{{Pos: token.NoPos, Message: "see elsewhere"}},
+		SuggestedFixes: []analysis.SuggestedFix{{
+			Message:   "bogus",
+			TextEdits: []analysis.TextEdit{{Pos: token.NoPos, NewText: []byte("x")}},
+		}},
+	}
+	log := analysisflags.NewSARIFLog("tool", "")
+	log.Add(fset, a, []analysis.Diagnostic{diag}, nil)
+	var out strings.Builder
+	if err := log.Print(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	var got struct {
+		Runs []struct {
+			Results []struct {
+				Locations        []json.RawMessage
+				RelatedLocations []map[string]json.RawMessage
+				Fixes            []json.RawMessage
+			}
+		}
+	}
+	if err := json.Unmarshal([]byte(out.String()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out.String())
+	}
+	if strings.Contains(out.String(), "file://") {
+		t.Errorf("log contains a file URI:\n%s", out.String())
+	}
+	result := got.Runs[0].Results[0]
+	if len(result.Locations) != 0 {
+		t.Errorf("got locations %s, want none", result.Locations)
+	}
+	if rel := result.RelatedLocations; len(rel) != 1 || rel[0]["physicalLocation"] != nil || rel[0]["message"] == nil {
+		t.Errorf("got related locations %v, want one with only a message", rel)
+	}
+	if len(result.Fixes) != 0 {
+		t.Errorf("got fixes %s, want none", result.Fixes)
+	}
+}
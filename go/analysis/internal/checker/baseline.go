@@ -0,0 +1,248 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file defines baseline files, which record the findings of a
+// previous run so that only new findings are reported.
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/checker"
+)
+
+const baselineVersion = 1
+
+// A baselineFile is the JSON form of a baseline file.
+type baselineFile struct {
+	Version  int               `json:"version"`
+	Findings []baselineFinding `json:"findings"`
+}
+
+// A baselineFinding records the number of diagnostics of one kind.
+//
+// Diagnostics are identified not by their position, but by their
+// analyzer, file, enclosing top-level declaration, and a fingerprint of
+// their message, so that a baseline is unaffected by edits that
+// merely shift the lines of a file.
+type baselineFinding struct {
+	Analyzer    string `json:"analyzer"`
+	File        string `json:"file"`               // slash-separated, relative to the directory of the baseline file
+	Function    string `json:"function,omitempty"` // e.g. "f", "T.m"; "" => none
+	Fingerprint string `json:"fingerprint"`
+	Message     string `json:"message"` // message of the first such diagnostic, for human readers
+	Count       int    `json:"count"`
+}
+
+type baselineKey struct {
+	analyzer, file, function, fingerprint string
+}
+
+func (f *baselineFinding) key() baselineKey {
+	return baselineKey{f.Analyzer, f.File, f.Function, f.Fingerprint}
+}
+
+// writeBaseline writes a baseline file that records the diagnostics
+// of the root actions.
+func writeBaseline(filename string, roots []*checker.Action) error {
+	findings := make(map[baselineKey]*baselineFinding)
+	forEachDiagnostic(filename, roots, func(f *baselineFinding) bool {
+		if prev, ok := findings[f.key()]; ok {
+			prev.Count++
+		} else {
+			f.Count = 1
+			findings[f.key()] = f
+		}
+		return false
+	})
+
+	baseline := baselineFile{Version: baselineVersion, Findings: []baselineFinding{}}
+	for _, f := range findings {
+		baseline.Findings = append(baseline.Findings, *f)
+	}
+	slices.SortFunc(baseline.Findings, func(x, y baselineFinding) int {
+		return cmp.Or(
+			cmp.Compare(x.File, y.File),
+			cmp.Compare(x.Function, y.Function),
+			cmp.Compare(x.Analyzer, y.Analyzer),
+			cmp.Compare(x.Message, y.Message),
+			cmp.Compare(x.Fingerprint, y.Fingerprint))
+	})
+	data, err := json.MarshalIndent(baseline, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, append(data, '\n'), 0666)
+}
+
+// applyBaseline removes the diagnostics of the root actions that are
+// recorded in the baseline file. If the file records n diagnostics of
+// a kind, only the first n diagnostics of that kind are removed.
+func applyBaseline(filename string, roots []*checker.Action) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var baseline baselineFile
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("invalid baseline file %s: %v", filename, err)
+	}
+	if baseline.Version != baselineVersion {
+		return fmt.Errorf("baseline file %s has unsupported version %d", filename, baseline.Version)
+	}
+	remaining := make(map[baselineKey]int)
+	for _, f := range baseline.Findings {
+		remaining[f.key()] += f.Count
+	}
+
+	forEachDiagnostic(filename, roots, func(f *baselineFinding) bool {
+		if remaining[f.key()] > 0 {
+			remaining[f.key()]--
+			return true
+		}
+		return false
+	})
+	return nil
+}
+
+// forEachDiagnostic calls f for each diagnostic of the root actions,
+// with its finding relative to the baseline file, and deletes the
+// diagnostic if f returns true. Duplicate diagnostics in files that
+// belong to multiple packages, such as foo and foo.test, are visited
+// once, and share the fate of the first.
+func forEachDiagnostic(filename string, roots []*checker.Action, f func(*baselineFinding) bool) {
+	dirs := []string{filepath.Dir(filename)}
+	if abs, err := filepath.Abs(dirs[0]); err == nil {
+		dirs[0] = abs
+	}
+	// The file names may be relative to the directory with symbolic links resolved.
+	if real, err := filepath.EvalSymlinks(dirs[0]); err == nil && real != dirs[0] {
+		dirs = append(dirs, real)
+	}
+
+	type key struct {
+		pos, end token.Position
+		*analysis.Analyzer
+		message string
+	}
+	deleted := make(map[key]bool)
+	for _, act := range roots {
+		if act.Err != nil {
+			continue
+		}
+		act.Diagnostics = slices.DeleteFunc(act.Diagnostics, func(diag analysis.Diagnostic) bool {
+			posn := act.Package.Fset.Position(diag.Pos)
+			k := key{posn, act.Package.Fset.Position(diag.End), act.Analyzer, diag.Message}
+			if del, ok := deleted[k]; ok {
+				return del // duplicate
+			}
+			finding := &baselineFinding{
+				Analyzer:    act.Analyzer.Name,
+				File:        baselineFilename(dirs, posn.Filename),
+				Function:    enclosingDeclName(act, diag.Pos),
+				Fingerprint: fingerprint(diag.Message),
+				Message:     diag.Message,
+			}
+			del := f(finding)
+			deleted[k] = del
+			return del
+		})
+	}
+}
+
+// baselineFilename returns the name of the file relative to the first
+// of dirs that contains it, or else its absolute name, in slash form.
+func baselineFilename(dirs []string, filename string) string {
+	for _, dir := range dirs {
+		if rel, err := filepath.Rel(dir, filename); err == nil && filepath.IsLocal(rel) {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return filepath.ToSlash(filename)
+}
+
+// enclosingDeclName returns the name of the top-level declaration
+// enclosing pos in the syntax of the action's package: "f" for a
+// function f, "T.m" for a method m of type T (or *T), or the name of
+// the first symbol declared by a var, const, or type declaration.
+// It returns "" if pos is not within a named declaration.
+func enclosingDeclName(act *checker.Action, pos token.Pos) string {
+	for _, file := range act.Package.Syntax {
+		if !(file.FileStart <= pos && pos <= file.FileEnd) {
+			continue
+		}
+		for _, decl := range file.Decls {
+			if !(decl.Pos() <= pos && pos < decl.End()) {
+				continue
+			}
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv != nil && len(decl.Recv.List) > 0 {
+					if recv := recvTypeName(decl.Recv.List[0].Type); recv != "" {
+						return recv + "." + decl.Name.Name
+					}
+				}
+				return decl.Name.Name
+			case *ast.GenDecl:
+				if len(decl.Specs) > 0 {
+					switch spec := decl.Specs[0].(type) {
+					case *ast.TypeSpec:
+						return spec.Name.Name
+					case *ast.ValueSpec:
+						return spec.Names[0].Name
+					}
+				}
+			}
+			return ""
+		}
+		break
+	}
+	return ""
+}
+
+// recvTypeName returns the name of the named type of a receiver, such
+// as T, *T, or *T[P].
+func recvTypeName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
+
+// digitsRx matches a run of decimal digits.
+var digitsRx = regexp.MustCompile(`[0-9]+`)
+
+// fingerprint returns a fingerprint of a diagnostic message that is
+// insensitive to the numbers in it, which are often line numbers
+// or counts.
+func fingerprint(message string) string {
+	normalized := digitsRx.ReplaceAllString(strings.TrimSpace(message), "0")
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:8])
+}
@@ -22,9 +22,11 @@ import (
 
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"runtime/pprof"
 	"runtime/trace"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -61,6 +63,35 @@ var (
 	// Diff causes the file updates to be displayed, but not applied.
 	// This flag has no effect unless Fix is true.
 	Diff bool
+
+	// FixAnalyzers, FixMessage, and FixFiles, if nonempty, restrict
+	// the fixes applied by Fix to those of the named analyzers (a
+	// comma-separated list), those whose message matches the regular
+	// expression, and those that edit only files matching the
+	// [filepath.Match] pattern, respectively.
+	FixAnalyzers, FixMessage, FixFiles string
+
+	// Interactive causes each fix to be displayed as a unified diff
+	// and applied only if the user confirms it.
+	// This flag has no effect unless Fix is true.
+	Interactive bool
+
+	// Baseline is the name of a baseline file of previous findings,
+	// which are not reported.
+	Baseline string
+
+	// WriteBaseline causes the findings to be recorded in the
+	// Baseline file, not reported.
+	WriteBaseline bool
+
+	// Nolint causes diagnostics to be suppressed by //nolint
+	// comments that name their analyzer, and those comments that
+	// suppress nothing to be reported.
+	Nolint bool
+
+	// CacheDir is the name of a directory in which to cache
+	// the facts and diagnostics of each analysis action.
+	CacheDir string
 )
 
 // RegisterFlags registers command-line flags used by the analysis driver.
@@ -77,6 +108,16 @@ func RegisterFlags() {
 
 	flag.BoolVar(&Fix, "fix", false, "apply all suggested fixes")
 	flag.BoolVar(&Diff, "diff", false, "with -fix, don't update the files, but print a unified diff")
+	flag.StringVar(&FixAnalyzers, "fixanalyzers", "", "with -fix, apply only the fixes of these analyzers (comma-separated)")
+	flag.StringVar(&FixMessage, "fixmessage", "", "with -fix, apply only the fixes whose message matches this regular expression")
+	flag.StringVar(&FixFiles, "fixfiles", "", "with -fix, apply only the fixes that edit files matching this pattern")
+	flag.BoolVar(&Interactive, "interactive", false, "with -fix, show each fix and ask whether to apply it")
+
+	flag.StringVar(&CacheDir, "cache", "", "cache analysis facts and diagnostics in this directory, to avoid reanalyzing unchanged packages")
+
+	flag.StringVar(&Baseline, "baseline", "", "report only findings not recorded in this baseline file")
+	flag.BoolVar(&WriteBaseline, "writebaseline", false, "with -baseline, record all findings in the baseline file instead of reporting them")
+	flag.BoolVar(&Nolint, "nolint", false, "suppress the diagnostics of analyzers named by //nolint comments, and report unused comments")
 }
 
 // Run loads the packages specified by args using go/packages,
@@ -116,6 +157,19 @@ func Run(args []string, analyzers []*analysis.Analyzer) (exitcode int) {
 		Debug += "v"
 	}
 
+	sel, err := newFixSelection()
+	if err != nil {
+		log.Print(err)
+		exitAtLeast(1)
+		return
+	}
+
+	if WriteBaseline && Baseline == "" {
+		log.Print("-writebaseline requires -baseline")
+		exitAtLeast(1)
+		return
+	}
+
 	if CPUProfile != "" {
 		f, err := os.Create(CPUProfile)
 		if err != nil {
@@ -192,20 +246,49 @@ func Run(args []string, analyzers []*analysis.Analyzer) (exitcode int) {
 		Sequential:  dbg('p'),
 		FactLog:     factLog,
 	}
+	if CacheDir != "" {
+		opts.Cache = checker.NewFileCache(CacheDir)
+	}
 	if dbg('v') {
 		log.Printf("building graph of analysis passes")
 	}
-	graph, err := checker.Analyze(analyzers, initial, opts)
+	graph, err := analyze(analyzers, initial, opts)
 	if err != nil {
 		log.Print(err)
 		exitAtLeast(1)
 		return
 	}
 
+	// Remove the diagnostics suppressed by //nolint comments
+	// or recorded in the baseline file.
+	if Nolint {
+		graph.Roots = append(graph.Roots, applyNolint(graph.Roots)...)
+	}
+	if WriteBaseline {
+		for act := range graph.All() {
+			if act.Err != nil {
+				log.Printf("%s: %v", act.Analyzer.Name, act.Err)
+				exitAtLeast(1)
+			}
+		}
+		if err := writeBaseline(Baseline, graph.Roots); err != nil {
+			log.Print(err)
+			exitAtLeast(1)
+		}
+		return
+	}
+	if Baseline != "" {
+		if err := applyBaseline(Baseline, graph.Roots); err != nil {
+			log.Print(err)
+			exitAtLeast(1)
+			return
+		}
+	}
+
 	// Don't print the diagnostics,
 	// but apply all fixes from the root actions.
 	if Fix {
-		if err := applyFixes(graph.Roots, Diff); err != nil {
+		if err := applyFixes(graph.Roots, Diff, sel); err != nil {
 			// Fail when applying fixes failed.
 			log.Print(err)
 			exitAtLeast(1)
@@ -226,15 +309,20 @@ func Run(args []string, analyzers []*analysis.Analyzer) (exitcode int) {
 	return
 }
 
-// printDiagnostics prints diagnostics in text or JSON form
+// printDiagnostics prints diagnostics in text, JSON, or SARIF form
 // and returns the appropriate exit code.
 func printDiagnostics(graph *checker.Graph) (exitcode int) {
 	// Print the results.
-	// With -json, the exit code is always zero.
+	// With -json or -sarif, the exit code is always zero.
 	if analysisflags.JSON {
 		if err := graph.PrintJSON(os.Stdout); err != nil {
 			return 1
 		}
+	} else if analysisflags.SARIF {
+		root, _ := os.Getwd()
+		if err := graph.PrintSARIF(os.Stdout, filepath.Base(os.Args[0]), root); err != nil {
+			return 1
+		}
 	} else {
 		if err := graph.PrintText(os.Stderr, analysisflags.Context); err != nil {
 			return 1
@@ -323,9 +411,13 @@ func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 // an arbitrary deterministic order as if by a three-way diff tool
 // such as the UNIX diff3 command or 'git merge'. Any fix that cannot be
 // cleanly merged is discarded, in which case the final summary tells
-// the user to re-run the tool.
+// the user to re-run the tool, and a conflict with a fix of another
+// analyzer is logged.
 // TODO(adonovan): make the checker tool re-run the analysis itself.
 //
+// Only the fixes chosen by sel are applied, if it is non-nil; see
+// [fixSelection].
+//
 // When the same file is analyzed as a member of both a primary
 // package "p" and a test-augmented package "p [p.test]", there may be
 // duplicate diagnostics and fixes. One set of fixes will be applied
@@ -370,7 +462,7 @@ func load(patterns []string, allSyntax bool) ([]*packages.Package, error) {
 //
 // TODO(adonovan): handle file-system level aliases such as symbolic
 // links using robustio.FileID.
-func applyFixes(actions []*checker.Action, showDiff bool) error {
+func applyFixes(actions []*checker.Action, showDiff bool, sel *fixSelection) error {
 
 	// Select fixes to apply.
 	//
@@ -386,7 +478,9 @@ func applyFixes(actions []*checker.Action, showDiff bool) error {
 			for i := range diag.SuggestedFixes {
 				fix := &diag.SuggestedFixes[i]
 				if i == 0 {
-					fixes = append(fixes, &fixact{fix, act})
+					if sel.selects(act, fix) {
+						fixes = append(fixes, &fixact{fix, act})
+					}
 				} else {
 					// TODO(adonovan): abstract the logger.
 					log.Printf("%s: ignoring alternative fix %q", act, fix.Message)
@@ -419,11 +513,14 @@ func applyFixes(actions []*checker.Action, showDiff bool) error {
 	}
 
 	// Apply each fix, updating the current state
-	// only if the entire fix can be cleanly merged.
+	// only if the entire fix can be cleanly merged
+	// (and, if interactive, the user confirms it).
 	accumulatedEdits := make(map[string][]diff.Edit)
-	goodFixes := 0
+	applied := make(map[string][]appliedFix) // applied fixes, by file
+	goodFixes, declinedFixes := 0, 0
+	applyAll := false // user chose to apply all remaining fixes
 fixloop:
-	for _, fixact := range fixes {
+	for i, fixact := range fixes {
 		readFile := internal.Pass(fixact.act).ReadFile
 
 		// Convert analysis.TextEdits to diff.Edits, grouped by file.
@@ -457,30 +554,61 @@ fixloop:
 		// Apply each set of edits by merging atop
 		// the previous accumulated state.
 		after := make(map[string][]diff.Edit)
+		changed := false // whether the fix changes the accumulated state
 		for file, edits := range fileEdits {
-			if prev := accumulatedEdits[file]; len(prev) > 0 {
-				merged, ok := diff.Merge(prev, edits)
+			prev := accumulatedEdits[file]
+			merged := edits
+			if len(prev) > 0 {
+				var ok bool
+				merged, ok = diff.Merge(prev, edits)
 				if !ok {
+					// Report a conflict with a fix of another analyzer.
+					if other := conflictingFix(applied[file], edits); other != nil && other.act.Analyzer != fixact.act.Analyzer {
+						log.Printf("%s: skipping fix %q of %s, which conflicts with fix %q of %s",
+							fset.Position(fixact.fix.TextEdits[0].Pos), fixact.fix.Message, fixact.act.Analyzer.Name,
+							other.fix.Message, other.act.Analyzer.Name)
+					}
 					// debugging
 					if false {
 						log.Printf("%s: fix %s conflicts", fixact.act, fixact.fix.Message)
 					}
 					continue fixloop // conflict
 				}
-				edits = merged
 			}
-			after[file] = edits
+			if !slices.Equal(merged, prev) {
+				changed = true
+			}
+			after[file] = merged
+		}
+
+		// Ask the user to confirm a fix that changes the state
+		// (unlike, say, the duplicate of a fix already applied).
+		if sel != nil && sel.prompt != nil && changed && !applyAll {
+			switch sel.confirm(fixact.act, fixact.fix, baselineContent, accumulatedEdits, after) {
+			case "y":
+			case "a":
+				applyAll = true
+			case "q":
+				declinedFixes += len(fixes) - i
+				break fixloop
+			default:
+				declinedFixes++
+				continue fixloop
+			}
 		}
 
 		// The entire fix applied cleanly; commit it.
 		goodFixes++
 		maps.Copy(accumulatedEdits, after)
+		for file, edits := range fileEdits {
+			applied[file] = append(applied[file], appliedFix{fixact.fix, fixact.act, edits})
+		}
 		// debugging
 		if false {
 			log.Printf("%s: fix %s applied", fixact.act, fixact.fix.Message)
 		}
 	}
-	badFixes := len(fixes) - goodFixes
+	badFixes := len(fixes) - goodFixes - declinedFixes
 
 	// Show diff or update files to final state.
 	var files []string
@@ -0,0 +1,105 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file applies the analyzer configuration file (-config).
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/checker"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/internal/analysisflags"
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+)
+
+// analyze applies the analyzers to the initial packages, as
+// checker.Analyze does, following the configuration file, if any.
+//
+// Packages for which the configuration enables different analyzers,
+// or sets different flags, are analyzed separately, and the roots of
+// the resulting graphs are combined. Diagnostics in files for which
+// the configuration disables their analyzer are removed.
+func analyze(analyzers []*analysis.Analyzer, initial []*packages.Package, opts *checker.Options) (*checker.Graph, error) {
+	cfg := analysisflags.Configuration
+	if cfg == nil {
+		return checker.Analyze(analyzers, initial, opts)
+	}
+
+	// Group the packages by their analyzers and flags.
+	type group struct {
+		analyzers []*analysis.Analyzer
+		flags     map[string]string
+		pkgs      []*packages.Package
+	}
+	var groups []*group
+	byKey := make(map[string]*group)
+	for _, pkg := range initial {
+		g := &group{flags: cfg.PackageFlags(pkg.PkgPath)}
+		var key strings.Builder
+		for _, a := range analyzers {
+			if cfg.Enabled(a.Name, pkg.PkgPath, "") {
+				g.analyzers = append(g.analyzers, a)
+				fmt.Fprintf(&key, "%s;", a.Name)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(g.flags)) {
+			fmt.Fprintf(&key, "-%s=%q;", name, g.flags[name])
+		}
+		if prev, ok := byKey[key.String()]; ok {
+			g = prev
+		} else {
+			byKey[key.String()] = g
+			groups = append(groups, g)
+		}
+		g.pkgs = append(g.pkgs, pkg)
+	}
+
+	// Save the values of the flags set by overrides,
+	// and restore them afterwards.
+	overridden := make(map[string]string)
+	for _, name := range cfg.OverriddenFlags() {
+		if f := flag.Lookup(name); f != nil {
+			overridden[name] = f.Value.String()
+		}
+	}
+	setFlags := func(flags map[string]string) {
+		for name, value := range flags {
+			if err := flag.Set(name, value); err != nil {
+				log.Fatalf("invalid value for -%s in configuration file: %v", name, err)
+			}
+		}
+	}
+	defer setFlags(overridden)
+
+	graph := new(checker.Graph)
+	for _, g := range groups {
+		if len(g.analyzers) == 0 {
+			continue
+		}
+		setFlags(overridden)
+		setFlags(g.flags)
+		sub, err := checker.Analyze(g.analyzers, g.pkgs, opts)
+		if err != nil {
+			return nil, err
+		}
+		graph.Roots = append(graph.Roots, sub.Roots...)
+	}
+
+	// Remove the diagnostics in files for which
+	// the configuration disables their analyzer.
+	for _, act := range graph.Roots {
+		act.Diagnostics = slices.DeleteFunc(act.Diagnostics, func(diag analysis.Diagnostic) bool {
+			filename := act.Package.Fset.Position(diag.Pos).Filename
+			return !cfg.Enabled(act.Analyzer.Name, act.Package.PkgPath, filename)
+		})
+	}
+	return graph, nil
+}
@@ -184,6 +184,8 @@ func TestReportInvalidDiagnostic(t *testing.T) {
 //	checker args...
 //		Run the checker command with the specified space-separated
 //		arguments; this fork+execs the [TestMain] function above.
+//		If the archive has a "stdin" section, it is the standard
+//		input of the checker command.
 //		If the archive has a "stdout" section, its contents must
 //		match the stdout output of the checker command.
 //		Do NOT use this for testing -diff: tests should not
@@ -236,6 +238,9 @@ func TestScript(t *testing.T) {
 				case "checker":
 					cmd := exec.Command(os.Args[0], strings.Fields(rest)...)
 					cmd.Dir = dir
+					if f := section(ar, "stdin"); f != nil {
+						cmd.Stdin = bytes.NewReader(f.Data)
+					}
 					cmd.Stdout = new(strings.Builder)
 					cmd.Stderr = new(strings.Builder)
 					cmd.Env = append(os.Environ(), "CHECKER_TEST_CHILD=1", "GOPROXY=off")
@@ -0,0 +1,176 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file defines the selection of the fixes applied by -fix.
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/checker"
+	"github.com/tinygo-org/tinygo/x-tools/internal/diff"
+)
+
+// A fixSelection determines which suggested fixes are applied.
+// A nil selection selects all fixes.
+type fixSelection struct {
+	analyzers []string       // if non-nil, apply only fixes of these analyzers
+	message   *regexp.Regexp // if non-nil, apply only fixes whose message matches
+	files     string         // if nonempty, apply only fixes that edit matching files
+	cwd       string         // current directory, for relative file names
+
+	// If prompt is non-nil, each fix is shown to the user
+	// on out, and applied only if the user confirms it.
+	prompt *bufio.Reader
+	out    io.Writer
+}
+
+// newFixSelection returns the fix selection specified by the
+// FixAnalyzers, FixMessage, FixFiles, and Interactive flags.
+func newFixSelection() (*fixSelection, error) {
+	if FixAnalyzers == "" && FixMessage == "" && FixFiles == "" && !Interactive {
+		return nil, nil // all fixes
+	}
+	sel := new(fixSelection)
+	if FixAnalyzers != "" {
+		sel.analyzers = strings.Split(FixAnalyzers, ",")
+	}
+	if FixMessage != "" {
+		rx, err := regexp.Compile(FixMessage)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -fixmessage: %v", err)
+		}
+		sel.message = rx
+	}
+	if FixFiles != "" {
+		if _, err := filepath.Match(FixFiles, ""); err != nil {
+			return nil, fmt.Errorf("invalid -fixfiles pattern: %v", err)
+		}
+		sel.files = FixFiles
+		sel.cwd, _ = os.Getwd()
+	}
+	if Interactive {
+		sel.prompt = bufio.NewReader(os.Stdin)
+		sel.out = os.Stdout
+	}
+	return sel, nil
+}
+
+// selects reports whether the fix of the action is selected.
+func (sel *fixSelection) selects(act *checker.Action, fix *analysis.SuggestedFix) bool {
+	if sel == nil {
+		return true
+	}
+	if sel.analyzers != nil && !slices.Contains(sel.analyzers, act.Analyzer.Name) {
+		return false
+	}
+	if sel.message != nil && !sel.message.MatchString(fix.Message) {
+		return false
+	}
+	if sel.files != "" {
+		for _, edit := range fix.TextEdits {
+			// An edit outside any file is invalid, and does not match.
+			f := act.Package.Fset.File(edit.Pos)
+			if f == nil || !sel.matchFile(f.Name()) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchFile reports whether the file name, its base name, or its name
+// relative to the current directory matches the files pattern.
+func (sel *fixSelection) matchFile(filename string) bool {
+	names := []string{filename, filepath.Base(filename)}
+	if rel, err := filepath.Rel(sel.cwd, filename); err == nil && filepath.IsLocal(rel) {
+		names = append(names, rel)
+	}
+	for _, name := range names {
+		if ok, _ := filepath.Match(sel.files, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm shows the fix of the action as a unified diff of each file
+// it edits, from the state after the accumulated edits (before) to
+// the state after the merged edits (after), and asks the user whether
+// to apply it. It returns the user's answer: "y" (yes), "n" (no),
+// "a" (all: this and all remaining fixes), or "q" (quit: neither this
+// nor any remaining fix). End of input is treated as "q".
+func (sel *fixSelection) confirm(act *checker.Action, fix *analysis.SuggestedFix, content map[string][]byte, before, after map[string][]diff.Edit) string {
+	posn := act.Package.Fset.Position(fix.TextEdits[0].Pos)
+	fmt.Fprintf(sel.out, "%s: %s: %s\n", posn, act.Analyzer.Name, fix.Message)
+	files := make([]string, 0, len(after))
+	for file := range after {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+	for _, file := range files {
+		old, err := diff.ApplyBytes(content[file], before[file])
+		if err != nil {
+			log.Fatalf("internal error in diff.ApplyBytes: %v", err)
+		}
+		new, err := diff.ApplyBytes(content[file], after[file])
+		if err != nil {
+			log.Fatalf("internal error in diff.ApplyBytes: %v", err)
+		}
+		io.WriteString(sel.out, diff.Unified(file, file, string(old), string(new)))
+	}
+
+	for {
+		fmt.Fprintf(sel.out, "Apply this fix [y,n,a,q,?]? ")
+		line, err := sel.prompt.ReadString('\n')
+		answer := strings.ToLower(strings.TrimSpace(line))
+		if answer == "" && err != nil {
+			fmt.Fprintln(sel.out)
+			return "q" // end of input
+		}
+		switch answer {
+		case "y", "n", "a", "q":
+			return answer
+		}
+		fmt.Fprintln(sel.out, "y - apply this fix\n"+
+			"n - do not apply this fix\n"+
+			"a - apply this fix and all remaining fixes\n"+
+			"q - quit; do not apply this fix or any remaining fixes")
+	}
+}
+
+// An appliedFix records the edits to one file of an applied fix.
+type appliedFix struct {
+	fix   *analysis.SuggestedFix
+	act   *checker.Action
+	edits []diff.Edit
+}
+
+// conflictingFix returns the first of the applied fixes with an edit
+// that overlaps one of the edits, or nil if there is none.
+func conflictingFix(applied []appliedFix, edits []diff.Edit) *appliedFix {
+	for i, prev := range applied {
+		for _, x := range prev.edits {
+			for _, y := range edits {
+				if x == y {
+					continue // identical edits are coalesced
+				}
+				if x.Start < y.End && y.Start < x.End || x.Start == y.Start {
+					return &applied[i]
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+import (
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/checker"
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+)
+
+// TestSelectsInvalidEdit checks that a -fixfiles selection does not
+// select, or crash on, a fix with an edit outside any file.
+func TestSelectsInvalidEdit(t *testing.T) {
+	sel := &fixSelection{files: "*.go"}
+	act := &checker.Action{
+		Analyzer: &analysis.Analyzer{Name: "a"},
+		Package:  &packages.Package{Fset: token.NewFileSet()},
+	}
+	fix := &analysis.SuggestedFix{
+		Message:   "fix",
+		TextEdits: []analysis.TextEdit{{Pos: token.NoPos, NewText: []byte("x")}},
+	}
+	if sel.selects(act, fix) {
+		t.Errorf("selects(%v) = true, want false", fix)
+	}
+}
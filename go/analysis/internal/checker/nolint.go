@@ -0,0 +1,220 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package checker
+
+// This file defines the inline suppression of diagnostics by
+// //nolint comments.
+
+import (
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/checker"
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+)
+
+// A nolint is a //nolint comment, which suppresses the diagnostics of
+// the named analyzers (or of all analyzers, if none are named) within
+// its scope:
+//
+//	x := f() //nolint:errcheck,unusedresult // reason
+//
+// The scope of a comment that follows code on its line is the line. The
+// scope of a comment alone on its line (perhaps within a comment group,
+// such as a doc comment) is the syntax that begins on the line after
+// the comment group, such as a statement or a whole declaration.
+type nolint struct {
+	comment    *ast.Comment
+	names      []string // analyzer names; nil => all
+	start, end token.Pos
+	used       map[string]bool // names of analyzers whose diagnostics it suppressed
+}
+
+// suppresses reports whether the comment suppresses diagnostics of
+// the named analyzer at pos.
+func (n *nolint) suppresses(name string, pos token.Pos) bool {
+	return n.start <= pos && pos < n.end && (n.names == nil || slices.Contains(n.names, name))
+}
+
+// parseNolint returns the analyzer names of a //nolint comment, and
+// whether the text is such a comment.
+func parseNolint(text string) ([]string, bool) {
+	rest, ok := strings.CutPrefix(text, "//nolint")
+	if !ok {
+		return nil, false
+	}
+	if rest == "" || rest[0] == ' ' || rest[0] == '\t' {
+		return nil, true // all analyzers
+	}
+	rest, ok = strings.CutPrefix(rest, ":")
+	if !ok {
+		return nil, false // e.g. //nolintfoo
+	}
+	if i := strings.IndexAny(rest, " \t"); i >= 0 {
+		rest = rest[:i] // strip explanation
+	}
+	names := strings.Split(rest, ",")
+	if slices.Contains(names, "all") {
+		return nil, true
+	}
+	return slices.DeleteFunc(names, func(name string) bool { return name == "" }), true
+}
+
+// fileNolints returns the //nolint comments of a file.
+func fileNolints(fset *token.FileSet, file *ast.File) []*nolint {
+	tokFile := fset.File(file.FileStart)
+	if tokFile == nil {
+		return nil
+	}
+	var nolints []*nolint
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			names, ok := parseNolint(c.Text)
+			if !ok {
+				continue
+			}
+			n := &nolint{comment: c, names: names, used: make(map[string]bool)}
+			line := tokFile.Line(c.Pos())
+			if !aloneOnLine(tokFile, file, c) {
+				n.start, n.end = lineExtent(tokFile, line)
+			} else if next := tokFile.Line(group.End()) + 1; next <= tokFile.LineCount() {
+				// The scope is the outermost node that begins on the next line,
+				// or else the line itself.
+				n.start, n.end = lineExtent(tokFile, next)
+				ast.Inspect(file, func(node ast.Node) bool {
+					if node == nil || node.End() < n.start || node.Pos() >= n.end {
+						return false
+					}
+					if _, ok := node.(*ast.File); !ok && node.Pos() >= n.start {
+						n.end = max(n.end, node.End())
+						return false
+					}
+					return true
+				})
+			}
+			nolints = append(nolints, n)
+		}
+	}
+	return nolints
+}
+
+// aloneOnLine reports whether the line comment is preceded on its line
+// only by white space and other comments.
+func aloneOnLine(tokFile *token.File, file *ast.File, c *ast.Comment) bool {
+	line := tokFile.Line(c.Pos())
+	alone := true
+	ast.Inspect(file, func(node ast.Node) bool {
+		switch node.(type) {
+		case nil, *ast.CommentGroup, *ast.Comment:
+			return false
+		}
+		if !alone || node.Pos() >= c.Pos() || tokFile.Line(node.End()) < line {
+			return false // node is after the comment, or on earlier lines
+		}
+		if node.End() <= c.Pos() {
+			alone = false // node ends on the line of the comment, before it
+		}
+		return alone
+	})
+	return alone
+}
+
+// lineExtent returns the extent of the given line of the file,
+// including its newline.
+func lineExtent(tokFile *token.File, line int) (token.Pos, token.Pos) {
+	start := tokFile.LineStart(line)
+	end := token.Pos(tokFile.Base() + tokFile.Size())
+	if line < tokFile.LineCount() {
+		end = tokFile.LineStart(line + 1)
+	}
+	return start, end
+}
+
+// nolintAnalyzer is the pseudo-analyzer under which stale //nolint
+// comments are reported, whichever analyzers they name.
+var nolintAnalyzer = &analysis.Analyzer{
+	Name: "nolint",
+	Doc:  "report //nolint comments that suppress no diagnostics",
+}
+
+// applyNolint removes the diagnostics of the root actions that are
+// suppressed by //nolint comments. It returns a root action of
+// nolintAnalyzer for each package with a stale suppression, one that
+// suppressed nothing, for an analyzer that was applied to the package.
+func applyNolint(roots []*checker.Action) []*checker.Action {
+	// Group root actions by package.
+	var pkgs []*packages.Package
+	actions := make(map[*packages.Package][]*checker.Action)
+	for _, act := range roots {
+		if _, ok := actions[act.Package]; !ok {
+			pkgs = append(pkgs, act.Package)
+		}
+		actions[act.Package] = append(actions[act.Package], act)
+	}
+
+	var stale []*checker.Action
+	for _, pkg := range pkgs {
+		var nolints []*nolint
+		for _, file := range pkg.Syntax {
+			nolints = append(nolints, fileNolints(pkg.Fset, file)...)
+		}
+		if len(nolints) == 0 {
+			continue
+		}
+
+		ran := make(map[string]*checker.Action) // analyzers applied to the package
+		for _, act := range actions[pkg] {
+			if act.Err != nil {
+				continue
+			}
+			ran[act.Analyzer.Name] = act
+			act.Diagnostics = slices.DeleteFunc(act.Diagnostics, func(diag analysis.Diagnostic) bool {
+				suppressed := false
+				for _, n := range nolints {
+					if n.suppresses(act.Analyzer.Name, diag.Pos) {
+						n.used[act.Analyzer.Name] = true
+						suppressed = true
+					}
+				}
+				return suppressed
+			})
+		}
+
+		// Report stale suppressions.
+		var diags []analysis.Diagnostic
+		for _, n := range nolints {
+			report := func(message string) {
+				diags = append(diags, analysis.Diagnostic{
+					Pos:     n.comment.Pos(),
+					End:     n.comment.End(),
+					Message: message,
+				})
+			}
+			if n.names == nil {
+				if len(n.used) == 0 && len(ran) > 0 {
+					report("unused //nolint directive")
+				}
+				continue
+			}
+			for _, name := range n.names {
+				if _, ok := ran[name]; ok && !n.used[name] {
+					report("unused //nolint directive for " + name)
+				}
+			}
+		}
+		if len(diags) > 0 {
+			stale = append(stale, &checker.Action{
+				Analyzer:    nolintAnalyzer,
+				Package:     pkg,
+				IsRoot:      true,
+				Diagnostics: diags,
+			})
+		}
+	}
+	return stale
+}
@@ -0,0 +1,43 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package errorwrap defines an Analyzer that reports inspections of
+// errors that do not account for error wrapping.
+//
+// # Analyzer errorwrap
+//
+// errorwrap: check that wrapped errors are inspected with errors.Is and errors.As
+//
+// An error may wrap another error, for example when it is created by
+// fmt.Errorf with the %w verb, by errors.Join, or as a value of a type
+// with an Unwrap method. The errorwrap analyzer reports operations that
+// do not see through such wrapping, when the error is the result of a
+// function that may return a wrapped error:
+//
+// A comparison of the error with a sentinel error, such as io.EOF,
+// whether by == or != or in a switch statement:
+//
+//	err := load()
+//	if err == fs.ErrNotExist { // load may return a wrapped error, which comparison with fs.ErrNotExist misses
+//
+// A type switch or type assertion on the error:
+//
+//	if pe, ok := err.(*fs.PathError); ok { // type assertion on an error from load, which may be wrapped, misses wrapped errors
+//
+// It also reports a call to fmt.Errorf that formats an error with the
+// %v or %s verb, rather than wrapping it with %w, when the result is
+// tested by errors.Is:
+//
+//	return fmt.Errorf("loading config: %v", err)
+//	...
+//	if errors.Is(err, fs.ErrNotExist) {
+//
+// The analyzer suggests fixes that use errors.Is or errors.As, or the
+// %w verb, where they are straightforward.
+//
+// To learn which functions return wrapped errors, and which format
+// errors without wrapping them, the analyzer follows the values
+// returned by each function through local variables and calls, and
+// records the result as a fact about the function.
+package errorwrap
@@ -0,0 +1,677 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package errorwrap
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/constant"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/internal/analysisutil"
+	"github.com/tinygo-org/tinygo/x-tools/go/types/typeutil"
+	"github.com/tinygo-org/tinygo/x-tools/internal/analysisinternal"
+	"github.com/tinygo-org/tinygo/x-tools/internal/fmtstr"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "errorwrap",
+	Doc:       analysisutil.MustExtractDoc(doc, "errorwrap"),
+	URL:       "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/errorwrap",
+	Run:       run,
+	FactTypes: []analysis.Fact{new(errorFact)},
+}
+
+// An errorFact records how the errors returned by a function are built.
+type errorFact struct {
+	Wraps    bool // may return an error that wraps another
+	Flattens bool // may return an error that formats another without wrapping it
+}
+
+func (*errorFact) AFact() {}
+
+func (f *errorFact) String() string {
+	var parts []string
+	if f.Wraps {
+		parts = append(parts, "wraps")
+	}
+	if f.Flattens {
+		parts = append(parts, "flattens")
+	}
+	return "errors(" + strings.Join(parts, ", ") + ")"
+}
+
+var (
+	errorType  = types.Universe.Lookup("error").Type()
+	errorIface = errorType.Underlying().(*types.Interface)
+)
+
+func run(pass *analysis.Pass) (any, error) {
+	c := &checker{
+		pass:  pass,
+		funcs: make(map[*types.Func]*summary),
+	}
+
+	// Summarize the functions of the package that return errors.
+	// A function's summary depends on those of the functions it
+	// calls, so iterate until they are stable. Summaries only grow,
+	// so this terminates.
+	var decls []*ast.FuncDecl
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+				decls = append(decls, decl)
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, decl := range decls {
+			fn, ok := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+			if !ok || errorResult(fn.Type().(*types.Signature)) < 0 {
+				continue
+			}
+			if s := c.summarize(decl); !s.equal(c.funcs[fn]) {
+				c.funcs[fn] = s
+				changed = true
+			}
+		}
+	}
+	for _, decl := range decls {
+		fn, _ := pass.TypesInfo.Defs[decl.Name].(*types.Func)
+		if s := c.funcs[fn]; s != nil && s.fact != (errorFact{}) {
+			fact := s.fact
+			pass.ExportObjectFact(fn, &fact)
+		}
+	}
+
+	for _, file := range pass.Files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+				c.check(file, decl.Body)
+			}
+		}
+	}
+	return nil, nil
+}
+
+// A checker holds the state of the analysis of a package.
+type checker struct {
+	pass     *analysis.Pass
+	funcs    map[*types.Func]*summary // summaries of functions of this package
+	reported map[*ast.CallExpr]bool   // calls to fmt.Errorf already reported
+}
+
+// A summary describes the errors returned by a function of this package.
+type summary struct {
+	fact errorFact
+	flat []*ast.CallExpr // calls to fmt.Errorf whose non-wrapping results it may return
+}
+
+func (s *summary) equal(t *summary) bool {
+	return t != nil && s.fact == t.fact && slices.Equal(s.flat, t.flat)
+}
+
+// An origin describes how an error value was produced.
+type origin struct {
+	from     string // description of the producer, such as a function name
+	wraps    bool   // the error may wrap another
+	flattens bool   // the error may format another without wrapping it
+	flat     []*ast.CallExpr
+}
+
+// merge returns the union of origins o and p, either of which may be nil.
+func merge(o, p *origin) *origin {
+	if o == nil || p == nil {
+		if o == nil {
+			return p
+		}
+		return o
+	}
+	m := &origin{
+		from:     o.from,
+		wraps:    o.wraps || p.wraps,
+		flattens: o.flattens || p.flattens,
+		flat:     o.flat,
+	}
+	if !o.wraps && p.wraps {
+		m.from = p.from
+	}
+	for _, call := range p.flat {
+		if !slices.Contains(m.flat, call) {
+			m.flat = append(slices.Clip(m.flat), call)
+		}
+	}
+	return m
+}
+
+func (o *origin) equal(p *origin) bool {
+	if o == nil || p == nil {
+		return o == p
+	}
+	return o.wraps == p.wraps && o.flattens == p.flattens && len(o.flat) == len(p.flat)
+}
+
+// summarize computes the summary of the function declared by decl.
+func (c *checker) summarize(decl *ast.FuncDecl) *summary {
+	sig := c.pass.TypesInfo.Defs[decl.Name].Type().(*types.Signature)
+	i := errorResult(sig)
+	vars := c.locals(decl.Body)
+
+	var results *origin
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false // returns of another function
+		case *ast.ReturnStmt:
+			if len(n.Results) == sig.Results().Len() {
+				results = merge(results, c.origin(vars, n.Results[i]))
+			}
+		}
+		return true
+	})
+	s := new(summary)
+	if results != nil {
+		s.fact = errorFact{Wraps: results.wraps, Flattens: results.flattens}
+		s.flat = results.flat
+	}
+	return s
+}
+
+// locals returns the origins of the error values assigned to the
+// local variables of a function body.
+func (c *checker) locals(body *ast.BlockStmt) map[*types.Var]*origin {
+	info := c.pass.TypesInfo
+	vars := make(map[*types.Var]*origin)
+	changed := false
+	assign := func(lhs ast.Expr, o *origin) {
+		if id, ok := ast.Unparen(lhs).(*ast.Ident); ok && o != nil {
+			if v, ok := info.ObjectOf(id).(*types.Var); ok && isError(v.Type()) {
+				if m := merge(vars[v], o); !m.equal(vars[v]) {
+					vars[v] = m
+					changed = true
+				}
+			}
+		}
+	}
+	assignAll := func(lhs []ast.Expr, rhs []ast.Expr) {
+		switch {
+		case len(lhs) == len(rhs):
+			for i := range lhs {
+				assign(lhs[i], c.origin(vars, rhs[i]))
+			}
+		case len(rhs) == 1:
+			// A call, such as "x, err := f()", whose
+			// origin is that of its error result.
+			if call, ok := ast.Unparen(rhs[0]).(*ast.CallExpr); ok {
+				o := c.origin(vars, call)
+				for _, lhs := range lhs {
+					assign(lhs, o)
+				}
+			}
+		}
+	}
+
+	// Iterate to a fixed point, since a variable may be assigned
+	// from another that is assigned later in the body.
+	for first := true; first || changed; first = false {
+		changed = false
+		ast.Inspect(body, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				assignAll(n.Lhs, n.Rhs)
+			case *ast.ValueSpec:
+				var lhs []ast.Expr
+				for _, id := range n.Names {
+					lhs = append(lhs, id)
+				}
+				assignAll(lhs, n.Values)
+			}
+			return true
+		})
+	}
+	return vars
+}
+
+// origin returns the origin of the error value of expression e, or nil
+// if it is neither wrapped nor flattened as far as we know. The origin
+// of a call with several results is that of its error result.
+func (c *checker) origin(vars map[*types.Var]*origin, e ast.Expr) *origin {
+	info := c.pass.TypesInfo
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		if v, ok := info.Uses[e].(*types.Var); ok {
+			return vars[v]
+		}
+
+	case *ast.CallExpr:
+		fn := typeutil.StaticCallee(info, e)
+		if fn == nil {
+			return nil
+		}
+		switch {
+		case analysisinternal.IsFunctionNamed(fn, "fmt", "Errorf"):
+			return c.errorf(vars, e)
+		case analysisinternal.IsFunctionNamed(fn, "errors", "Join"):
+			return &origin{from: "errors.Join", wraps: true}
+		}
+		var fact errorFact
+		var flat []*ast.CallExpr
+		if s, ok := c.funcs[fn]; ok {
+			fact, flat = s.fact, s.flat
+		} else if fn.Pkg() != c.pass.Pkg {
+			c.pass.ImportObjectFact(fn, &fact)
+		}
+		if fact != (errorFact{}) {
+			return &origin{from: fn.FullName(), wraps: fact.Wraps, flattens: fact.Flattens, flat: flat}
+		}
+
+	case *ast.UnaryExpr:
+		if e.Op == token.AND {
+			if is[*ast.CompositeLit](ast.Unparen(e.X)) {
+				return c.literal(info.TypeOf(e))
+			}
+		}
+
+	case *ast.CompositeLit:
+		return c.literal(info.TypeOf(e))
+	}
+	return nil
+}
+
+// literal returns the origin of a composite literal, or of its
+// address, of type t.
+func (c *checker) literal(t types.Type) *origin {
+	if obj, _, _ := types.LookupFieldOrMethod(t, true, nil, "Unwrap"); obj != nil {
+		if _, ok := obj.(*types.Func); ok {
+			return &origin{
+				from:  types.TypeString(t, types.RelativeTo(c.pass.Pkg)) + " literal",
+				wraps: true,
+			}
+		}
+	}
+	return nil
+}
+
+// errorf returns the origin of the result of a call to fmt.Errorf.
+func (c *checker) errorf(vars map[*types.Var]*origin, call *ast.CallExpr) *origin {
+	o := &origin{from: "fmt.Errorf"}
+	for _, op := range c.errorOperations(call) {
+		switch op.Verb.Verb {
+		case 'w':
+			o.wraps = true
+			if inner := c.origin(vars, call.Args[op.Verb.ArgIndex]); inner != nil && inner.flattens {
+				o = merge(o, &origin{flattens: true, flat: inner.flat})
+			}
+		case 'v', 's':
+			o.flattens = true
+			if !slices.Contains(o.flat, call) {
+				o.flat = append(o.flat, call)
+			}
+		}
+	}
+	if !o.wraps && !o.flattens {
+		return nil
+	}
+	return o
+}
+
+// errorOperations returns the operations of a call to fmt.Errorf with
+// a constant format whose operands are errors.
+func (c *checker) errorOperations(call *ast.CallExpr) []*fmtstr.Operation {
+	info := c.pass.TypesInfo
+	if len(call.Args) < 2 {
+		return nil
+	}
+	tv := info.Types[call.Args[0]]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		return nil
+	}
+	ops, err := fmtstr.Parse(constant.StringVal(tv.Value), 0)
+	if err != nil {
+		return nil
+	}
+	var errorOps []*fmtstr.Operation
+	for _, op := range ops {
+		i := op.Verb.ArgIndex
+		if 0 < i && i < len(call.Args) && isError(info.TypeOf(call.Args[i])) {
+			errorOps = append(errorOps, op)
+		}
+	}
+	return errorOps
+}
+
+// check reports the inspections of errors in a function body that do
+// not account for wrapping.
+func (c *checker) check(file *ast.File, body *ast.BlockStmt) {
+	info := c.pass.TypesInfo
+	vars := c.locals(body)
+	fixed := make(map[*ast.TypeAssertExpr]bool) // assertions reported with a fix
+
+	ast.Inspect(body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.BinaryExpr:
+			if n.Op != token.EQL && n.Op != token.NEQ {
+				break
+			}
+			x, sentinel := n.X, n.Y
+			if !isSentinel(info, sentinel) {
+				x, sentinel = sentinel, x
+			}
+			if !isSentinel(info, sentinel) {
+				break
+			}
+			if o := c.origin(vars, x); o != nil && o.wraps {
+				_, prefix, edits := analysisinternal.AddImport(info, file, "errors", "errors", "Is", n.Pos())
+				not := ""
+				if n.Op == token.NEQ {
+					not = "!"
+				}
+				c.pass.Report(analysis.Diagnostic{
+					Pos:     n.Pos(),
+					End:     n.End(),
+					Message: fmt.Sprintf("%s may return a wrapped error, which comparison with %s misses; use errors.Is", o.from, c.format(sentinel)),
+					SuggestedFixes: []analysis.SuggestedFix{{
+						Message: "Use errors.Is",
+						TextEdits: append(edits, analysis.TextEdit{
+							Pos:     n.Pos(),
+							End:     n.End(),
+							NewText: fmt.Appendf(nil, "%s%sIs(%s, %s)", not, prefix, c.format(x), c.format(sentinel)),
+						}),
+					}},
+				})
+			}
+
+		case *ast.SwitchStmt:
+			if n.Tag != nil {
+				c.checkSwitch(file, vars, n)
+			}
+
+		case *ast.TypeSwitchStmt:
+			var assert *ast.TypeAssertExpr
+			switch stmt := n.Assign.(type) {
+			case *ast.ExprStmt:
+				assert, _ = stmt.X.(*ast.TypeAssertExpr)
+			case *ast.AssignStmt:
+				assert, _ = stmt.Rhs[0].(*ast.TypeAssertExpr)
+			}
+			if assert == nil {
+				break
+			}
+			o := c.origin(vars, assert.X)
+			if o == nil || !o.wraps {
+				break
+			}
+			for _, clause := range n.Body.List {
+				if slices.ContainsFunc(clause.(*ast.CaseClause).List, func(e ast.Expr) bool { return isConcrete(info, e) }) {
+					c.pass.ReportRangef(assert, "type switch on an error from %s, which may be wrapped, misses wrapped errors; use errors.As", o.from)
+					break
+				}
+			}
+
+		case *ast.IfStmt:
+			if assert, fix := c.asFix(file, vars, n); fix != nil {
+				fixed[assert] = true
+				o := c.origin(vars, assert.X)
+				c.pass.Report(analysis.Diagnostic{
+					Pos:            assert.Pos(),
+					End:            assert.End(),
+					Message:        fmt.Sprintf("type assertion on an error from %s, which may be wrapped, misses wrapped errors; use errors.As", o.from),
+					SuggestedFixes: []analysis.SuggestedFix{*fix},
+				})
+			}
+
+		case *ast.TypeAssertExpr:
+			if n.Type == nil || fixed[n] || !isConcrete(info, n.Type) {
+				break
+			}
+			if o := c.origin(vars, n.X); o != nil && o.wraps {
+				c.pass.ReportRangef(n, "type assertion on an error from %s, which may be wrapped, misses wrapped errors; use errors.As", o.from)
+			}
+
+		case *ast.CallExpr:
+			if len(n.Args) == 2 && analysisinternal.IsFunctionNamed(typeutil.Callee(info, n), "errors", "Is") {
+				if o := c.origin(vars, n.Args[0]); o != nil && o.flattens {
+					c.checkIs(n, o)
+				}
+			}
+		}
+		return true
+	})
+}
+
+// checkSwitch reports a switch statement whose tag is an error that
+// may be wrapped and whose cases include sentinel errors.
+func (c *checker) checkSwitch(file *ast.File, vars map[*types.Var]*origin, stmt *ast.SwitchStmt) {
+	info := c.pass.TypesInfo
+	o := c.origin(vars, stmt.Tag)
+	if o == nil || !o.wraps {
+		return
+	}
+	var (
+		sentinels []ast.Expr
+		fixable   = stmt.Init == nil && is[*ast.Ident](ast.Unparen(stmt.Tag))
+	)
+	for _, clause := range stmt.Body.List {
+		for _, e := range clause.(*ast.CaseClause).List {
+			if isSentinel(info, e) {
+				sentinels = append(sentinels, e)
+			} else if info.Types[e].IsNil() {
+				// Compare with nil below.
+			} else {
+				fixable = false
+			}
+		}
+	}
+	if len(sentinels) == 0 {
+		return
+	}
+
+	// Rewrite the switch to a tagless one whose cases use errors.Is.
+	var fixes []analysis.SuggestedFix
+	if fixable {
+		_, prefix, edits := analysisinternal.AddImport(info, file, "errors", "errors", "Is", stmt.Pos())
+		tag := c.format(stmt.Tag)
+		edits = append(edits, analysis.TextEdit{
+			Pos: stmt.Tag.Pos(),
+			End: stmt.Body.Lbrace,
+		})
+		for _, clause := range stmt.Body.List {
+			for _, e := range clause.(*ast.CaseClause).List {
+				var text string
+				if info.Types[e].IsNil() {
+					text = fmt.Sprintf("%s == nil", tag)
+				} else {
+					text = fmt.Sprintf("%sIs(%s, %s)", prefix, tag, c.format(e))
+				}
+				edits = append(edits, analysis.TextEdit{Pos: e.Pos(), End: e.End(), NewText: []byte(text)})
+			}
+		}
+		fixes = []analysis.SuggestedFix{{Message: "Use errors.Is in each case", TextEdits: edits}}
+	}
+	c.pass.Report(analysis.Diagnostic{
+		Pos:            stmt.Tag.Pos(),
+		End:            stmt.Tag.End(),
+		Message:        fmt.Sprintf("%s may return a wrapped error, which comparison with %s misses; use errors.Is", o.from, c.format(sentinels[0])),
+		SuggestedFixes: fixes,
+	})
+}
+
+// asFix returns a fix that replaces the type assertion in an if
+// statement of the form
+//
+//	if v, ok := err.(T); ok {
+//
+// by a call to errors.As, if err is an error that may be wrapped. It
+// declares v before the if statement:
+//
+//	var v T
+//	if errors.As(err, &v) {
+func (c *checker) asFix(file *ast.File, vars map[*types.Var]*origin, stmt *ast.IfStmt) (*ast.TypeAssertExpr, *analysis.SuggestedFix) {
+	info := c.pass.TypesInfo
+	assign, ok := stmt.Init.(*ast.AssignStmt)
+	if !ok || assign.Tok != token.DEFINE || len(assign.Lhs) != 2 || len(assign.Rhs) != 1 {
+		return nil, nil
+	}
+	assert, ok := assign.Rhs[0].(*ast.TypeAssertExpr)
+	if !ok || !isConcrete(info, assert.Type) {
+		return nil, nil
+	}
+	if o := c.origin(vars, assert.X); o == nil || !o.wraps {
+		return nil, nil
+	}
+	v, _ := assign.Lhs[0].(*ast.Ident)
+	okID, _ := assign.Lhs[1].(*ast.Ident)
+	cond, _ := stmt.Cond.(*ast.Ident)
+	if v == nil || v.Name == "_" || okID == nil || cond == nil || info.Uses[cond] != info.Defs[okID] {
+		return nil, nil
+	}
+
+	// ok must be used only by the condition.
+	okObj := info.Defs[okID]
+	for id, obj := range info.Uses {
+		if obj == okObj && id != cond {
+			return nil, nil
+		}
+	}
+
+	// v must not conflict with a declaration in or around the
+	// block that encloses the if statement.
+	scope := info.Scopes[stmt].Parent()
+	if scope.Lookup(v.Name) != nil {
+		return nil, nil
+	}
+	if _, obj := scope.LookupParent(v.Name, stmt.Pos()); obj != nil {
+		return nil, nil
+	}
+
+	_, prefix, edits := analysisinternal.AddImport(info, file, "errors", "errors", "As", stmt.Pos())
+	indent := strings.Repeat("\t", c.pass.Fset.Position(stmt.Pos()).Column-1)
+	return assert, &analysis.SuggestedFix{
+		Message: "Use errors.As",
+		TextEdits: append(edits,
+			analysis.TextEdit{
+				Pos:     stmt.Pos(),
+				End:     stmt.Pos(),
+				NewText: fmt.Appendf(nil, "var %s %s\n%s", v.Name, c.format(assert.Type), indent),
+			},
+			analysis.TextEdit{
+				Pos:     assign.Pos(),
+				End:     cond.End(),
+				NewText: fmt.Appendf(nil, "%sAs(%s, &%s)", prefix, c.format(assert.X), v.Name),
+			}),
+	}
+}
+
+// checkIs reports a call to errors.Is whose first operand may be an
+// error that formats another without wrapping it. If the formatting is
+// done by a call to fmt.Errorf in this package, it reports the call,
+// with a fix to use %w; otherwise it reports the call to errors.Is.
+func (c *checker) checkIs(is *ast.CallExpr, o *origin) {
+	if len(o.flat) == 0 {
+		c.pass.ReportRangef(is, "%s may return an error that formats another with %%v or %%s, which errors.Is cannot see through", o.from)
+		return
+	}
+	if c.reported == nil {
+		c.reported = make(map[*ast.CallExpr]bool)
+	}
+	for _, call := range o.flat {
+		if c.reported[call] {
+			continue
+		}
+		c.reported[call] = true
+
+		// Replace each %v or %s applied to an error by %w,
+		// if the format is a literal without escapes and the
+		// operations have no flags, width, or precision.
+		var edits []analysis.TextEdit
+		lit, ok := call.Args[0].(*ast.BasicLit)
+		if ok && lit.Value[1:len(lit.Value)-1] != constant.StringVal(c.pass.TypesInfo.Types[lit].Value) {
+			ok = false
+		}
+		for _, op := range c.errorOperations(call) {
+			if op.Verb.Verb != 'v' && op.Verb.Verb != 's' {
+				continue
+			}
+			if op.Flags != "" || op.Width.Fixed >= 0 || op.Width.Dynamic >= 0 || op.Prec.Fixed >= 0 || op.Prec.Dynamic >= 0 {
+				ok = false
+			}
+			if ok {
+				pos := lit.Pos() + 1 + token.Pos(op.Verb.Range.Start)
+				edits = append(edits, analysis.TextEdit{Pos: pos, End: pos + 1, NewText: []byte("w")})
+			}
+		}
+		var fixes []analysis.SuggestedFix
+		if ok && len(edits) > 0 {
+			fixes = []analysis.SuggestedFix{{Message: "Wrap the error with %w", TextEdits: edits}}
+		}
+		c.pass.Report(analysis.Diagnostic{
+			Pos:            call.Pos(),
+			End:            call.End(),
+			Message:        "fmt.Errorf formats an error with %v or %s rather than wrapping it with %w, but the result is tested by errors.Is",
+			SuggestedFixes: fixes,
+			Related: []analysis.RelatedInformation{{
+				Pos:     is.Pos(),
+				End:     is.End(),
+				Message: "tested by errors.Is here",
+			}},
+		})
+	}
+}
+
+// format returns the source text of expression e.
+func (c *checker) format(e ast.Expr) string {
+	return analysisinternal.Format(c.pass.Fset, e)
+}
+
+// errorResult returns the index of the last result of sig if it is of
+// type error, or -1 otherwise.
+func errorResult(sig *types.Signature) int {
+	results := sig.Results()
+	if n := results.Len(); n > 0 && types.Identical(results.At(n-1).Type(), errorType) {
+		return n - 1
+	}
+	return -1
+}
+
+// isError reports whether t is an interface type that implements error.
+func isError(t types.Type) bool {
+	return t != nil && types.IsInterface(t) && types.Implements(t, errorIface)
+}
+
+// isSentinel reports whether e denotes a package-level variable whose
+// type implements error, such as io.EOF.
+func isSentinel(info *types.Info, e ast.Expr) bool {
+	var id *ast.Ident
+	switch e := ast.Unparen(e).(type) {
+	case *ast.Ident:
+		id = e
+	case *ast.SelectorExpr:
+		id = e.Sel
+	default:
+		return false
+	}
+	v, ok := info.Uses[id].(*types.Var)
+	return ok && v.Pkg() != nil && v.Parent() == v.Pkg().Scope() && types.Implements(v.Type(), errorIface)
+}
+
+// isConcrete reports whether e denotes a type that is not an interface.
+func isConcrete(info *types.Info, e ast.Expr) bool {
+	tv, ok := info.Types[e]
+	return ok && tv.IsType() && !types.IsInterface(tv.Type)
+}
+
+func is[T any](x any) bool {
+	_, ok := x.(T)
+	return ok
+}
@@ -0,0 +1,69 @@
+package a
+
+import (
+	"b"
+	"fmt"
+	"io"
+)
+
+func compare() {
+	err := b.Load("x")
+	if err == b.ErrMissing { // want `b.Load may return a wrapped error, which comparison with b.ErrMissing misses; use errors.Is`
+		return
+	}
+	if io.EOF != err { // want `b.Load may return a wrapped error, which comparison with io.EOF misses; use errors.Is`
+		return
+	}
+	if err == nil { // ok
+		return
+	}
+	if b.Plain() == b.ErrMissing { // ok: not wrapped
+		return
+	}
+	if _, err := b.Indirect(); err == b.ErrMissing { // want `b.Indirect may return a wrapped error`
+		return
+	}
+	if b.Custom() == b.ErrMissing { // want `b.Custom may return a wrapped error`
+		return
+	}
+}
+
+func local() error { // want local:"errors\\(wraps\\)"
+	err := fmt.Errorf("local: %w", io.EOF)
+	if err == io.EOF { // want `fmt.Errorf may return a wrapped error, which comparison with io.EOF misses; use errors.Is`
+		return nil
+	}
+	return err
+}
+
+func callsLocal() {
+	if local() == io.EOF { // want `a.local may return a wrapped error`
+		return
+	}
+}
+
+func switches() {
+	err := b.Load("x")
+	switch err { // want `b.Load may return a wrapped error, which comparison with b.ErrMissing misses; use errors.Is`
+	case nil:
+	case b.ErrMissing, io.EOF:
+	}
+	switch b.Plain() { // ok
+	case b.ErrMissing:
+	}
+}
+
+func types() {
+	err := b.Custom()
+	switch err.(type) { // want `type switch on an error from b.Custom, which may be wrapped, misses wrapped errors; use errors.As`
+	case *b.Error:
+	}
+	switch err.(type) { // ok: interface cases only
+	case interface{ Timeout() bool }:
+	}
+	if e, ok := err.(*b.Error); ok { // want `type assertion on an error from b.Custom, which may be wrapped, misses wrapped errors; use errors.As`
+		print(e)
+	}
+	e2, ok := err.(*b.Error) // want `type assertion on an error from b.Custom`
+	print(e2, ok)
+}
@@ -0,0 +1,38 @@
+package a
+
+import (
+	"b"
+	"errors"
+	"fmt"
+	"io"
+)
+
+func read() error { // want read:"errors\\(flattens\\)"
+	return fmt.Errorf("read: %v", io.EOF) // want `fmt.Errorf formats an error with %v or %s rather than wrapping it with %w, but the result is tested by errors.Is`
+}
+
+func readAll() error { // want readAll:"errors\\(flattens\\)"
+	err := read()
+	return err
+}
+
+func format() error { // want format:"errors\\(flattens\\)"
+	return fmt.Errorf("format: %s", io.EOF)
+}
+
+func is() {
+	if errors.Is(readAll(), io.EOF) {
+		return
+	}
+	if errors.Is(read(), io.EOF) { // already reported
+		return
+	}
+	err := fmt.Errorf("%d: %+v", 1, io.EOF) // want `fmt.Errorf formats an error`
+	if errors.Is(err, io.EOF) {
+		return
+	}
+	if errors.Is(b.Flat(), b.ErrMissing) { // want `b.Flat may return an error that formats another with %v or %s, which errors.Is cannot see through`
+		return
+	}
+	_ = format()
+}
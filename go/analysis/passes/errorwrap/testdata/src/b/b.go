@@ -0,0 +1,41 @@
+package b
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrMissing = errors.New("missing")
+
+func Load(name string) error { // want Load:"errors\\(wraps\\)"
+	if name == "" {
+		return fmt.Errorf("load: %w", ErrMissing)
+	}
+	return nil
+}
+
+// Indirect returns the error of Load through a variable.
+func Indirect() (int, error) { // want Indirect:"errors\\(wraps\\)"
+	err := Load("x")
+	if err != nil {
+		return 0, err
+	}
+	return 1, nil
+}
+
+func Flat() error { // want Flat:"errors\\(flattens\\)"
+	return fmt.Errorf("flat: %v", ErrMissing)
+}
+
+func Plain() error {
+	return ErrMissing
+}
+
+type Error struct{ Err error }
+
+func (e *Error) Error() string { return "b: " + e.Err.Error() }
+func (e *Error) Unwrap() error { return e.Err }
+
+func Custom() error { // want Custom:"errors\\(wraps\\)"
+	return &Error{ErrMissing}
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package goroutineleak defines an Analyzer that reports goroutines
+// that may block forever.
+//
+// # Analyzer goroutineleak
+//
+// goroutineleak: report goroutines that may block forever
+//
+// A goroutine that blocks forever is never garbage collected, nor are
+// the values it refers to. The goroutineleak analyzer reports three
+// common causes of such leaks.
+//
+// First, a goroutine that sends its result on an unbuffered channel
+// that the function that started it does not receive from on every
+// path, for example because of an early return:
+//
+//	ch := make(chan result)
+//	go func() { ch <- compute() }() // goroutine may leak
+//	select {
+//	case r := <-ch:
+//		return r, nil
+//	case <-ctx.Done():
+//		return nil, ctx.Err() // no receive from ch on this path
+//	}
+//
+// Buffering the channel, with a capacity of one, fixes the leak.
+//
+// Second, a goroutine started in a loop that receives from a channel
+// until it is closed, typically using a range loop, when nothing closes
+// the channel:
+//
+//	work := make(chan job)
+//	for range n {
+//		go func() {
+//			for j := range work { // never ends: work is never closed
+//				...
+//			}
+//		}()
+//	}
+//
+// Third, a select statement with no cases, which blocks forever,
+// in a package other than main.
+//
+// The analyzer is conservative, to keep false positives rare: it
+// considers only channels created by make in the function that starts
+// the goroutine and whose every use is visible to it. It reports a
+// goroutine that sends only if its send is unconditional and it is the
+// only goroutine that uses the channel. Paths that end in a call to a
+// function that does not return, such as log.Fatal, are not considered
+// to return.
+package goroutineleak
@@ -0,0 +1,484 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package goroutineleak
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/buildssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/ctrlflow"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/internal/analysisutil"
+	"github.com/tinygo-org/tinygo/x-tools/go/cfg"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "goroutineleak",
+	Doc:      analysisutil.MustExtractDoc(doc, "goroutineleak"),
+	URL:      "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/goroutineleak",
+	Run:      run,
+	Requires: []*analysis.Analyzer{buildssa.Analyzer, ctrlflow.Analyzer},
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	cfgs := pass.ResultOf[ctrlflow.Analyzer].(*ctrlflow.CFGs)
+
+	for _, fn := range ssainput.SrcFuncs {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				switch instr := instr.(type) {
+				case *ssa.MakeChan:
+					if ch := trackChannel(fn, instr); ch != nil {
+						checkSender(pass, cfgs, ch)
+						checkReceivers(pass, ch)
+					}
+				case *ssa.Select:
+					if len(instr.States) == 0 && instr.Blocking && pass.Pkg.Name() != "main" &&
+						!strings.HasSuffix(pass.Fset.File(instr.Pos()).Name(), "_test.go") {
+						pass.Reportf(instr.Pos(), "select {} blocks forever, leaking the calling goroutine; avoid it outside package main")
+					}
+				}
+			}
+		}
+	}
+	return nil, nil
+}
+
+// A channel describes a channel created by make in a function, and
+// the operations on it, all of which are visible to the function.
+type channel struct {
+	fn         *ssa.Function
+	make       *ssa.MakeChan
+	name       string
+	recvs      []token.Pos // receives in fn: receive operators, select states, and range loops
+	sends      int         // sends in fn
+	closed     bool        // closed anywhere
+	goroutines []*goroutine
+}
+
+// A goroutine describes the operations on a channel by a goroutine
+// that runs a function literal.
+type goroutine struct {
+	go_   *ssa.Go
+	fn    *ssa.Function
+	sends []*ssa.Send
+	recvs []*ssa.UnOp
+	other bool // the goroutine uses the channel in a select statement
+}
+
+// The contexts in which a channel is used.
+type context int
+
+const (
+	inFunc      context = iota // the function that made the channel
+	inGoroutine                // a function literal run by a go statement
+	inHelper                   // a function literal called directly or by a defer statement
+)
+
+// trackChannel returns a description of the channel created by mc in
+// fn, or nil if the channel may be used in a way that is not visible.
+func trackChannel(fn *ssa.Function, mc *ssa.MakeChan) *channel {
+	ch := &channel{fn: fn, make: mc, name: "channel"}
+	if !ch.uses(fn, mc, inFunc, nil) {
+		return nil
+	}
+	return ch
+}
+
+// uses records the uses of v, which denotes the channel within
+// function fn, and reports whether they are all understood.
+func (ch *channel) uses(fn *ssa.Function, v ssa.Value, ctx context, g *goroutine) bool {
+	for _, instr := range *v.Referrers() {
+		switch instr := instr.(type) {
+		case *ssa.DebugRef:
+			// ignore
+
+		case *ssa.Send:
+			if instr.Chan != v {
+				return false // a channel of channels
+			}
+			switch ctx {
+			case inFunc:
+				ch.sends++
+			case inGoroutine:
+				g.sends = append(g.sends, instr)
+			default:
+				return false
+			}
+
+		case *ssa.UnOp:
+			switch {
+			case instr.Op == token.ARROW && ctx == inFunc:
+				ch.recvs = append(ch.recvs, instr.Pos())
+			case instr.Op == token.ARROW && ctx == inGoroutine:
+				g.recvs = append(g.recvs, instr)
+			default:
+				return false
+			}
+
+		case *ssa.Select:
+			for _, st := range instr.States {
+				if st.Chan != v {
+					continue
+				}
+				switch {
+				case ctx == inFunc && st.Dir == types.RecvOnly:
+					ch.recvs = append(ch.recvs, st.Pos)
+				case ctx == inFunc:
+					ch.sends++
+				case ctx == inGoroutine:
+					g.other = true
+				default:
+					return false
+				}
+			}
+
+		case *ssa.Call, *ssa.Defer:
+			builtin, ok := instr.(ssa.CallInstruction).Common().Value.(*ssa.Builtin)
+			if !ok {
+				return false // passed to a function
+			}
+			switch builtin.Name() {
+			case "close":
+				ch.closed = true
+			case "len", "cap":
+			default:
+				return false
+			}
+
+		case *ssa.ChangeType:
+			// Conversion to a directional channel type.
+			if !ch.uses(fn, instr, ctx, g) {
+				return false
+			}
+
+		case *ssa.Store:
+			// A variable captured by a function literal.
+			alloc, ok := instr.Addr.(*ssa.Alloc)
+			if !ok || instr.Val != v || ctx != inFunc || !ch.variable(fn, alloc, v) {
+				return false
+			}
+
+		case *ssa.MakeClosure:
+			if !ch.closure(instr, v, ctx) {
+				return false
+			}
+
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// variable records the uses of the channel through alloc, a variable
+// of fn that is assigned only the channel value v.
+func (ch *channel) variable(fn *ssa.Function, alloc *ssa.Alloc, v ssa.Value) bool {
+	ch.name = alloc.Comment
+	for _, instr := range *alloc.Referrers() {
+		switch instr := instr.(type) {
+		case *ssa.DebugRef:
+		case *ssa.Store:
+			if instr.Addr != alloc || instr.Val != v {
+				return false // another assignment
+			}
+		case *ssa.UnOp:
+			if instr.Op != token.MUL || !ch.uses(fn, instr, inFunc, nil) {
+				return false
+			}
+		case *ssa.MakeClosure:
+			if !ch.closure(instr, alloc, inFunc) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// closure records the uses of the channel by a function literal that
+// captures v, which denotes either the channel or a variable that
+// holds it.
+func (ch *channel) closure(mc *ssa.MakeClosure, v ssa.Value, ctx context) bool {
+	if ctx != inFunc {
+		return false // a nested function literal
+	}
+	lit := mc.Fn.(*ssa.Function)
+
+	// Is the function literal run by a go statement,
+	// or only called directly?
+	var g *goroutine
+	ctx = inHelper
+	for _, instr := range *mc.Referrers() {
+		switch instr := instr.(type) {
+		case *ssa.Go:
+			if instr.Call.Value != mc || len(*mc.Referrers()) > 1 {
+				return false
+			}
+			g = &goroutine{go_: instr, fn: lit}
+			ch.goroutines = append(ch.goroutines, g)
+			ctx = inGoroutine
+		case *ssa.Call, *ssa.Defer:
+			if instr.(ssa.CallInstruction).Common().Value != mc {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	for i, binding := range mc.Bindings {
+		if binding != v {
+			continue
+		}
+		fv := lit.FreeVars[i]
+		if _, ok := v.(*ssa.Alloc); !ok {
+			if !ch.uses(lit, fv, ctx, g) {
+				return false
+			}
+			continue
+		}
+		// The function literal captures the variable.
+		for _, instr := range *fv.Referrers() {
+			load, ok := instr.(*ssa.UnOp)
+			if !ok || load.Op != token.MUL || !ch.uses(lit, load, ctx, g) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkSender reports a goroutine that sends unconditionally on an
+// unbuffered channel if there is a path from the go statement to a
+// return that does not receive from the channel.
+func checkSender(pass *analysis.Pass, cfgs *ctrlflow.CFGs, ch *channel) {
+	if size, ok := ch.make.Size.(*ssa.Const); !ok || size.Int64() != 0 {
+		return // buffered
+	}
+	if ch.closed || ch.sends > 0 || len(ch.goroutines) != 1 {
+		return
+	}
+	g := ch.goroutines[0]
+	if len(g.recvs) > 0 || g.other || inLoop(g.go_.Block()) || !sendsUnconditionally(g) {
+		return
+	}
+
+	if len(ch.recvs) == 0 {
+		pass.Reportf(g.go_.Pos(), "goroutine may leak: it sends on unbuffered channel %s, which is never received from", ch.name)
+		return
+	}
+
+	var graph *cfg.CFG
+	switch syntax := ch.fn.Syntax().(type) {
+	case *ast.FuncDecl:
+		graph = cfgs.FuncDecl(syntax)
+	case *ast.FuncLit:
+		graph = cfgs.FuncLit(syntax)
+	}
+	if graph == nil {
+		return
+	}
+	if ret := pathWithoutReceive(graph, g.go_.Pos(), ch.recvs); ret != nil {
+		pass.Report(analysis.Diagnostic{
+			Pos:     g.go_.Pos(),
+			Message: fmt.Sprintf("goroutine may leak: it sends on unbuffered channel %s, which is not received from on all paths", ch.name),
+			Related: []analysis.RelatedInformation{{
+				Pos:     ret.Pos(),
+				Message: fmt.Sprintf("this return statement may be reached without receiving from %s", ch.name),
+			}},
+		})
+	}
+}
+
+// checkReceivers reports a goroutine started in a loop whose only way
+// to finish is for the channel to be closed, if it is never closed.
+func checkReceivers(pass *analysis.Pass, ch *channel) {
+	if ch.closed {
+		return
+	}
+	for _, g := range ch.goroutines {
+		if g.other || !inLoop(g.go_.Block()) {
+			continue
+		}
+		for _, recv := range g.recvs {
+			if done := closedSucc(recv); done != nil && dominatesReturns(done, g.fn) {
+				pass.Reportf(g.go_.Pos(), "goroutine started in a loop may leak: it receives from channel %s until it is closed, but %s is never closed", ch.name, ch.name)
+				break
+			}
+		}
+	}
+}
+
+// sendsUnconditionally reports whether goroutine g sends on the
+// channel on every path to a return.
+func sendsUnconditionally(g *goroutine) bool {
+	for _, send := range g.sends {
+		if dominatesReturns(send.Block(), g.fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// dominatesReturns reports whether b dominates every return of fn,
+// of which there is at least one.
+func dominatesReturns(b *ssa.BasicBlock, fn *ssa.Function) bool {
+	found := false
+	for _, ret := range fn.Blocks {
+		if _, ok := ret.Instrs[len(ret.Instrs)-1].(*ssa.Return); ok {
+			if !b.Dominates(ret) {
+				return false
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// closedSucc returns the block to which control passes when a
+// receive of the form "v, ok := <-ch" finds that the channel is
+// closed, as in a range loop, or nil if there is no such block.
+func closedSucc(recv *ssa.UnOp) *ssa.BasicBlock {
+	if !recv.CommaOk {
+		return nil
+	}
+	for _, instr := range *recv.Referrers() {
+		if ok, isExtract := instr.(*ssa.Extract); isExtract && ok.Index == 1 {
+			for _, instr := range *ok.Referrers() {
+				if cond, isIf := instr.(*ssa.If); isIf && cond.Cond == ok {
+					return cond.Block().Succs[1]
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// inLoop reports whether block b is part of a cycle.
+func inLoop(b *ssa.BasicBlock) bool {
+	seen := make(map[*ssa.BasicBlock]bool)
+	var visit func(b *ssa.BasicBlock) bool
+	visit = func(x *ssa.BasicBlock) bool {
+		if x == b {
+			return true
+		}
+		if seen[x] {
+			return false
+		}
+		seen[x] = true
+		for _, succ := range x.Succs {
+			if visit(succ) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, succ := range b.Succs {
+		if visit(succ) {
+			return true
+		}
+	}
+	return false
+}
+
+// pathWithoutReceive returns a return statement of the function whose
+// control-flow graph is g that may be reached from the go statement at
+// pos without passing through any of the receives at recvs. It
+// returns nil if there is no such path, or if any receive cannot be
+// located in the graph.
+func pathWithoutReceive(g *cfg.CFG, pos token.Pos, recvs []token.Pos) *ast.ReturnStmt {
+	// Locate the go statement, and the blocks that receive.
+	var (
+		goBlock *cfg.Block
+		rest    []ast.Node // nodes of goBlock that follow the go statement
+	)
+	receives := make(map[*cfg.Block]bool)
+	for _, b := range g.Blocks {
+		for i, n := range b.Nodes {
+			if stmt, ok := n.(*ast.GoStmt); ok && stmt.Go == pos {
+				goBlock, rest = b, b.Nodes[i+1:]
+			}
+		}
+	}
+	if goBlock == nil {
+		return nil
+	}
+	for _, recv := range recvs {
+		b := receiveBlock(g, recv)
+		if b == nil {
+			return nil
+		}
+		if b == goBlock {
+			// Does the receive follow the go statement?
+			for _, n := range rest {
+				if n.Pos() <= recv && recv < n.End() {
+					return nil
+				}
+			}
+			continue
+		}
+		receives[b] = true
+	}
+
+	if ret := goBlock.Return(); ret != nil {
+		return ret
+	}
+	seen := make(map[*cfg.Block]bool)
+	var search func(blocks []*cfg.Block) *ast.ReturnStmt
+	search = func(blocks []*cfg.Block) *ast.ReturnStmt {
+		for _, b := range blocks {
+			if seen[b] || receives[b] {
+				continue
+			}
+			seen[b] = true
+			if ret := b.Return(); ret != nil {
+				return ret
+			}
+			if ret := search(b.Succs); ret != nil {
+				return ret
+			}
+		}
+		return nil
+	}
+	return search(goBlock.Succs)
+}
+
+// receiveBlock returns the block of g in which the receive at pos
+// takes place. The receive of a case of a select statement takes
+// place at the start of the case's body, and that of a range loop at
+// the head of the loop.
+func receiveBlock(g *cfg.CFG, pos token.Pos) *cfg.Block {
+	var found *cfg.Block
+	for _, b := range g.Blocks {
+		switch stmt := b.Stmt.(type) {
+		case *ast.CommClause:
+			if b.Kind == cfg.KindSelectCaseBody && stmt.Comm.Pos() <= pos && pos < stmt.Comm.End() {
+				return b
+			}
+		case *ast.RangeStmt:
+			if b.Kind == cfg.KindRangeLoop && stmt.For == pos {
+				return b
+			}
+		}
+		for _, n := range b.Nodes {
+			if found == nil && n.Pos() <= pos && pos < n.End() {
+				found = b
+			}
+		}
+	}
+	return found
+}
@@ -0,0 +1,134 @@
+package a
+
+import (
+	"context"
+	"errors"
+	"log"
+)
+
+func compute() int { return 1 }
+
+func earlyReturn(ctx context.Context) (int, error) {
+	ch := make(chan int)
+	go func() { ch <- compute() }() // want "goroutine may leak: it sends on unbuffered channel ch, which is not received from on all paths"
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func earlyIf(fail bool) (int, error) {
+	ch := make(chan int)
+	go func() { ch <- compute() }() // want "goroutine may leak"
+	if fail {
+		return 0, errors.New("fail")
+	}
+	return <-ch, nil
+}
+
+func neverReceived() {
+	ch := make(chan int)
+	go func() { ch <- compute() }() // want "goroutine may leak: it sends on unbuffered channel ch, which is never received from"
+}
+
+func buffered(ctx context.Context) (int, error) {
+	ch := make(chan int, 1)
+	go func() { ch <- compute() }() // ok: buffered
+	select {
+	case v := <-ch:
+		return v, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+func allPaths(fail bool) int {
+	ch := make(chan int)
+	go func() { ch <- compute() }() // ok: received on every path
+	if fail {
+		log.Fatal("fail") // does not return
+	}
+	return <-ch
+}
+
+func conditionalSend(ctx context.Context, ok bool) int {
+	ch := make(chan int)
+	go func() { // ok: the send is conditional
+		if ok {
+			ch <- compute()
+		}
+	}()
+	select {
+	case v := <-ch:
+		return v
+	case <-ctx.Done():
+		return 0
+	}
+}
+
+func escapes(ctx context.Context, sink func(chan int)) int {
+	ch := make(chan int)
+	sink(ch)
+	go func() { ch <- compute() }() // ok: ch escapes
+	select {
+	case v := <-ch:
+		return v
+	case <-ctx.Done():
+		return 0
+	}
+}
+
+func workers(n int) chan<- int {
+	work := make(chan int)
+	for range n {
+		go func() { // want "goroutine started in a loop may leak: it receives from channel work until it is closed, but work is never closed"
+			for j := range work {
+				print(j)
+			}
+		}()
+	}
+	for i := range 10 {
+		work <- i
+	}
+	return nil
+}
+
+func closedWorkers(n int) {
+	work := make(chan int)
+	for range n {
+		go func() { // ok: work is closed
+			for j := range work {
+				print(j)
+			}
+		}()
+	}
+	for i := range 10 {
+		work <- i
+	}
+	close(work)
+}
+
+func quitWorkers(n int, quit chan struct{}) {
+	work := make(chan int)
+	for range n {
+		go func() { // ok: the goroutine may also stop on quit
+			for {
+				select {
+				case j := <-work:
+					print(j)
+				case <-quit:
+					return
+				}
+			}
+		}()
+	}
+	for i := range 10 {
+		work <- i
+	}
+}
+
+func block() {
+	select {} // want "select {} blocks forever, leaking the calling goroutine; avoid it outside package main"
+}
@@ -0,0 +1,8 @@
+package main
+
+func main() {
+	go serve()
+	select {} // ok: package main
+}
+
+func serve() {}
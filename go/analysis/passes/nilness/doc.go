@@ -69,4 +69,40 @@
 //	}
 //
 // ...
+//
+// # Analyzer nilnessfacts
+//
+// nilnessfacts: check for nil dereferences across function boundaries
+//
+// The nilnessfacts analyzer extends the checks of nilness across calls.
+// It summarizes each function by whether each of its results may be
+// nil or is never nil, and by which of its parameters it dereferences
+// on every path, and it exports these summaries as facts so that they
+// are available to calls from other packages. It reports a call that
+// passes nil for a parameter that the callee dereferences:
+//
+//	func name(u *User) string { return u.Name }
+//
+//	name(nil) // nil argument for parameter u of name, which dereferences it
+//
+// and a dereference, without a nil check, of a result that the callee
+// may return as nil:
+//
+//	func find(id int) *User {
+//		if id < 0 {
+//			return nil
+//		}
+//		...
+//	}
+//
+//	print(find(id).Name) // possible nil dereference in field selection: find may return nil
+//
+// A result that is nil only when the function's error result is
+// non-nil, as in the common "return nil, err" idiom, is reported only
+// if the caller ignores the error. The analyzer also reports nil
+// comparisons that are degenerate because the callee never returns
+// nil. It does not repeat the diagnostics of the nilness analyzer.
+//
+// This analyzer is not enabled by default, because it requires the
+// SSA form of every dependency of a package.
 package nilness
@@ -0,0 +1,408 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package nilness
+
+// This file defines InterproceduralAnalyzer, which extends the checks
+// of Analyzer across calls by means of a summary of each function.
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/buildssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/internal/analysisutil"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/internal/typeparams"
+)
+
+// InterproceduralAnalyzer is a variant of Analyzer that reports nil
+// dereferences that span function boundaries. It is a separate
+// analyzer, not enabled by default, because its facts require that
+// the SSA form of every dependency of a package be built.
+var InterproceduralAnalyzer = &analysis.Analyzer{
+	Name:      "nilnessfacts",
+	Doc:       analysisutil.MustExtractDoc(doc, "nilnessfacts"),
+	URL:       "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/nilness",
+	Run:       runInterprocedural,
+	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
+	FactTypes: []analysis.Fact{new(funcFact)},
+}
+
+// maxRounds bounds the number of times the functions of a package
+// are summarized. Summaries need not converge, because a summary of a
+// callee may make a condition of its caller degenerate, and so prune
+// one of the caller's returns.
+const maxRounds = 10
+
+func runInterprocedural(pass *analysis.Pass) (any, error) {
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	ip := &interproc{
+		pass:      pass,
+		summaries: make(map[*ssa.Function]*funcFact),
+	}
+
+	// Summarize the functions of the package,
+	// until the summaries are stable.
+	for range maxRounds {
+		changed := false
+		for _, fn := range ssainput.SrcFuncs {
+			sum := runFunc(pass, fn, ip)
+			if !sum.equal(ip.summaries[fn]) {
+				ip.summaries[fn] = sum
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	ip.reporting = true
+	for _, fn := range ssainput.SrcFuncs {
+		runFunc(pass, fn, ip)
+	}
+
+	for _, fn := range ssainput.SrcFuncs {
+		if sum := ip.summaries[fn]; sum != nil && fn.Parent() == nil && fn.Object() != nil {
+			pass.ExportObjectFact(fn.Object(), sum)
+		}
+	}
+	return nil, nil
+}
+
+// A funcFact summarizes the nilness of the results and parameters of
+// a function.
+type funcFact struct {
+	Results []resultNilness // nilness of each result; nil if all unknown
+	Derefs  []bool          // whether each parameter, including any receiver, is dereferenced on every path to a return; nil if none
+}
+
+func (*funcFact) AFact() {}
+
+func (f *funcFact) String() string {
+	var parts []string
+	if f.Results != nil {
+		var results []string
+		for _, r := range f.Results {
+			results = append(results, r.String())
+		}
+		parts = append(parts, "results("+strings.Join(results, ", ")+")")
+	}
+	if f.Derefs != nil {
+		var derefs []string
+		for i, d := range f.Derefs {
+			if d {
+				derefs = append(derefs, fmt.Sprint(i))
+			}
+		}
+		parts = append(parts, "derefs("+strings.Join(derefs, ", ")+")")
+	}
+	return "nilness " + strings.Join(parts, " ")
+}
+
+func (f *funcFact) equal(g *funcFact) bool {
+	if f == nil || g == nil {
+		return f == g
+	}
+	return slices.Equal(f.Results, g.Results) && slices.Equal(f.Derefs, g.Derefs)
+}
+
+// result returns the nilness of result i.
+func (f *funcFact) result(i int) resultNilness {
+	if f == nil || i >= len(f.Results) {
+		return resultUnknown
+	}
+	return f.Results[i]
+}
+
+// A resultNilness describes the nilness of a result of a function.
+type resultNilness uint8
+
+const (
+	resultUnknown  resultNilness = iota
+	resultNonNil                 // never nil
+	resultNil                    // may be nil, even when the error result is nil
+	resultNilIfErr               // nil only when the error result is non-nil
+)
+
+var resultNilnessStrings = []string{"unknown", "nonnil", "nil", "nil-if-error"}
+
+func (r resultNilness) String() string { return resultNilnessStrings[r] }
+
+// An interproc holds the state of the interprocedural analysis of a
+// package.
+type interproc struct {
+	pass      *analysis.Pass
+	summaries map[*ssa.Function]*funcFact // summaries of functions of this package
+	reporting bool                        // report diagnostics
+}
+
+// factOf returns the summary of fn, or nil if it is unknown.
+func (ip *interproc) factOf(fn *ssa.Function) *funcFact {
+	if fn == nil {
+		return nil
+	}
+	if orig := fn.Origin(); orig != nil {
+		fn = orig
+	}
+	if sum, ok := ip.summaries[fn]; ok {
+		return sum
+	}
+	// Functions from other packages are summarized by facts.
+	// Wrappers, such as bound methods, have no summary.
+	obj, ok := fn.Object().(*types.Func)
+	if !ok || obj.Pkg() == ip.pass.Pkg || fn.Signature != obj.Type() {
+		return nil
+	}
+	sum := new(funcFact)
+	if !ip.pass.ImportObjectFact(obj, sum) {
+		return nil
+	}
+	return sum
+}
+
+// seeds returns the facts that hold throughout fn because of the
+// summaries of the functions it calls.
+func (ip *interproc) seeds(fn *ssa.Function) []fact {
+	var seeds []fact
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(ssa.Value); ok {
+				if call, i := callResult(v); call != nil &&
+					ip.factOf(call.Call.StaticCallee()).result(i) == resultNonNil {
+					seeds = append(seeds, fact{v, isnonnil})
+				}
+			}
+		}
+	}
+	return seeds
+}
+
+// mayBeNil returns a description of why v may be nil if v is a result
+// of a call whose summary says so and that is not known to be non-nil
+// given the dominating stack of facts. Otherwise it returns "".
+func (ip *interproc) mayBeNil(stack []fact, v ssa.Value) string {
+	call, i := callResult(v)
+	if call == nil || nilnessOf(stack, v) != unknown {
+		return ""
+	}
+	callee := call.Call.StaticCallee()
+	switch ip.factOf(callee).result(i) {
+	case resultNil:
+		return fmt.Sprintf("%s may return nil", funcName(callee))
+	case resultNilIfErr:
+		if errorIgnored(call) {
+			return fmt.Sprintf("%s may return nil with an error, which is ignored", funcName(callee))
+		}
+	}
+	return ""
+}
+
+// checkArgs reports a call that passes a nil or possibly nil argument
+// for a parameter that the callee dereferences. It records in sum the
+// parameters of the caller that are so passed.
+func (ip *interproc) checkArgs(stack []fact, instr ssa.CallInstruction, sum *summarizer, reportf func(string, token.Pos, string, ...any)) {
+	cc := instr.Common()
+	callee := cc.StaticCallee()
+	f := ip.factOf(callee)
+	if f == nil {
+		return
+	}
+	for i, arg := range cc.Args {
+		if i >= len(f.Derefs) || !f.Derefs[i] {
+			continue
+		}
+		if nilnessOf(stack, arg) == isnil {
+			reportf("nilarg", instr.Pos(), "nil argument for parameter %s of %s, which dereferences it",
+				paramName(callee.Signature, i), funcName(callee))
+		} else if why := ip.mayBeNil(stack, arg); why != "" {
+			reportf("nilarg", instr.Pos(), "possibly nil argument for parameter %s of %s, which dereferences it: %s",
+				paramName(callee.Signature, i), funcName(callee), why)
+		}
+		sum.deref(instr.Block(), arg)
+	}
+}
+
+// A summarizer accumulates the summary of a function as runFunc
+// visits its blocks.
+type summarizer struct {
+	fn       *ssa.Function
+	returns  []*ssa.BasicBlock // blocks that end in a return
+	errIndex int               // index of the error result, or -1
+	results  []resultState
+	derefs   []bool
+}
+
+// A resultState records the nilness of one result over the returns
+// visited so far.
+type resultState struct {
+	maybeNonNil bool // some return may not be non-nil
+	nil         bool // some return is nil
+	nilNoErr    bool // some return is nil with a nil error, or without one
+}
+
+func newSummarizer(fn *ssa.Function) *summarizer {
+	s := &summarizer{
+		fn:       fn,
+		errIndex: errorResult(fn.Signature),
+		results:  make([]resultState, fn.Signature.Results().Len()),
+		derefs:   make([]bool, len(fn.Params)),
+	}
+	for _, b := range fn.Blocks {
+		if _, ok := b.Instrs[len(b.Instrs)-1].(*ssa.Return); ok {
+			s.returns = append(s.returns, b)
+		}
+	}
+	return s
+}
+
+// deref records that v is dereferenced in block b. If v is a
+// parameter and b dominates every return, the parameter is
+// dereferenced unconditionally.
+func (s *summarizer) deref(b *ssa.BasicBlock, v ssa.Value) {
+	p, ok := v.(*ssa.Parameter)
+	if !ok || len(s.returns) == 0 {
+		return
+	}
+	for _, ret := range s.returns {
+		if !b.Dominates(ret) {
+			return
+		}
+	}
+	if i := slices.Index(s.fn.Params, p); i >= 0 {
+		s.derefs[i] = true
+	}
+}
+
+// ret records the nilness of the results of a reachable return, given
+// the dominating stack of facts.
+func (s *summarizer) ret(stack []fact, ret *ssa.Return) {
+	for i, v := range ret.Results {
+		r := &s.results[i]
+		switch nilnessOf(stack, v) {
+		case isnonnil:
+		case isnil:
+			r.nil = true
+			if s.errIndex < 0 {
+				// A nil result accompanied by other results,
+				// such as "ok bool", may be part of a contract
+				// that we can't see.
+				r.nilNoErr = len(ret.Results) == 1
+			} else if nilnessOf(stack, ret.Results[s.errIndex]) == isnil {
+				r.nilNoErr = true
+			}
+			fallthrough
+		default:
+			r.maybeNonNil = true
+		}
+	}
+}
+
+// fact returns the summary of the function, or nil if nothing is known.
+func (s *summarizer) fact() *funcFact {
+	f := new(funcFact)
+	for i, r := range s.results {
+		if i == s.errIndex || !isNillableResult(s.fn.Signature.Results().At(i).Type()) {
+			continue
+		}
+		var n resultNilness
+		switch {
+		case r.nilNoErr:
+			n = resultNil
+		case r.nil && s.errIndex >= 0:
+			n = resultNilIfErr
+		case !r.maybeNonNil && !r.nil && len(s.returns) > 0:
+			n = resultNonNil
+		}
+		if n != resultUnknown {
+			if f.Results == nil {
+				f.Results = make([]resultNilness, len(s.results))
+			}
+			f.Results[i] = n
+		}
+	}
+	if slices.Contains(s.derefs, true) {
+		f.Derefs = s.derefs
+	}
+	if f.Results == nil && f.Derefs == nil {
+		return nil
+	}
+	return f
+}
+
+// callResult returns the call and result index of v if it is the
+// result of a call, or nil otherwise.
+func callResult(v ssa.Value) (*ssa.Call, int) {
+	switch v := v.(type) {
+	case *ssa.Call:
+		if v.Call.Signature().Results().Len() == 1 {
+			return v, 0
+		}
+	case *ssa.Extract:
+		if call, ok := v.Tuple.(*ssa.Call); ok {
+			return call, v.Index
+		}
+	}
+	return nil, 0
+}
+
+// errorIgnored reports whether the error result of call is unused.
+func errorIgnored(call *ssa.Call) bool {
+	i := errorResult(call.Call.Signature())
+	for _, instr := range *call.Referrers() {
+		if extract, ok := instr.(*ssa.Extract); ok && extract.Index == i && len(*extract.Referrers()) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// errorResult returns the index of the last result of sig if it is of
+// type error, or -1 otherwise.
+func errorResult(sig *types.Signature) int {
+	results := sig.Results()
+	if n := results.Len(); n > 0 && types.Identical(results.At(n-1).Type(), types.Universe.Lookup("error").Type()) {
+		return n - 1
+	}
+	return -1
+}
+
+// isNillableResult reports whether a nil value of type t is likely to
+// be dereferenced. Nil slices and channels are usually harmless.
+func isNillableResult(t types.Type) bool {
+	switch typeparams.CoreType(t).(type) {
+	case *types.Pointer, *types.Map, *types.Signature, *types.Interface:
+		return true
+	}
+	return false
+}
+
+// paramName returns the name of parameter i of sig, counting any
+// receiver as parameter 0.
+func paramName(sig *types.Signature, i int) string {
+	v := sig.Recv()
+	if v == nil || i > 0 {
+		if v != nil {
+			i--
+		}
+		v = sig.Params().At(i)
+	}
+	if v.Name() == "" || v.Name() == "_" {
+		return fmt.Sprintf("#%d", i)
+	}
+	return v.Name()
+}
+
+// funcName returns the name of fn for use in a diagnostic.
+func funcName(fn *ssa.Function) string {
+	if orig := fn.Origin(); orig != nil {
+		fn = orig
+	}
+	return fn.String()
+}
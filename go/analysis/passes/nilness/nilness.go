@@ -31,16 +31,19 @@ var Analyzer = &analysis.Analyzer{
 func run(pass *analysis.Pass) (any, error) {
 	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
 	for _, fn := range ssainput.SrcFuncs {
-		runFunc(pass, fn)
+		runFunc(pass, fn, nil)
 	}
 	return nil, nil
 }
 
-func runFunc(pass *analysis.Pass, fn *ssa.Function) {
+// runFunc checks function fn. If ip is non-nil, it applies the
+// interprocedural checks of InterproceduralAnalyzer instead of the
+// checks of Analyzer, and returns the summary of fn.
+func runFunc(pass *analysis.Pass, fn *ssa.Function, ip *interproc) *funcFact {
 	reportf := func(category string, pos token.Pos, format string, args ...any) {
 		// We ignore nil-checking ssa.Instructions
 		// that don't correspond to syntax.
-		if pos.IsValid() {
+		if pos.IsValid() && (ip == nil || ip.reporting) {
 			pass.Report(analysis.Diagnostic{
 				Pos:      pos,
 				Category: category,
@@ -51,11 +54,39 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 
 	// notNil reports an error if v is provably nil.
 	notNil := func(stack []fact, instr ssa.Instruction, v ssa.Value, descr string) {
-		if nilnessOf(stack, v) == isnil {
+		if ip == nil && nilnessOf(stack, v) == isnil {
 			reportf("nilderef", instr.Pos(), descr)
 		}
 	}
 
+	// In interprocedural mode, sum accumulates the summary of fn.
+	var sum *summarizer
+	if ip != nil {
+		sum = newSummarizer(fn)
+	}
+
+	// deref is like notNil, but for operations that panic if v is nil.
+	// In interprocedural mode, it also reports a dereference of a
+	// result that a callee may return as nil, and records an
+	// unconditional dereference of a parameter.
+	deref := func(stack []fact, instr ssa.Instruction, v ssa.Value, descr string) {
+		notNil(stack, instr, v, descr)
+		if ip != nil {
+			if why := ip.mayBeNil(stack, v); why != "" {
+				reportf("nilresult", instr.Pos(), "possible %s: %s", descr, why)
+			}
+			sum.deref(instr.Block(), v)
+		}
+	}
+
+	// In interprocedural mode, the stack initially holds the facts
+	// that follow from the summaries of the functions fn calls.
+	var seeds []fact
+	if ip != nil {
+		seeds = ip.seeds(fn)
+	}
+	nseed := len(seeds)
+
 	// visit visits reachable blocks of the CFG in dominance order,
 	// maintaining a stack of dominating nilness facts.
 	//
@@ -77,14 +108,17 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 				// A nil receiver may be okay for type params.
 				cc := instr.Common()
 				if !(cc.IsInvoke() && typeparams.IsTypeParam(cc.Value.Type())) {
-					notNil(stack, instr, cc.Value, "nil dereference in "+cc.Description())
+					deref(stack, instr, cc.Value, "nil dereference in "+cc.Description())
+				}
+				if ip != nil {
+					ip.checkArgs(stack, instr, sum, reportf)
 				}
 			case *ssa.FieldAddr:
-				notNil(stack, instr, instr.X, "nil dereference in field selection")
+				deref(stack, instr, instr.X, "nil dereference in field selection")
 			case *ssa.IndexAddr:
 				switch typeparams.CoreType(instr.X.Type()).(type) {
 				case *types.Pointer: // *array
-					notNil(stack, instr, instr.X, "nil dereference in array index operation")
+					deref(stack, instr, instr.X, "nil dereference in array index operation")
 				case *types.Slice:
 					// This is not necessarily a runtime error, because
 					// it is usually dominated by a bounds check.
@@ -95,25 +129,25 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 					}
 				}
 			case *ssa.MapUpdate:
-				notNil(stack, instr, instr.Map, "nil dereference in map update")
+				deref(stack, instr, instr.Map, "nil dereference in map update")
 			case *ssa.Range:
 				// (Not a runtime error, but a likely mistake.)
 				notNil(stack, instr, instr.X, "range over nil map")
 			case *ssa.Slice:
 				// A nilcheck occurs in ptr[:] iff ptr is a pointer to an array.
 				if is[*types.Pointer](instr.X.Type().Underlying()) {
-					notNil(stack, instr, instr.X, "nil dereference in slice operation")
+					deref(stack, instr, instr.X, "nil dereference in slice operation")
 				}
 			case *ssa.Store:
-				notNil(stack, instr, instr.Addr, "nil dereference in store")
+				deref(stack, instr, instr.Addr, "nil dereference in store")
 			case *ssa.TypeAssert:
 				if !instr.CommaOk {
-					notNil(stack, instr, instr.X, "nil dereference in type assertion")
+					deref(stack, instr, instr.X, "nil dereference in type assertion")
 				}
 			case *ssa.UnOp:
 				switch instr.Op {
 				case token.MUL: // *X
-					notNil(stack, instr, instr.X, "nil dereference in load")
+					deref(stack, instr, instr.X, "nil dereference in load")
 				case token.ARROW: // <-ch
 					// (Not a runtime error, but a likely mistake.)
 					notNil(stack, instr, instr.X, "receive from nil channel")
@@ -121,11 +155,18 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 			case *ssa.Send:
 				// (Not a runtime error, but a likely mistake.)
 				notNil(stack, instr, instr.Chan, "send to nil channel")
+			case *ssa.Return:
+				if sum != nil {
+					sum.ret(stack, instr)
+				}
 			}
 		}
 
 		// Look for panics with nil value
 		for _, instr := range b.Instrs {
+			if ip != nil {
+				break // reported by Analyzer
+			}
 			switch instr := instr.(type) {
 			case *ssa.Panic:
 				if nilnessOf(stack, instr.X) == isnil {
@@ -156,7 +197,13 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 				} else {
 					adj = "impossible"
 				}
-				reportf("cond", binop.Pos(), "%s condition: %s %s %s", adj, xnil, binop.Op, ynil)
+				// In interprocedural mode, report only the
+				// conditions that depend on the summary of a callee.
+				if ip == nil ||
+					nilnessOf(stack[nseed:], binop.X) == unknown ||
+					nilnessOf(stack[nseed:], binop.Y) == unknown {
+					reportf("cond", binop.Pos(), "%s condition: %s %s %s", adj, xnil, binop.Op, ynil)
+				}
 
 				// If tsucc's or fsucc's sole incoming edge is impossible,
 				// it is unreachable.  Prune traversal of it and
@@ -253,8 +300,13 @@ func runFunc(pass *analysis.Pass, fn *ssa.Function) {
 
 	// Visit the entry block.  No need to visit fn.Recover.
 	if fn.Blocks != nil {
-		visit(fn.Blocks[0], make([]fact, 0, 20)) // 20 is plenty
+		visit(fn.Blocks[0], append(make([]fact, 0, nseed+20), seeds...)) // 20 is plenty
+	}
+
+	if sum == nil {
+		return nil
 	}
+	return sum.fact()
 }
 
 // A fact records that a block is dominated
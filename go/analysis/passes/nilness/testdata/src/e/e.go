@@ -0,0 +1,72 @@
+package e
+
+import "f"
+
+func args() {
+	f.Get(nil)       // want "nil argument for parameter t of f.Get, which dereferences it"
+	f.Safe(nil)      // ok: Safe checks for nil
+	f.Get(f.Find(1)) // want `possibly nil argument for parameter t of f.Get, which dereferences it: f.Find may return nil`
+	f.Get(f.New())   // ok
+	get(nil)         // want `nil argument for parameter t of e.get, which dereferences it`
+
+	var t *f.T
+	t.Get() // want `nil argument for parameter t of \(\*f.T\).Get, which dereferences it`
+}
+
+// get dereferences t through its call to f.Get.
+func get(t *f.T) int { // want get:`nilness derefs\(0\)`
+	return f.Get(t)
+}
+
+func results() {
+	print(f.Find(1).X) // want "possible nil dereference in field selection: f.Find may return nil"
+	if t := f.Find(2); t != nil {
+		print(t.X) // ok
+	}
+	print(f.New().X) // ok
+
+	t, _ := f.Open("x")
+	print(t.X) // want "possible nil dereference in field selection: f.Open may return nil with an error, which is ignored"
+
+	t, err := f.Open("y")
+	if err != nil {
+		return
+	}
+	print(t.X) // ok
+
+	m := map[int]*f.T{}
+	if t, ok := f.Lookup(m, 1); ok {
+		print(t.X) // ok: Lookup's contract is not known
+	}
+
+	print(maybe(true).X) // want "possible nil dereference in field selection: e.maybe may return nil"
+}
+
+func maybe(b bool) *f.T { // want maybe:`nilness results\(nil\)`
+	if b {
+		return nil
+	}
+	return f.New()
+}
+
+// wrap never returns nil, because f.New never does.
+func wrap() *f.T { // want wrap:`nilness results\(nonnil\)`
+	return f.New()
+}
+
+func conds() {
+	if f.New() == nil { // want "impossible condition: non-nil == nil"
+		print("unreachable")
+	}
+	if wrap() != nil { // want "tautological condition: non-nil != nil"
+		print("reachable")
+	}
+	if f.Find(1) == nil { // ok
+		print("not found")
+	}
+}
+
+func intraprocedural() {
+	var t *f.T
+	print(t.X) // ok: reported by nilness, not nilnessfacts
+}
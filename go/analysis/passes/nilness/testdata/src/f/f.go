@@ -0,0 +1,47 @@
+package f
+
+type T struct{ X int }
+
+func Get(t *T) int { // want Get:`nilness derefs\(0\)`
+	return t.X
+}
+
+func (t *T) Get() int { // want Get:`nilness derefs\(0\)`
+	return t.X
+}
+
+func Safe(t *T) int {
+	if t == nil {
+		return 0
+	}
+	return t.X
+}
+
+func Find(k int) *T { // want Find:`nilness results\(nil\)`
+	if k < 0 {
+		return nil
+	}
+	return &T{k}
+}
+
+func New() *T { // want New:`nilness results\(nonnil\)`
+	return &T{}
+}
+
+type Error struct{}
+
+func (*Error) Error() string { return "error" }
+
+func Open(name string) (*T, error) { // want Open:`nilness results\(nil-if-error, unknown\)`
+	if name == "" {
+		return nil, &Error{}
+	}
+	return &T{}, nil
+}
+
+func Lookup(m map[int]*T, k int) (*T, bool) {
+	if t, ok := m[k]; ok {
+		return t, true
+	}
+	return nil, false
+}
@@ -0,0 +1,14 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// The taint command applies the golang.org/x/tools/go/analysis/passes/taint
+// analysis to the specified packages of Go source code.
+package main
+
+import (
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/taint"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/singlechecker"
+)
+
+func main() { singlechecker.Main(taint.Analyzer) }
@@ -0,0 +1,44 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package taint defines an Analyzer that reports flows of untrusted
+// data from sources to sensitive sinks.
+//
+// # Analyzer taint
+//
+// taint: report flows of untrusted data to sensitive functions
+//
+// The taint analyzer tracks data from taint sources, such as the
+// fields and methods of an incoming http.Request, through the SSA form
+// of each function, and reports calls that pass it to a taint sink,
+// such as exec.Command or the query string of sql.DB.Query, without
+// first passing it through a sanitizer, such as strconv.Atoi:
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		name := r.FormValue("name")
+//		db.Query("SELECT * FROM users WHERE name = '" + name + "'") // tainted data reaches sink
+//	}
+//
+// Flows are tracked across function and package boundaries by means
+// of a summary of each function, which records whether its results or
+// the memory reachable from its parameters depend on its parameters
+// or on a source, and which of its parameters reach a sink. The
+// diagnostic for each flow reports its steps as related information.
+//
+// The analysis is intentionally approximate: it does not distinguish
+// the fields or elements of a data structure, and it assumes that a
+// call to a function whose summary is unavailable, such as a dynamic
+// call, propagates taint from each argument to its results and to the
+// memory reachable from its other arguments.
+//
+// Sources, sinks, and sanitizers are configured by the -sources,
+// -sinks, and -sanitizers flags, each a comma-separated list of names
+// that replaces the default list. A function is named as by
+// [types.Func.FullName], such as "os/exec.Command" or
+// "(*net/http.Request).FormValue", and a field of a named struct type
+// by its qualified type name and field name, such as
+// "net/http.Request.URL". A sink may be restricted to one of its
+// arguments (not counting any receiver) by a suffix #i, such as
+// "(*database/sql.DB).Query#0".
+package taint
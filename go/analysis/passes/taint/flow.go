@@ -0,0 +1,429 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package taint
+
+// This file defines the propagation of taint within a function.
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"slices"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/internal/typeparams"
+)
+
+// A flow records the taint of the values of one function.
+//
+// The taint of a pointer, slice, map, or channel (or an interface
+// holding one) includes that of the memory it refers to, without
+// distinguishing fields or elements: a store to any memory derived from
+// a value by field or element selection or loads taints the value.
+type flow struct {
+	st    *state
+	fn    *ssa.Function
+	taint map[ssa.Value]labels
+	cause map[causeKey]cause
+
+	changed bool // a taint changed during the current iteration
+}
+
+type causeKey struct {
+	v   ssa.Value
+	bit int
+}
+
+// A cause records the step by which a value became tainted with an
+// origin.
+type cause struct {
+	from   ssa.Value // tainted value from which it flowed, or nil at the origin
+	pos    token.Pos
+	msg    string // description of the step, or "" if it is not shown
+	source string // at the origin of a source, the name of the source
+}
+
+// analyze computes the taint of the values of the function, and
+// returns its summary. If report is set, it reports the flows from
+// sources to sinks.
+func (st *state) analyze(fn *ssa.Function, report bool) *summary {
+	f := &flow{
+		st:    st,
+		fn:    fn,
+		taint: make(map[ssa.Value]labels),
+		cause: make(map[causeKey]cause),
+	}
+	for i, p := range fn.Params {
+		f.add(p, paramLabel(i), cause{pos: p.Pos(), msg: fmt.Sprintf("parameter %s of %s", p.Name(), fn.Name())})
+	}
+	for f.changed = true; f.changed; {
+		f.changed = false
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				f.visit(instr)
+			}
+		}
+	}
+
+	sum := new(summary)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			switch instr := instr.(type) {
+			case *ssa.Return:
+				for _, res := range instr.Results {
+					l := f.taint[res]
+					if l&fromSource != 0 && sum.Source == "" {
+						sum.Source = f.source(res)
+					}
+					sum.Returns |= l
+				}
+			case ssa.CallInstruction:
+				f.checkSinks(instr, sum, report)
+			}
+		}
+	}
+	for i, p := range fn.Params {
+		// The taint of a parameter, other than its own,
+		// is that of the memory reachable from it.
+		if l := f.taint[p] &^ paramLabel(i); l != 0 && isPointerLike(p.Type()) {
+			if l&fromSource != 0 && sum.Source == "" {
+				sum.Source = f.source(p)
+			}
+			sum.Stores = append(sum.Stores, store{Param: i, Labels: l})
+		}
+	}
+	return sum
+}
+
+// add adds the origins l to the taint of v, recording the cause of
+// each new one.
+func (f *flow) add(v ssa.Value, l labels, c cause) {
+	new := l &^ f.taint[v]
+	if new == 0 {
+		return
+	}
+	f.taint[v] |= new
+	f.changed = true
+	for bit := range 64 {
+		if new&(1<<bit) != 0 {
+			f.cause[causeKey{v, bit}] = c
+		}
+	}
+}
+
+// flow adds the taint of x to that of v.
+func (f *flow) flow(v, x ssa.Value, pos token.Pos, msg string) {
+	if l := f.taint[x]; l != 0 {
+		f.add(v, l, cause{from: x, pos: pos, msg: msg})
+	}
+}
+
+// visit propagates the taint of the operands of instr.
+func (f *flow) visit(instr ssa.Instruction) {
+	switch instr := instr.(type) {
+	case *ssa.Store:
+		f.flow(root(instr.Addr), instr.Val, instr.Pos(), "")
+
+	case *ssa.MapUpdate:
+		f.flow(root(instr.Map), instr.Key, instr.Pos(), "")
+		f.flow(root(instr.Map), instr.Value, instr.Pos(), "")
+
+	case *ssa.Send:
+		f.flow(root(instr.Chan), instr.X, instr.Pos(), "")
+
+	case ssa.CallInstruction:
+		f.call(instr)
+
+	case *ssa.Field:
+		if name := fieldName(instr.X.Type(), instr.Field); sources[name] {
+			f.add(instr, fromSource, cause{pos: instr.Pos(), msg: "source: field " + name, source: name})
+		}
+		f.flow(instr, instr.X, instr.Pos(), "")
+
+	case *ssa.FieldAddr:
+		if name := fieldName(instr.X.Type(), instr.Field); sources[name] {
+			f.add(instr, fromSource, cause{pos: instr.Pos(), msg: "source: field " + name, source: name})
+		}
+		f.flow(instr, instr.X, instr.Pos(), "")
+
+	case *ssa.BinOp:
+		switch instr.Op {
+		case token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ:
+			// Comparisons are not tainted.
+		default:
+			f.flow(instr, instr.X, instr.Pos(), "")
+			f.flow(instr, instr.Y, instr.Pos(), "")
+		}
+
+	case *ssa.UnOp:
+		f.flow(instr, instr.X, instr.Pos(), "")
+		if instr.Op == token.MUL {
+			// A load is tainted by any store to related memory.
+			f.flow(instr, root(instr.X), instr.Pos(), "")
+		}
+
+	default:
+		if v, ok := instr.(ssa.Value); ok {
+			for _, op := range instr.Operands(nil) {
+				if *op != nil {
+					f.flow(v, *op, instr.Pos(), "")
+				}
+			}
+		}
+	}
+}
+
+// call propagates taint through a call.
+func (f *flow) call(call ssa.CallInstruction) {
+	common := call.Common()
+	result := call.Value() // nil for go and defer
+	pos := call.Pos()
+
+	if b, ok := common.Value.(*ssa.Builtin); ok {
+		f.builtin(b, common.Args, result, pos)
+		return
+	}
+
+	callee := common.StaticCallee()
+	name := calleeName(callee)
+	if sanitizers[name] {
+		return
+	}
+	if sources[name] {
+		if result != nil {
+			f.add(result, fromSource, cause{pos: pos, msg: "source: call to " + name, source: name})
+		}
+		return
+	}
+
+	// The free variables of a closure are conservatively
+	// assumed to flow to its result.
+	if mc, ok := common.Value.(*ssa.MakeClosure); ok && result != nil {
+		for _, b := range mc.Bindings {
+			f.flow(result, b, pos, "")
+		}
+	}
+
+	sum := f.st.summaryOf(callee)
+	if sum == nil {
+		f.unknownCall(common, result, pos)
+		return
+	}
+
+	// Apply the summary of the callee.
+	args := common.Args
+	apply := func(v ssa.Value, l labels) {
+		if l&fromSource != 0 {
+			f.add(v, fromSource, cause{
+				pos:    pos,
+				msg:    fmt.Sprintf("source: %s, via call to %s", sum.Source, name),
+				source: sum.Source,
+			})
+		}
+		for i, arg := range args {
+			if l&paramLabel(i) != 0 {
+				f.flow(v, arg, pos, "through call to "+name)
+			}
+		}
+	}
+	if result != nil {
+		apply(result, sum.Returns)
+	}
+	for _, st := range sum.Stores {
+		if st.Param < len(args) {
+			apply(root(args[st.Param]), st.Labels)
+		}
+	}
+}
+
+// unknownCall propagates taint through a call to an unknown function:
+// conservatively, from each operand to the result, and to the memory
+// reachable from each other operand.
+func (f *flow) unknownCall(common *ssa.CallCommon, result ssa.Value, pos token.Pos) {
+	operands := common.Args
+	if _, ok := common.Value.(*ssa.Function); !ok {
+		operands = append([]ssa.Value{common.Value}, operands...) // function value or interface receiver
+	}
+	desc := calleeName(common.StaticCallee())
+	if desc == "" {
+		desc = common.Description()
+	}
+	msg := "through call to " + desc
+	for _, x := range operands {
+		if f.taint[x] == 0 {
+			continue
+		}
+		if result != nil {
+			f.flow(result, x, pos, msg)
+		}
+		for _, y := range operands {
+			if y != x {
+				if mi, ok := y.(*ssa.MakeInterface); ok {
+					y = mi.X
+				}
+				if isPointerLike(y.Type()) {
+					f.flow(root(y), x, pos, msg)
+				}
+			}
+		}
+	}
+}
+
+// builtin propagates taint through a call to a built-in function.
+func (f *flow) builtin(b *ssa.Builtin, args []ssa.Value, result ssa.Value, pos token.Pos) {
+	switch b.Name() {
+	case "append", "min", "max", "complex", "real", "imag", "ssa:wrapnilchk":
+		if result != nil {
+			for _, arg := range args {
+				f.flow(result, arg, pos, "")
+			}
+		}
+	case "copy":
+		f.flow(root(args[0]), args[1], pos, "")
+	}
+}
+
+// checkSinks checks whether a call passes tainted data to a sink,
+// either directly or through the callee, and records the flows from
+// parameters in the summary. If report is set, it reports the flows
+// from sources.
+func (f *flow) checkSinks(call ssa.CallInstruction, sum *summary, report bool) {
+	common := call.Common()
+	callee := common.StaticCallee()
+	if callee == nil {
+		return
+	}
+	name := calleeName(callee)
+	args := common.Args
+
+	// Direct calls to sinks.
+	if indices, ok := f.st.sinks[name]; ok {
+		offset := 0
+		if callee.Signature.Recv() != nil {
+			offset = 1 // receiver is Args[0]
+		}
+		for i, arg := range args {
+			if i < offset || indices != nil && !slices.Contains(indices, i-offset) {
+				continue
+			}
+			f.sink(call, arg, name, nil, sum, report)
+		}
+	}
+
+	// Calls to functions whose parameters reach sinks.
+	if calleeSum := f.st.summaryOf(callee); calleeSum != nil && !sanitizers[name] {
+		for _, sf := range calleeSum.Sinks {
+			if sf.Param < len(args) {
+				via := append([]string{"passed to " + name}, sf.Path...)
+				f.sink(call, args[sf.Param], sf.Sink, via, sum, report)
+			}
+		}
+	}
+}
+
+// sink records the flow of the taint of arg to a sink at a call, after
+// the steps via within the callee, if any.
+func (f *flow) sink(call ssa.CallInstruction, arg ssa.Value, sink string, via []string, sum *summary, report bool) {
+	l := f.taint[arg]
+	if l&fromSource != 0 && report {
+		var related []analysis.RelatedInformation
+		for _, c := range f.path(arg, 0) {
+			related = append(related, analysis.RelatedInformation{Pos: c.pos, Message: c.msg})
+		}
+		for _, step := range via {
+			related = append(related, analysis.RelatedInformation{Pos: call.Pos(), Message: step})
+		}
+		f.st.report(call.Pos(), sink, f.source(arg), related)
+	}
+	for i := range f.fn.Params {
+		if l&paramLabel(i) == 0 || slices.ContainsFunc(sum.Sinks, func(sf sinkFlow) bool {
+			return sf.Param == i && sf.Sink == sink
+		}) {
+			continue
+		}
+		var path []string
+		for _, c := range f.path(arg, i+1) {
+			path = append(path, f.st.describe(c.pos, c.msg))
+		}
+		if via == nil {
+			path = append(path, f.st.describe(call.Pos(), "call to "+sink))
+		}
+		sum.Sinks = append(sum.Sinks, sinkFlow{Param: i, Sink: sink, Path: append(path, via...)})
+	}
+}
+
+// path returns the shown steps by which v became tainted with the
+// origin of the specified bit, from the origin.
+func (f *flow) path(v ssa.Value, bit int) []cause {
+	var steps []cause
+	seen := make(map[ssa.Value]bool)
+	for v != nil && !seen[v] {
+		seen[v] = true
+		c, ok := f.cause[causeKey{v, bit}]
+		if !ok {
+			break
+		}
+		if c.msg != "" && c.pos.IsValid() {
+			steps = append(steps, c)
+		}
+		v = c.from
+	}
+	slices.Reverse(steps)
+	return steps
+}
+
+// source returns the name of the source of the taint of v.
+func (f *flow) source(v ssa.Value) string {
+	seen := make(map[ssa.Value]bool)
+	for v != nil && !seen[v] {
+		seen[v] = true
+		c, ok := f.cause[causeKey{v, 0}]
+		if !ok {
+			break
+		}
+		if c.from == nil {
+			return c.source
+		}
+		v = c.from
+	}
+	return "a source"
+}
+
+// root returns the value from which the memory referred to by v is
+// derived by field and element selections, loads, and conversions.
+func root(v ssa.Value) ssa.Value {
+	for {
+		switch x := v.(type) {
+		case *ssa.FieldAddr:
+			v = x.X
+		case *ssa.IndexAddr:
+			v = x.X
+		case *ssa.Slice:
+			v = x.X
+		case *ssa.ChangeType:
+			v = x.X
+		case *ssa.MakeInterface:
+			v = x.X
+		case *ssa.UnOp:
+			if x.Op != token.MUL {
+				return v
+			}
+			v = x.X
+		default:
+			return v
+		}
+	}
+}
+
+// isPointerLike reports whether values of type t refer to memory that
+// may be tainted.
+func isPointerLike(t types.Type) bool {
+	switch typeparams.CoreType(t).(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,391 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package taint
+
+import (
+	_ "embed"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/buildssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/internal/analysisutil"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+)
+
+//go:embed doc.go
+var doc string
+
+var Analyzer = &analysis.Analyzer{
+	Name:      "taint",
+	Doc:       analysisutil.MustExtractDoc(doc, "taint"),
+	URL:       "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/taint",
+	Run:       run,
+	Requires:  []*analysis.Analyzer{buildssa.Analyzer},
+	FactTypes: []analysis.Fact{new(summary), new(packageSummary)},
+}
+
+func init() {
+	Analyzer.Flags.Var(sources, "sources", "comma-separated list of taint sources: functions whose results, and fields whose values, are tainted")
+	Analyzer.Flags.Var(sinks, "sinks", "comma-separated list of taint sinks: functions whose arguments (or argument #i) must not be tainted")
+	Analyzer.Flags.Var(sanitizers, "sanitizers", "comma-separated list of sanitizers: functions whose results are never tainted")
+}
+
+// sources records the functions whose results,
+// and the fields whose values, are tainted.
+var sources = nameSet{
+	"(*net/http.Request).Cookie":        true,
+	"(*net/http.Request).Cookies":       true,
+	"(*net/http.Request).FormFile":      true,
+	"(*net/http.Request).FormValue":     true,
+	"(*net/http.Request).PathValue":     true,
+	"(*net/http.Request).PostFormValue": true,
+	"(*net/http.Request).Referer":       true,
+	"(*net/http.Request).UserAgent":     true,
+	"net/http.Request.Body":             true,
+	"net/http.Request.Form":             true,
+	"net/http.Request.Header":           true,
+	"net/http.Request.Host":             true,
+	"net/http.Request.MultipartForm":    true,
+	"net/http.Request.PostForm":         true,
+	"net/http.Request.RequestURI":       true,
+	"net/http.Request.URL":              true,
+}
+
+// sinks records the functions whose arguments must not be tainted.
+// A suffix #i restricts a sink to argument i.
+var sinks = nameSet{
+	"os/exec.Command":                        true,
+	"os/exec.CommandContext":                 true,
+	"(*database/sql.Conn).ExecContext#1":     true,
+	"(*database/sql.Conn).PrepareContext#1":  true,
+	"(*database/sql.Conn).QueryContext#1":    true,
+	"(*database/sql.Conn).QueryRowContext#1": true,
+	"(*database/sql.DB).Exec#0":              true,
+	"(*database/sql.DB).ExecContext#1":       true,
+	"(*database/sql.DB).Prepare#0":           true,
+	"(*database/sql.DB).PrepareContext#1":    true,
+	"(*database/sql.DB).Query#0":             true,
+	"(*database/sql.DB).QueryContext#1":      true,
+	"(*database/sql.DB).QueryRow#0":          true,
+	"(*database/sql.DB).QueryRowContext#1":   true,
+	"(*database/sql.Tx).Exec#0":              true,
+	"(*database/sql.Tx).ExecContext#1":       true,
+	"(*database/sql.Tx).Prepare#0":           true,
+	"(*database/sql.Tx).PrepareContext#1":    true,
+	"(*database/sql.Tx).Query#0":             true,
+	"(*database/sql.Tx).QueryContext#1":      true,
+	"(*database/sql.Tx).QueryRow#0":          true,
+	"(*database/sql.Tx).QueryRowContext#1":   true,
+}
+
+// sanitizers records the functions whose results are never tainted.
+var sanitizers = nameSet{
+	"html.EscapeString":    true,
+	"net/url.PathEscape":   true,
+	"net/url.QueryEscape":  true,
+	"path/filepath.Base":   true,
+	"strconv.Atoi":         true,
+	"strconv.ParseBool":    true,
+	"strconv.ParseFloat":   true,
+	"strconv.ParseInt":     true,
+	"strconv.ParseUint":    true,
+	"strconv.Quote":        true,
+	"strconv.QuoteToASCII": true,
+}
+
+// nameSet is a set-of-names-valued flag.
+// Unlike most set-valued flags, setting it replaces its elements.
+type nameSet map[string]bool
+
+func (ns nameSet) String() string {
+	var list []string
+	for name := range ns {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ",")
+}
+
+func (ns nameSet) Set(flag string) error {
+	clear(ns)
+	for _, name := range strings.Split(flag, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			return fmt.Errorf("empty name")
+		}
+		ns[name] = true
+	}
+	return nil
+}
+
+// parseSinks returns the indices of the arguments, not counting any
+// receiver, that must not be tainted, for each sink. A nil slice means
+// all arguments.
+func parseSinks() map[string][]int {
+	args := make(map[string][]int)
+	for sink := range sinks {
+		name, index, ok := strings.Cut(sink, "#")
+		if !ok {
+			args[name] = nil
+			continue
+		}
+		i, err := strconv.Atoi(index)
+		if err != nil || i < 0 {
+			continue
+		}
+		if prev, ok := args[name]; !ok || prev != nil {
+			args[name] = append(prev, i)
+		}
+	}
+	for _, list := range args {
+		sort.Ints(list)
+	}
+	return args
+}
+
+// A summary is a fact that summarizes the flow of tainted data through
+// a function, for use at its calls. Parameters are numbered as in
+// [ssa.Function.Params], so a receiver is parameter 0.
+//
+// Only nonempty summaries are exported: a function without one in a
+// package with a packageSummary has no flows.
+type summary struct {
+	Returns labels     // origins of the taint of its results
+	Stores  []store    // taint stored in memory reachable from its parameters
+	Sinks   []sinkFlow // flows from its parameters to sinks
+	Source  string     // name of the source, if Returns or Stores include a source
+	Opaque  bool       // function has no body, so its flows are unknown
+}
+
+// A packageSummary is a fact recording that the functions of a package
+// have been summarized.
+type packageSummary struct {
+	Summaries int // number of nonempty summaries
+}
+
+func (*packageSummary) AFact() {}
+
+func (s *packageSummary) String() string { return fmt.Sprintf("taint(%d summaries)", s.Summaries) }
+
+// A store records that memory reachable from a parameter
+// receives taint from the specified origins.
+type store struct {
+	Param  int
+	Labels labels
+}
+
+// A sinkFlow records that a parameter reaches a sink.
+type sinkFlow struct {
+	Param int
+	Sink  string
+	Path  []string // steps from the parameter to the sink
+}
+
+func (*summary) AFact() {}
+
+func (s *summary) String() string {
+	if s.Opaque {
+		return "taint(opaque)"
+	}
+	var parts []string
+	if s.Returns != 0 {
+		parts = append(parts, "returns "+s.Returns.String())
+	}
+	for _, st := range s.Stores {
+		parts = append(parts, fmt.Sprintf("stores %s in param %d", st.Labels, st.Param))
+	}
+	for _, sf := range s.Sinks {
+		parts = append(parts, fmt.Sprintf("param %d reaches %s", sf.Param, sf.Sink))
+	}
+	return "taint(" + strings.Join(parts, "; ") + ")"
+}
+
+// labels is a set of the origins of tainted data, relative to a
+// function: bit 0 stands for a source, and bit i+1 for parameter i.
+// Parameters beyond the 63rd are not tracked.
+type labels uint64
+
+const fromSource labels = 1
+
+func paramLabel(i int) labels {
+	if i >= 63 {
+		return 0
+	}
+	return 1 << (i + 1)
+}
+
+func (l labels) String() string {
+	var parts []string
+	if l&fromSource != 0 {
+		parts = append(parts, "source")
+	}
+	for i := range 63 {
+		if l&paramLabel(i) != 0 {
+			parts = append(parts, fmt.Sprintf("param %d", i))
+		}
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// maxRounds bounds the number of iterations of the computation of the
+// summaries of the functions of a package.
+const maxRounds = 10
+
+// A state holds the state of the analysis of a package.
+type state struct {
+	pass      *analysis.Pass
+	pkg       *ssa.Package
+	sinks     map[string][]int           // see parseSinks
+	summaries map[*ssa.Function]*summary // summaries of the source functions of the package
+	reported  map[reportKey]bool
+}
+
+type reportKey struct {
+	pos  token.Pos
+	sink string
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	ssainput := pass.ResultOf[buildssa.Analyzer].(*buildssa.SSA)
+	st := &state{
+		pass:      pass,
+		pkg:       ssainput.Pkg,
+		sinks:     parseSinks(),
+		summaries: make(map[*ssa.Function]*summary),
+		reported:  make(map[reportKey]bool),
+	}
+
+	// Compute the summaries of the functions of the package,
+	// which may call each other recursively, by iterating
+	// to a fixed point from empty summaries.
+	for _, fn := range ssainput.SrcFuncs {
+		st.summaries[fn] = new(summary)
+	}
+	for range maxRounds {
+		changed := false
+		for _, fn := range ssainput.SrcFuncs {
+			if sum := st.analyze(fn, false); !reflect.DeepEqual(sum, st.summaries[fn]) {
+				st.summaries[fn] = sum
+				changed = true
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+
+	// Report flows from sources to sinks, and export the summaries.
+	pkgSum := new(packageSummary)
+	for _, fn := range ssainput.SrcFuncs {
+		st.analyze(fn, true)
+		sum := st.summaries[fn]
+		if fn.Blocks == nil {
+			sum = &summary{Opaque: true}
+		}
+		if obj, ok := fn.Object().(*types.Func); ok && fn.Parent() == nil && !reflect.DeepEqual(sum, new(summary)) {
+			pass.ExportObjectFact(obj, sum)
+			pkgSum.Summaries++
+		}
+	}
+	pass.ExportPackageFact(pkgSum)
+	return nil, nil
+}
+
+// summaryOf returns the summary of the function, or nil if it is
+// unknown.
+func (st *state) summaryOf(fn *ssa.Function) *summary {
+	if fn == nil {
+		return nil
+	}
+	if orig := fn.Origin(); orig != nil {
+		fn = orig // generic instantiation
+	}
+	if fn.Pkg == st.pkg {
+		if fn.Blocks == nil {
+			return nil // no body
+		}
+		return st.summaries[fn] // nil for synthetic functions
+	}
+	// Summaries apply only to declared functions,
+	// not wrappers, which have a different signature.
+	if obj, ok := fn.Object().(*types.Func); ok && fn.Signature == obj.Type() {
+		sum := new(summary)
+		if st.pass.ImportObjectFact(obj, sum) {
+			if sum.Opaque {
+				return nil
+			}
+			return sum
+		}
+		if st.pass.ImportPackageFact(obj.Pkg(), new(packageSummary)) {
+			return sum // no flows
+		}
+	}
+	return nil
+}
+
+// calleeName returns the name of a function as used in the lists of
+// sources, sinks, and sanitizers, or "" if it has none.
+func calleeName(fn *ssa.Function) string {
+	if fn == nil {
+		return ""
+	}
+	if orig := fn.Origin(); orig != nil {
+		fn = orig
+	}
+	if obj, ok := fn.Object().(*types.Func); ok {
+		return obj.FullName()
+	}
+	return ""
+}
+
+// fieldName returns the name of field i of the struct type t, or a
+// pointer to it, as used in the list of sources, or "" if it has none.
+func fieldName(t types.Type, i int) string {
+	if ptr, ok := t.Underlying().(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return ""
+	}
+	obj := named.Origin().Obj()
+	struc, ok := named.Underlying().(*types.Struct)
+	if !ok || obj.Pkg() == nil || i >= struc.NumFields() {
+		return ""
+	}
+	return obj.Pkg().Path() + "." + obj.Name() + "." + struc.Field(i).Name()
+}
+
+// report reports a flow of tainted data from a source to a sink at a
+// call, described by the steps of its path.
+func (st *state) report(pos token.Pos, sink, source string, steps []analysis.RelatedInformation) {
+	key := reportKey{pos, sink}
+	if !pos.IsValid() || st.reported[key] {
+		return
+	}
+	st.reported[key] = true
+	st.pass.Report(analysis.Diagnostic{
+		Pos:      pos,
+		Category: "taint",
+		Message:  fmt.Sprintf("tainted data from %s reaches %s", source, sink),
+		Related:  steps,
+	})
+}
+
+// describe returns a textual form of a step of a path, for use in a
+// summary: positions are not meaningful in other packages.
+func (st *state) describe(pos token.Pos, msg string) string {
+	if !pos.IsValid() {
+		return msg
+	}
+	posn := st.pass.Fset.Position(pos)
+	return fmt.Sprintf("%s:%d:%d: %s", filepath.Base(posn.Filename), posn.Line, posn.Column, msg)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package taint_test
+
+import (
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/analysistest"
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis/passes/taint"
+)
+
+func Test(t *testing.T) {
+	for name, value := range map[string]string{
+		"sources":    "(*web.Request).FormValue,web.Request.URL",
+		"sinks":      "run.Command,(*db.DB).Query#0",
+		"sanitizers": "strconv.Atoi",
+	} {
+		f := taint.Analyzer.Flags.Lookup(name)
+		defer f.Value.Set(f.Value.String())
+		if err := f.Value.Set(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testdata := analysistest.TestData()
+	results := analysistest.Run(t, testdata, taint.Analyzer, "a")
+
+	// Check the related information of a flow through another package,
+	// reported in the function crossPackageSink.
+	found := false
+	for _, result := range results {
+		var decl *ast.FuncDecl
+		for _, file := range result.Pass.Files {
+			for _, d := range file.Decls {
+				if d, ok := d.(*ast.FuncDecl); ok && d.Name.Name == "crossPackageSink" {
+					decl = d
+				}
+			}
+		}
+		if decl == nil {
+			continue
+		}
+		for _, diag := range result.Diagnostics {
+			if diag.Pos < decl.Pos() || diag.Pos >= decl.End() {
+				continue
+			}
+			found = true
+			posn := result.Pass.Fset.Position(diag.Pos)
+			var steps []string
+			for _, rel := range diag.Related {
+				steps = append(steps, rel.Message)
+			}
+			got := strings.Join(steps, "\n")
+			for _, want := range []string{
+				"source: call to (*web.Request).FormValue",
+				"passed to helper.Shell",
+				"parameter script of Shell",
+				"call to run.Command",
+			} {
+				if !strings.Contains(got, want) {
+					t.Errorf("related information of %s does not contain %q:\n%s", posn, want, got)
+				}
+			}
+		}
+	}
+	if !found {
+		t.Errorf("no diagnostic for crossPackageSink")
+	}
+}
@@ -0,0 +1,90 @@
+package a // want package:`taint\(3 summaries\)`
+
+import (
+	"db"
+	"helper"
+	"run"
+	"strconv"
+	"web"
+)
+
+func direct(r *web.Request) {
+	name := r.FormValue("name")
+	run.Command(name) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
+
+func field(r *web.Request) { // want field:`taint\(param 0 reaches run.Command\)`
+	run.Command("curl", r.URL) // want `tainted data from web.Request.URL reaches run.Command`
+}
+
+func concat(d *db.DB, r *web.Request) {
+	d.Query("SELECT * FROM users WHERE name = '" + r.FormValue("name") + "'") // want `tainted data from \(\*web.Request\).FormValue reaches \(\*db.DB\).Query`
+}
+
+func queryArgs(d *db.DB, r *web.Request) {
+	d.Query("SELECT * FROM users WHERE name = ?", r.FormValue("name")) // ok: only the query is a sink
+}
+
+func sanitized(r *web.Request) {
+	n, _ := strconv.Atoi(r.FormValue("n"))
+	run.Command("seq", strconv.Itoa(n)) // ok: sanitized
+}
+
+func untainted() {
+	run.Command("ls", "-l") // ok
+}
+
+// Flows through functions of other packages.
+
+func crossPackage(r *web.Request) {
+	run.Command(helper.Name(r)) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
+
+func crossPackageSink(r *web.Request) {
+	helper.Shell(r.FormValue("script")) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
+
+func crossPackagePropagation(r *web.Request) {
+	run.Command(helper.Upper(r.FormValue("cmd"))) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+	run.Command("seq", strconv.Itoa(helper.Length(r.FormValue("s"))))
+}
+
+func throughMemory(r *web.Request) {
+	var b helper.Builder
+	b.Add("echo ")
+	b.Add(r.FormValue("msg"))
+	run.Command("sh", "-c", b.String()) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
+
+// Flows through functions of this package.
+
+func exec(cmd string) { // want exec:`taint\(param 0 reaches run.Command\)`
+	run.Command(cmd) // ok: no source in this function
+}
+
+func execRequest(r *web.Request) {
+	exec(r.FormValue("cmd")) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
+
+func recursive(s string, n int) string { // want recursive:`taint\(returns {param 0}\)`
+	if n == 0 {
+		return s
+	}
+	return recursive(s+s, n-1)
+}
+
+func execRecursive(r *web.Request) {
+	run.Command(recursive(r.FormValue("cmd"), 3)) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
+
+func closure(r *web.Request) {
+	name := r.FormValue("name")
+	f := func() string { return name }
+	run.Command(f()) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
+
+func structField(r *web.Request) {
+	type options struct{ path string }
+	opts := &options{path: r.FormValue("path")}
+	run.Command("cat", opts.path) // want `tainted data from \(\*web.Request\).FormValue reaches run.Command`
+}
@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"run"
+	"web"
+)
+
+// Name returns tainted data from a source.
+func Name(r *web.Request) string {
+	return r.FormValue("name")
+}
+
+// Shell passes its argument to a sink.
+func Shell(script string) {
+	run.Command("sh", "-c", script)
+}
+
+// Upper propagates taint from its argument to its result.
+func Upper(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'a' <= c && c <= 'z' {
+			b[i] = c - 'a' + 'A'
+		}
+	}
+	return string(b)
+}
+
+// Length returns a result that is not tainted.
+func Length(s string) int {
+	n := 0
+	for range s {
+		n++
+	}
+	return n
+}
+
+// A Builder accumulates strings.
+type Builder struct{ parts []string }
+
+func (b *Builder) Add(s string) { b.parts = append(b.parts, s) }
+
+func (b *Builder) String() string {
+	var s string
+	for _, p := range b.parts {
+		s += p
+	}
+	return s
+}
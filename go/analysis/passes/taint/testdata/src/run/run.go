@@ -0,0 +1,11 @@
+// Package run is a stand-in for os/exec.
+package run
+
+type Cmd struct {
+	Path string
+	Args []string
+}
+
+func Command(name string, args ...string) *Cmd {
+	return &Cmd{Path: name, Args: append([]string{name}, args...)}
+}
@@ -0,0 +1,14 @@
+// Package web is a stand-in for net/http.
+package web
+
+type Request struct {
+	URL  string
+	Form map[string][]string
+}
+
+func (r *Request) FormValue(key string) string {
+	if vs := r.Form[key]; len(vs) > 0 {
+		return vs[0]
+	}
+	return ""
+}
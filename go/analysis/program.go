@@ -0,0 +1,90 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package analysis
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+)
+
+// A ProgramPass provides information to the RunProgram function of an
+// analyzer, which is applied once to a whole program after the
+// analyzer's Run function has been applied to each of its packages.
+//
+// Whole-program analysis requires a driver that analyzes a whole
+// program, such as the checker package and the singlechecker and
+// multichecker drivers based on it, or gopls, which applies it to the
+// workspace packages. Unitchecker (as invoked by 'go vet') analyzes
+// each package separately and never calls RunProgram.
+//
+// The RunProgram function should not call any of the ProgramPass
+// functions concurrently.
+type ProgramPass struct {
+	Analyzer *Analyzer // the identity of the current analyzer
+
+	Fset *token.FileSet // file position information
+
+	// Packages holds the outcome of the analyzer's Run function
+	// on each package to which it was applied, in dependency order
+	// (each package follows all of its dependencies). These are the
+	// packages requested by the user and, if the analyzer uses
+	// facts, all their dependencies. (gopls provides only the
+	// workspace packages, whose dependencies it does not hold in
+	// memory; their facts are available through AllObjectFacts and
+	// AllPackageFacts.)
+	Packages []*PackageResult
+
+	// Report reports a Diagnostic, a finding about a specific location
+	// in the analyzed source code. Drivers report only diagnostics
+	// within the packages requested by the user (see
+	// [PackageResult.Root]), and may discard others.
+	Report func(Diagnostic)
+
+	// ReadFile returns the contents of the named file, as
+	// [Pass.ReadFile] does for the pass of the file's package.
+	ReadFile func(filename string) ([]byte, error)
+
+	// AllObjectFacts returns a new slice containing all object
+	// facts of the analysis's FactTypes exported by any package
+	// of the program, in unspecified order.
+	AllObjectFacts func() []ObjectFact
+
+	// AllPackageFacts returns a new slice containing all package
+	// facts of the analysis's FactTypes exported by any package
+	// of the program, in unspecified order.
+	AllPackageFacts func() []PackageFact
+
+	/* Further fields may be added in future. */
+}
+
+// A PackageResult holds the inputs and outputs of an application of
+// an analyzer's Run function to one package of a program.
+type PackageResult struct {
+	Pkg       *types.Package // type information about the package
+	Files     []*ast.File    // the abstract syntax tree of each file
+	TypesInfo *types.Info    // type information about the syntax trees
+	Root      bool           // package was requested by the user, not merely a dependency
+	Result    any            // result of the Run function, of type Analyzer.ResultType
+}
+
+// Reportf is a helper function that reports a Diagnostic using the
+// specified position and formatted error message.
+func (pass *ProgramPass) Reportf(pos token.Pos, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	pass.Report(Diagnostic{Pos: pos, Message: msg})
+}
+
+// ReportRangef is a helper function that reports a Diagnostic using the
+// range provided.
+func (pass *ProgramPass) ReportRangef(rng Range, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	pass.Report(Diagnostic{Pos: rng.Pos(), End: rng.End(), Message: msg})
+}
+
+func (pass *ProgramPass) String() string {
+	return pass.Analyzer.Name
+}
@@ -145,6 +145,20 @@ func Run(configFile string, analyzers []*analysis.Analyzer) {
 				tree.Add(fset, cfg.ID, res.a.Name, res.diagnostics, res.err)
 			}
 			tree.Print(os.Stdout)
+		} else if analysisflags.SARIF {
+			// SARIF output.
+			//
+			// The go command runs a unitchecker once per package,
+			// so "go vet -sarif" prints a separate log for each,
+			// whose root is that package's directory; the
+			// concatenation is not itself a SARIF log.
+			// Standalone checkers emit a single log.
+			root, _ := os.Getwd()
+			sarif := analysisflags.NewSARIFLog(filepath.Base(os.Args[0]), root)
+			for _, res := range results {
+				sarif.Add(fset, res.a, res.diagnostics, res.err)
+			}
+			sarif.Print(os.Stdout)
 		} else {
 			// plain text
 			exit := 0
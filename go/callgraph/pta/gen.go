@@ -0,0 +1,481 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pta
+
+// This file defines the generation of constraints from SSA code.
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/internal/typeparams"
+)
+
+// flatten returns the components of a value of type t, in order.
+// A struct has an identity component, denoting the struct itself,
+// followed by the components of its fields; all elements of an array
+// are represented by a single element; a tuple has the components of
+// its elements; any other type has a single component.
+func (a *analysis) flatten(t types.Type) []types.Type {
+	if tuple, ok := t.(*types.Tuple); ok && tuple.Len() == 0 || isOpaque(t) {
+		return nil
+	}
+	if fl, ok := a.flat.At(t).([]types.Type); ok {
+		return fl
+	}
+	var fl []types.Type
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		fl = append(fl, t) // identity
+		for i := range u.NumFields() {
+			fl = append(fl, a.flatten(u.Field(i).Type())...)
+		}
+	case *types.Array:
+		fl = a.flatten(u.Elem())
+	case *types.Tuple:
+		for i := range u.Len() {
+			fl = append(fl, a.flatten(u.At(i).Type())...)
+		}
+	default:
+		fl = []types.Type{t}
+	}
+	a.flat.Set(t, fl)
+	return fl
+}
+
+// isOpaque reports whether t is one of the SSA builder's internal
+// types, such as the type of range iterators, or a pointer to one.
+// Values of these types have no components.
+func isOpaque(t types.Type) bool {
+	switch t := t.(type) {
+	case *types.Pointer:
+		return isOpaque(t.Elem())
+	case *types.Alias, *types.Array, *types.Basic, *types.Chan, *types.Interface,
+		*types.Map, *types.Named, *types.Signature, *types.Slice, *types.Struct,
+		*types.Tuple, *types.TypeParam, *types.Union:
+		return false
+	}
+	return true
+}
+
+// sizeof returns the number of components of a value of type t.
+func (a *analysis) sizeof(t types.Type) int {
+	return len(a.flatten(t))
+}
+
+// hasPointers reports whether a value of type t has a pointer-like
+// component.
+func (a *analysis) hasPointers(t types.Type) bool {
+	for _, c := range a.flatten(t) {
+		if isPointerLike(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPointerLike reports whether a value of type t may point to an
+// object. Type parameters, like interfaces, are pointer-like.
+func isPointerLike(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Pointer, *types.Map, *types.Chan, *types.Signature, *types.Slice, *types.Interface:
+		return true
+	case *types.Basic:
+		return u.Kind() == types.UnsafePointer
+	}
+	return false
+}
+
+// fieldOffset returns the offset of the i'th field of a struct.
+func (a *analysis) fieldOffset(t *types.Struct, i int) int {
+	offset := 1 // identity
+	for j := range i {
+		offset += a.sizeof(t.Field(j).Type())
+	}
+	return offset
+}
+
+// tupleOffset returns the offset of the i'th element of a tuple.
+func (a *analysis) tupleOffset(t *types.Tuple, i int) int {
+	offset := 0
+	for j := range i {
+		offset += a.sizeof(t.At(j).Type())
+	}
+	return offset
+}
+
+// path returns the path to the component at the given offset in a
+// value of type t, as described at Label.Path.
+func (a *analysis) path(t types.Type, offset int) string {
+	if t == nil {
+		return ""
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Struct:
+		if offset == 0 {
+			return ""
+		}
+		offset--
+		for i := range u.NumFields() {
+			f := u.Field(i)
+			size := a.sizeof(f.Type())
+			if offset < size {
+				return "." + f.Name() + a.path(f.Type(), offset)
+			}
+			offset -= size
+		}
+	case *types.Array:
+		return "[*]" + a.path(u.Elem(), offset)
+	}
+	return ""
+}
+
+// funcInfo returns the object and nodes of function fn.
+func (a *analysis) funcInfo(fn *ssa.Function) *funcInfo {
+	info := a.funcs[fn]
+	if info == nil {
+		obj := &object{site: fn, size: 1, fn: fn}
+		obj.start = a.addNodes(1, obj)
+		info = &funcInfo{obj: obj}
+		for _, p := range fn.Params {
+			info.params = append(info.params, a.valueNode(p))
+		}
+		for _, fv := range fn.FreeVars {
+			info.freevars = append(info.freevars, a.valueNode(fv))
+		}
+		if results := fn.Signature.Results(); a.hasPointers(results) {
+			info.results = a.addNodes(a.sizeof(results), nil)
+		}
+		a.funcs[fn] = info
+	}
+	return info
+}
+
+// valueNode returns the first node of value v, or zero if v has no
+// pointer-like components.
+func (a *analysis) valueNode(v ssa.Value) nodeid {
+	id, ok := a.values[v]
+	if ok {
+		return id
+	}
+	switch v := v.(type) {
+	case *ssa.Function:
+		id = a.addNodes(1, nil)
+		a.addressOf(id, a.funcInfo(v).obj.start)
+	case *ssa.Global:
+		obj := a.globals[v]
+		if obj == nil {
+			obj = a.newObject(v, typeparams.MustDeref(v.Type()))
+			a.globals[v] = obj
+		}
+		id = a.addNodes(1, nil)
+		a.addressOf(id, obj.start)
+	case *ssa.Const:
+		// A constant has no pointers, unless it is nil.
+	default:
+		if a.hasPointers(v.Type()) {
+			id = a.addNodes(a.sizeof(v.Type()), nil)
+		}
+	}
+	a.values[v] = id
+	return id
+}
+
+// load adds the constraint dst = *(src + offset), for size nodes.
+func (a *analysis) load(dst, src nodeid, offset, size int) {
+	if dst != 0 && size > 0 {
+		a.addConstraint(src, &loadConstraint{dst, offset, size})
+	}
+}
+
+// store adds the constraint *(dst + offset) = src, for size nodes.
+func (a *analysis) store(dst, src nodeid, offset, size int) {
+	if src != 0 && size > 0 {
+		a.addConstraint(dst, &storeConstraint{src, offset, size})
+	}
+}
+
+// genFunc generates the constraints for the body of fn.
+func (a *analysis) genFunc(fn *ssa.Function) {
+	info := a.funcInfo(fn)
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			a.genInstr(fn, info, instr)
+		}
+	}
+}
+
+func (a *analysis) genInstr(fn *ssa.Function, info *funcInfo, instr ssa.Instruction) {
+	var dst nodeid
+	if v, ok := instr.(ssa.Value); ok {
+		dst = a.valueNode(v)
+	}
+
+	switch instr := instr.(type) {
+	case *ssa.Alloc:
+		a.addressOf(dst, a.newObject(instr, typeparams.MustDeref(instr.Type())).start)
+
+	case *ssa.Call:
+		a.genCall(fn, instr)
+
+	case *ssa.Go:
+		a.genCall(fn, instr)
+
+	case *ssa.Defer:
+		a.genCall(fn, instr)
+
+	case *ssa.ChangeInterface:
+		a.copy(dst, a.valueNode(instr.X))
+
+	case *ssa.ChangeType:
+		a.assign(dst, instr.Type(), a.valueNode(instr.X), instr.X.Type(), instr)
+
+	case *ssa.MultiConvert:
+		a.assign(dst, instr.Type(), a.valueNode(instr.X), instr.X.Type(), instr)
+
+	case *ssa.Convert:
+		// Conversions between pointers and unsafe.Pointer preserve
+		// the referent; conversions from strings or integers make
+		// a new object.
+		if src := a.valueNode(instr.X); src != 0 && isPointerLike(instr.X.Type()) {
+			a.copy(dst, src)
+		} else if dst != 0 && isPointerLike(instr.Type()) {
+			var t types.Type = types.Typ[types.Invalid]
+			if s, ok := typeparams.CoreType(instr.Type()).(*types.Slice); ok {
+				t = types.NewArray(s.Elem(), 1)
+			}
+			a.addressOf(dst, a.newObject(instr, t).start)
+		}
+
+	case *ssa.Extract:
+		t := instr.Tuple.Type().(*types.Tuple)
+		a.copyN(dst, at(a.valueNode(instr.Tuple), a.tupleOffset(t, instr.Index)), a.sizeof(instr.Type()))
+
+	case *ssa.Field:
+		t := typeparams.CoreType(instr.X.Type()).(*types.Struct)
+		a.copyN(dst, at(a.valueNode(instr.X), a.fieldOffset(t, instr.Field)), a.sizeof(instr.Type()))
+
+	case *ssa.FieldAddr:
+		t := typeparams.CoreType(typeparams.MustDeref(instr.X.Type())).(*types.Struct)
+		if dst != 0 {
+			a.addConstraint(a.valueNode(instr.X), &offsetAddrConstraint{dst, a.fieldOffset(t, instr.Field)})
+		}
+
+	case *ssa.Index:
+		// All elements of an array are represented by one.
+		a.copyN(dst, a.valueNode(instr.X), a.sizeof(instr.Type()))
+
+	case *ssa.IndexAddr:
+		// A slice, or pointer to an array, points to its element.
+		a.copy(dst, a.valueNode(instr.X))
+
+	case *ssa.Lookup:
+		if m, ok := typeparams.CoreType(instr.X.Type()).(*types.Map); ok {
+			a.load(dst, a.valueNode(instr.X), a.sizeof(m.Key()), a.sizeof(m.Elem()))
+		}
+
+	case *ssa.MakeChan:
+		elem := typeparams.CoreType(instr.Type()).(*types.Chan).Elem()
+		obj := &object{site: instr, typ: instr.Type(), size: a.sizeof(elem)}
+		obj.start = a.addNodes(obj.size, obj)
+		a.addressOf(dst, obj.start)
+
+	case *ssa.MakeMap:
+		m := typeparams.CoreType(instr.Type()).(*types.Map)
+		obj := &object{site: instr, typ: instr.Type(), size: a.sizeof(m.Key()) + a.sizeof(m.Elem())}
+		obj.start = a.addNodes(obj.size, obj)
+		a.addressOf(dst, obj.start)
+
+	case *ssa.MakeSlice:
+		elem := typeparams.CoreType(instr.Type()).(*types.Slice).Elem()
+		a.addressOf(dst, a.newObject(instr, types.NewArray(elem, 1)).start)
+
+	case *ssa.MakeClosure:
+		callee := a.funcInfo(instr.Fn.(*ssa.Function))
+		a.addressOf(dst, callee.obj.start)
+		for i, b := range instr.Bindings {
+			a.copyN(callee.freevars[i], a.valueNode(b), a.sizeof(b.Type()))
+		}
+
+	case *ssa.MakeInterface:
+		if types.IsInterface(instr.X.Type()) {
+			// A type parameter already holds tagged objects.
+			a.copy(dst, a.valueNode(instr.X))
+		} else {
+			obj := a.newTagged(instr, instr.X.Type())
+			a.copyN(obj.start+1, a.valueNode(instr.X), a.sizeof(instr.X.Type()))
+			a.addressOf(dst, obj.start)
+		}
+
+	case *ssa.Next:
+		if instr.IsString {
+			break
+		}
+		// The result is a tuple (ok, k, v), in which k and v are
+		// invalid if unused.
+		m := typeparams.CoreType(instr.Iter.(*ssa.Range).X.Type()).(*types.Map)
+		src := a.valueNode(instr.Iter.(*ssa.Range).X)
+		t := instr.Type().(*types.Tuple)
+		ksize, vsize := a.sizeof(m.Key()), a.sizeof(m.Elem())
+		if t.At(1).Type() != types.Typ[types.Invalid] {
+			a.load(at(dst, 1), src, 0, ksize)
+		}
+		if t.At(2).Type() != types.Typ[types.Invalid] {
+			a.load(at(dst, a.tupleOffset(t, 2)), src, ksize, vsize)
+		}
+
+	case *ssa.Phi:
+		size := a.sizeof(instr.Type())
+		for _, e := range instr.Edges {
+			a.copyN(dst, a.valueNode(e), size)
+		}
+
+	case *ssa.Select:
+		// The result is a tuple (index, recvOk, r_0, ..., r_n-1).
+		offset := 2
+		for _, st := range instr.States {
+			ch := a.valueNode(st.Chan)
+			elem := typeparams.CoreType(st.Chan.Type()).(*types.Chan).Elem()
+			size := a.sizeof(elem)
+			switch st.Dir {
+			case types.SendOnly:
+				a.store(ch, a.valueNode(st.Send), 0, size)
+			case types.RecvOnly:
+				a.load(at(dst, offset), ch, 0, size)
+				offset += size
+			}
+		}
+
+	case *ssa.Slice:
+		a.copy(dst, a.valueNode(instr.X))
+
+	case *ssa.SliceToArrayPointer:
+		a.copy(dst, a.valueNode(instr.X))
+
+	case *ssa.TypeAssert:
+		// If CommaOk, the asserted value is the first element of
+		// the result tuple.
+		src := a.valueNode(instr.X)
+		if dst == 0 {
+			break
+		}
+		if types.IsInterface(instr.AssertedType) {
+			var iface *types.Interface // nil for a type parameter: any type
+			if _, ok := instr.AssertedType.(*types.TypeParam); !ok {
+				iface = instr.AssertedType.Underlying().(*types.Interface)
+			}
+			a.addConstraint(src, &typeFilterConstraint{iface, dst})
+		} else {
+			a.addConstraint(src, &untagConstraint{instr.AssertedType, dst, a.sizeof(instr.AssertedType)})
+		}
+
+	case *ssa.UnOp:
+		switch instr.Op {
+		case token.MUL:
+			a.load(dst, a.valueNode(instr.X), 0, a.sizeof(typeparams.MustDeref(instr.X.Type())))
+		case token.ARROW:
+			elem := typeparams.CoreType(instr.X.Type()).(*types.Chan).Elem()
+			a.load(dst, a.valueNode(instr.X), 0, a.sizeof(elem))
+		}
+
+	case *ssa.Store:
+		a.store(a.valueNode(instr.Addr), a.valueNode(instr.Val), 0, a.sizeof(instr.Val.Type()))
+
+	case *ssa.MapUpdate:
+		m := typeparams.CoreType(instr.Map.Type()).(*types.Map)
+		ksize := a.sizeof(m.Key())
+		a.store(a.valueNode(instr.Map), a.valueNode(instr.Key), 0, ksize)
+		a.store(a.valueNode(instr.Map), a.valueNode(instr.Value), ksize, a.sizeof(m.Elem()))
+
+	case *ssa.Send:
+		a.store(a.valueNode(instr.Chan), a.valueNode(instr.X), 0, a.sizeof(instr.X.Type()))
+
+	case *ssa.Return:
+		results := fn.Signature.Results()
+		for i, r := range instr.Results {
+			a.assign(at(info.results, a.tupleOffset(results, i)), results.At(i).Type(), a.valueNode(r), r.Type(), r)
+		}
+
+	case *ssa.Panic:
+		a.copy(a.panic, a.valueNode(instr.X))
+
+	case *ssa.BinOp, *ssa.DebugRef, *ssa.If, *ssa.Jump, *ssa.Range, *ssa.RunDefers:
+		// no pointer flow
+
+	default:
+		panic(fmt.Sprintf("unexpected instruction %T", instr))
+	}
+}
+
+// genCall generates the constraints for a call, go or defer
+// instruction.
+func (a *analysis) genCall(fn *ssa.Function, instr ssa.CallInstruction) {
+	common := instr.Common()
+	switch {
+	case common.IsInvoke():
+		a.addConstraint(a.valueNode(common.Value), &invokeConstraint{fn, instr})
+
+	case common.StaticCallee() != nil:
+		a.call(fn, instr, common.StaticCallee(), 0)
+
+	default:
+		if b, ok := common.Value.(*ssa.Builtin); ok {
+			a.genBuiltin(instr, b)
+		} else {
+			a.addConstraint(a.valueNode(common.Value), &callConstraint{fn, instr})
+		}
+	}
+}
+
+// genBuiltin generates the constraints for a call of a built-in
+// function.
+func (a *analysis) genBuiltin(instr ssa.CallInstruction, b *ssa.Builtin) {
+	args := instr.Common().Args
+	var dst nodeid
+	if v := instr.Value(); v != nil {
+		dst = a.valueNode(v)
+	}
+
+	switch b.Name() {
+	case "append":
+		// append(s, x...) returns either s or a new array that
+		// holds the elements of both.
+		if dst == 0 {
+			break
+		}
+		s := a.valueNode(args[0])
+		a.copy(dst, s)
+		elem := typeparams.CoreType(args[0].Type()).(*types.Slice).Elem()
+		size := a.sizeof(elem)
+		obj := a.newObject(instr.Value(), types.NewArray(elem, 1))
+		a.addressOf(dst, obj.start)
+		a.load(obj.start, s, 0, size)
+		if _, ok := typeparams.CoreType(args[1].Type()).(*types.Slice); ok {
+			a.load(obj.start, a.valueNode(args[1]), 0, size)
+		}
+
+	case "copy":
+		// copy(dst, src) copies the elements of src to those of dst.
+		if _, ok := typeparams.CoreType(args[1].Type()).(*types.Slice); !ok {
+			break
+		}
+		elem := typeparams.CoreType(args[0].Type()).(*types.Slice).Elem()
+		if size := a.sizeof(elem); a.hasPointers(elem) {
+			tmp := a.addNodes(size, nil)
+			a.load(tmp, a.valueNode(args[1]), 0, size)
+			a.store(a.valueNode(args[0]), tmp, 0, size)
+		}
+
+	case "recover":
+		a.copy(dst, a.panic)
+
+	case "ssa:wrapnilchk", "Add", "Slice", "SliceData":
+		// These return their first argument, or a pointer to
+		// the same object.
+		a.copy(dst, a.valueNode(args[0]))
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package pta provides a pointer analysis for Go programs in SSA form.
+// It computes the call graph of a whole program, and answers queries
+// of the form "what may this pointer, interface, slice, map, channel
+// or function value point to?".
+//
+// The analysis is inclusion-based, in the style of Andersen, as was
+// the former go/pointer package. It is field-sensitive, flow-insensitive
+// and context-insensitive: each allocation site is modeled by a single
+// abstract object, each field of a struct by a distinct part of that
+// object, and all elements of an array, slice, map or channel by a
+// single element. Each function has a single set of parameters and
+// results for all its calls.
+//
+// The analysis discovers reachable code on the fly: starting from the
+// root functions, it generates constraints for the body of each
+// function when a call to it is first found, whether the call is
+// static, through a func value, or through an interface method. A
+// dynamic call site calls exactly those functions whose values reach
+// it, and an interface method call calls the methods of exactly those
+// dynamic types that reach it, so the resulting call graph is usually
+// more precise than one computed by RTA or VTA.
+//
+// An interface value points to tagged objects, one for each site that
+// converts a non-interface value to an interface type. A tagged object
+// records the dynamic type and holds a copy of the converted value.
+//
+// Programs should be built with the [ssa.InstantiateGenerics] mode,
+// so that each instantiation of a generic function has its own body.
+// The analysis also accepts generic function bodies, treating a value
+// of type parameter type like a value of interface type.
+//
+// The analysis is unsound in the presence of reflection, unsafe pointer
+// arithmetic, and functions without bodies, such as those implemented
+// in assembly: it does not model their effects.
+package pta // import "github.com/tinygo-org/tinygo/x-tools/go/callgraph/pta"
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"sort"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/container/intsets"
+	"github.com/tinygo-org/tinygo/x-tools/go/callgraph"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/types/typeutil"
+)
+
+// A Result holds the results of the pointer analysis.
+type Result struct {
+	// CallGraph is the discovered call graph.
+	// It does not include edges for calls made via reflection.
+	CallGraph *callgraph.Graph
+
+	// Reachable contains the set of reachable functions and methods.
+	Reachable map[*ssa.Function]bool
+
+	a *analysis
+}
+
+// Analyze performs the pointer analysis of the program, starting at
+// the specified root functions. It returns nil if no roots were
+// specified.
+//
+// The root functions must be one or more entrypoints (main and init
+// functions) of a complete SSA program, with function bodies for all
+// dependencies.
+func Analyze(roots []*ssa.Function) *Result {
+	if len(roots) == 0 {
+		return nil
+	}
+
+	a := newAnalysis(roots[0].Prog)
+	a.result.CallGraph = callgraph.New(roots[0])
+	for _, root := range roots {
+		a.reach(root)
+	}
+	a.solve()
+	return a.result
+}
+
+// PointsTo returns the set of objects to which v may point.
+//
+// For a value of struct, array or tuple type, the set is the union of
+// the sets of its pointer-like components. The set is empty if v has
+// no pointer-like components, or is not part of a reachable function.
+func (r *Result) PointsTo(v ssa.Value) PointsToSet {
+	pts := new(intsets.Sparse)
+	if id := r.a.values[v]; id != 0 {
+		for i := range r.a.sizeof(v.Type()) {
+			pts.UnionWith(&r.a.nodes[id+nodeid(i)].pts)
+		}
+	}
+	return PointsToSet{r.a, pts}
+}
+
+// A PointsToSet is a set of labels, each of which denotes an abstract
+// object, or a part of one, to which a value may point.
+type PointsToSet struct {
+	a   *analysis
+	pts *intsets.Sparse
+}
+
+// Labels returns the labels of the set, in no particular order.
+func (s PointsToSet) Labels() []*Label {
+	var labels []*Label
+	for _, id := range s.pts.AppendTo(nil) {
+		labels = append(labels, &Label{s.a, nodeid(id)})
+	}
+	return labels
+}
+
+// Intersects reports whether the two sets have a label in common,
+// that is, whether values that point to them may alias.
+func (s PointsToSet) Intersects(y PointsToSet) bool {
+	return s.pts.Intersects(y.pts)
+}
+
+// DynamicTypes returns the dynamic types of the tagged objects in the
+// set, which is the points-to set of an interface value. The value
+// for each type is the PointsToSet of the pointer-like components of
+// the values of that type held by the interface.
+func (s PointsToSet) DynamicTypes() *typeutil.Map {
+	var m typeutil.Map
+	m.SetHasher(s.a.hasher)
+	for _, id := range s.pts.AppendTo(nil) {
+		obj := s.a.nodes[id].obj
+		if obj == nil || obj.tag == nil || nodeid(id) != obj.start {
+			continue
+		}
+		payload, _ := m.At(obj.tag).(PointsToSet)
+		if payload.pts == nil {
+			payload = PointsToSet{s.a, new(intsets.Sparse)}
+		}
+		for i := 1; i < obj.size; i++ {
+			payload.pts.UnionWith(&s.a.nodes[obj.start+nodeid(i)].pts)
+		}
+		m.Set(obj.tag, payload)
+	}
+	return &m
+}
+
+func (s PointsToSet) String() string {
+	var strs []string
+	for _, l := range s.Labels() {
+		strs = append(strs, l.String())
+	}
+	sort.Strings(strs)
+	return "[" + strings.Join(strs, ", ") + "]"
+}
+
+// A Label denotes an abstract object, or a field or element of one.
+//
+// An object is created by an allocation site: an [ssa.Alloc],
+// [ssa.MakeMap], [ssa.MakeChan], [ssa.MakeSlice], [ssa.MakeInterface]
+// or [ssa.Convert] instruction, a call to append, an [ssa.Global], or
+// an [ssa.Function], which denotes the function itself and all
+// closures of it.
+type Label struct {
+	a  *analysis
+	id nodeid
+}
+
+// Value returns the allocation site of the labeled object.
+func (l *Label) Value() ssa.Value {
+	return l.obj().site
+}
+
+// Pos returns the position of the allocation site of the labeled
+// object, if known.
+func (l *Label) Pos() token.Pos {
+	return l.obj().site.Pos()
+}
+
+// Path returns the path from the labeled object to the labeled part of
+// it, such as ".f" for field f of a struct, or "[*]" for the elements
+// of an array or slice. It is empty if the label denotes a whole
+// object.
+func (l *Label) Path() string {
+	obj := l.obj()
+	return l.a.path(obj.typ, int(l.id-obj.start))
+}
+
+// String returns a description of the labeled object, followed by
+// the path to the labeled part of it.
+func (l *Label) String() string {
+	obj := l.obj()
+	var s string
+	switch {
+	case obj.fn != nil:
+		s = obj.fn.String()
+	case obj.tag != nil:
+		s = "makeinterface:" + obj.tag.String()
+	default:
+		switch site := obj.site.(type) {
+		case *ssa.Global:
+			s = site.String()
+		case *ssa.Alloc:
+			s = site.Comment
+			if s == "" {
+				s = "alloc"
+			}
+		case *ssa.Call:
+			s = site.Call.Value.Name()
+		default:
+			s = strings.ToLower(strings.TrimPrefix(fmt.Sprintf("%T", site), "*ssa."))
+		}
+	}
+	return s + l.Path()
+}
+
+func (l *Label) obj() *object {
+	return l.a.nodes[l.id].obj
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// No testdata on Android.
+
+//go:build !android
+
+package pta_test
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/callgraph"
+	"github.com/tinygo-org/tinygo/x-tools/go/callgraph/pta"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa/ssautil"
+	"github.com/tinygo-org/tinygo/x-tools/internal/testfiles"
+	"github.com/tinygo-org/tinygo/x-tools/txtar"
+)
+
+// TestPTA runs the pointer analysis on each testdata/*.txtar file,
+// which contains a main package, and compares the results with the
+// expectations expressed in its WANT comment and @pointsto comments.
+func TestPTA(t *testing.T) {
+	archivePaths := []string{
+		"testdata/fields.txtar",
+		"testdata/func.txtar",
+		"testdata/generics.txtar",
+		"testdata/iface.txtar",
+	}
+	for _, archive := range archivePaths {
+		t.Run(archive, func(t *testing.T) {
+			ar, err := txtar.ParseFile(archive)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pkgs := testfiles.LoadPackages(t, ar, "./...")
+			prog, spkgs := ssautil.Packages(pkgs, ssa.SanityCheckFunctions|ssa.InstantiateGenerics)
+			prog.Build()
+			mainPkg := spkgs[0]
+			f := pkgs[0].Syntax[0]
+
+			res := pta.Analyze([]*ssa.Function{
+				mainPkg.Func("main"),
+				mainPkg.Func("init"),
+			})
+
+			checkCallGraph(t, f, mainPkg, res)
+			checkPointsTo(t, f, mainPkg, res)
+		})
+	}
+}
+
+// checkCallGraph tests the call graph and reachable functions against
+// the test expectations defined by a comment starting with a line
+// "WANT:".
+//
+// The rest of the comment consists of lines of the following forms:
+//
+//	edge      <func> --kind--> <func>	# call graph edge
+//	reachable <func>			# reachable function
+//
+// Each line asserts that an element is found in the given set, or, if
+// the line is preceded by "!", that it is not in the set.
+func checkCallGraph(t *testing.T, f *ast.File, pkg *ssa.Package, res *pta.Result) {
+	var want string
+	for _, c := range f.Comments {
+		text := strings.TrimSpace(c.Text())
+		if rest := strings.TrimPrefix(text, "WANT:\n"); rest != text {
+			want = rest
+		}
+	}
+
+	got := map[string]map[string]bool{
+		"edge":      make(map[string]bool),
+		"reachable": make(map[string]bool),
+	}
+	callgraph.GraphVisitEdges(res.CallGraph, func(e *callgraph.Edge) error {
+		edge := fmt.Sprintf("%s --%s--> %s",
+			e.Caller.Func.RelString(pkg.Pkg),
+			e.Description(),
+			e.Callee.Func.RelString(pkg.Pkg))
+		got["edge"][edge] = true
+		return nil
+	})
+	for fn := range res.Reachable {
+		got["reachable"][fn.RelString(pkg.Pkg)] = true
+	}
+
+	ok := true
+	for _, line := range strings.Split(want, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		sense := !strings.HasPrefix(line, "!")
+		line = strings.TrimSpace(strings.TrimPrefix(line, "!"))
+		kind, str, _ := strings.Cut(line, " ")
+		set, found := got[kind]
+		if !found {
+			t.Fatalf("invalid assertion: %q", line)
+		}
+		str = strings.TrimSpace(str)
+		if set[str] != sense {
+			ok = false
+			if sense {
+				t.Errorf("missing %s %q", kind, str)
+			} else {
+				t.Errorf("unwanted %s %q", kind, str)
+			}
+		}
+	}
+	if !ok {
+		var strs []string
+		for kind, set := range got {
+			for str := range set {
+				strs = append(strs, kind+" "+str)
+			}
+		}
+		sort.Strings(strs)
+		t.Errorf("got:\n%s", strings.Join(strs, "\n"))
+	}
+}
+
+var pointstoRE = regexp.MustCompile(`@pointsto (.*)`)
+
+// checkPointsTo tests the points-to set of the argument of each call
+// to print against the labels listed in the @pointsto comment on the
+// same line, if any.
+func checkPointsTo(t *testing.T, f *ast.File, pkg *ssa.Package, res *pta.Result) {
+	fset := pkg.Prog.Fset
+	want := make(map[int]string) // by line
+	for _, c := range f.Comments {
+		if m := pointstoRE.FindStringSubmatch(c.Text()); m != nil {
+			want[fset.Position(c.Pos()).Line] = strings.TrimSpace(m[1])
+		}
+	}
+
+	checked := 0
+	for fn := range ssautil.AllFunctions(pkg.Prog) {
+		if fn.Pkg != pkg {
+			continue
+		}
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				call, ok := instr.(*ssa.Call)
+				if !ok {
+					continue
+				}
+				if b, ok := call.Call.Value.(*ssa.Builtin); !ok || b.Name() != "print" {
+					continue
+				}
+				line := fset.Position(call.Pos()).Line
+				labels, ok := want[line]
+				if !ok {
+					continue
+				}
+				checked++
+				got := res.PointsTo(call.Call.Args[0]).String()
+				if got != "["+labels+"]" {
+					t.Errorf("line %d: points-to set is %s, want [%s]", line, got, labels)
+				}
+			}
+		}
+	}
+	if checked != len(want) {
+		t.Errorf("checked %d @pointsto comments, want %d", checked, len(want))
+	}
+}
+
+// TestGenericBodies checks that the analysis of a program whose
+// generic functions are not instantiated treats values of type
+// parameter type like interfaces.
+func TestGenericBodies(t *testing.T) {
+	ar, err := txtar.ParseFile("testdata/generics.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs := testfiles.LoadPackages(t, ar, "./...")
+	prog, spkgs := ssautil.Packages(pkgs, ssa.SanityCheckFunctions)
+	prog.Build()
+	mainPkg := spkgs[0]
+
+	res := pta.Analyze([]*ssa.Function{mainPkg.Func("main"), mainPkg.Func("init")})
+
+	callF := mainPkg.Func("callF")
+	got := make(map[string]bool)
+	for _, e := range res.CallGraph.Nodes[callF].Out {
+		got[e.Callee.Func.RelString(mainPkg.Pkg)] = true
+	}
+	for _, want := range []string{"(*A).f", "(*B).f"} {
+		if !got[want] {
+			t.Errorf("callF does not call %s; calls %v", want, got)
+		}
+	}
+
+	// Calls of id share its body, so the result of each may be any
+	// argument of id: &n, or new(B) boxed in an interface.
+	want := map[string]string{
+		"id[*int]":          "[n, new]",
+		"id[example.com.I]": "[makeinterface:*example.com.B, makeinterface:*int]",
+	}
+	id := mainPkg.Func("id")
+	checked := 0
+	for _, b := range mainPkg.Func("main").Blocks {
+		for _, instr := range b.Instrs {
+			call, ok := instr.(*ssa.Call)
+			if !ok || call.Call.StaticCallee() == nil || call.Call.StaticCallee().Origin() != id {
+				continue
+			}
+			checked++
+			name := call.Call.StaticCallee().Name()
+			if got := res.PointsTo(call).String(); got != want[name] {
+				t.Errorf("%s: points-to set is %s, want %s", call, got, want[name])
+			}
+		}
+	}
+	if checked != len(want) {
+		t.Errorf("checked %d calls of id, want %d", checked, len(want))
+	}
+}
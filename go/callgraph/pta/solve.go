@@ -0,0 +1,438 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package pta
+
+// This file defines the constraint graph and its solver.
+//
+// Each pointer-like component of each value, and of each object, is a
+// node of the graph, identified by a nodeid. Each node has a points-to
+// set, whose elements are nodeids of objects or parts of objects.
+// Nodes are connected by copy edges (pts(dst) ⊇ pts(src)) and by
+// complex constraints, such as loads and stores, which add copy edges
+// as the points-to set of their source node grows.
+//
+// The solver propagates differences: each node records the part of
+// its points-to set already processed, and only the remainder is
+// pushed along edges and through constraints.
+
+import (
+	"github.com/tinygo-org/tinygo/alt_go/types"
+
+	"github.com/tinygo-org/tinygo/x-tools/container/intsets"
+	"github.com/tinygo-org/tinygo/x-tools/go/callgraph"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/types/typeutil"
+)
+
+// A nodeid identifies a node of the constraint graph.
+// Zero means "no node": it is used for values with no pointers.
+type nodeid uint32
+
+type node struct {
+	obj     *object        // object of which this node is a part, if any
+	pts     intsets.Sparse // points-to set
+	prev    intsets.Sparse // part of pts already processed by the solver
+	copyTo  intsets.Sparse // successors in the copy graph
+	complex []constraint   // constraints whose source is this node
+}
+
+// An object is an abstract memory location: a consecutive block of
+// nodes, one per component of its type, as defined by flatten.
+type object struct {
+	start nodeid
+	size  int
+	site  ssa.Value     // allocation site
+	typ   types.Type    // type of the object, for paths of labels; may be nil
+	tag   types.Type    // dynamic type of a tagged object, or nil
+	fn    *ssa.Function // function of a function object, or nil
+}
+
+// funcInfo holds the nodes of a function's parameters, free variables
+// and results, shared by all calls of the function.
+type funcInfo struct {
+	obj      *object
+	params   []nodeid
+	freevars []nodeid
+	results  nodeid
+}
+
+type edge struct {
+	caller *ssa.Function
+	site   ssa.CallInstruction
+	callee *ssa.Function
+}
+
+type boxKey struct {
+	site ssa.Value
+	src  nodeid
+}
+
+// Working state of the pointer analysis.
+type analysis struct {
+	prog    *ssa.Program
+	result  *Result
+	hasher  typeutil.Hasher
+	nodes   []*node
+	values  map[ssa.Value]nodeid // nodes of values of reachable functions
+	funcs   map[*ssa.Function]*funcInfo
+	globals map[*ssa.Global]*object
+	boxes   map[boxKey]*object // tagged objects created at function boundaries
+	edges   map[edge]bool
+	flat    typeutil.Map // maps each type to its flattened components
+	panic   nodeid       // values passed to panic, and returned by recover
+	queue   []*ssa.Function
+	work    intsets.Sparse // nodes whose points-to set has grown
+}
+
+func newAnalysis(prog *ssa.Program) *analysis {
+	a := &analysis{
+		prog:    prog,
+		result:  &Result{Reachable: make(map[*ssa.Function]bool)},
+		hasher:  typeutil.MakeHasher(),
+		nodes:   []*node{new(node)}, // node 0 means "no node"
+		values:  make(map[ssa.Value]nodeid),
+		funcs:   make(map[*ssa.Function]*funcInfo),
+		globals: make(map[*ssa.Global]*object),
+		boxes:   make(map[boxKey]*object),
+		edges:   make(map[edge]bool),
+	}
+	a.result.a = a
+	a.flat.SetHasher(a.hasher)
+	a.panic = a.addNodes(1, nil)
+	return a
+}
+
+// addNodes adds n nodes, belonging to obj if non-nil, and returns the
+// nodeid of the first, or zero if n is zero.
+func (a *analysis) addNodes(n int, obj *object) nodeid {
+	if n == 0 {
+		return 0
+	}
+	id := nodeid(len(a.nodes))
+	for range n {
+		a.nodes = append(a.nodes, &node{obj: obj})
+	}
+	return id
+}
+
+// newObject returns a new object with a node for each component of
+// a value of type t.
+func (a *analysis) newObject(site ssa.Value, t types.Type) *object {
+	obj := &object{site: site, typ: t, size: max(a.sizeof(t), 1)}
+	obj.start = a.addNodes(obj.size, obj)
+	return obj
+}
+
+// newTagged returns a new tagged object that holds a value of dynamic
+// type t. Its first node is the tag; the value follows it.
+func (a *analysis) newTagged(site ssa.Value, t types.Type) *object {
+	obj := &object{site: site, tag: t, size: 1 + a.sizeof(t)}
+	obj.start = a.addNodes(obj.size, obj)
+	return obj
+}
+
+// at returns the nodeid at the given offset from id, or zero if id is
+// zero.
+func at(id nodeid, offset int) nodeid {
+	if id == 0 {
+		return 0
+	}
+	return id + nodeid(offset)
+}
+
+// addressOf adds obj to the points-to set of dst.
+func (a *analysis) addressOf(dst, obj nodeid) {
+	if dst != 0 && a.nodes[dst].pts.Insert(int(obj)) {
+		a.work.Insert(int(dst))
+	}
+}
+
+// copy adds a copy edge from src to dst.
+func (a *analysis) copy(dst, src nodeid) {
+	if dst == 0 || src == 0 || dst == src {
+		return
+	}
+	if a.nodes[src].copyTo.Insert(int(dst)) && a.nodes[dst].pts.UnionWith(&a.nodes[src].pts) {
+		a.work.Insert(int(dst))
+	}
+}
+
+// copyN adds copy edges from the n consecutive nodes at src to those
+// at dst.
+func (a *analysis) copyN(dst, src nodeid, n int) {
+	if dst == 0 || src == 0 {
+		return
+	}
+	for i := range n {
+		a.copy(dst+nodeid(i), src+nodeid(i))
+	}
+}
+
+// addConstraint adds a complex constraint whose source is src.
+func (a *analysis) addConstraint(src nodeid, c constraint) {
+	if src == 0 {
+		return
+	}
+	n := a.nodes[src]
+	n.complex = append(n.complex, c)
+	if !n.pts.IsEmpty() {
+		// Apply all constraints to the whole set; they are idempotent.
+		n.prev.Clear()
+		a.work.Insert(int(src))
+	}
+}
+
+// solve generates constraints for reachable functions and propagates
+// points-to sets until a fixed point is reached.
+func (a *analysis) solve() {
+	var delta intsets.Sparse
+	for {
+		for len(a.queue) > 0 {
+			fn := a.queue[0]
+			a.queue = a.queue[1:]
+			a.genFunc(fn)
+		}
+
+		var x int
+		if !a.work.TakeMin(&x) {
+			break
+		}
+		n := a.nodes[x]
+		delta.Difference(&n.pts, &n.prev)
+		if delta.IsEmpty() {
+			continue
+		}
+		n.prev.Copy(&n.pts)
+
+		for i := 0; i < len(n.complex); i++ { // n.complex may grow
+			n.complex[i].solve(a, &delta)
+		}
+		for _, dst := range n.copyTo.AppendTo(nil) {
+			if a.nodes[dst].pts.UnionWith(&delta) {
+				a.work.Insert(dst)
+			}
+		}
+	}
+}
+
+// reach marks fn as reachable, and queues the generation of
+// constraints for its body.
+func (a *analysis) reach(fn *ssa.Function) {
+	if !a.result.Reachable[fn] {
+		a.result.Reachable[fn] = true
+		a.result.CallGraph.CreateNode(fn)
+		a.queue = append(a.queue, fn)
+	}
+}
+
+// call records a call from caller to callee at site, and binds the
+// arguments and results of the call to the parameters and results of
+// the callee. For a call in invoke mode, recv holds the receiver.
+func (a *analysis) call(caller *ssa.Function, site ssa.CallInstruction, callee *ssa.Function, recv nodeid) {
+	e := edge{caller, site, callee}
+	if a.edges[e] {
+		return
+	}
+	a.edges[e] = true
+	g := a.result.CallGraph
+	callgraph.AddEdge(g.CreateNode(caller), site, g.CreateNode(callee))
+	a.reach(callee)
+
+	if callee.Blocks == nil {
+		return // no body: effects not modeled
+	}
+	info := a.funcInfo(callee)
+	common := site.Common()
+	params, nodes := callee.Params, info.params
+	if common.IsInvoke() {
+		a.copyN(nodes[0], recv, a.sizeof(params[0].Type()))
+		params, nodes = params[1:], nodes[1:]
+	}
+	for i, arg := range common.Args {
+		if i < len(params) {
+			a.assign(nodes[i], params[i].Type(), a.valueNode(arg), arg.Type(), arg)
+		}
+	}
+	if v := site.Value(); v != nil {
+		a.assign(a.valueNode(v), v.Type(), info.results, callee.Signature.Results(), v)
+	}
+}
+
+// assign adds the constraints for the assignment of a value of type
+// srcT at src to a variable of type dstT at dst. The types differ only
+// at the boundaries of generic functions, when one is a type parameter.
+func (a *analysis) assign(dst nodeid, dstT types.Type, src nodeid, srcT types.Type, site ssa.Value) {
+	if dt, ok := dstT.(*types.Tuple); ok {
+		st := srcT.(*types.Tuple)
+		if dt.Len() == 1 && st.Len() == 1 {
+			a.assign(dst, dt.At(0).Type(), src, st.At(0).Type(), site)
+			return
+		}
+		for i := 0; i < dt.Len() && i < st.Len(); i++ {
+			a.assign(at(dst, a.tupleOffset(dt, i)), dt.At(i).Type(),
+				at(src, a.tupleOffset(st, i)), st.At(i).Type(), site)
+		}
+		return
+	}
+	if st, ok := srcT.(*types.Tuple); ok && st.Len() == 1 {
+		srcT = st.At(0).Type()
+	}
+
+	switch dstIface, srcIface := types.IsInterface(dstT), types.IsInterface(srcT); {
+	case dstIface == srcIface:
+		a.copyN(dst, src, min(a.sizeof(dstT), a.sizeof(srcT)))
+
+	case dstIface:
+		// A concrete value passed as a type parameter.
+		if dst == 0 {
+			return
+		}
+		key := boxKey{site, src}
+		obj := a.boxes[key]
+		if obj == nil {
+			obj = a.newTagged(site, srcT)
+			a.boxes[key] = obj
+			a.copyN(obj.start+1, src, a.sizeof(srcT))
+		}
+		a.addressOf(dst, obj.start)
+
+	default:
+		// A type parameter passed as a concrete value.
+		a.addConstraint(src, &untagConstraint{dst: dst, size: a.sizeof(dstT)})
+	}
+}
+
+// method returns the method of dynamic type t that implements m, or
+// nil if there is none.
+func (a *analysis) method(t types.Type, m *types.Func) *ssa.Function {
+	sel := a.prog.MethodSets.MethodSet(t).Lookup(m.Pkg(), m.Name())
+	if sel == nil {
+		return nil
+	}
+	return a.prog.MethodValue(sel)
+}
+
+// -- constraints ------------------------------------------------------
+
+// A constraint is a complex constraint, applied to each element added
+// to the points-to set of its source node.
+type constraint interface {
+	solve(a *analysis, delta *intsets.Sparse)
+}
+
+// parts calls f for each part of an object in delta that has size
+// nodes at the given offset.
+func (a *analysis) parts(delta *intsets.Sparse, offset, size int, f func(id nodeid)) {
+	for _, x := range delta.AppendTo(nil) {
+		id := nodeid(x)
+		obj := a.nodes[id].obj
+		if obj == nil || obj.tag != nil && id == obj.start {
+			continue // not addressable
+		}
+		if int(id-obj.start)+offset+size <= obj.size {
+			f(id + nodeid(offset))
+		}
+	}
+}
+
+// dst = *(src + offset), for size nodes.
+type loadConstraint struct {
+	dst          nodeid
+	offset, size int
+}
+
+func (c *loadConstraint) solve(a *analysis, delta *intsets.Sparse) {
+	a.parts(delta, c.offset, c.size, func(id nodeid) { a.copyN(c.dst, id, c.size) })
+}
+
+// *(dst + offset) = src, for size nodes.
+type storeConstraint struct {
+	src          nodeid
+	offset, size int
+}
+
+func (c *storeConstraint) solve(a *analysis, delta *intsets.Sparse) {
+	a.parts(delta, c.offset, c.size, func(id nodeid) { a.copyN(id, c.src, c.size) })
+}
+
+// dst = &src.field, where field is at the given offset.
+type offsetAddrConstraint struct {
+	dst    nodeid
+	offset int
+}
+
+func (c *offsetAddrConstraint) solve(a *analysis, delta *intsets.Sparse) {
+	a.parts(delta, c.offset, 1, func(id nodeid) { a.addressOf(c.dst, id) })
+}
+
+// dst = src.(typ), where typ is an interface type, or nil for any
+// type.
+type typeFilterConstraint struct {
+	typ *types.Interface
+	dst nodeid
+}
+
+func (c *typeFilterConstraint) solve(a *analysis, delta *intsets.Sparse) {
+	for _, x := range delta.AppendTo(nil) {
+		obj := a.nodes[x].obj
+		if obj == nil || obj.tag == nil {
+			continue
+		}
+		if c.typ == nil || types.Implements(obj.tag, c.typ) {
+			a.addressOf(c.dst, nodeid(x))
+		}
+	}
+}
+
+// dst = src.(typ), where typ is a concrete type, or nil for any type.
+type untagConstraint struct {
+	typ  types.Type
+	dst  nodeid
+	size int
+}
+
+func (c *untagConstraint) solve(a *analysis, delta *intsets.Sparse) {
+	for _, x := range delta.AppendTo(nil) {
+		obj := a.nodes[x].obj
+		if obj == nil || obj.tag == nil {
+			continue
+		}
+		if c.typ == nil || types.Identical(obj.tag, c.typ) {
+			a.copyN(c.dst, obj.start+1, min(c.size, obj.size-1))
+		}
+	}
+}
+
+// A call of a func value.
+type callConstraint struct {
+	caller *ssa.Function
+	site   ssa.CallInstruction
+}
+
+func (c *callConstraint) solve(a *analysis, delta *intsets.Sparse) {
+	for _, x := range delta.AppendTo(nil) {
+		if obj := a.nodes[x].obj; obj != nil && obj.fn != nil {
+			a.call(c.caller, c.site, obj.fn, 0)
+		}
+	}
+}
+
+// A call of an interface method.
+type invokeConstraint struct {
+	caller *ssa.Function
+	site   ssa.CallInstruction
+}
+
+func (c *invokeConstraint) solve(a *analysis, delta *intsets.Sparse) {
+	for _, x := range delta.AppendTo(nil) {
+		obj := a.nodes[x].obj
+		if obj == nil || obj.tag == nil {
+			continue
+		}
+		if fn := a.method(obj.tag, c.site.Common().Method); fn != nil {
+			a.call(c.caller, c.site, fn, obj.start+1)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 	"github.com/tinygo-org/tinygo/alt_go/constant"
 	"github.com/tinygo-org/tinygo/alt_go/token"
 	"github.com/tinygo-org/tinygo/alt_go/types"
+	"math"
 	"strconv"
 
 	"github.com/tinygo-org/tinygo/x-tools/internal/typeparams"
@@ -93,6 +94,45 @@ func (c *Const) RelString(from *types.Package) string {
 	return s + ":" + relType(c.Type(), from)
 }
 
+// exactString is like RelString, but prints the value of c exactly,
+// in the form read by ParsePackage: a string in full, and a number in
+// decimal if that is exact, or else as a fraction, or in hexadecimal
+// floating-point notation if it is too large for a fraction.
+func (c *Const) exactString(from *types.Package) string {
+	if c.Value == nil {
+		return c.RelString(from)
+	}
+	var s string
+	switch c.Value.Kind() {
+	case constant.String:
+		s = strconv.Quote(constant.StringVal(c.Value))
+	case constant.Float:
+		s = exactNumber(c.Value)
+	case constant.Complex:
+		s = fmt.Sprintf("(%s + %si)", exactNumber(constant.Real(c.Value)), exactNumber(constant.Imag(c.Value)))
+	default:
+		s = c.Value.ExactString()
+	}
+	return s + ":" + relType(c.Type(), from)
+}
+
+// exactNumber returns the shortest decimal literal for v, which must
+// be of kind Int or Float, if it has exactly the value of v, and
+// otherwise the value printed by exactFloat.
+func exactNumber(v constant.Value) string {
+	f, _ := constant.Float64Val(v)
+	lit := strconv.FormatFloat(math.Abs(f), 'g', -1, 64)
+	dec := constant.MakeFromLiteral(lit, token.FLOAT, 0)
+	if f < 0 {
+		dec = constant.UnaryOp(token.SUB, dec, 0)
+		lit = "-" + lit
+	}
+	if dec.Kind() != constant.Unknown && constant.Compare(dec, token.EQL, v) {
+		return lit
+	}
+	return exactFloat(v)
+}
+
 func (c *Const) Name() string {
 	return c.RelString(nil)
 }
@@ -0,0 +1,974 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+// This file defines ReadExportData, the decoder of SSA export data.
+// See encode.go for a description of the format.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"io"
+	"strconv"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/types/objectpath"
+	"github.com/tinygo-org/tinygo/x-tools/internal/gcimporter"
+	"github.com/tinygo-org/tinygo/x-tools/internal/pkgbits"
+)
+
+// ReadExportData reads the export data of a package, written by
+// [WriteExportData], creates the package in prog with the specified
+// import path, and returns it. The packages on which it depends must
+// have been created in prog, and prog must have the same mode as the
+// program of the exported package.
+//
+// The returned package is built: the bodies of its functions are
+// those of the exported package, and any synthetic functions on which
+// they depend are created and built as needed. Instances of generic
+// functions in the data that have not yet been built in prog are
+// built from the data too. If an error is returned, prog may contain
+// a partially created package.
+func ReadExportData(prog *Program, in io.Reader, path string) (_ *Package, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(importError); ok {
+				err = e
+			} else {
+				err = fmt.Errorf("internal error reading SSA export data of %q: %v", path, r)
+			}
+		}
+	}()
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	rest, ok := bytes.CutPrefix(data, []byte(exportMagic))
+	if !ok {
+		return nil, fmt.Errorf("reading %q: not SSA export data", path)
+	}
+	n, size := binary.Uvarint(rest)
+	if size <= 0 || n > uint64(len(rest)-size) {
+		return nil, fmt.Errorf("reading %q: invalid SSA export data", path)
+	}
+	typesData, codeData := rest[size:size+int(n)], rest[size+int(n):]
+
+	// Import the types of the package, and create it.
+	getPackages := func(items []gcimporter.GetPackagesItem) error {
+		for i, item := range items {
+			switch {
+			case item.Path == path:
+				items[i].Pkg = types.NewPackage(item.Path, item.Name)
+			case prog.packageByPath(item.Path) != nil:
+				items[i].Pkg = prog.packageByPath(item.Path)
+			default:
+				return fmt.Errorf("package %q has not been created", item.Path)
+			}
+		}
+		return nil
+	}
+	tpkg, err := gcimporter.IImportShallow(prog.Fset, getPackages, typesData, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &importer{
+		prog:   prog,
+		tpkg:   tpkg,
+		pr:     pkgbits.NewPkgDecoder(path, string(codeData)),
+		filled: make(map[*Function]bool),
+		bounds: make(map[*types.Func]*Function),
+		thunks: make(map[string]*Function),
+	}
+	d.files = make([]*token.File, d.pr.NumElems(pkgbits.RelocPosBase))
+	d.pkgs = make([]*types.Package, d.pr.NumElems(pkgbits.RelocPkg))
+	d.types = make([]types.Type, d.pr.NumElems(pkgbits.RelocType))
+	d.objs = make([]types.Object, d.pr.NumElems(pkgbits.RelocObj))
+	return d.readPackage(), nil
+}
+
+// An importError is an error that occurred during decoding.
+type importError struct{ msg string }
+
+func (e importError) Error() string { return e.msg }
+
+// An importer holds the state of ReadExportData.
+type importer struct {
+	prog *Program
+	tpkg *types.Package
+	pkg  *Package
+	pr   pkgbits.PkgDecoder
+	b    builder
+
+	// Decoded elements, by index.
+	files []*token.File
+	pkgs  []*types.Package
+	types []types.Type
+	objs  []types.Object
+
+	bodies []pendingBody             // functions whose bodies are to be decoded
+	filled map[*Function]bool        // functions whose elements have been decoded
+	bounds map[*types.Func]*Function // bound method wrappers, by method
+	thunks map[string]*Function      // thunks, by receiver type and method
+
+	// State of the current function.
+	fn     *Function
+	values []Value  // local values, by number
+	fixups []func() // actions to complete instructions once operands are resolved
+}
+
+// A pendingBody is a function together with a decoder positioned at
+// its body.
+type pendingBody struct {
+	fn *Function
+	r  pkgbits.Decoder
+}
+
+func (d *importer) errorf(format string, args ...any) {
+	panic(importError{fmt.Sprintf("reading %s: %s", d.tpkg.Path(), fmt.Sprintf(format, args...))})
+}
+
+// readPackage creates the package and decodes the root element and
+// the functions it lists.
+func (d *importer) readPackage() *Package {
+	r := d.pr.NewDecoder(pkgbits.RelocMeta, 0, pkgbits.SyncPublic)
+	if path := r.String(); path != d.tpkg.Path() {
+		d.errorf("export data is for package %s", path)
+	}
+	d.pkg = d.prog.CreatePackage(d.tpkg, nil, nil, true)
+	d.b.fns = d.pkg.created
+	d.pkg.init.build = (*builder).buildParamsOnly // unless in the data
+
+	// Create the init#N functions.
+	ninit := r.Len()
+	for i := range ninit {
+		name := fmt.Sprintf("init#%d", i+1)
+		obj := types.NewFunc(token.NoPos, d.tpkg, "init", new(types.Signature))
+		fn := createFunction(d.prog, obj, name, nil, nil, "")
+		fn.Pkg = d.pkg
+		d.pkg.Members[name] = fn
+		d.pkg.objects[obj] = fn
+		d.pkg.created = append(d.pkg.created, fn)
+		d.b.enqueue(fn)
+	}
+	d.pkg.ninit = int32(ninit)
+
+	// Create the functions, then decode their bodies. The element
+	// of a function that has already been built, such as an instance
+	// shared with another package, is skipped.
+	var filled []*Function
+	for n := r.Len(); n > 0; n-- {
+		fn := d.funcRef(&r)
+		idx := r.Reloc(pkgbits.RelocBody)
+		if fn.Blocks == nil && fn.build != nil && !d.filled[fn] {
+			d.readFunc(fn, idx)
+			filled = append(filled, fn)
+		}
+	}
+	for _, body := range d.bodies {
+		d.readBody(body.fn, &body.r)
+	}
+	for _, fn := range filled {
+		if fn.Blocks != nil {
+			fn.done()
+		}
+	}
+
+	// Build the remaining functions and mark the package built.
+	d.b.iterate()
+	d.pkg.buildOnce.Do(func() {})
+	d.pkg.created = nil
+	d.pkg.files = nil
+	d.pkg.initVersion = nil
+	return d.pkg
+}
+
+// readFunc decodes the element of fn, creating its anonymous
+// functions, and records its body, if any, for decoding.
+func (d *importer) readFunc(fn *Function, idx pkgbits.Index) {
+	d.filled[fn] = true
+	r := d.pr.NewDecoder(pkgbits.RelocBody, idx, pkgbits.SyncFuncExt)
+	fn.Synthetic = r.String()
+	fn.pos = d.pos(&r)
+	if fn.parent != nil {
+		fn.Signature = d.typ(&r).(*types.Signature)
+		for n := r.Len(); n > 0; n-- {
+			fn.FreeVars = append(fn.FreeVars, &FreeVar{
+				name:   r.String(),
+				typ:    d.typ(&r),
+				pos:    d.pos(&r),
+				parent: fn,
+			})
+		}
+	}
+	for n := r.Len(); n > 0; n-- {
+		anon := &Function{
+			name:       fmt.Sprintf("%s$%d", fn.Name(), 1+len(fn.AnonFuncs)),
+			parent:     fn,
+			anonIdx:    int32(len(fn.AnonFuncs)),
+			Pkg:        fn.Pkg,
+			Prog:       fn.Prog,
+			typeparams: fn.typeparams,
+			typeargs:   fn.typeargs,
+		}
+		fn.AnonFuncs = append(fn.AnonFuncs, anon)
+		d.readFunc(anon, r.Reloc(pkgbits.RelocBody))
+	}
+	if r.Bool() {
+		fn.Params = nil
+		fn.build = nil // the body is built by decoding it
+		d.bodies = append(d.bodies, pendingBody{fn, r})
+	}
+}
+
+// readBody decodes the body of fn.
+func (d *importer) readBody(fn *Function, r *pkgbits.Decoder) {
+	d.fn = fn
+	d.values = nil
+	d.fixups = nil
+
+	// Declare the parameters and free variables.
+	if recv := fn.Signature.Recv(); recv != nil {
+		fn.addParamVar(recv)
+	}
+	params := fn.Signature.Params()
+	for i := range params.Len() {
+		fn.addParamVar(params.At(i))
+	}
+	if n := r.Len(); n != len(fn.Params) {
+		d.errorf("function %s has %d parameters, want %d", fn, len(fn.Params), n)
+	}
+	for _, p := range fn.Params {
+		d.values = append(d.values, p)
+	}
+	for _, fv := range fn.FreeVars {
+		d.values = append(d.values, fv)
+	}
+
+	// Decode the blocks. Operands that are not yet defined are
+	// unresolved until all instructions have been decoded.
+	nblocks := r.Len()
+	for range nblocks {
+		fn.newBasicBlock("")
+	}
+	block := func(r *pkgbits.Decoder) *BasicBlock {
+		index := r.Len()
+		if index >= nblocks {
+			d.errorf("function %s has no block %d", fn, index)
+		}
+		return fn.Blocks[index]
+	}
+	for _, b := range fn.Blocks {
+		b.Comment = r.String()
+		for n := r.Len(); n > 0; n-- {
+			b.Preds = append(b.Preds, block(r))
+		}
+		for n := r.Len(); n > 0; n-- {
+			b.Succs = append(b.Succs, block(r))
+		}
+		for n := r.Len(); n > 0; n-- {
+			instr := d.instr(r)
+			instr.setBlock(b)
+			b.Instrs = append(b.Instrs, instr)
+			if v, ok := instr.(Value); ok {
+				d.values = append(d.values, v)
+			}
+		}
+	}
+	var rands []*Value
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			for _, rand := range instr.Operands(rands[:0]) {
+				if u, ok := (*rand).(*unresolved); ok {
+					n, _ := strconv.Atoi(u.name)
+					*rand = d.local(n)
+				}
+			}
+		}
+	}
+	for _, fixup := range d.fixups {
+		fixup()
+	}
+
+	// Decode the recover block and the locals.
+	if index := r.Len(); index > 0 {
+		if index > nblocks {
+			d.errorf("function %s has no block %d", fn, index-1)
+		}
+		fn.Recover = fn.Blocks[index-1]
+	}
+	for n := r.Len(); n > 0; n-- {
+		alloc, ok := d.local(r.Len()).(*Alloc)
+		if !ok {
+			d.errorf("local of function %s is not an Alloc", fn)
+		}
+		fn.Locals = append(fn.Locals, alloc)
+	}
+
+	buildReferrers(fn)
+	buildDomTree(fn)
+	numberRegisters(fn)
+	d.fn = nil
+}
+
+// local returns the local value of the current function with the
+// specified number.
+func (d *importer) local(n int) Value {
+	if n >= len(d.values) {
+		d.errorf("function %s has no local value %d", d.fn, n)
+	}
+	return d.values[n]
+}
+
+// instr decodes an instruction.
+func (d *importer) instr(r *pkgbits.Decoder) Instruction {
+	// reg decodes the type and position of a value-defining instruction.
+	reg := func(v interface {
+		setType(types.Type)
+		setPos(token.Pos)
+	}) {
+		v.setType(d.typ(r))
+		v.setPos(d.pos(r))
+	}
+	switch code := codeInstr(r.Code(pkgbits.SyncStmt1)); code {
+	case instrAlloc:
+		v := new(Alloc)
+		reg(v)
+		v.Comment = r.String()
+		v.Heap = r.Bool()
+		return v
+	case instrPhi:
+		v := new(Phi)
+		reg(v)
+		v.Comment = r.String()
+		v.Edges = d.valueList(r)
+		return v
+	case instrCall:
+		v := new(Call)
+		v.setType(d.typ(r))
+		d.call(r, &v.Call)
+		return v
+	case instrBinOp:
+		v := new(BinOp)
+		reg(v)
+		v.Op = token.Token(r.Len())
+		v.X = d.value(r)
+		v.Y = d.value(r)
+		return v
+	case instrUnOp:
+		v := new(UnOp)
+		reg(v)
+		v.Op = token.Token(r.Len())
+		v.X = d.value(r)
+		v.CommaOk = r.Bool()
+		return v
+	case instrChangeType:
+		v := new(ChangeType)
+		reg(v)
+		v.X = d.value(r)
+		return v
+	case instrConvert:
+		v := new(Convert)
+		reg(v)
+		v.X = d.value(r)
+		return v
+	case instrMultiConvert:
+		v := new(MultiConvert)
+		reg(v)
+		v.X = d.value(r)
+		v.from = d.typ(r)
+		v.to = d.typ(r)
+		return v
+	case instrChangeInterface:
+		v := new(ChangeInterface)
+		reg(v)
+		v.X = d.value(r)
+		return v
+	case instrSliceToArrayPointer:
+		v := new(SliceToArrayPointer)
+		reg(v)
+		v.X = d.value(r)
+		return v
+	case instrMakeInterface:
+		v := new(MakeInterface)
+		reg(v)
+		v.X = d.value(r)
+		return v
+	case instrMakeClosure:
+		v := new(MakeClosure)
+		reg(v)
+		v.Fn = d.value(r)
+		v.Bindings = d.valueList(r)
+		return v
+	case instrMakeMap:
+		v := new(MakeMap)
+		reg(v)
+		v.Reserve = d.value(r)
+		return v
+	case instrMakeChan:
+		v := new(MakeChan)
+		reg(v)
+		v.Size = d.value(r)
+		return v
+	case instrMakeSlice:
+		v := new(MakeSlice)
+		reg(v)
+		v.Len = d.value(r)
+		v.Cap = d.value(r)
+		return v
+	case instrSlice:
+		v := new(Slice)
+		reg(v)
+		v.X = d.value(r)
+		v.Low = d.value(r)
+		v.High = d.value(r)
+		v.Max = d.value(r)
+		return v
+	case instrFieldAddr:
+		v := new(FieldAddr)
+		reg(v)
+		v.X = d.value(r)
+		v.Field = r.Len()
+		return v
+	case instrField:
+		v := new(Field)
+		reg(v)
+		v.X = d.value(r)
+		v.Field = r.Len()
+		return v
+	case instrIndexAddr:
+		v := new(IndexAddr)
+		reg(v)
+		v.X = d.value(r)
+		v.Index = d.value(r)
+		return v
+	case instrIndex:
+		v := new(Index)
+		reg(v)
+		v.X = d.value(r)
+		v.Index = d.value(r)
+		return v
+	case instrLookup:
+		v := new(Lookup)
+		reg(v)
+		v.X = d.value(r)
+		v.Index = d.value(r)
+		v.CommaOk = r.Bool()
+		return v
+	case instrSelect:
+		v := new(Select)
+		reg(v)
+		for n := r.Len(); n > 0; n-- {
+			v.States = append(v.States, &SelectState{
+				Dir:  types.ChanDir(r.Len()),
+				Chan: d.value(r),
+				Send: d.value(r),
+				Pos:  d.pos(r),
+			})
+		}
+		v.Blocking = r.Bool()
+		return v
+	case instrRange:
+		v := new(Range)
+		reg(v)
+		v.X = d.value(r)
+		return v
+	case instrNext:
+		v := new(Next)
+		reg(v)
+		v.Iter = d.value(r)
+		v.IsString = r.Bool()
+		return v
+	case instrTypeAssert:
+		v := new(TypeAssert)
+		reg(v)
+		v.X = d.value(r)
+		v.AssertedType = d.typ(r)
+		v.CommaOk = r.Bool()
+		return v
+	case instrExtract:
+		v := new(Extract)
+		reg(v)
+		v.Tuple = d.value(r)
+		v.Index = r.Len()
+		return v
+	case instrJump:
+		return new(Jump)
+	case instrIf:
+		return &If{Cond: d.value(r)}
+	case instrReturn:
+		s := &Return{pos: d.pos(r)}
+		s.Results = d.valueList(r)
+		return s
+	case instrRunDefers:
+		return new(RunDefers)
+	case instrPanic:
+		s := &Panic{pos: d.pos(r)}
+		s.X = d.value(r)
+		return s
+	case instrGo:
+		s := &Go{pos: d.pos(r)}
+		d.call(r, &s.Call)
+		return s
+	case instrDefer:
+		s := &Defer{pos: d.pos(r)}
+		d.call(r, &s.Call)
+		s.DeferStack = d.value(r)
+		return s
+	case instrSend:
+		s := &Send{pos: d.pos(r)}
+		s.Chan = d.value(r)
+		s.X = d.value(r)
+		return s
+	case instrStore:
+		s := &Store{pos: d.pos(r)}
+		s.Addr = d.value(r)
+		s.Val = d.value(r)
+		return s
+	case instrMapUpdate:
+		s := &MapUpdate{pos: d.pos(r)}
+		s.Map = d.value(r)
+		s.Key = d.value(r)
+		s.Value = d.value(r)
+		return s
+	default:
+		d.errorf("invalid instruction code %d", code)
+		panic("unreachable")
+	}
+}
+
+// call decodes a call. The method of an invoke-mode call is looked up
+// once the type of its receiver is known.
+func (d *importer) call(r *pkgbits.Decoder, c *CallCommon) {
+	c.Value = d.value(r)
+	if r.Bool() {
+		pkg, name := d.pkgRef(r), r.String()
+		d.fixups = append(d.fixups, func() {
+			obj, _, _ := types.LookupFieldOrMethod(c.Value.Type(), false, pkg, name)
+			m, ok := obj.(*types.Func)
+			if !ok {
+				d.errorf("type %s has no method %s", c.Value.Type(), name)
+			}
+			c.Method = m
+		})
+	}
+	c.Args = d.valueList(r)
+	c.pos = d.pos(r)
+}
+
+func (d *importer) valueList(r *pkgbits.Decoder) []Value {
+	var vs []Value
+	for n := r.Len(); n > 0; n-- {
+		vs = append(vs, d.value(r))
+	}
+	return vs
+}
+
+// value decodes an operand, which may be nil.
+func (d *importer) value(r *pkgbits.Decoder) Value {
+	switch code := codeValue(r.Code(pkgbits.SyncExpr)); code {
+	case valueNil:
+		return nil
+	case valueLocal:
+		n := r.Len()
+		if n < len(d.values) {
+			return d.values[n]
+		}
+		return &unresolved{name: strconv.Itoa(n)} // defined later
+
+	case valueConst:
+		t := d.typ(r)
+		if r.Bool() {
+			return NewConst(r.Value(), t)
+		}
+		return NewConst(nil, t)
+	case valueGlobal:
+		pkg, name := d.pkgRef(r), r.String()
+		g, ok := d.member(pkg, name).(*Global)
+		if !ok {
+			d.errorf("%s.%s is not a global", pkg.Path(), name)
+		}
+		return g
+	case valueFunction:
+		return d.funcRef(r)
+	case valueBuiltin:
+		name := r.String()
+		sig := d.typ(r).(*types.Signature)
+		if name == vDeferStack.name {
+			return vDeferStack
+		}
+		return &Builtin{name: name, sig: sig}
+	default:
+		d.errorf("invalid value code %d", code)
+		panic("unreachable")
+	}
+}
+
+// member returns the member of the created package pkg with the
+// specified name, or nil if there is none.
+func (d *importer) member(pkg *types.Package, name string) Member {
+	if p := d.prog.Package(pkg); p != nil {
+		return p.Members[name]
+	}
+	return nil
+}
+
+// funcRef decodes a reference to a function, creating it if
+// necessary.
+func (d *importer) funcRef(r *pkgbits.Decoder) *Function {
+	switch code := codeFunc(r.Code(pkgbits.SyncCodeObj)); code {
+	case funcAnon:
+		parent := d.funcRef(r)
+		i := r.Len()
+		if i >= len(parent.AnonFuncs) {
+			d.errorf("function %s has no anonymous function %d", parent, i+1)
+		}
+		return parent.AnonFuncs[i]
+
+	case funcMember:
+		pkg, name := d.pkgRef(r), r.String()
+		fn, ok := d.member(pkg, name).(*Function)
+		if !ok {
+			d.errorf("%s.%s is not a function", pkg.Path(), name)
+		}
+		return fn
+
+	case funcInstance:
+		origin := d.funcRef(r)
+		targs := d.typeList(r)
+		if origin.generic == nil {
+			d.errorf("function %s is not generic", origin)
+		}
+		return origin.instance(targs, &d.b)
+
+	case funcBound:
+		obj := d.funcObj(r)
+		fn := d.bounds[obj]
+		if fn == nil {
+			fn = createBound(d.prog, obj)
+			d.b.enqueue(fn)
+			d.bounds[obj] = fn
+		}
+		return fn
+
+	case funcThunk:
+		sel := &selection{
+			kind: types.MethodExpr,
+			recv: d.typ(r),
+			typ:  d.typ(r),
+			obj:  d.funcObj(r),
+		}
+		for n := r.Len(); n > 0; n-- {
+			sel.index = append(sel.index, r.Len())
+		}
+		sel.indirect = r.Bool()
+		key := types.TypeString(sel.recv, nil) + "." + sel.obj.Name()
+		fn := d.thunks[key]
+		if fn == nil {
+			fn = createThunk(d.prog, sel)
+			d.b.enqueue(fn)
+			d.thunks[key] = fn
+		}
+		return fn
+
+	case funcWrapper:
+		recv, obj := d.typ(r), d.funcObj(r)
+		mset := d.prog.MethodSets.MethodSet(recv)
+		for i := range mset.Len() {
+			if sel := mset.At(i); sel.Obj() == obj {
+				return d.prog.MethodValue(sel)
+			}
+		}
+		d.errorf("type %s has no method %s", recv, obj.Name())
+
+	case funcObject:
+		obj := d.funcObj(r)
+		var fn *Function
+		if obj.Type().(*types.Signature).Recv() != nil {
+			fn = d.prog.objectMethod(obj, &d.b)
+		} else {
+			fn = d.prog.FuncValue(obj)
+		}
+		if fn == nil {
+			d.errorf("no function for %s", obj)
+		}
+		return fn
+
+	default:
+		d.errorf("invalid function code %d", code)
+	}
+	panic("unreachable")
+}
+
+// pkgRef decodes a reference to a package, which may be nil.
+func (d *importer) pkgRef(r *pkgbits.Decoder) *types.Package {
+	if !r.Bool() {
+		return nil
+	}
+	idx := r.Reloc(pkgbits.RelocPkg)
+	if pkg := d.pkgs[idx]; pkg != nil {
+		return pkg
+	}
+	pr := d.pr.NewDecoder(pkgbits.RelocPkg, idx, pkgbits.SyncPkgDef)
+	var pkg *types.Package
+	switch path := pr.String(); path {
+	case d.tpkg.Path():
+		pkg = d.tpkg
+	case "unsafe":
+		pkg = types.Unsafe
+	default:
+		pkg = d.prog.packageByPath(path)
+		if pkg == nil {
+			d.errorf("package %q has not been created", path)
+		}
+	}
+	d.pkgs[idx] = pkg
+	return pkg
+}
+
+// pos decodes a position, which may be invalid.
+func (d *importer) pos(r *pkgbits.Decoder) token.Pos {
+	if !r.Bool() {
+		return token.NoPos
+	}
+	idx := r.Reloc(pkgbits.RelocPosBase)
+	file := d.files[idx]
+	if file == nil {
+		fr := d.pr.NewDecoder(pkgbits.RelocPosBase, idx, pkgbits.SyncPosBase)
+		name, size := fr.String(), fr.Len()
+		lines := make([]int, fr.Len())
+		line := 0
+		for i := range lines {
+			line += fr.Len()
+			lines[i] = line
+		}
+		file = d.prog.Fset.AddFile(name, -1, size)
+		if !file.SetLines(lines) {
+			d.errorf("invalid line table for %s", name)
+		}
+		d.files[idx] = file
+	}
+	offset := r.Len()
+	if offset > file.Size() {
+		d.errorf("invalid offset %d in %s", offset, file.Name())
+	}
+	return file.Pos(offset)
+}
+
+// funcObj decodes a reference to a function or method object.
+func (d *importer) funcObj(r *pkgbits.Decoder) *types.Func {
+	obj, ok := d.obj(r).(*types.Func)
+	if !ok {
+		d.errorf("%s is not a function", obj)
+	}
+	return obj
+}
+
+// obj decodes a reference to an object.
+func (d *importer) obj(r *pkgbits.Decoder) types.Object {
+	idx := r.Reloc(pkgbits.RelocObj)
+	if obj := d.objs[idx]; obj != nil {
+		return obj
+	}
+	or := d.pr.NewDecoder(pkgbits.RelocObj, idx, pkgbits.SyncObject)
+	var obj types.Object
+	switch code := codeObj(or.Code(pkgbits.SyncObject)); code {
+	case objPath:
+		pkg, path := d.pkgRef(&or), or.String()
+		var err error
+		obj, err = objectpath.Object(pkg, objectpath.Path(path))
+		if err != nil {
+			d.errorf("%v", err)
+		}
+	case objMethod:
+		recv, pkg, name := d.typ(&or), d.pkgRef(&or), or.String()
+		obj, _, _ = types.LookupFieldOrMethod(recv, true, pkg, name)
+		if _, ok := obj.(*types.Func); !ok {
+			d.errorf("type %s has no method %s", recv, name)
+		}
+	case objMethodInstance:
+		origin := d.funcObj(&or)
+		obj = d.prog.canon.instantiateMethod(origin, d.typeList(&or), d.prog.ctxt)
+	case objMember:
+		pkg, name := d.pkgRef(&or), or.String()
+		if obj = pkg.Scope().Lookup(name); obj == nil {
+			d.errorf("package %s has no member %s", pkg.Path(), name)
+		}
+	case objTypeParam:
+		owner := d.funcObj(&or)
+		tparams := owner.Type().(*types.Signature).TypeParams()
+		i := or.Len()
+		if i >= tparams.Len() {
+			d.errorf("function %s has no type parameter %d", owner, i)
+		}
+		obj = tparams.At(i).Obj()
+	default:
+		d.errorf("invalid object code %d", code)
+	}
+	d.objs[idx] = obj
+	return obj
+}
+
+func (d *importer) typeList(r *pkgbits.Decoder) []types.Type {
+	var ts []types.Type
+	for n := r.Len(); n > 0; n-- {
+		ts = append(ts, d.typ(r))
+	}
+	return ts
+}
+
+// typ decodes a reference to a type.
+func (d *importer) typ(r *pkgbits.Decoder) types.Type {
+	idx := r.Reloc(pkgbits.RelocType)
+	if t := d.types[idx]; t != nil {
+		return t
+	}
+	tr := d.pr.NewDecoder(pkgbits.RelocType, idx, pkgbits.SyncTypeIdx)
+	var t types.Type
+	switch code := codeType(tr.Code(pkgbits.SyncType)); code {
+	case typeBasic:
+		name := tr.String()
+		t = basicTypes[name]
+		if t == nil {
+			d.errorf("invalid basic type %s", name)
+		}
+
+	case typeUniverse:
+		name := tr.String()
+		tn, ok := types.Universe.Lookup(name).(*types.TypeName)
+		if !ok {
+			d.errorf("invalid predeclared type %s", name)
+		}
+		t = tn.Type()
+
+	case typeNamed:
+		tn, ok := d.obj(&tr).(*types.TypeName)
+		if !ok {
+			d.errorf("%s is not a type", tn)
+		}
+		t = tn.Type()
+		if targs := d.typeList(&tr); len(targs) > 0 {
+			inst, err := types.Instantiate(d.prog.ctxt, t, targs, false)
+			if err != nil {
+				d.errorf("%v", err)
+			}
+			t = inst
+		}
+
+	case typeLocal:
+		name, pkg, pos := tr.String(), d.pkgRef(&tr), d.pos(&tr)
+		named := types.NewNamed(types.NewTypeName(pos, pkg, name, nil), nil, nil)
+		d.types[idx] = named // before decoding a recursive underlying type
+		named.SetUnderlying(d.typ(&tr))
+		t = named
+
+	case typeLocalAlias:
+		name, pkg, pos := tr.String(), d.pkgRef(&tr), d.pos(&tr)
+		t = types.NewAlias(types.NewTypeName(pos, pkg, name, nil), d.typ(&tr))
+
+	case typeTypeParam:
+		tn, ok := d.obj(&tr).(*types.TypeName)
+		if !ok {
+			d.errorf("%s is not a type parameter", tn)
+		}
+		t = tn.Type()
+
+	case typePointer:
+		t = types.NewPointer(d.typ(&tr))
+
+	case typeSlice:
+		t = types.NewSlice(d.typ(&tr))
+
+	case typeArray:
+		n := tr.Int64()
+		t = types.NewArray(d.typ(&tr), n)
+
+	case typeMap:
+		key := d.typ(&tr)
+		t = types.NewMap(key, d.typ(&tr))
+
+	case typeChan:
+		dir := types.ChanDir(tr.Len())
+		t = types.NewChan(dir, d.typ(&tr))
+
+	case typeSignature:
+		var recv *types.Var
+		if tr.Bool() {
+			recv = d.variable(&tr)
+		}
+		t = d.signature(&tr, recv)
+
+	case typeStruct:
+		var (
+			fields []*types.Var
+			tags   []string
+		)
+		for n := tr.Len(); n > 0; n-- {
+			name, pkg, typ := tr.String(), d.pkgRef(&tr), d.typ(&tr)
+			fields = append(fields, types.NewField(token.NoPos, pkg, name, typ, tr.Bool()))
+			tags = append(tags, tr.String())
+		}
+		t = types.NewStruct(fields, tags)
+
+	case typeInterface:
+		var methods []*types.Func
+		for n := tr.Len(); n > 0; n-- {
+			name, pkg := tr.String(), d.pkgRef(&tr)
+			methods = append(methods, types.NewFunc(token.NoPos, pkg, name, d.signature(&tr, nil)))
+		}
+		embeddeds := d.typeList(&tr)
+		t = types.NewInterfaceType(methods, embeddeds).Complete()
+
+	case typeTuple:
+		t = d.tuple(&tr)
+
+	case typeRangeIter:
+		t = tRangeIter
+
+	case typeDeferStack:
+		t = tDeferStack
+
+	default:
+		d.errorf("invalid type code %d", code)
+	}
+	d.types[idx] = t
+	return t
+}
+
+// signature decodes the parameters and results of a signature.
+func (d *importer) signature(r *pkgbits.Decoder, recv *types.Var) *types.Signature {
+	params := d.tuple(r)
+	results := d.tuple(r)
+	return types.NewSignatureType(recv, nil, nil, params, results, r.Bool())
+}
+
+func (d *importer) tuple(r *pkgbits.Decoder) *types.Tuple {
+	var vars []*types.Var
+	for n := r.Len(); n > 0; n-- {
+		vars = append(vars, d.variable(r))
+	}
+	return types.NewTuple(vars...)
+}
+
+// variable decodes a parameter or result.
+func (d *importer) variable(r *pkgbits.Decoder) *types.Var {
+	name, pkg, pos := r.String(), d.pkgRef(r), d.pos(r)
+	return types.NewParam(pos, pkg, name, d.typ(r))
+}
+
+// basicTypes maps the name of each basic type to the type.
+var basicTypes = func() map[string]*types.Basic {
+	m := make(map[string]*types.Basic)
+	for _, t := range types.Typ {
+		m[t.Name()] = t
+	}
+	for _, name := range []string{"byte", "rune"} {
+		m[name] = types.Universe.Lookup(name).Type().(*types.Basic)
+	}
+	return m
+}()
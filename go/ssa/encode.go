@@ -0,0 +1,955 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+// This file defines WriteExportData, the encoder of SSA export data.
+// See decode.go for the decoder.
+//
+// The export data of a package consists of the magic string
+// exportMagic, the length (a uvarint) and contents of the "shallow"
+// export data of its types (see gcimporter.IExportShallowAll), and a
+// unified IR bitstream (see package pkgbits) describing its functions,
+// whose sections are used as follows:
+//
+//	RelocMeta     the root element: the package path, the number of
+//	              init#N functions, and a reference to each function
+//	              together with its element
+//	RelocPosBase  source files, with their line tables
+//	RelocPkg      packages, by path
+//	RelocType     types
+//	RelocObj      objects, by object path, member name, or owner
+//	RelocBody     functions: provenance, anonymous functions, body
+//
+// The functions are those of the package members, the declared methods
+// of its types, and the instances of generic functions (in
+// InstantiateGenerics mode) on which their bodies depend, transitively.
+// Other synthetic functions, such as wrappers, are created on demand
+// by the decoder, as during building.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/types/objectpath"
+	"github.com/tinygo-org/tinygo/x-tools/internal/aliases"
+	"github.com/tinygo-org/tinygo/x-tools/internal/gcimporter"
+	"github.com/tinygo-org/tinygo/x-tools/internal/pkgbits"
+)
+
+// exportMagic identifies SSA export data and its version.
+const exportMagic = "go/ssa export data v0\n"
+
+// Codes of the various kinds of encoded entities.
+type (
+	codeType  int
+	codeObj   int
+	codeFunc  int
+	codeValue int
+	codeInstr int
+)
+
+func (c codeType) Marker() pkgbits.SyncMarker  { return pkgbits.SyncType }
+func (c codeType) Value() int                  { return int(c) }
+func (c codeObj) Marker() pkgbits.SyncMarker   { return pkgbits.SyncObject }
+func (c codeObj) Value() int                   { return int(c) }
+func (c codeFunc) Marker() pkgbits.SyncMarker  { return pkgbits.SyncCodeObj }
+func (c codeFunc) Value() int                  { return int(c) }
+func (c codeValue) Marker() pkgbits.SyncMarker { return pkgbits.SyncExpr }
+func (c codeValue) Value() int                 { return int(c) }
+func (c codeInstr) Marker() pkgbits.SyncMarker { return pkgbits.SyncStmt1 }
+func (c codeInstr) Value() int                 { return int(c) }
+
+const (
+	typeBasic      codeType = iota // name
+	typeUniverse                   // name of predeclared type
+	typeNamed                      // TypeName object, type arguments
+	typeLocal                      // name, package, pos, underlying
+	typeLocalAlias                 // name, package, pos, rhs
+	typeTypeParam                  // TypeName object
+	typePointer                    // elem
+	typeSlice                      // elem
+	typeArray                      // len, elem
+	typeMap                        // key, elem
+	typeChan                       // dir, elem
+	typeSignature                  // recv?, params, results, variadic
+	typeStruct                     // fields
+	typeInterface                  // methods, embeddeds
+	typeTuple                      // vars
+	typeRangeIter                  // tRangeIter
+	typeDeferStack                 // tDeferStack
+)
+
+const (
+	objPath           codeObj = iota // package, object path
+	objMethod                        // receiver type, package, name
+	objMethodInstance                // origin method, receiver type arguments
+	objTypeParam                     // function, index of type parameter
+	objMember                        // package, name
+)
+
+const (
+	funcMember   codeFunc = iota // package, member name
+	funcObject                   // declared function or method
+	funcInstance                 // origin function, type arguments
+	funcAnon                     // parent function, index
+	funcBound                    // method
+	funcThunk                    // selection
+	funcWrapper                  // receiver type, method
+)
+
+const (
+	valueNil      codeValue = iota // absent optional operand
+	valueLocal                     // parameter, free variable or register, by number
+	valueConst                     // type, value?
+	valueGlobal                    // package, member name
+	valueFunction                  // function
+	valueBuiltin                   // name, signature
+)
+
+const (
+	instrAlloc codeInstr = iota
+	instrPhi
+	instrCall
+	instrBinOp
+	instrUnOp
+	instrChangeType
+	instrConvert
+	instrMultiConvert
+	instrChangeInterface
+	instrSliceToArrayPointer
+	instrMakeInterface
+	instrMakeClosure
+	instrMakeMap
+	instrMakeChan
+	instrMakeSlice
+	instrSlice
+	instrFieldAddr
+	instrField
+	instrIndexAddr
+	instrIndex
+	instrLookup
+	instrSelect
+	instrRange
+	instrNext
+	instrTypeAssert
+	instrExtract
+	instrJump
+	instrIf
+	instrReturn
+	instrRunDefers
+	instrPanic
+	instrGo
+	instrDefer
+	instrSend
+	instrStore
+	instrMapUpdate
+)
+
+// WriteExportData writes the export data of package p, which must
+// have been built, to out. The data describes the types of the
+// package and the code of its functions, so that [ReadExportData] can
+// create the package in another program without type-checking or
+// building it. Tools may use it to cache the SSA form of each package,
+// keyed by a hash of its inputs.
+//
+// Debugging information ([DebugRef] instructions) is not preserved,
+// nor are the types declared within functions of an instance of a
+// generic function. As with gcexportdata, the values of
+// floating-point constants declared by the package may lose precision.
+//
+// The format of the data is not specified and may change: data
+// should be read only by the executable that wrote it.
+func WriteExportData(out io.Writer, p *Package) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if e, ok := r.(exportError); ok {
+				err = e
+				return
+			}
+			panic(r)
+		}
+	}()
+
+	if p.Pkg == types.Unsafe {
+		return fmt.Errorf("cannot export package unsafe")
+	}
+	typesData, err := gcimporter.IExportShallowAll(p.Prog.Fset, p.Pkg, nil)
+	if err != nil {
+		return err
+	}
+
+	e := &exporter{
+		prog:     p.Prog,
+		pkg:      p,
+		pw:       pkgbits.NewPkgEncoder(pkgbits.V2, -1),
+		files:    make(map[*token.File]pkgbits.Index),
+		pkgs:     make(map[*types.Package]pkgbits.Index),
+		types:    make(map[types.Type]pkgbits.Index),
+		objs:     make(map[types.Object]pkgbits.Index),
+		queued:   make(map[*Function]bool),
+		wrappers: make(map[*Function]bool),
+	}
+
+	// Queue the package members, then the declared methods.
+	var names []string
+	for name := range p.Members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch mem := p.Members[name].(type) {
+		case *Function:
+			e.enqueue(mem)
+		case *Type:
+			if named, ok := mem.Type().(*types.Named); ok {
+				for i := range named.NumMethods() {
+					if fn, ok := p.objects[named.Method(i)].(*Function); ok {
+						e.enqueue(fn)
+					}
+				}
+			}
+		}
+	}
+
+	// Encode the functions, queueing the instances they refer to.
+	var elems []pkgbits.Index
+	for i := 0; i < len(e.queue); i++ {
+		fn := e.queue[i]
+		if fn.build != nil {
+			e.errorf("function %s has not been built", fn)
+		}
+		elems = append(elems, e.function(fn))
+	}
+
+	// The number of init#N functions.
+	ninit := 0
+	for p.Members[fmt.Sprintf("init#%d", ninit+1)] != nil {
+		ninit++
+	}
+
+	w := e.pw.NewEncoder(pkgbits.RelocMeta, pkgbits.SyncPublic)
+	w.String(p.Pkg.Path())
+	w.Len(ninit)
+	w.Len(len(e.queue))
+	for i, fn := range e.queue {
+		e.funcRef(&w, fn)
+		w.Reloc(pkgbits.RelocBody, elems[i])
+	}
+	w.Flush()
+
+	var buf bytes.Buffer
+	buf.WriteString(exportMagic)
+	buf.Write(binary.AppendUvarint(nil, uint64(len(typesData))))
+	buf.Write(typesData)
+	e.pw.DumpTo(&buf)
+	_, err = out.Write(buf.Bytes())
+	return err
+}
+
+// An exportError is an error that occurred during encoding.
+type exportError struct{ msg string }
+
+func (e exportError) Error() string { return e.msg }
+
+// An exporter holds the state of WriteExportData.
+type exporter struct {
+	prog     *Program
+	pkg      *Package
+	pw       pkgbits.PkgEncoder
+	objpaths objectpath.Encoder
+
+	files map[*token.File]pkgbits.Index
+	pkgs  map[*types.Package]pkgbits.Index
+	types map[types.Type]pkgbits.Index
+	objs  map[types.Object]pkgbits.Index
+
+	queue    []*Function        // top-level functions to encode
+	queued   map[*Function]bool // set of elements of queue
+	wrappers map[*Function]bool // wrappers whose callees have been queued
+
+	values map[Value]int // numbers of the local values of the current function
+}
+
+func (e *exporter) errorf(format string, args ...any) {
+	panic(exportError{fmt.Sprintf("exporting %s: %s", e.pkg.Pkg.Path(), fmt.Sprintf(format, args...))})
+}
+
+// enqueue queues the top-level function fn for encoding.
+func (e *exporter) enqueue(fn *Function) {
+	if !e.queued[fn] {
+		e.queued[fn] = true
+		e.queue = append(e.queue, fn)
+	}
+}
+
+// function encodes the element of fn, which is top-level or
+// anonymous, and returns its index.
+func (e *exporter) function(fn *Function) pkgbits.Index {
+	w := e.pw.NewEncoder(pkgbits.RelocBody, pkgbits.SyncFuncExt)
+	w.String(fn.Synthetic)
+	e.pos(&w, fn.pos)
+	if fn.parent != nil {
+		e.typ(&w, fn.Signature)
+		w.Len(len(fn.FreeVars))
+		for _, fv := range fn.FreeVars {
+			w.String(fv.name)
+			e.typ(&w, fv.typ)
+			e.pos(&w, fv.pos)
+		}
+	}
+	w.Len(len(fn.AnonFuncs))
+	for _, anon := range fn.AnonFuncs {
+		w.Reloc(pkgbits.RelocBody, e.function(anon))
+	}
+	if w.Bool(fn.Blocks != nil) {
+		e.body(&w, fn)
+	}
+	return w.Flush()
+}
+
+// body encodes the parameters, blocks, recover block and locals of fn.
+func (e *exporter) body(w *pkgbits.Encoder, fn *Function) {
+	// Number the local values.
+	e.values = make(map[Value]int)
+	for _, p := range fn.Params {
+		e.values[p] = len(e.values)
+	}
+	for _, fv := range fn.FreeVars {
+		e.values[fv] = len(e.values)
+	}
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			if v, ok := instr.(Value); ok {
+				e.values[v] = len(e.values)
+			}
+		}
+	}
+
+	w.Len(len(fn.Params))
+	w.Len(len(fn.Blocks))
+	for _, b := range fn.Blocks {
+		w.String(b.Comment)
+		w.Len(len(b.Preds))
+		for _, pred := range b.Preds {
+			w.Len(pred.Index)
+		}
+		w.Len(len(b.Succs))
+		for _, succ := range b.Succs {
+			w.Len(succ.Index)
+		}
+		n := 0
+		for _, instr := range b.Instrs {
+			if _, ok := instr.(*DebugRef); !ok {
+				n++
+			}
+		}
+		w.Len(n)
+		for _, instr := range b.Instrs {
+			if _, ok := instr.(*DebugRef); !ok {
+				e.instr(w, instr)
+			}
+		}
+	}
+	if fn.Recover != nil {
+		w.Len(fn.Recover.Index + 1)
+	} else {
+		w.Len(0)
+	}
+	w.Len(len(fn.Locals))
+	for _, l := range fn.Locals {
+		w.Len(e.values[l])
+	}
+	e.values = nil
+}
+
+// instr encodes an instruction. A value-defining instruction other
+// than a call is followed by its type and position.
+func (e *exporter) instr(w *pkgbits.Encoder, instr Instruction) {
+	code := func(c codeInstr, v Value) {
+		w.Code(c)
+		e.typ(w, v.Type())
+		e.pos(w, v.Pos())
+	}
+	switch instr := instr.(type) {
+	case *Alloc:
+		code(instrAlloc, instr)
+		w.String(instr.Comment)
+		w.Bool(instr.Heap)
+	case *Phi:
+		code(instrPhi, instr)
+		w.String(instr.Comment)
+		e.valueList(w, instr.Edges)
+	case *Call:
+		w.Code(instrCall)
+		e.typ(w, instr.Type())
+		e.call(w, &instr.Call)
+	case *BinOp:
+		code(instrBinOp, instr)
+		w.Len(int(instr.Op))
+		e.value(w, instr.X)
+		e.value(w, instr.Y)
+	case *UnOp:
+		code(instrUnOp, instr)
+		w.Len(int(instr.Op))
+		e.value(w, instr.X)
+		w.Bool(instr.CommaOk)
+	case *ChangeType:
+		code(instrChangeType, instr)
+		e.value(w, instr.X)
+	case *Convert:
+		code(instrConvert, instr)
+		e.value(w, instr.X)
+	case *MultiConvert:
+		code(instrMultiConvert, instr)
+		e.value(w, instr.X)
+		e.typ(w, instr.from)
+		e.typ(w, instr.to)
+	case *ChangeInterface:
+		code(instrChangeInterface, instr)
+		e.value(w, instr.X)
+	case *SliceToArrayPointer:
+		code(instrSliceToArrayPointer, instr)
+		e.value(w, instr.X)
+	case *MakeInterface:
+		code(instrMakeInterface, instr)
+		e.value(w, instr.X)
+	case *MakeClosure:
+		code(instrMakeClosure, instr)
+		e.value(w, instr.Fn)
+		e.valueList(w, instr.Bindings)
+	case *MakeMap:
+		code(instrMakeMap, instr)
+		e.value(w, instr.Reserve)
+	case *MakeChan:
+		code(instrMakeChan, instr)
+		e.value(w, instr.Size)
+	case *MakeSlice:
+		code(instrMakeSlice, instr)
+		e.value(w, instr.Len)
+		e.value(w, instr.Cap)
+	case *Slice:
+		code(instrSlice, instr)
+		e.value(w, instr.X)
+		e.value(w, instr.Low)
+		e.value(w, instr.High)
+		e.value(w, instr.Max)
+	case *FieldAddr:
+		code(instrFieldAddr, instr)
+		e.value(w, instr.X)
+		w.Len(instr.Field)
+	case *Field:
+		code(instrField, instr)
+		e.value(w, instr.X)
+		w.Len(instr.Field)
+	case *IndexAddr:
+		code(instrIndexAddr, instr)
+		e.value(w, instr.X)
+		e.value(w, instr.Index)
+	case *Index:
+		code(instrIndex, instr)
+		e.value(w, instr.X)
+		e.value(w, instr.Index)
+	case *Lookup:
+		code(instrLookup, instr)
+		e.value(w, instr.X)
+		e.value(w, instr.Index)
+		w.Bool(instr.CommaOk)
+	case *Select:
+		code(instrSelect, instr)
+		w.Len(len(instr.States))
+		for _, st := range instr.States {
+			w.Len(int(st.Dir))
+			e.value(w, st.Chan)
+			e.value(w, st.Send)
+			e.pos(w, st.Pos)
+		}
+		w.Bool(instr.Blocking)
+	case *Range:
+		code(instrRange, instr)
+		e.value(w, instr.X)
+	case *Next:
+		code(instrNext, instr)
+		e.value(w, instr.Iter)
+		w.Bool(instr.IsString)
+	case *TypeAssert:
+		code(instrTypeAssert, instr)
+		e.value(w, instr.X)
+		e.typ(w, instr.AssertedType)
+		w.Bool(instr.CommaOk)
+	case *Extract:
+		code(instrExtract, instr)
+		e.value(w, instr.Tuple)
+		w.Len(instr.Index)
+	case *Jump:
+		w.Code(instrJump)
+	case *If:
+		w.Code(instrIf)
+		e.value(w, instr.Cond)
+	case *Return:
+		w.Code(instrReturn)
+		e.pos(w, instr.pos)
+		e.valueList(w, instr.Results)
+	case *RunDefers:
+		w.Code(instrRunDefers)
+	case *Panic:
+		w.Code(instrPanic)
+		e.pos(w, instr.pos)
+		e.value(w, instr.X)
+	case *Go:
+		w.Code(instrGo)
+		e.pos(w, instr.pos)
+		e.call(w, &instr.Call)
+	case *Defer:
+		w.Code(instrDefer)
+		e.pos(w, instr.pos)
+		e.call(w, &instr.Call)
+		e.value(w, instr.DeferStack)
+	case *Send:
+		w.Code(instrSend)
+		e.pos(w, instr.pos)
+		e.value(w, instr.Chan)
+		e.value(w, instr.X)
+	case *Store:
+		w.Code(instrStore)
+		e.pos(w, instr.pos)
+		e.value(w, instr.Addr)
+		e.value(w, instr.Val)
+	case *MapUpdate:
+		w.Code(instrMapUpdate)
+		e.pos(w, instr.pos)
+		e.value(w, instr.Map)
+		e.value(w, instr.Key)
+		e.value(w, instr.Value)
+	default:
+		e.errorf("unexpected instruction %T", instr)
+	}
+}
+
+// call encodes a call. The method of an invoke-mode call is
+// identified by its package and name.
+func (e *exporter) call(w *pkgbits.Encoder, c *CallCommon) {
+	e.value(w, c.Value)
+	if w.Bool(c.Method != nil) {
+		e.pkgRef(w, c.Method.Pkg())
+		w.String(c.Method.Name())
+	}
+	e.valueList(w, c.Args)
+	e.pos(w, c.pos)
+}
+
+func (e *exporter) valueList(w *pkgbits.Encoder, vs []Value) {
+	w.Len(len(vs))
+	for _, v := range vs {
+		e.value(w, v)
+	}
+}
+
+// value encodes an operand, which may be nil.
+func (e *exporter) value(w *pkgbits.Encoder, v Value) {
+	switch v := v.(type) {
+	case nil:
+		w.Code(valueNil)
+	case *Const:
+		w.Code(valueConst)
+		e.typ(w, v.typ)
+		if w.Bool(v.Value != nil) {
+			w.Value(v.Value)
+		}
+	case *Global:
+		w.Code(valueGlobal)
+		e.pkgRef(w, v.Pkg.Pkg)
+		w.String(v.name)
+	case *Function:
+		w.Code(valueFunction)
+		e.funcRef(w, v)
+	case *Builtin:
+		w.Code(valueBuiltin)
+		w.String(v.name)
+		e.typ(w, v.sig)
+	default:
+		n, ok := e.values[v]
+		if !ok {
+			e.errorf("operand %s is not local to its function", v.Name())
+		}
+		w.Code(valueLocal)
+		w.Len(n)
+	}
+}
+
+// funcRef encodes a reference to a function. Instances of generic
+// functions with bodies are queued for encoding.
+func (e *exporter) funcRef(w *pkgbits.Encoder, fn *Function) {
+	switch {
+	case fn.parent != nil:
+		w.Code(funcAnon)
+		e.funcRef(w, fn.parent)
+		w.Len(int(fn.anonIdx))
+
+	case fn.Pkg != nil && fn.Pkg.Members[fn.name] == fn:
+		w.Code(funcMember)
+		e.pkgRef(w, fn.Pkg.Pkg)
+		w.String(fn.name)
+
+	case fn.topLevelOrigin != nil:
+		w.Code(funcInstance)
+		e.funcRef(w, fn.topLevelOrigin)
+		e.typeList(w, fn.typeargs)
+		if strings.HasPrefix(fn.Synthetic, "instance of ") && fn.Blocks != nil {
+			e.enqueue(fn)
+		}
+
+	case strings.HasPrefix(fn.Synthetic, "bound method wrapper"):
+		w.Code(funcBound)
+		e.obj(w, fn.object)
+		e.wrapper(fn)
+
+	case fn.method != nil && fn.method.kind == types.MethodExpr:
+		w.Code(funcThunk)
+		sel := fn.method
+		e.typ(w, sel.recv)
+		e.typ(w, sel.typ)
+		e.obj(w, sel.obj)
+		w.Len(len(sel.index))
+		for _, i := range sel.index {
+			w.Len(i)
+		}
+		w.Bool(sel.indirect)
+		e.wrapper(fn)
+
+	case fn.method != nil:
+		w.Code(funcWrapper)
+		e.typ(w, fn.method.recv)
+		e.obj(w, fn.method.obj)
+		e.wrapper(fn)
+
+	case fn.object != nil:
+		w.Code(funcObject)
+		e.obj(w, fn.object)
+
+	default:
+		e.errorf("cannot export reference to function %s", fn)
+	}
+}
+
+// wrapper queues the instances called by fn, a synthetic wrapper that
+// is created on demand by the decoder, so that their bodies are
+// available to it.
+func (e *exporter) wrapper(fn *Function) {
+	if e.wrappers[fn] {
+		return
+	}
+	e.wrappers[fn] = true
+	var rands []*Value
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			for _, rand := range instr.Operands(rands[:0]) {
+				if callee, ok := (*rand).(*Function); ok {
+					if callee.method != nil || strings.HasPrefix(callee.Synthetic, "bound method wrapper") {
+						e.wrapper(callee)
+					} else if strings.HasPrefix(callee.Synthetic, "instance of ") && callee.Blocks != nil {
+						e.enqueue(callee)
+					}
+				}
+			}
+		}
+	}
+}
+
+// pkgRef encodes a reference to a package, which may be nil.
+func (e *exporter) pkgRef(w *pkgbits.Encoder, pkg *types.Package) {
+	if !w.Bool(pkg != nil) {
+		return
+	}
+	idx, ok := e.pkgs[pkg]
+	if !ok {
+		pw := e.pw.NewEncoder(pkgbits.RelocPkg, pkgbits.SyncPkgDef)
+		pw.String(pkg.Path())
+		idx = pw.Flush()
+		e.pkgs[pkg] = idx
+	}
+	w.Reloc(pkgbits.RelocPkg, idx)
+}
+
+// pos encodes a position, which may be invalid, as a file and an
+// offset.
+func (e *exporter) pos(w *pkgbits.Encoder, pos token.Pos) {
+	if !w.Bool(pos.IsValid()) {
+		return
+	}
+	file := e.prog.Fset.File(pos)
+	idx, ok := e.files[file]
+	if !ok {
+		fw := e.pw.NewEncoder(pkgbits.RelocPosBase, pkgbits.SyncPosBase)
+		fw.String(file.Name())
+		fw.Len(file.Size())
+		lines := file.Lines()
+		fw.Len(len(lines))
+		prev := 0
+		for _, line := range lines {
+			fw.Len(line - prev)
+			prev = line
+		}
+		idx = fw.Flush()
+		e.files[file] = idx
+	}
+	w.Reloc(pkgbits.RelocPosBase, idx)
+	w.Len(file.Offset(pos))
+}
+
+// obj encodes a reference to an object: a package-level object or a
+// part of one, such as a method or a type parameter; a method of a
+// type; or an instance of a method of a generic type.
+func (e *exporter) obj(w *pkgbits.Encoder, obj types.Object) {
+	idx, ok := e.objs[obj]
+	if !ok {
+		ow := e.pw.NewEncoder(pkgbits.RelocObj, pkgbits.SyncObject)
+		fn, isFunc := obj.(*types.Func)
+		if isFunc && fn.Origin() != fn {
+			ow.Code(objMethodInstance)
+			e.obj(&ow, fn.Origin())
+			e.typeList(&ow, receiverTypeArgs(fn))
+		} else if path, err := e.objpaths.For(obj); err == nil {
+			ow.Code(objPath)
+			e.pkgRef(&ow, obj.Pkg())
+			ow.String(string(path))
+		} else if isPackageLevel(obj) {
+			// An unexported function, variable or constant.
+			ow.Code(objMember)
+			e.pkgRef(&ow, obj.Pkg())
+			ow.String(obj.Name())
+		} else if isFunc && fn.Type().(*types.Signature).Recv() != nil {
+			ow.Code(objMethod)
+			e.typ(&ow, recvType(fn))
+			e.pkgRef(&ow, fn.Pkg())
+			ow.String(fn.Name())
+		} else if owner, i := typeParamOwner(obj); owner != nil {
+			// A type parameter of an unexported function.
+			ow.Code(objTypeParam)
+			e.obj(&ow, owner)
+			ow.Len(i)
+		} else {
+			e.errorf("cannot export reference to %s: %v", obj, err)
+		}
+		idx = ow.Flush()
+		e.objs[obj] = idx
+	}
+	w.Reloc(pkgbits.RelocObj, idx)
+}
+
+func (e *exporter) typeList(w *pkgbits.Encoder, ts []types.Type) {
+	w.Len(len(ts))
+	for _, t := range ts {
+		e.typ(w, t)
+	}
+}
+
+// typ encodes a reference to a type.
+func (e *exporter) typ(w *pkgbits.Encoder, t types.Type) {
+	w.Reloc(pkgbits.RelocType, e.typeIdx(t))
+}
+
+// typeIdx returns the index of the element of type t, encoding it if
+// necessary.
+func (e *exporter) typeIdx(t types.Type) pkgbits.Index {
+	if idx, ok := e.types[t]; ok {
+		return idx
+	}
+
+	w := e.pw.NewEncoder(pkgbits.RelocType, pkgbits.SyncTypeIdx)
+	e.types[t] = w.Idx // before encoding the parts of a recursive type
+	switch t := t.(type) {
+	case *types.Basic:
+		w.Code(typeBasic)
+		w.String(t.Name())
+
+	case *types.Alias:
+		if t.Obj().Pkg() == nil {
+			w.Code(typeUniverse)
+			w.String(t.Obj().Name())
+			break
+		}
+		if !isPackageLevel(t.Obj()) {
+			w.Code(typeLocalAlias)
+			w.String(t.Obj().Name())
+			e.pkgRef(&w, t.Obj().Pkg())
+			e.pos(&w, t.Obj().Pos())
+			e.typ(&w, aliases.Rhs(t))
+			break
+		}
+		w.Code(typeNamed)
+		e.obj(&w, aliases.Origin(t).Obj())
+		var targs []types.Type
+		for i := range aliases.TypeArgs(t).Len() {
+			targs = append(targs, aliases.TypeArgs(t).At(i))
+		}
+		e.typeList(&w, targs)
+
+	case *types.Named:
+		obj := t.Obj()
+		switch {
+		case obj.Pkg() == nil:
+			w.Code(typeUniverse)
+			w.String(obj.Name())
+		case isPackageLevel(t.Origin().Obj()):
+			w.Code(typeNamed)
+			e.obj(&w, t.Origin().Obj())
+			var targs []types.Type
+			for i := range t.TypeArgs().Len() {
+				targs = append(targs, t.TypeArgs().At(i))
+			}
+			e.typeList(&w, targs)
+		case t.TypeArgs().Len() > 0:
+			e.errorf("cannot export local type %s", t)
+		default:
+			w.Code(typeLocal)
+			w.String(obj.Name())
+			e.pkgRef(&w, obj.Pkg())
+			e.pos(&w, obj.Pos())
+			e.typ(&w, t.Underlying())
+		}
+
+	case *types.TypeParam:
+		w.Code(typeTypeParam)
+		e.obj(&w, t.Obj())
+
+	case *types.Pointer:
+		if t == tDeferStack {
+			w.Code(typeDeferStack)
+			break
+		}
+		w.Code(typePointer)
+		e.typ(&w, t.Elem())
+
+	case *types.Slice:
+		w.Code(typeSlice)
+		e.typ(&w, t.Elem())
+
+	case *types.Array:
+		w.Code(typeArray)
+		w.Int64(t.Len())
+		e.typ(&w, t.Elem())
+
+	case *types.Map:
+		w.Code(typeMap)
+		e.typ(&w, t.Key())
+		e.typ(&w, t.Elem())
+
+	case *types.Chan:
+		w.Code(typeChan)
+		w.Len(int(t.Dir()))
+		e.typ(&w, t.Elem())
+
+	case *types.Signature:
+		if t.TypeParams().Len() > 0 || t.RecvTypeParams().Len() > 0 {
+			e.errorf("cannot export generic signature %s", t)
+		}
+		w.Code(typeSignature)
+		if w.Bool(t.Recv() != nil) {
+			e.variable(&w, t.Recv())
+		}
+		e.signature(&w, t)
+
+	case *types.Struct:
+		w.Code(typeStruct)
+		w.Len(t.NumFields())
+		for i := range t.NumFields() {
+			f := t.Field(i)
+			w.String(f.Name())
+			e.pkgRef(&w, f.Pkg())
+			e.typ(&w, f.Type())
+			w.Bool(f.Embedded())
+			w.String(t.Tag(i))
+		}
+
+	case *types.Interface:
+		w.Code(typeInterface)
+		w.Len(t.NumExplicitMethods())
+		for i := range t.NumExplicitMethods() {
+			m := t.ExplicitMethod(i)
+			w.String(m.Name())
+			e.pkgRef(&w, m.Pkg())
+			e.signature(&w, m.Type().(*types.Signature))
+		}
+		w.Len(t.NumEmbeddeds())
+		for i := range t.NumEmbeddeds() {
+			if _, ok := t.EmbeddedType(i).(*types.Union); ok {
+				e.errorf("cannot export constraint interface %s", t)
+			}
+			e.typ(&w, t.EmbeddedType(i))
+		}
+
+	case *types.Tuple:
+		w.Code(typeTuple)
+		e.tuple(&w, t)
+
+	case *opaqueType:
+		switch t {
+		case tRangeIter:
+			w.Code(typeRangeIter)
+		default:
+			e.errorf("unexpected type %s", t)
+		}
+
+	default:
+		e.errorf("unexpected type %T", t)
+	}
+	return w.Flush()
+}
+
+// signature encodes the parameters and results of a signature, but
+// not its receiver, which is the interface of an interface method.
+func (e *exporter) signature(w *pkgbits.Encoder, sig *types.Signature) {
+	e.tuple(w, sig.Params())
+	e.tuple(w, sig.Results())
+	w.Bool(sig.Variadic())
+}
+
+func (e *exporter) tuple(w *pkgbits.Encoder, t *types.Tuple) {
+	w.Len(t.Len())
+	for i := range t.Len() {
+		e.variable(w, t.At(i))
+	}
+}
+
+// variable encodes a parameter or result.
+func (e *exporter) variable(w *pkgbits.Encoder, v *types.Var) {
+	w.String(v.Name())
+	e.pkgRef(w, v.Pkg())
+	e.pos(w, v.Pos())
+	e.typ(w, v.Type())
+}
+
+// typeParamOwner returns the package-level function that declares
+// obj as its ith type parameter, or nil if obj is not a type
+// parameter of a function.
+func typeParamOwner(obj types.Object) (*types.Func, int) {
+	tparam, ok := obj.Type().(*types.TypeParam)
+	if !ok || obj.Pkg() == nil {
+		return nil, 0
+	}
+	scope := obj.Pkg().Scope()
+	for _, name := range scope.Names() {
+		if fn, ok := scope.Lookup(name).(*types.Func); ok {
+			tparams := fn.Type().(*types.Signature).TypeParams()
+			for i := range tparams.Len() {
+				if tparams.At(i) == tparam {
+					return fn, i
+				}
+			}
+		}
+	}
+	return nil, 0
+}
+
+// isPackageLevel reports whether obj is declared at package level.
+func isPackageLevel(obj types.Object) bool {
+	return obj.Pkg() != nil && obj.Parent() == obj.Pkg().Scope()
+}
@@ -0,0 +1,133 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa/ssautil"
+	"github.com/tinygo-org/tinygo/x-tools/internal/diff"
+	"github.com/tinygo-org/tinygo/x-tools/internal/testfiles"
+	"github.com/tinygo-org/tinygo/x-tools/txtar"
+)
+
+// TestExportData writes the export data of each package of
+// testdata/exportdata.txtar and testdata/text/*.txtar, in both
+// builder modes, and checks that the packages read from it into a
+// new program print the same text, with the same positions.
+func TestExportData(t *testing.T) {
+	files, err := filepath.Glob("testdata/text/*.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, "testdata/exportdata.txtar")
+	for _, file := range files {
+		for _, mode := range []ssa.BuilderMode{0, ssa.InstantiateGenerics} {
+			mode |= ssa.SanityCheckFunctions
+			t.Run(fmt.Sprintf("%s/%s", filepath.Base(file), mode), func(t *testing.T) {
+				ar, err := txtar.ParseFile(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pkgs := testfiles.LoadPackages(t, ar, "./...")
+				prog, _ := ssautil.Packages(pkgs, mode)
+				prog.Build()
+
+				// Write and read each package, dependencies first.
+				prog2 := ssa.NewProgram(token.NewFileSet(), mode)
+				read := make(map[*ssa.Package]*ssa.Package)
+				packages.Visit(pkgs, nil, func(p *packages.Package) {
+					if t.Failed() {
+						return
+					}
+					path := p.Types.Path()
+					if !strings.HasPrefix(path, "example.com/") {
+						prog2.CreatePackage(p.Types, nil, nil, true)
+						return
+					}
+					var buf bytes.Buffer
+					if err := ssa.WriteExportData(&buf, prog.Package(p.Types)); err != nil {
+						t.Fatalf("writing %s: %v", path, err)
+					}
+					p2, err := ssa.ReadExportData(prog2, &buf, path)
+					if err != nil {
+						t.Fatalf("reading %s: %v", path, err)
+					}
+					read[prog.Package(p.Types)] = p2
+				})
+
+				// Compare the packages once all are read, as reading a
+				// package may create instances of generic functions of
+				// its dependencies.
+				for p, p2 := range read {
+					if want, got := packageText(p), packageText(p2); got != want {
+						t.Errorf("package %s read from export data differs:\n%s", p.Pkg.Path(), diff.Unified("written", "read", want, got))
+					}
+				}
+			})
+		}
+	}
+}
+
+// packageText returns the text of pkg, followed by the positions of
+// its functions, and the functions they refer to, and of their
+// instructions.
+func packageText(pkg *ssa.Package) string {
+	var buf bytes.Buffer
+	ssa.WritePackageText(&buf, pkg)
+
+	fset := pkg.Prog.Fset
+	var lines []string
+	seen := make(map[*ssa.Function]bool)
+	var visit func(fn *ssa.Function)
+	visit = func(fn *ssa.Function) {
+		if fn == nil || seen[fn] {
+			return
+		}
+		seen[fn] = true
+		lines = append(lines, fmt.Sprintf("%s: %s", fn, fset.Position(fn.Pos())))
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+		}
+		var rands []*ssa.Value
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				if _, ok := instr.(*ssa.DebugRef); ok {
+					continue
+				}
+				lines = append(lines, fmt.Sprintf("%s: %d.%s: %s", fn, b.Index, instr, fset.Position(instr.Pos())))
+				for _, rand := range instr.Operands(rands[:0]) {
+					if fn, ok := (*rand).(*ssa.Function); ok {
+						visit(fn)
+					}
+				}
+			}
+		}
+	}
+	for _, mem := range pkg.Members {
+		switch mem := mem.(type) {
+		case *ssa.Function:
+			visit(mem)
+		case *ssa.Type:
+			if named, ok := mem.Type().(*types.Named); ok {
+				for i := range named.NumMethods() {
+					visit(pkg.Prog.FuncValue(named.Method(i)))
+				}
+			}
+		}
+	}
+	sort.Strings(lines)
+	buf.WriteString(strings.Join(lines, "\n"))
+	return buf.String()
+}
@@ -614,6 +614,13 @@ func (f *Function) WriteTo(w io.Writer) (int64, error) {
 
 // WriteFunction writes to buf a human-readable "disassembly" of f.
 func WriteFunction(buf *bytes.Buffer, f *Function) {
+	writeFunction(buf, f, false)
+}
+
+// writeFunction is like WriteFunction, but if text is set, it writes f
+// in the form read by ParsePackage: without its location, and with
+// constants printed exactly.
+func writeFunction(buf *bytes.Buffer, f *Function, text bool) {
 	fmt.Fprintf(buf, "# Name: %s\n", f.String())
 	if f.Pkg != nil {
 		fmt.Fprintf(buf, "# Package: %s\n", f.Pkg.Pkg.Path())
@@ -621,7 +628,7 @@ func WriteFunction(buf *bytes.Buffer, f *Function) {
 	if syn := f.Synthetic; syn != "" {
 		fmt.Fprintln(buf, "# Synthetic:", syn)
 	}
-	if pos := f.Pos(); pos.IsValid() {
+	if pos := f.Pos(); !text && pos.IsValid() {
 		fmt.Fprintf(buf, "# Location: %s\n", f.Prog.Fset.Position(pos))
 	}
 
@@ -682,7 +689,7 @@ func WriteFunction(buf *bytes.Buffer, f *Function) {
 					n, _ := fmt.Fprintf(buf, "%s = ", name)
 					l -= n
 				}
-				n, _ := buf.WriteString(instr.String())
+				n, _ := buf.WriteString(instrString(instr, text))
 				l -= n
 				// Right-align the type if there's space.
 				if t := v.Type(); t != nil {
@@ -698,7 +705,7 @@ func WriteFunction(buf *bytes.Buffer, f *Function) {
 				// Be robust against bad transforms.
 				buf.WriteString("<deleted>")
 			default:
-				buf.WriteString(instr.String())
+				buf.WriteString(instrString(instr, text))
 			}
 			// -mode=S: show line numbers
 			if f.Prog.mode&LogSource != 0 {
@@ -21,49 +21,83 @@ import (
 
 type externalFn func(fr *frame, args []value) value
 
-// TODO(adonovan): fix: reflect.Value abstracts an lvalue or an
-// rvalue; Set() causes mutations that can be observed via aliases.
-// We have not captured that correctly here.
-
 // Key strings are from Function.String().
 var externals = make(map[string]externalFn)
 
 func init() {
 	// That little dot ۰ is an Arabic zero numeral (U+06F0), categories [Nd].
 	for k, v := range map[string]externalFn{
+		"(*reflect.MapIter).Key":          ext۰reflect۰MapIter۰Key,
+		"(*reflect.MapIter).Next":         ext۰reflect۰MapIter۰Next,
+		"(*reflect.MapIter).Reset":        ext۰reflect۰MapIter۰Reset,
+		"(*reflect.MapIter).Value":        ext۰reflect۰MapIter۰Value,
+		"(reflect.Value).Addr":            ext۰reflect۰Value۰Addr,
 		"(reflect.Value).Bool":            ext۰reflect۰Value۰Bool,
+		"(reflect.Value).Bytes":           ext۰reflect۰Value۰Bytes,
+		"(reflect.Value).Call":            ext۰reflect۰Value۰Call,
+		"(reflect.Value).CallSlice":       ext۰reflect۰Value۰CallSlice,
 		"(reflect.Value).CanAddr":         ext۰reflect۰Value۰CanAddr,
 		"(reflect.Value).CanInterface":    ext۰reflect۰Value۰CanInterface,
+		"(reflect.Value).CanSet":          ext۰reflect۰Value۰CanSet,
+		"(reflect.Value).Cap":             ext۰reflect۰Value۰Cap,
+		"(reflect.Value).Complex":         ext۰reflect۰Value۰Complex,
+		"(reflect.Value).Convert":         ext۰reflect۰Value۰Convert,
 		"(reflect.Value).Elem":            ext۰reflect۰Value۰Elem,
 		"(reflect.Value).Field":           ext۰reflect۰Value۰Field,
+		"(reflect.Value).FieldByName":     ext۰reflect۰Value۰FieldByName,
 		"(reflect.Value).Float":           ext۰reflect۰Value۰Float,
 		"(reflect.Value).Index":           ext۰reflect۰Value۰Index,
 		"(reflect.Value).Int":             ext۰reflect۰Value۰Int,
 		"(reflect.Value).Interface":       ext۰reflect۰Value۰Interface,
 		"(reflect.Value).IsNil":           ext۰reflect۰Value۰IsNil,
 		"(reflect.Value).IsValid":         ext۰reflect۰Value۰IsValid,
+		"(reflect.Value).IsZero":          ext۰reflect۰Value۰IsZero,
 		"(reflect.Value).Kind":            ext۰reflect۰Value۰Kind,
 		"(reflect.Value).Len":             ext۰reflect۰Value۰Len,
 		"(reflect.Value).MapIndex":        ext۰reflect۰Value۰MapIndex,
 		"(reflect.Value).MapKeys":         ext۰reflect۰Value۰MapKeys,
+		"(reflect.Value).MapRange":        ext۰reflect۰Value۰MapRange,
+		"(reflect.Value).Method":          ext۰reflect۰Value۰Method,
+		"(reflect.Value).MethodByName":    ext۰reflect۰Value۰MethodByName,
 		"(reflect.Value).NumField":        ext۰reflect۰Value۰NumField,
 		"(reflect.Value).NumMethod":       ext۰reflect۰Value۰NumMethod,
 		"(reflect.Value).Pointer":         ext۰reflect۰Value۰Pointer,
 		"(reflect.Value).Set":             ext۰reflect۰Value۰Set,
+		"(reflect.Value).SetBool":         ext۰reflect۰Value۰SetBool,
+		"(reflect.Value).SetFloat":        ext۰reflect۰Value۰SetFloat,
+		"(reflect.Value).SetInt":          ext۰reflect۰Value۰SetInt,
+		"(reflect.Value).SetLen":          ext۰reflect۰Value۰SetLen,
+		"(reflect.Value).SetMapIndex":     ext۰reflect۰Value۰SetMapIndex,
+		"(reflect.Value).SetString":       ext۰reflect۰Value۰SetString,
+		"(reflect.Value).SetUint":         ext۰reflect۰Value۰SetUint,
+		"(reflect.Value).Slice":           ext۰reflect۰Value۰Slice,
 		"(reflect.Value).String":          ext۰reflect۰Value۰String,
 		"(reflect.Value).Type":            ext۰reflect۰Value۰Type,
 		"(reflect.Value).Uint":            ext۰reflect۰Value۰Uint,
 		"(reflect.error).Error":           ext۰reflect۰error۰Error,
+		"(reflect.rtype).Align":           ext۰reflect۰rtype۰Align,
+		"(reflect.rtype).AssignableTo":    ext۰reflect۰rtype۰AssignableTo,
 		"(reflect.rtype).Bits":            ext۰reflect۰rtype۰Bits,
+		"(reflect.rtype).Comparable":      ext۰reflect۰rtype۰Comparable,
+		"(reflect.rtype).ConvertibleTo":   ext۰reflect۰rtype۰ConvertibleTo,
 		"(reflect.rtype).Elem":            ext۰reflect۰rtype۰Elem,
 		"(reflect.rtype).Field":           ext۰reflect۰rtype۰Field,
+		"(reflect.rtype).FieldByName":     ext۰reflect۰rtype۰FieldByName,
+		"(reflect.rtype).Implements":      ext۰reflect۰rtype۰Implements,
 		"(reflect.rtype).In":              ext۰reflect۰rtype۰In,
+		"(reflect.rtype).IsVariadic":      ext۰reflect۰rtype۰IsVariadic,
+		"(reflect.rtype).Key":             ext۰reflect۰rtype۰Key,
 		"(reflect.rtype).Kind":            ext۰reflect۰rtype۰Kind,
+		"(reflect.rtype).Len":             ext۰reflect۰rtype۰Len,
+		"(reflect.rtype).Method":          ext۰reflect۰rtype۰Method,
+		"(reflect.rtype).MethodByName":    ext۰reflect۰rtype۰MethodByName,
+		"(reflect.rtype).Name":            ext۰reflect۰rtype۰Name,
 		"(reflect.rtype).NumField":        ext۰reflect۰rtype۰NumField,
 		"(reflect.rtype).NumIn":           ext۰reflect۰rtype۰NumIn,
 		"(reflect.rtype).NumMethod":       ext۰reflect۰rtype۰NumMethod,
 		"(reflect.rtype).NumOut":          ext۰reflect۰rtype۰NumOut,
 		"(reflect.rtype).Out":             ext۰reflect۰rtype۰Out,
+		"(reflect.rtype).PkgPath":         ext۰reflect۰rtype۰PkgPath,
 		"(reflect.rtype).Size":            ext۰reflect۰rtype۰Size,
 		"(reflect.rtype).String":          ext۰reflect۰rtype۰String,
 		"bytes.Equal":                     ext۰bytes۰Equal,
@@ -85,11 +119,20 @@ func init() {
 		"math.Sqrt":                       ext۰math۰Sqrt,
 		"os.Exit":                         ext۰os۰Exit,
 		"os.Getenv":                       ext۰os۰Getenv,
+		"reflect.Append":                  ext۰reflect۰Append,
+		"reflect.AppendSlice":             ext۰reflect۰AppendSlice,
+		"reflect.MakeFunc":                ext۰reflect۰MakeFunc,
+		"reflect.MakeMap":                 ext۰reflect۰MakeMap,
+		"reflect.MakeSlice":               ext۰reflect۰MakeSlice,
+		"reflect.MapOf":                   ext۰reflect۰MapOf,
 		"reflect.New":                     ext۰reflect۰New,
+		"reflect.PointerTo":               ext۰reflect۰PointerTo,
+		"reflect.PtrTo":                   ext۰reflect۰PointerTo,
 		"reflect.SliceOf":                 ext۰reflect۰SliceOf,
 		"reflect.TypeOf":                  ext۰reflect۰TypeOf,
 		"reflect.ValueOf":                 ext۰reflect۰ValueOf,
 		"reflect.Zero":                    ext۰reflect۰Zero,
+		"reflect.valueInterface":          ext۰reflect۰valueInterface,
 		"runtime.Breakpoint":              ext۰runtime۰Breakpoint,
 		"runtime.GC":                      ext۰runtime۰GC,
 		"runtime.GOMAXPROCS":              ext۰runtime۰GOMAXPROCS,
@@ -101,8 +144,9 @@ func init() {
 		"sort.Ints":                       ext۰sort۰Ints,
 		"sort.Strings":                    ext۰sort۰Strings,
 		"strconv.Atoi":                    ext۰strconv۰Atoi,
-		"strconv.Itoa":                    ext۰strconv۰Itoa,
 		"strconv.FormatFloat":             ext۰strconv۰FormatFloat,
+		"strconv.Itoa":                    ext۰strconv۰Itoa,
+		"strconv.Unquote":                 ext۰strconv۰Unquote,
 		"strings.Count":                   ext۰strings۰Count,
 		"strings.EqualFold":               ext۰strings۰EqualFold,
 		"strings.Index":                   ext۰strings۰Index,
@@ -233,6 +277,13 @@ func ext۰strconv۰Atoi(fr *frame, args []value) value {
 	}
 	return tuple{i, iface{}}
 }
+func ext۰strconv۰Unquote(fr *frame, args []value) value {
+	s, e := strconv.Unquote(args[0].(string))
+	if e != nil {
+		return tuple{s, iface{fr.i.runtimeErrorString, e.Error()}}
+	}
+	return tuple{s, iface{}}
+}
 func ext۰strconv۰Itoa(fr *frame, args []value) value {
 	return strconv.Itoa(args[0].(int))
 }
@@ -290,6 +341,10 @@ func ext۰runtime۰GC(fr *frame, args []value) value {
 }
 
 func ext۰runtime۰Gosched(fr *frame, args []value) value {
+	if s := fr.i.sched; s != nil {
+		s.gosched(fr)
+		return nil
+	}
 	runtime.Gosched()
 	return nil
 }
@@ -299,6 +354,10 @@ func ext۰runtime۰NumCPU(fr *frame, args []value) value {
 }
 
 func ext۰time۰Sleep(fr *frame, args []value) value {
+	if s := fr.i.sched; s != nil {
+		s.gosched(fr) // the scheduler has no clock
+		return nil
+	}
 	time.Sleep(time.Duration(args[0].(int64)))
 	return nil
 }
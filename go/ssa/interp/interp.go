@@ -11,14 +11,25 @@
 // instruction.  It is not, and will never be, a production-quality Go
 // interpreter.
 //
+// By default, goroutines run concurrently on goroutines of the
+// interpreter, so their interleaving is nondeterministic.
+// [InterpretSchedule] instead runs them one at a time under a
+// deterministic, seedable scheduler that reports deadlocks, and
+// [Explore] systematically searches their interleavings for one that
+// fails.
+//
 // The following is a partial list of Go features that are currently
 // unsupported or incomplete in the interpreter.
 //
-// * Unsafe operations, including all uses of unsafe.Pointer, are
-// impossible to support given the "boxed" value representation we
-// have chosen.
+// * Unsafe operations are only partially supported given the "boxed"
+// value representation we have chosen: unsafe.Pointer conversions
+// between types of like representation, and unsafe.Add, Slice and
+// String within the array or struct containing the pointer work (and
+// panic outside it), but arithmetic on uintptr values derived from
+// pointers does not.
 //
-// * The reflect package is only partially implemented.
+// * The reflect package is largely emulated, but not all of its
+// functions are implemented.
 //
 // * The "testing" package is no longer supported because it
 // depends on low-level details that change too often.
@@ -26,7 +37,7 @@
 // * "sync/atomic" operations are not atomic due to the "boxed" value
 // representation: it is not possible to read, modify and write an
 // interface value atomically. As a consequence, Mutexes are currently
-// broken.
+// broken, except under a deterministic schedule.
 //
 // * recover is only partially implemented.  Also, the interpreter
 // makes no attempt to distinguish target panics from interpreter
@@ -48,6 +59,7 @@ import (
 	"fmt"
 	"github.com/tinygo-org/tinygo/alt_go/token"
 	"github.com/tinygo-org/tinygo/alt_go/types"
+	"io"
 	"log"
 	"os"
 	"reflect"
@@ -87,9 +99,12 @@ type interpreter struct {
 	reflectPackage     *ssa.Package           // the fake reflect package
 	errorMethods       methodSet              // the method set of reflect.error, which implements the error interface.
 	rtypeMethods       methodSet              // the method set of rtype, which implements the reflect.Type interface.
+	makeFuncStub       *ssa.Function          // the code of function values implemented by the interpreter
 	runtimeErrorString types.Type             // the runtime.errorString type
 	sizes              types.Sizes            // the effective type-sizing function
 	goroutines         int32                  // atomically updated
+	stderr             io.Writer              // the target program's standard error
+	sched              *scheduler             // the deterministic scheduler, if any
 }
 
 type deferred struct {
@@ -104,6 +119,7 @@ type frame struct {
 	caller           *frame
 	fn               *ssa.Function
 	block, prevBlock *ssa.BasicBlock
+	instr            ssa.Instruction     // the current instruction
 	env              map[ssa.Value]value // dynamic values of SSA variables
 	locals           []value
 	defers           *deferred
@@ -145,8 +161,12 @@ func (fr *frame) runDefer(d *deferred) {
 	defer func() {
 		if !ok {
 			// Deferred call created a new state of panic.
+			p := recover()
+			if _, killed := p.(killedPanic); killed {
+				panic(p)
+			}
 			fr.panicking = true
-			fr.panic = recover()
+			fr.panic = p
 		}
 	}()
 	call(fr.i, fr, d.instr.Pos(), d.fn, d.args)
@@ -195,6 +215,17 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 		// no-op
 
 	case *ssa.UnOp:
+		if s := fr.i.sched; s != nil && instr.Op == token.ARROW {
+			v, ok := s.recv(fr, fr.get(instr.X).(chan value))
+			if !ok {
+				v = zero(instr.X.Type().Underlying().(*types.Chan).Elem())
+			}
+			if instr.CommaOk {
+				v = tuple{v, ok}
+			}
+			fr.env[instr] = v
+			break
+		}
 		fr.env[instr] = unop(instr, fr.get(instr.X))
 
 	case *ssa.BinOp:
@@ -247,6 +278,10 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 		panic(targetPanic{fr.get(instr.X)})
 
 	case *ssa.Send:
+		if s := fr.i.sched; s != nil {
+			s.send(fr, fr.get(instr.Chan).(chan value), fr.get(instr.X))
+			break
+		}
 		fr.get(instr.Chan).(chan value) <- fr.get(instr.X)
 
 	case *ssa.Store:
@@ -279,6 +314,10 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 
 	case *ssa.Go:
 		fn, args := prepareCall(fr, &instr.Call)
+		if s := fr.i.sched; s != nil {
+			s.goStmt(fr, instr, fn, args)
+			break
+		}
 		atomic.AddInt32(&fr.i.goroutines, 1)
 		go func() {
 			call(fr.i, nil, instr.Pos(), fn, args)
@@ -385,32 +424,58 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 		log.Fatal("unreachable") // phis are processed at block entry
 
 	case *ssa.Select:
-		var cases []reflect.SelectCase
-		if !instr.Blocking {
-			cases = append(cases, reflect.SelectCase{
-				Dir: reflect.SelectDefault,
-			})
-		}
-		for _, state := range instr.States {
-			var dir reflect.SelectDir
-			if state.Dir == types.RecvOnly {
-				dir = reflect.SelectRecv
-			} else {
-				dir = reflect.SelectSend
+		var (
+			chosen int
+			recv   value
+			recvOk bool
+		)
+		if s := fr.i.sched; s != nil {
+			op := &chanOp{nonblocking: !instr.Blocking}
+			for _, state := range instr.States {
+				cas := chanCase{ch: fr.get(state.Chan).(chan value)}
+				if state.Send != nil {
+					cas.send, cas.val = true, fr.get(state.Send)
+				}
+				op.cases = append(op.cases, cas)
 			}
-			var send reflect.Value
-			if state.Send != nil {
-				send = reflect.ValueOf(fr.get(state.Send))
+			status := "select"
+			if len(op.cases) == 0 {
+				status += " (no cases)"
+			}
+			chosen = s.chanOp(fr, op, status)
+			recv, recvOk = op.recv, op.recvOk
+		} else {
+			var cases []reflect.SelectCase
+			if !instr.Blocking {
+				cases = append(cases, reflect.SelectCase{
+					Dir: reflect.SelectDefault,
+				})
+			}
+			for _, state := range instr.States {
+				var dir reflect.SelectDir
+				if state.Dir == types.RecvOnly {
+					dir = reflect.SelectRecv
+				} else {
+					dir = reflect.SelectSend
+				}
+				var send reflect.Value
+				if state.Send != nil {
+					send = reflect.ValueOf(fr.get(state.Send))
+				}
+				cases = append(cases, reflect.SelectCase{
+					Dir:  dir,
+					Chan: reflect.ValueOf(fr.get(state.Chan)),
+					Send: send,
+				})
+			}
+			var recvValue reflect.Value
+			chosen, recvValue, recvOk = reflect.Select(cases)
+			if !instr.Blocking {
+				chosen-- // default case should have index -1.
+			}
+			if recvOk {
+				recv = recvValue.Interface().(value)
 			}
-			cases = append(cases, reflect.SelectCase{
-				Dir:  dir,
-				Chan: reflect.ValueOf(fr.get(state.Chan)),
-				Send: send,
-			})
-		}
-		chosen, recv, recvOk := reflect.Select(cases)
-		if !instr.Blocking {
-			chosen-- // default case should have index -1.
 		}
 		r := tuple{chosen, recvOk}
 		for i, st := range instr.States {
@@ -418,7 +483,7 @@ func visitInstr(fr *frame, instr ssa.Instruction) continuation {
 				var v value
 				if i == chosen && recvOk {
 					// No need to copy since send makes an unaliased copy.
-					v = recv.Interface().(value)
+					v = recv
 				} else {
 					v = zero(st.Chan.Type().Underlying().(*types.Chan).Elem())
 				}
@@ -477,9 +542,12 @@ func call(i *interpreter, caller *frame, callpos token.Pos, fn value, args []val
 		}
 		return callSSA(i, caller, callpos, fn, args, nil)
 	case *closure:
+		if fn.Fn == i.makeFuncStub {
+			return fn.Env[0].(nativeFunc)(caller, args)
+		}
 		return callSSA(i, caller, callpos, fn.Fn, args, fn.Env)
 	case *ssa.Builtin:
-		return callBuiltin(caller, callpos, fn, args)
+		return callBuiltin(i, caller, callpos, fn, args)
 	}
 	panic(fmt.Sprintf("cannot call %T", fn))
 }
@@ -574,8 +642,15 @@ func runFrame(fr *frame) {
 		if fr.i.mode&DisableRecover != 0 {
 			return // let interpreter crash
 		}
+		p := recover()
+		if _, killed := p.(killedPanic); killed {
+			panic(p) // program terminated; don't run defers
+		}
+		if s := fr.i.sched; s != nil && s.running.stack == "" {
+			s.running.stack = s.stack(fr)
+		}
 		fr.panicking = true
-		fr.panic = recover()
+		fr.panic = p
 		if fr.i.mode&EnableTracing != 0 {
 			fmt.Fprintf(os.Stderr, "Panicking: %T %v.\n", fr.panic, fr.panic)
 		}
@@ -597,6 +672,7 @@ func runFrame(fr *frame) {
 					fmt.Fprintln(os.Stderr, "\t", instr)
 				}
 			}
+			fr.instr = instr
 			if visitInstr(fr, instr) == kReturn {
 				return
 			}
@@ -652,6 +728,9 @@ func doRecover(caller *frame) value {
 		caller.caller.panicking = false
 		p := caller.caller.panic
 		caller.caller.panic = nil
+		if s := caller.i.sched; s != nil {
+			s.running.stack = ""
+		}
 
 		// TODO(adonovan): support runtime.Goexit.
 		switch p := p.(type) {
@@ -684,12 +763,19 @@ func doRecover(caller *frame) value {
 // Type parameterized functions must have been built with
 // InstantiateGenerics in the ssa.BuilderMode to be interpreted.
 func Interpret(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename string, args []string) (exitCode int) {
+	return newInterpreter(mainpkg, mode, sizes, filename, args).runMain(mainpkg)
+}
+
+// newInterpreter returns an interpreter for the program of mainpkg,
+// with its global variables initialized to zero.
+func newInterpreter(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename string, args []string) *interpreter {
 	i := &interpreter{
 		prog:       mainpkg.Prog,
 		globals:    make(map[*ssa.Global]*value),
 		mode:       mode,
 		sizes:      sizes,
 		goroutines: 1,
+		stderr:     os.Stderr,
 	}
 	runtimePkg := i.prog.ImportedPackage("runtime")
 	if runtimePkg == nil {
@@ -714,7 +800,12 @@ func Interpret(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename stri
 			}
 		}
 	}
+	return i
+}
 
+// runMain initializes mainpkg and calls its main function, and returns
+// the exit code of the program.
+func (i *interpreter) runMain(mainpkg *ssa.Package) (exitCode int) {
 	// Top-level error handler.
 	exitCode = 2
 	defer func() {
@@ -724,22 +815,11 @@ func Interpret(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename stri
 		switch p := recover().(type) {
 		case exitPanic:
 			exitCode = int(p)
-			return
-		case targetPanic:
-			fmt.Fprintln(os.Stderr, "panic:", toString(p.v))
-		case runtime.Error:
-			fmt.Fprintln(os.Stderr, "panic:", p.Error())
-		case string:
-			fmt.Fprintln(os.Stderr, "panic:", p)
+		case killedPanic:
+			// The scheduler has terminated the program.
 		default:
-			fmt.Fprintf(os.Stderr, "panic: unexpected type: %T: %v\n", p, p)
+			i.reportPanic(p)
 		}
-
-		// TODO(adonovan): dump panicking interpreter goroutine?
-		// buf := make([]byte, 0x10000)
-		// runtime.Stack(buf, false)
-		// fmt.Fprintln(os.Stderr, string(buf))
-		// (Or dump panicking target goroutine?)
 	}()
 
 	// Run!
@@ -748,8 +828,31 @@ func Interpret(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename stri
 		call(i, nil, token.NoPos, mainFn, nil)
 		exitCode = 0
 	} else {
-		fmt.Fprintln(os.Stderr, "No main function.")
+		fmt.Fprintln(i.stderr, "No main function.")
 		exitCode = 1
 	}
 	return
 }
+
+// reportPanic reports an unrecovered panic with value p.
+func (i *interpreter) reportPanic(p any) {
+	switch p := p.(type) {
+	case targetPanic:
+		fmt.Fprintln(i.stderr, "panic:", toString(p.v))
+	case runtime.Error:
+		fmt.Fprintln(i.stderr, "panic:", p.Error())
+	case string:
+		fmt.Fprintln(i.stderr, "panic:", p)
+	default:
+		fmt.Fprintf(i.stderr, "panic: unexpected type: %T: %v\n", p, p)
+	}
+
+	if s := i.sched; s != nil {
+		s.dump(i.stderr, s.running)
+	}
+	// TODO(adonovan): dump panicking interpreter goroutine?
+	// buf := make([]byte, 0x10000)
+	// runtime.Stack(buf, false)
+	// fmt.Fprintln(os.Stderr, string(buf))
+	// (Or dump panicking target goroutine?)
+}
@@ -127,6 +127,10 @@ var testdataTests = []string{
 	"rangeoverint.go",
 	"recover.go",
 	"reflect.go",
+	"reflectcall.go",
+	"reflectprint.go",
+	"reflecttemplate.go",
+	"reflectvalue.go",
 	"slice2arrayptr.go",
 	"static.go",
 	"width32.go",
@@ -138,6 +142,9 @@ var testdataTests = []string{
 	"fixedbugs/issue66783.go",
 	"fixedbugs/issue69929.go",
 	"typeassert.go",
+	"unsafe.go",
+	"unsafebounds.go",
+	"json.go",
 	"zeros.go",
 	"slice2array.go",
 	"minmax.go",
@@ -215,44 +222,10 @@ func run(t *testing.T, input string, goroot string) string {
 	}
 	hint = fmt.Sprintf("To trace execution, run:\n%% go build golang.org/x/tools/cmd/ssadump && ./ssadump -build=C -test -run --interp=T %s\n", input)
 
-	// Capture anything written by the interpreter to os.Std{out,err}
-	// by temporarily redirecting them to a buffer via a pipe.
-	//
+	// Capture anything written by the interpreter to os.Std{out,err}.
 	// While capturing is in effect, we must not write any
 	// test-related stuff to stderr (including log.Print, t.Log, etc).
-	var restore func() string // restore files and log+return the mixed out/err.
-	{
-		// Connect std{out,err} to pipe.
-		r, w, err := os.Pipe()
-		if err != nil {
-			t.Fatalf("can't create pipe for stderr: %v", err)
-		}
-		savedStdout := os.Stdout
-		savedStderr := os.Stderr
-		os.Stdout = w
-		os.Stderr = w
-
-		// Buffer what is written.
-		var buf strings.Builder
-		done := make(chan struct{})
-		go func() {
-			if _, err := io.Copy(&buf, r); err != nil {
-				fmt.Fprintf(savedStderr, "io.Copy: %v", err)
-			}
-			close(done)
-		}()
-
-		// Finally, restore the files and log what was captured.
-		restore = func() string {
-			os.Stdout = savedStdout
-			os.Stderr = savedStderr
-			w.Close()
-			<-done
-			captured := buf.String()
-			t.Logf("Interpreter's stdout+stderr:\n%s", captured)
-			return captured
-		}
-	}
+	restore := captureOutput(t)
 
 	var imode interp.Mode // default mode
 	// imode |= interp.DisableRecover // enable for debugging
@@ -272,6 +245,42 @@ func run(t *testing.T, input string, goroot string) string {
 	return capturedOutput
 }
 
+// captureOutput temporarily redirects os.Std{out,err} to a buffer via
+// a pipe. It returns a function that restores the files and logs and
+// returns the mixed output.
+func captureOutput(t *testing.T) (restore func() string) {
+	// Connect std{out,err} to pipe.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("can't create pipe for stderr: %v", err)
+	}
+	savedStdout := os.Stdout
+	savedStderr := os.Stderr
+	os.Stdout = w
+	os.Stderr = w
+
+	// Buffer what is written.
+	var buf strings.Builder
+	done := make(chan struct{})
+	go func() {
+		if _, err := io.Copy(&buf, r); err != nil {
+			fmt.Fprintf(savedStderr, "io.Copy: %v", err)
+		}
+		close(done)
+	}()
+
+	// Finally, restore the files and log what was captured.
+	return func() string {
+		os.Stdout = savedStdout
+		os.Stderr = savedStderr
+		w.Close()
+		<-done
+		captured := buf.String()
+		t.Logf("Interpreter's stdout+stderr:\n%s", captured)
+		return captured
+	}
+}
+
 // makeGoroot copies testdata/src into the "src" directory of a temporary
 // location to mimic GOROOT/src, and adds a file "runtime/consts.go" containing
 // declarations for GOOS and GOARCH that match the GOOS and GOARCH of this test.
@@ -358,8 +367,6 @@ func TestTypeparamTest(t *testing.T) {
 	skip := map[string]string{
 		"chans.go":      "interp tests do not support runtime.SetFinalizer",
 		"issue23536.go": "unknown reason",
-		"issue48042.go": "interp tests do not handle reflect.Value.SetInt",
-		"issue47716.go": "interp tests do not handle unsafe.Sizeof",
 		"issue50419.go": "interp tests do not handle dispatch to String() correctly",
 		"issue51733.go": "interp does not handle unsafe casts",
 		"ordered.go":    "math.NaN() comparisons not being handled correctly",
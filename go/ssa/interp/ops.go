@@ -10,7 +10,6 @@ import (
 	"github.com/tinygo-org/tinygo/alt_go/constant"
 	"github.com/tinygo-org/tinygo/alt_go/token"
 	"github.com/tinygo-org/tinygo/alt_go/types"
-	"os"
 	"reflect"
 	"strings"
 	"unsafe"
@@ -954,7 +953,7 @@ var CapturedOutput *bytes.Buffer
 
 // callBuiltin interprets a call to builtin fn with arguments args,
 // returning its result.
-func callBuiltin(caller *frame, callpos token.Pos, fn *ssa.Builtin, args []value) value {
+func callBuiltin(i *interpreter, caller *frame, callpos token.Pos, fn *ssa.Builtin, args []value) value {
 	switch fn.Name() {
 	case "append":
 		if len(args) == 1 {
@@ -980,6 +979,10 @@ func callBuiltin(caller *frame, callpos token.Pos, fn *ssa.Builtin, args []value
 		return copy(args[0].([]value), src.([]value))
 
 	case "close": // close(chan T)
+		if s := i.sched; s != nil {
+			s.close(caller, args[0].(chan value))
+			return nil
+		}
 		close(args[0].(chan value))
 		return nil
 
@@ -1006,7 +1009,7 @@ func callBuiltin(caller *frame, callpos token.Pos, fn *ssa.Builtin, args []value
 		if ln {
 			buf.WriteRune('\n')
 		}
-		os.Stderr.Write(buf.Bytes())
+		i.stderr.Write(buf.Bytes())
 		return nil
 
 	case "len":
@@ -1024,6 +1027,9 @@ func callBuiltin(caller *frame, callpos token.Pos, fn *ssa.Builtin, args []value
 		case *hashmap:
 			return x.len()
 		case chan value:
+			if s := i.sched; s != nil {
+				return len(s.channel(x).buf)
+			}
 			return len(x)
 		default:
 			panic(fmt.Sprintf("len: illegal operand: %T", x))
@@ -1100,6 +1106,9 @@ func callBuiltin(caller *frame, callpos token.Pos, fn *ssa.Builtin, args []value
 		return &caller.defers
 	}
 
+	if v, ok := callUnsafe(i, caller, fn, args); ok {
+		return v
+	}
 	panic("unknown built-in: " + fn.Name())
 }
 
@@ -1250,25 +1259,12 @@ func conv(t_dst, t_src types.Type, x value) value {
 			break // fail: no other conversions for string
 		}
 
-		// unsafe.Pointer -> *value
+		// unsafe.Pointer -> *value or uintptr
 		if ut_src.Kind() == types.UnsafePointer {
-			// TODO(adonovan): this is wrong and cannot
-			// really be fixed with the current design.
-			//
-			// return (*value)(x.(unsafe.Pointer))
-			// creates a new pointer of a different
-			// type but the underlying interface value
-			// knows its "true" type and so cannot be
-			// meaningfully used through the new pointer.
-			//
-			// To make this work, the interpreter needs to
-			// simulate the memory layout of a real
-			// compiled implementation.
-			//
-			// To at least preserve type-safety, we'll
-			// just return the zero value of the
-			// destination type.
-			return zero(t_dst)
+			if _, ok := ut_dst.(*types.Pointer); ok {
+				return unsafePointerToPointer(t_dst, x.(unsafe.Pointer))
+			}
+			return uintptr(x.(unsafe.Pointer))
 		}
 
 		// Conversions between complex numeric types?
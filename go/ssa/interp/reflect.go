@@ -9,12 +9,23 @@ package interp
 // We completely replace the built-in "reflect" package.
 // The only thing clients can depend upon are that reflect.Type is an
 // interface and reflect.Value is an (opaque) struct.
+//
+// A reflect.Value is represented as a structure of four fields:
+//
+//	rtype    the type of the value, or rtype{nil} for the zero Value
+//	value    the value itself, unless it is addressable
+//	*value   the address of the variable, if the value is addressable
+//	roFlag   whether the value was obtained through an unexported field
+//
+// An addressable Value denotes its variable, not a copy of it, so that
+// updates made through Set and friends are observed by the program.
 
 import (
 	"fmt"
 	"github.com/tinygo-org/tinygo/alt_go/token"
 	"github.com/tinygo-org/tinygo/alt_go/types"
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
@@ -49,35 +60,276 @@ func makeNamedType(name string, underlying types.Type) *types.Named {
 	return types.NewNamed(obj, underlying, nil)
 }
 
+// newReflectValue returns a reflect.Value of type t denoting the
+// variable at addr, if non-nil, or else the value v.
+// ro indicates whether it was obtained through an unexported field.
+func newReflectValue(t types.Type, v value, addr *value, ro roFlag) value {
+	if addr != nil {
+		v = nil
+	}
+	return structure{rtype{t}, v, addr, ro}
+}
+
 func makeReflectValue(t types.Type, v value) value {
-	return structure{rtype{t}, v}
+	return newReflectValue(t, v, nil, 0)
 }
 
 // Given a reflect.Value, returns its rtype.
 func rV2T(v value) rtype {
-	return v.(structure)[0].(rtype)
+	rt, _ := v.(structure)[0].(rtype) // the zero Value holds iface{}
+	return rt
 }
 
 // Given a reflect.Value, returns the underlying interpreter value.
 func rV2V(v value) value {
+	if addr := rV2Addr(v); addr != nil {
+		return load(rV2T(v).t, addr)
+	}
 	return v.(structure)[1]
 }
 
+// Given a reflect.Value, returns the address of its variable, or nil
+// if it is not addressable.
+func rV2Addr(v value) *value {
+	addr, _ := v.(structure)[2].(*value)
+	return addr
+}
+
+// An roFlag records whether a reflect.Value was obtained through an
+// unexported field, and so may not be set or converted to an interface.
+type roFlag uint8
+
+const (
+	stickyRO roFlag = 1 << iota // through an unexported non-embedded field
+	embedRO                     // through an unexported embedded field
+)
+
+// sticky returns the flag of a Value derived from one with flag ro.
+// Only the exported fields of an unexported embedded field escape it.
+func (ro roFlag) sticky() roFlag {
+	if ro != 0 {
+		return stickyRO
+	}
+	return 0
+}
+
+// Given a reflect.Value, returns whether it was obtained through an
+// unexported field.
+func rV2RO(v value) roFlag {
+	ro, _ := v.(structure)[3].(roFlag)
+	return ro
+}
+
+// reflectError returns a panic by which the target program may
+// recover the string msg, as from the real reflect package.
+func reflectError(msg string) targetPanic {
+	return targetPanic{iface{types.Typ[types.String], msg}}
+}
+
 // makeReflectType boxes up an rtype in a reflect.Type interface.
 func makeReflectType(rt rtype) value {
 	return iface{rtypeType, rt}
 }
 
+// Given a reflect.Type, returns the type it denotes.
+func rT2T(v value) types.Type {
+	return v.(iface).v.(rtype).t
+}
+
+// typeString returns the name of type t, as printed by reflect.
+func typeString(t types.Type) string {
+	return types.TypeString(t, (*types.Package).Name)
+}
+
+// mustBe panics unless reflect.Value v has one of the specified kinds.
+func mustBe(v value, method string, kinds ...reflect.Kind) reflect.Kind {
+	k := reflectKind(rV2T(v).t)
+	for _, want := range kinds {
+		if k == want {
+			return k
+		}
+	}
+	panic(reflectError(fmt.Sprintf("reflect: call of reflect.Value.%s on %s Value", method, kindString(k))))
+}
+
+func kindString(k reflect.Kind) string {
+	if k == reflect.Invalid {
+		return "zero"
+	}
+	return k.String()
+}
+
+// mustBeAssignable returns the address of the variable denoted by
+// reflect.Value v, panicking if it may not be set.
+func mustBeAssignable(v value, method string) *value {
+	if rV2RO(v) != 0 {
+		panic(reflectError("reflect: reflect.Value." + method + " using value obtained using unexported field"))
+	}
+	addr := rV2Addr(v)
+	if addr == nil {
+		panic(reflectError("reflect: reflect.Value." + method + " using unaddressable value"))
+	}
+	return addr
+}
+
+// assignTo returns the interpreter value of reflect.Value x
+// converted, as if by assignment, to type t.
+// The context names the operation, for error messages.
+func assignTo(x value, t types.Type, context string) value {
+	xt := rV2T(x).t
+	if xt == nil {
+		panic(reflectError(context + ": zero Value"))
+	}
+	if rV2RO(x) != 0 {
+		panic(reflectError(context + ": value obtained using unexported field"))
+	}
+	if !types.AssignableTo(xt, t) {
+		panic(reflectError(fmt.Sprintf("%s: value of type %s is not assignable to type %s", context, typeString(xt), typeString(t))))
+	}
+	v := rV2V(x)
+	if types.IsInterface(t) && !types.IsInterface(xt) {
+		return iface{xt, v}
+	}
+	return v
+}
+
+// copyValue returns a copy of v, of type t, that does not share
+// variables with it.
+func copyValue(t types.Type, v value) value {
+	c := zero(t)
+	store(t, &c, v)
+	return c
+}
+
+// funcType returns the type of the function value of method type sig.
+func funcType(sig *types.Signature) *types.Signature {
+	return types.NewSignatureType(nil, nil, nil, sig.Params(), sig.Results(), sig.Variadic())
+}
+
+// methodFuncType returns the type of the function that implements a
+// method of type sig for receiver type recv: the receiver becomes the
+// first parameter.
+func methodFuncType(recv types.Type, sig *types.Signature) *types.Signature {
+	params := []*types.Var{types.NewParam(token.NoPos, nil, "", recv)}
+	for i := 0; i < sig.Params().Len(); i++ {
+		params = append(params, sig.Params().At(i))
+	}
+	return types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), sig.Results(), sig.Variadic())
+}
+
+// reflectMethods returns the methods of type t that are visible to
+// reflection, in index order: the exported methods of its method set,
+// or all the methods of an interface type, sorted by name.
+func reflectMethods(i *interpreter, t types.Type) []*types.Selection {
+	var methods []*types.Selection
+	mset := i.prog.MethodSets.MethodSet(t)
+	for j := 0; j < mset.Len(); j++ {
+		if sel := mset.At(j); sel.Obj().Exported() || types.IsInterface(t) {
+			methods = append(methods, sel)
+		}
+	}
+	return methods
+}
+
+// makeReflectMethod returns the reflect.Method describing the index'th
+// method sel of type t.
+func makeReflectMethod(i *interpreter, t types.Type, sel *types.Selection, index int) value {
+	obj := sel.Obj()
+	sig := obj.Type().(*types.Signature)
+	var pkgPath string
+	if !obj.Exported() {
+		pkgPath = obj.Pkg().Path()
+	}
+	if types.IsInterface(t) {
+		return structure{obj.Name(), pkgPath, makeReflectType(rtype{funcType(sig)}), makeReflectValue(nil, nil), index}
+	}
+	ft := methodFuncType(t, sig)
+	return structure{obj.Name(), pkgPath, makeReflectType(rtype{ft}), makeReflectValue(ft, i.prog.MethodValue(sel)), index}
+}
+
+// makeStructField returns the reflect.StructField describing field k
+// of struct type st, whose index sequence is index.
+func makeStructField(i *interpreter, st *types.Struct, k int, index []int) value {
+	f := st.Field(k)
+	var pkgPath string
+	if !f.Exported() {
+		pkgPath = f.Pkg().Path()
+	}
+	fields := make([]*types.Var, st.NumFields())
+	for j := range fields {
+		fields[j] = st.Field(j)
+	}
+	indices := make([]value, len(index))
+	for j, x := range index {
+		indices[j] = x
+	}
+	return structure{
+		f.Name(),
+		pkgPath,
+		makeReflectType(rtype{f.Type()}),
+		st.Tag(k),
+		uintptr(i.sizes.Offsetsof(fields)[k]),
+		indices,
+		f.Anonymous(),
+	}
+}
+
+// fieldByName returns the index sequence of the field of struct type t
+// with the specified name, which may be promoted from an embedded
+// field, or nil if there is no such field.
+func fieldByName(t types.Type, name string) []int {
+	st, ok := t.Underlying().(*types.Struct)
+	if !ok || st.NumFields() == 0 {
+		return nil
+	}
+	// All the fields of a struct type belong to the same package.
+	obj, index, _ := types.LookupFieldOrMethod(t, false, st.Field(0).Pkg(), name)
+	if _, ok := obj.(*types.Var); !ok {
+		return nil
+	}
+	return index
+}
+
+// A nativeFunc implements a function value created by the interpreter
+// itself, such as the result of reflect.MakeFunc.
+type nativeFunc func(caller *frame, args []value) value
+
+// makeNativeClosure returns a function value implemented by fn.
+// Calls to it are dispatched by [call].
+func (i *interpreter) makeNativeClosure(fn nativeFunc) *closure {
+	return &closure{i.makeFuncStub, []value{fn}}
+}
+
+func ext۰reflect۰rtype۰Align(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype) int
+	return int(fr.i.sizes.Alignof(args[0].(rtype).t))
+}
+
+func ext۰reflect۰rtype۰AssignableTo(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype, u reflect.Type) bool
+	return types.AssignableTo(args[0].(rtype).t, rT2T(args[1]))
+}
+
 func ext۰reflect۰rtype۰Bits(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) int
 	rt := args[0].(rtype).t
 	basic, ok := rt.Underlying().(*types.Basic)
 	if !ok {
-		panic(fmt.Sprintf("reflect.Type.Bits(%T): non-basic type", rt))
+		panic(reflectError(fmt.Sprintf("reflect.Type.Bits(%T): non-basic type", rt)))
 	}
 	return int(fr.i.sizes.Sizeof(basic)) * 8
 }
 
+func ext۰reflect۰rtype۰Comparable(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype) bool
+	return types.Comparable(args[0].(rtype).t)
+}
+
+func ext۰reflect۰rtype۰ConvertibleTo(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype, u reflect.Type) bool
+	return types.ConvertibleTo(args[0].(rtype).t, rT2T(args[1]))
+}
+
 func ext۰reflect۰rtype۰Elem(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) reflect.Type
 	return makeReflectType(rtype{args[0].(rtype).t.Underlying().(interface {
@@ -89,22 +341,49 @@ func ext۰reflect۰rtype۰Field(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype, i int) reflect.StructField
 	st := args[0].(rtype).t.Underlying().(*types.Struct)
 	i := args[1].(int)
-	f := st.Field(i)
-	return structure{
-		f.Name(),
-		f.Pkg().Path(),
-		makeReflectType(rtype{f.Type()}),
-		st.Tag(i),
-		0,         // TODO(adonovan): offset
-		[]value{}, // TODO(adonovan): indices
-		f.Anonymous(),
+	return makeStructField(fr.i, st, i, []int{i})
+}
+
+func ext۰reflect۰rtype۰FieldByName(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype, name string) (reflect.StructField, bool)
+	t := args[0].(rtype).t
+	index := fieldByName(t, args[1].(string))
+	if index == nil {
+		return tuple{structure{"", "", iface{}, "", uintptr(0), []value(nil), false}, false}
 	}
+	// Find the struct that declares the field.
+	for _, k := range index[:len(index)-1] {
+		t = t.Underlying().(*types.Struct).Field(k).Type()
+		if ptr, ok := t.Underlying().(*types.Pointer); ok {
+			t = ptr.Elem()
+		}
+	}
+	return tuple{makeStructField(fr.i, t.Underlying().(*types.Struct), index[len(index)-1], index), true}
+}
+
+func ext۰reflect۰rtype۰Implements(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype, u reflect.Type) bool
+	u, ok := rT2T(args[1]).Underlying().(*types.Interface)
+	if !ok {
+		panic(reflectError("reflect: non-interface type passed to Type.Implements"))
+	}
+	return types.Implements(args[0].(rtype).t, u)
 }
 
 func ext۰reflect۰rtype۰In(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype, i int) int
 	i := args[1].(int)
-	return makeReflectType(rtype{args[0].(rtype).t.(*types.Signature).Params().At(i).Type()})
+	return makeReflectType(rtype{args[0].(rtype).t.Underlying().(*types.Signature).Params().At(i).Type()})
+}
+
+func ext۰reflect۰rtype۰IsVariadic(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype) bool
+	return args[0].(rtype).t.Underlying().(*types.Signature).Variadic()
+}
+
+func ext۰reflect۰rtype۰Key(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype) reflect.Type
+	return makeReflectType(rtype{args[0].(rtype).t.Underlying().(*types.Map).Key()})
 }
 
 func ext۰reflect۰rtype۰Kind(fr *frame, args []value) value {
@@ -112,6 +391,55 @@ func ext۰reflect۰rtype۰Kind(fr *frame, args []value) value {
 	return uint(reflectKind(args[0].(rtype).t))
 }
 
+func ext۰reflect۰rtype۰Len(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype) int
+	return int(args[0].(rtype).t.Underlying().(*types.Array).Len())
+}
+
+func ext۰reflect۰rtype۰Method(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype, i int) reflect.Method
+	t := args[0].(rtype).t
+	i := args[1].(int)
+	methods := reflectMethods(fr.i, t)
+	if i < 0 || i >= len(methods) {
+		panic(reflectError("reflect: Method index out of range"))
+	}
+	return makeReflectMethod(fr.i, t, methods[i], i)
+}
+
+func ext۰reflect۰rtype۰MethodByName(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype, name string) (reflect.Method, bool)
+	t := args[0].(rtype).t
+	for i, sel := range reflectMethods(fr.i, t) {
+		if sel.Obj().Name() == args[1].(string) {
+			return tuple{makeReflectMethod(fr.i, t, sel, i), true}
+		}
+	}
+	return tuple{structure{"", "", iface{}, makeReflectValue(nil, nil), 0}, false}
+}
+
+func ext۰reflect۰rtype۰Name(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype) string
+	switch t := types.Unalias(args[0].(rtype).t).(type) {
+	case *types.Basic:
+		return types.Typ[t.Kind()].Name() // "uint8", not "byte"
+	case *types.Named:
+		name := t.Obj().Name()
+		if targs := t.TypeArgs(); targs.Len() > 0 {
+			var buf strings.Builder
+			for i := 0; i < targs.Len(); i++ {
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				buf.WriteString(typeString(targs.At(i)))
+			}
+			name += "[" + buf.String() + "]"
+		}
+		return name
+	}
+	return ""
+}
+
 func ext۰reflect۰rtype۰NumField(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) int
 	return args[0].(rtype).t.Underlying().(*types.Struct).NumFields()
@@ -124,7 +452,7 @@ func ext۰reflect۰rtype۰NumIn(fr *frame, args []value) value {
 
 func ext۰reflect۰rtype۰NumMethod(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) int
-	return fr.i.prog.MethodSets.MethodSet(args[0].(rtype).t).Len()
+	return len(reflectMethods(fr.i, args[0].(rtype).t))
 }
 
 func ext۰reflect۰rtype۰NumOut(fr *frame, args []value) value {
@@ -138,6 +466,14 @@ func ext۰reflect۰rtype۰Out(fr *frame, args []value) value {
 	return makeReflectType(rtype{args[0].(rtype).t.Underlying().(*types.Signature).Results().At(i).Type()})
 }
 
+func ext۰reflect۰rtype۰PkgPath(fr *frame, args []value) value {
+	// Signature: func (t reflect.rtype) string
+	if t, ok := types.Unalias(args[0].(rtype).t).(*types.Named); ok && t.Obj().Pkg() != nil {
+		return t.Obj().Pkg().Path()
+	}
+	return ""
+}
+
 func ext۰reflect۰rtype۰Size(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) uintptr
 	return uintptr(fr.i.sizes.Sizeof(args[0].(rtype).t))
@@ -145,24 +481,133 @@ func ext۰reflect۰rtype۰Size(fr *frame, args []value) value {
 
 func ext۰reflect۰rtype۰String(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) string
-	return args[0].(rtype).t.String()
+	return typeString(args[0].(rtype).t)
+}
+
+func ext۰reflect۰Append(fr *frame, args []value) value {
+	// Signature: func (s reflect.Value, x ...reflect.Value) reflect.Value
+	s := args[0]
+	mustBe(s, "Append", reflect.Slice)
+	t := rV2T(s).t
+	elem := t.Underlying().(*types.Slice).Elem()
+	res := rV2V(s).([]value)
+	for _, x := range args[1].([]value) {
+		res = append(res, copyValue(elem, assignTo(x, elem, "reflect.Append")))
+	}
+	return makeReflectValue(t, res)
+}
+
+func ext۰reflect۰AppendSlice(fr *frame, args []value) value {
+	// Signature: func (s, t reflect.Value) reflect.Value
+	s, x := args[0], args[1]
+	mustBe(s, "AppendSlice", reflect.Slice)
+	mustBe(x, "AppendSlice", reflect.Slice)
+	t := rV2T(s).t
+	elem := t.Underlying().(*types.Slice).Elem()
+	if xelem := rV2T(x).t.Underlying().(*types.Slice).Elem(); !types.Identical(elem, xelem) {
+		panic(reflectError(fmt.Sprintf("reflect.AppendSlice: %s != %s", typeString(elem), typeString(xelem))))
+	}
+	res := rV2V(s).([]value)
+	for _, v := range rV2V(x).([]value) {
+		res = append(res, copyValue(elem, v))
+	}
+	return makeReflectValue(t, res)
+}
+
+func ext۰reflect۰MakeFunc(fr *frame, args []value) value {
+	// Signature: func (typ reflect.Type, fn func([]reflect.Value) []reflect.Value) reflect.Value
+	t := rT2T(args[0])
+	sig, ok := t.Underlying().(*types.Signature)
+	if !ok {
+		panic(reflectError("reflect: call of MakeFunc with non-Func type"))
+	}
+	i, fn := fr.i, args[1]
+	return makeReflectValue(t, i.makeNativeClosure(func(caller *frame, args []value) value {
+		in := make([]value, len(args))
+		for k, arg := range args {
+			in[k] = makeReflectValue(sig.Params().At(k).Type(), arg)
+		}
+		out := call(i, caller, token.NoPos, fn, []value{in}).([]value)
+		results := sig.Results()
+		if len(out) != results.Len() {
+			panic(reflectError("reflect: wrong return count from function created by MakeFunc"))
+		}
+		res := make(tuple, len(out))
+		for k, v := range out {
+			res[k] = assignTo(v, results.At(k).Type(), "reflect.MakeFunc")
+		}
+		switch len(res) {
+		case 0:
+			return nil
+		case 1:
+			return res[0]
+		}
+		return res
+	}))
+}
+
+func ext۰reflect۰MakeMap(fr *frame, args []value) value {
+	// Signature: func (typ reflect.Type) reflect.Value
+	t := rT2T(args[0])
+	mt, ok := t.Underlying().(*types.Map)
+	if !ok {
+		panic(reflectError("reflect.MakeMap of non-map type"))
+	}
+	return makeReflectValue(t, makeMap(mt.Key(), 0))
+}
+
+func ext۰reflect۰MakeSlice(fr *frame, args []value) value {
+	// Signature: func (typ reflect.Type, len, cap int) reflect.Value
+	t := rT2T(args[0])
+	st, ok := t.Underlying().(*types.Slice)
+	if !ok {
+		panic(reflectError("reflect.MakeSlice of non-slice type"))
+	}
+	n, m := args[1].(int), args[2].(int)
+	switch {
+	case n < 0:
+		panic(reflectError("reflect.MakeSlice: negative len"))
+	case m < 0:
+		panic(reflectError("reflect.MakeSlice: negative cap"))
+	case n > m:
+		panic(reflectError("reflect.MakeSlice: len > cap"))
+	}
+	slice := make([]value, m)
+	for i := range slice {
+		slice[i] = zero(st.Elem())
+	}
+	return makeReflectValue(t, slice[:n])
+}
+
+func ext۰reflect۰MapOf(fr *frame, args []value) value {
+	// Signature: func (key, elem reflect.Type) reflect.Type
+	return makeReflectType(rtype{types.NewMap(rT2T(args[0]), rT2T(args[1]))})
 }
 
 func ext۰reflect۰New(fr *frame, args []value) value {
 	// Signature: func (t reflect.Type) reflect.Value
-	t := args[0].(iface).v.(rtype).t
+	t := rT2T(args[0])
 	alloc := zero(t)
 	return makeReflectValue(types.NewPointer(t), &alloc)
 }
 
+func ext۰reflect۰PointerTo(fr *frame, args []value) value {
+	// Signature: func (t reflect.Type) reflect.Type
+	return makeReflectType(rtype{types.NewPointer(rT2T(args[0]))})
+}
+
 func ext۰reflect۰SliceOf(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) Type
-	return makeReflectType(rtype{types.NewSlice(args[0].(iface).v.(rtype).t)})
+	return makeReflectType(rtype{types.NewSlice(rT2T(args[0]))})
 }
 
 func ext۰reflect۰TypeOf(fr *frame, args []value) value {
 	// Signature: func (t reflect.rtype) Type
-	return makeReflectType(rtype{args[0].(iface).t})
+	t := args[0].(iface).t
+	if t == nil {
+		return iface{} // nil reflect.Type
+	}
+	return makeReflectType(rtype{t})
 }
 
 func ext۰reflect۰ValueOf(fr *frame, args []value) value {
@@ -173,12 +618,14 @@ func ext۰reflect۰ValueOf(fr *frame, args []value) value {
 
 func ext۰reflect۰Zero(fr *frame, args []value) value {
 	// Signature: func (t reflect.Type) reflect.Value
-	t := args[0].(iface).v.(rtype).t
+	t := rT2T(args[0])
 	return makeReflectValue(t, zero(t))
 }
 
 func reflectKind(t types.Type) reflect.Kind {
 	switch t := t.(type) {
+	case nil:
+		return reflect.Invalid
 	case *types.Named, *types.Alias:
 		return reflectKind(t.Underlying())
 	case *types.Basic:
@@ -240,6 +687,173 @@ func reflectKind(t types.Type) reflect.Kind {
 	panic(fmt.Sprint("unexpected type: ", t))
 }
 
+func ext۰reflect۰Value۰Addr(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value) reflect.Value
+	v := args[0]
+	addr := rV2Addr(v)
+	if addr == nil {
+		panic(reflectError("reflect.Value.Addr of unaddressable value"))
+	}
+	return newReflectValue(types.NewPointer(rV2T(v).t), addr, nil, rV2RO(v))
+}
+
+func ext۰reflect۰Value۰Bytes(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value) []byte
+	v := args[0]
+	mustBe(v, "Bytes", reflect.Slice)
+	if reflectKind(rV2T(v).t.Underlying().(*types.Slice).Elem()) != reflect.Uint8 {
+		panic(reflectError("reflect.Value.Bytes of non-byte slice"))
+	}
+	return rV2V(v)
+}
+
+func ext۰reflect۰Value۰Call(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, in []reflect.Value) []reflect.Value
+	return reflectCall(fr, "Call", args[0], args[1].([]value))
+}
+
+func ext۰reflect۰Value۰CallSlice(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, in []reflect.Value) []reflect.Value
+	return reflectCall(fr, "CallSlice", args[0], args[1].([]value))
+}
+
+// reflectCall implements the Call and CallSlice methods of reflect.Value.
+func reflectCall(fr *frame, op string, v value, in []value) []value {
+	mustBe(v, op, reflect.Func)
+	if rV2RO(v) != 0 {
+		panic(reflectError("reflect: reflect.Value." + op + " using value obtained using unexported field"))
+	}
+	sig := rV2T(v).t.Underlying().(*types.Signature)
+	params := sig.Params()
+	n := params.Len()
+	arg := func(x value, t types.Type) value {
+		xt := rV2T(x).t
+		if xt == nil {
+			panic(reflectError("reflect: " + op + " using zero Value argument"))
+		}
+		if !types.AssignableTo(xt, t) {
+			panic(reflectError("reflect: " + op + " using " + typeString(xt) + " as type " + typeString(t)))
+		}
+		return assignTo(x, t, "reflect.Value."+op)
+	}
+	var args []value
+	if sig.Variadic() && op == "Call" {
+		if len(in) < n-1 {
+			panic(reflectError("reflect: Call with too few input arguments"))
+		}
+		elem := params.At(n - 1).Type().Underlying().(*types.Slice).Elem()
+		var rest []value
+		for _, x := range in[n-1:] {
+			rest = append(rest, arg(x, elem))
+		}
+		for k, x := range in[:n-1] {
+			args = append(args, arg(x, params.At(k).Type()))
+		}
+		args = append(args, rest)
+	} else if len(in) < n {
+		panic(reflectError("reflect: " + op + " with too few input arguments"))
+	} else if len(in) > n {
+		panic(reflectError("reflect: " + op + " with too many input arguments"))
+	} else {
+		for k, x := range in {
+			args = append(args, arg(x, params.At(k).Type()))
+		}
+	}
+	return reflectResults(sig, func() value { return call(fr.i, fr, token.NoPos, rV2V(v), args) })
+}
+
+// reflectResults returns the reflect.Values of the results of calling f,
+// a function of type sig.
+func reflectResults(sig *types.Signature, f func() value) []value {
+	res := f()
+	results := sig.Results()
+	out := make([]value, results.Len())
+	for k := range out {
+		var v value
+		if len(out) == 1 {
+			v = res
+		} else {
+			v = res.(tuple)[k]
+		}
+		out[k] = makeReflectValue(results.At(k).Type(), v)
+	}
+	return out
+}
+
+func ext۰reflect۰Value۰Bool(fr *frame, args []value) value {
+	// Signature: func (reflect.Value) bool
+	return rV2V(args[0]).(bool)
+}
+
+func ext۰reflect۰Value۰CanAddr(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value) bool
+	return rV2Addr(args[0]) != nil
+}
+
+func ext۰reflect۰Value۰CanInterface(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value) bool
+	if rV2T(args[0]).t == nil {
+		panic(reflectError("reflect: call of reflect.Value.CanInterface on zero Value"))
+	}
+	return rV2RO(args[0]) == 0
+}
+
+func ext۰reflect۰Value۰CanSet(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value) bool
+	return rV2Addr(args[0]) != nil && rV2RO(args[0]) == 0
+}
+
+func ext۰reflect۰Value۰Cap(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value) int
+	switch v := rV2V(args[0]).(type) {
+	case array:
+		return len(v)
+	case []value:
+		return cap(v)
+	case chan value:
+		if s := fr.i.sched; s != nil {
+			return s.channel(v).cap
+		}
+		return cap(v)
+	default:
+		panic(reflectError(fmt.Sprintf("reflect.(Value).Cap(%T)", v)))
+	}
+}
+
+func ext۰reflect۰Value۰Complex(fr *frame, args []value) value {
+	// Signature: func (reflect.Value) complex128
+	switch v := rV2V(args[0]).(type) {
+	case complex64:
+		return complex128(v)
+	case complex128:
+		return v
+	}
+	panic(reflectError("reflect.Value.Complex"))
+}
+
+func ext۰reflect۰Value۰Convert(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, t reflect.Type) reflect.Value
+	x, t := args[0], rT2T(args[1])
+	xt := rV2T(x).t
+	if xt == nil || !types.ConvertibleTo(xt, t) {
+		panic(reflectError(fmt.Sprintf("reflect.Value.Convert: value of type %s cannot be converted to type %s", typeString(xt), typeString(t))))
+	}
+	v := rV2V(x)
+	_, xptr := xt.Underlying().(*types.Pointer)
+	_, ptr := t.Underlying().(*types.Pointer)
+	switch {
+	case types.IsInterface(t):
+		if !types.IsInterface(xt) {
+			v = iface{xt, v}
+		}
+	case types.Identical(xt.Underlying(), t.Underlying()), xptr && ptr:
+		// The representation is unchanged.
+	default:
+		v = conv(t, xt, v)
+	}
+	return newReflectValue(t, v, nil, rV2RO(x).sticky())
+}
+
 func ext۰reflect۰Value۰Kind(fr *frame, args []value) value {
 	// Signature: func (reflect.Value) uint
 	return uint(reflectKind(rV2T(args[0]).t))
@@ -247,12 +861,23 @@ func ext۰reflect۰Value۰Kind(fr *frame, args []value) value {
 
 func ext۰reflect۰Value۰String(fr *frame, args []value) value {
 	// Signature: func (reflect.Value) string
-	return toString(rV2V(args[0]))
+	switch t := rV2T(args[0]).t; reflectKind(t) {
+	case reflect.Invalid:
+		return "<invalid Value>"
+	case reflect.String:
+		return rV2V(args[0])
+	default:
+		return "<" + typeString(t) + " Value>"
+	}
 }
 
 func ext۰reflect۰Value۰Type(fr *frame, args []value) value {
 	// Signature: func (reflect.Value) reflect.Type
-	return makeReflectType(rV2T(args[0]))
+	rt := rV2T(args[0])
+	if rt.t == nil {
+		panic(reflectError("reflect: call of reflect.Value.Type on zero Value"))
+	}
+	return makeReflectType(rt)
 }
 
 func ext۰reflect۰Value۰Uint(fr *frame, args []value) value {
@@ -271,7 +896,7 @@ func ext۰reflect۰Value۰Uint(fr *frame, args []value) value {
 	case uintptr:
 		return uint64(v)
 	}
-	panic("reflect.Value.Uint")
+	panic(reflectError("reflect.Value.Uint"))
 }
 
 func ext۰reflect۰Value۰Len(fr *frame, args []value) value {
@@ -282,7 +907,10 @@ func ext۰reflect۰Value۰Len(fr *frame, args []value) value {
 	case array:
 		return len(v)
 	case chan value:
-		return cap(v)
+		if s := fr.i.sched; s != nil {
+			return len(s.channel(v).buf)
+		}
+		return len(v)
 	case []value:
 		return len(v)
 	case *hashmap:
@@ -290,23 +918,46 @@ func ext۰reflect۰Value۰Len(fr *frame, args []value) value {
 	case map[value]value:
 		return len(v)
 	default:
-		panic(fmt.Sprintf("reflect.(Value).Len(%v)", v))
+		panic(reflectError(fmt.Sprintf("reflect.(Value).Len(%v)", v)))
 	}
 }
 
+// mapEntries returns the keys and elements of map m.
+func mapEntries(m value) (keys, elems []value) {
+	switch m := m.(type) {
+	case map[value]value:
+		for k, e := range m {
+			keys = append(keys, k)
+			elems = append(elems, e)
+		}
+
+	case *hashmap:
+		for _, e := range m.entries() {
+			for ; e != nil; e = e.next {
+				keys = append(keys, e.key)
+				elems = append(elems, e.value)
+			}
+		}
+
+	default:
+		panic(fmt.Sprintf("illegal map type: %T", m))
+	}
+	return keys, elems
+}
+
 func ext۰reflect۰Value۰MapIndex(fr *frame, args []value) value {
 	// Signature: func (reflect.Value) Value
-	tValue := rV2T(args[0]).t.Underlying().(*types.Map).Key()
-	k := rV2V(args[1])
+	mt := rV2T(args[0]).t.Underlying().(*types.Map)
+	k := assignTo(args[1], mt.Key(), "reflect.Value.MapIndex")
 	switch m := rV2V(args[0]).(type) {
 	case map[value]value:
 		if v, ok := m[k]; ok {
-			return makeReflectValue(tValue, v)
+			return makeReflectValue(mt.Elem(), v)
 		}
 
 	case *hashmap:
 		if v := m.lookup(k.(hashable)); v != nil {
-			return makeReflectValue(tValue, v)
+			return makeReflectValue(mt.Elem(), v)
 		}
 
 	default:
@@ -317,25 +968,124 @@ func ext۰reflect۰Value۰MapIndex(fr *frame, args []value) value {
 
 func ext۰reflect۰Value۰MapKeys(fr *frame, args []value) value {
 	// Signature: func (reflect.Value) []Value
-	var keys []value
 	tKey := rV2T(args[0]).t.Underlying().(*types.Map).Key()
-	switch v := rV2V(args[0]).(type) {
-	case map[value]value:
-		for k := range v {
-			keys = append(keys, makeReflectValue(tKey, k))
-		}
+	keys, _ := mapEntries(rV2V(args[0]))
+	for i, k := range keys {
+		keys[i] = makeReflectValue(tKey, k)
+	}
+	return keys
+}
 
-	case *hashmap:
-		for _, e := range v.entries() {
-			for ; e != nil; e = e.next {
-				keys = append(keys, makeReflectValue(tKey, e.key))
-			}
+// A reflectMapIter is the state of a reflect.MapIter.
+// The interpreter's reflect.MapIter is a struct whose only field
+// points to it.
+type reflectMapIter struct {
+	m           value   // the reflect.Value of the map
+	keys, elems []value // the entries of the map, once started
+	started     bool
+	i           int // the index of the current entry
+}
+
+func ext۰reflect۰Value۰MapRange(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value) *reflect.MapIter
+	mustBe(args[0], "MapRange", reflect.Map)
+	var it value = structure{&reflectMapIter{m: args[0]}}
+	return &it
+}
+
+// mapIterOf returns the state of the *reflect.MapIter v.
+func mapIterOf(v value, method string) *reflectMapIter {
+	it, _ := (*v.(*value)).(structure)[0].(*reflectMapIter)
+	if it == nil || rV2T(it.m).t == nil {
+		panic(reflectError("MapIter." + method + " called on an iterator that does not have an associated map Value"))
+	}
+	return it
+}
+
+// current returns the index of the current entry of the iterator.
+func (it *reflectMapIter) current(method string) int {
+	if !it.started {
+		panic(reflectError("MapIter." + method + " called before Next"))
+	}
+	if it.i >= len(it.keys) {
+		panic(reflectError("MapIter." + method + " called on exhausted iterator"))
+	}
+	return it.i
+}
+
+func ext۰reflect۰MapIter۰Key(fr *frame, args []value) value {
+	// Signature: func (it *reflect.MapIter) reflect.Value
+	it := mapIterOf(args[0], "Key")
+	return makeReflectValue(rV2T(it.m).t.Underlying().(*types.Map).Key(), it.keys[it.current("Key")])
+}
+
+func ext۰reflect۰MapIter۰Next(fr *frame, args []value) value {
+	// Signature: func (it *reflect.MapIter) bool
+	it := mapIterOf(args[0], "Next")
+	if !it.started {
+		it.keys, it.elems = mapEntries(rV2V(it.m))
+		it.started = true
+	} else if it.i >= len(it.keys) {
+		panic(reflectError("MapIter.Next called on exhausted iterator"))
+	} else {
+		it.i++
+	}
+	return it.i < len(it.keys)
+}
+
+func ext۰reflect۰MapIter۰Reset(fr *frame, args []value) value {
+	// Signature: func (it *reflect.MapIter, v reflect.Value)
+	if rV2T(args[1]).t != nil {
+		mustBe(args[1], "MapIter.Reset", reflect.Map)
+	}
+	(*args[0].(*value)).(structure)[0] = &reflectMapIter{m: args[1]}
+	return nil
+}
+
+func ext۰reflect۰MapIter۰Value(fr *frame, args []value) value {
+	// Signature: func (it *reflect.MapIter) reflect.Value
+	it := mapIterOf(args[0], "Value")
+	return makeReflectValue(rV2T(it.m).t.Underlying().(*types.Map).Elem(), it.elems[it.current("Value")])
+}
+
+func ext۰reflect۰Value۰Method(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, i int) reflect.Value
+	v := args[0]
+	i := args[1].(int)
+	methods := reflectMethods(fr.i, rV2T(v).t)
+	if i < 0 || i >= len(methods) {
+		panic(reflectError("reflect: Method index out of range"))
+	}
+	return makeMethodValue(fr.i, v, methods[i])
+}
+
+func ext۰reflect۰Value۰MethodByName(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, name string) reflect.Value
+	v := args[0]
+	for _, sel := range reflectMethods(fr.i, rV2T(v).t) {
+		if sel.Obj().Name() == args[1].(string) {
+			return makeMethodValue(fr.i, v, sel)
 		}
+	}
+	return makeReflectValue(nil, nil)
+}
 
-	default:
-		panic(fmt.Sprintf("(reflect.Value).MapKeys(%T)", v))
+// makeMethodValue returns the reflect.Value of the method value v.m,
+// where sel selects method m.
+func makeMethodValue(i *interpreter, v value, sel *types.Selection) value {
+	var fn, recv value
+	if types.IsInterface(rV2T(v).t) {
+		itf := rV2V(v).(iface)
+		if itf.t == nil {
+			panic(reflectError("reflect: Method on nil interface value"))
+		}
+		fn, recv = lookupMethod(i, itf.t, sel.Obj().(*types.Func)), itf.v
+	} else {
+		fn, recv = i.prog.MethodValue(sel), rV2V(v)
 	}
-	return keys
+	return newReflectValue(funcType(sel.Type().(*types.Signature)), i.makeNativeClosure(func(caller *frame, args []value) value {
+		return call(i, caller, token.NoPos, fn, append([]value{recv}, args...))
+	}), nil, rV2RO(v).sticky())
 }
 
 func ext۰reflect۰Value۰NumField(fr *frame, args []value) value {
@@ -345,7 +1095,7 @@ func ext۰reflect۰Value۰NumField(fr *frame, args []value) value {
 
 func ext۰reflect۰Value۰NumMethod(fr *frame, args []value) value {
 	// Signature: func (reflect.Value) int
-	return fr.i.prog.MethodSets.MethodSet(rV2T(args[0]).t).Len()
+	return len(reflectMethods(fr.i, rV2T(args[0]).t))
 }
 
 func ext۰reflect۰Value۰Pointer(fr *frame, args []value) value {
@@ -365,55 +1115,59 @@ func ext۰reflect۰Value۰Pointer(fr *frame, args []value) value {
 		return uintptr(unsafe.Pointer(v))
 	case *closure:
 		return uintptr(unsafe.Pointer(v))
+	case unsafe.Pointer:
+		return uintptr(v)
 	default:
-		panic(fmt.Sprintf("reflect.(Value).Pointer(%T)", v))
+		panic(reflectError(fmt.Sprintf("reflect.(Value).Pointer(%T)", v)))
 	}
 }
 
 func ext۰reflect۰Value۰Index(fr *frame, args []value) value {
 	// Signature: func (v reflect.Value, i int) Value
+	v := args[0]
 	i := args[1].(int)
-	t := rV2T(args[0]).t.Underlying()
-	switch v := rV2V(args[0]).(type) {
-	case array:
-		return makeReflectValue(t.(*types.Array).Elem(), v[i])
-	case []value:
-		return makeReflectValue(t.(*types.Slice).Elem(), v[i])
-	default:
-		panic(fmt.Sprintf("reflect.(Value).Index(%T)", v))
+	t := rV2T(v).t.Underlying()
+	switch t := t.(type) {
+	case *types.Array:
+		if i < 0 || int64(i) >= t.Len() {
+			panic(reflectError("reflect: array index out of range"))
+		}
+		if addr := rV2Addr(v); addr != nil {
+			return newReflectValue(t.Elem(), nil, &(*addr).(array)[i], rV2RO(v).sticky())
+		}
+		return newReflectValue(t.Elem(), rV2V(v).(array)[i], nil, rV2RO(v).sticky())
+	case *types.Slice:
+		s := rV2V(v).([]value)
+		if i < 0 || i >= len(s) {
+			panic(reflectError("reflect: slice index out of range"))
+		}
+		// Slice elements are always addressable.
+		return newReflectValue(t.Elem(), nil, &s[i], rV2RO(v).sticky())
+	case *types.Basic:
+		if t.Info()&types.IsString != 0 {
+			s := rV2V(v).(string)
+			if i < 0 || i >= len(s) {
+				panic(reflectError("reflect: string index out of range"))
+			}
+			return newReflectValue(types.Typ[types.Uint8], s[i], nil, rV2RO(v).sticky())
+		}
 	}
-}
-
-func ext۰reflect۰Value۰Bool(fr *frame, args []value) value {
-	// Signature: func (reflect.Value) bool
-	return rV2V(args[0]).(bool)
-}
-
-func ext۰reflect۰Value۰CanAddr(fr *frame, args []value) value {
-	// Signature: func (v reflect.Value) bool
-	// Always false for our representation.
-	return false
-}
-
-func ext۰reflect۰Value۰CanInterface(fr *frame, args []value) value {
-	// Signature: func (v reflect.Value) bool
-	// Always true for our representation.
-	return true
+	panic(reflectError(fmt.Sprintf("reflect: call of reflect.Value.Index on %s Value", kindString(reflectKind(t)))))
 }
 
 func ext۰reflect۰Value۰Elem(fr *frame, args []value) value {
 	// Signature: func (v reflect.Value) reflect.Value
-	switch x := rV2V(args[0]).(type) {
+	v := args[0]
+	switch x := rV2V(v).(type) {
 	case iface:
-		return makeReflectValue(x.t, x.v)
+		return newReflectValue(x.t, x.v, nil, rV2RO(v).sticky())
 	case *value:
-		var v value
-		if x != nil {
-			v = *x
+		if x == nil {
+			return makeReflectValue(nil, nil)
 		}
-		return makeReflectValue(rV2T(args[0]).t.Underlying().(*types.Pointer).Elem(), v)
+		return newReflectValue(rV2T(v).t.Underlying().(*types.Pointer).Elem(), nil, x, rV2RO(v).sticky())
 	default:
-		panic(fmt.Sprintf("reflect.(Value).Elem(%T)", x))
+		panic(reflectError(fmt.Sprintf("reflect.(Value).Elem(%T)", x)))
 	}
 }
 
@@ -421,7 +1175,40 @@ func ext۰reflect۰Value۰Field(fr *frame, args []value) value {
 	// Signature: func (v reflect.Value, i int) reflect.Value
 	v := args[0]
 	i := args[1].(int)
-	return makeReflectValue(rV2T(v).t.Underlying().(*types.Struct).Field(i).Type(), rV2V(v).(structure)[i])
+	mustBe(v, "Field", reflect.Struct)
+	f := rV2T(v).t.Underlying().(*types.Struct).Field(i)
+	ro := rV2RO(v) & stickyRO
+	if !f.Exported() {
+		if f.Embedded() {
+			ro |= embedRO
+		} else {
+			ro |= stickyRO
+		}
+	}
+	if addr := rV2Addr(v); addr != nil {
+		return newReflectValue(f.Type(), nil, &(*addr).(structure)[i], ro)
+	}
+	return newReflectValue(f.Type(), rV2V(v).(structure)[i], nil, ro)
+}
+
+func ext۰reflect۰Value۰FieldByName(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, name string) reflect.Value
+	v := args[0]
+	mustBe(v, "FieldByName", reflect.Struct)
+	index := fieldByName(rV2T(v).t, args[1].(string))
+	if index == nil {
+		return makeReflectValue(nil, nil)
+	}
+	for j, k := range index {
+		if j > 0 && reflectKind(rV2T(v).t) == reflect.Pointer {
+			if rV2V(v).(*value) == nil {
+				panic(reflectError("reflect: indirection through nil pointer to embedded struct"))
+			}
+			v = ext۰reflect۰Value۰Elem(fr, []value{v})
+		}
+		v = ext۰reflect۰Value۰Field(fr, []value{v, k})
+	}
+	return v
 }
 
 func ext۰reflect۰Value۰Float(fr *frame, args []value) value {
@@ -432,12 +1219,12 @@ func ext۰reflect۰Value۰Float(fr *frame, args []value) value {
 	case float64:
 		return float64(v)
 	}
-	panic("reflect.Value.Float")
+	panic(reflectError("reflect.Value.Float"))
 }
 
 func ext۰reflect۰Value۰Interface(fr *frame, args []value) value {
 	// Signature: func (v reflect.Value) interface{}
-	return ext۰reflect۰valueInterface(fr, args)
+	return valueInterface(args[0], true)
 }
 
 func ext۰reflect۰Value۰Int(fr *frame, args []value) value {
@@ -454,13 +1241,14 @@ func ext۰reflect۰Value۰Int(fr *frame, args []value) value {
 	case int64:
 		return x
 	default:
-		panic(fmt.Sprintf("reflect.(Value).Int(%T)", x))
+		panic(reflectError(fmt.Sprintf("reflect.(Value).Int(%T)", x)))
 	}
 }
 
-func ext۰reflect۰Value۰IsNil(fr *frame, args []value) value {
-	// Signature: func (reflect.Value) bool
-	switch x := rV2V(args[0]).(type) {
+// isNil reports whether x, a value of a pointer, channel, map, slice,
+// function or interface type, is nil.
+func isNil(x value) bool {
+	switch x := x.(type) {
 	case *value:
 		return x == nil
 	case chan value:
@@ -479,39 +1267,219 @@ func ext۰reflect۰Value۰IsNil(fr *frame, args []value) value {
 		return x == nil
 	case *closure:
 		return x == nil
+	case unsafe.Pointer:
+		return x == nil
 	default:
-		panic(fmt.Sprintf("reflect.(Value).IsNil(%T)", x))
+		panic(reflectError(fmt.Sprintf("reflect.(Value).IsNil(%T)", x)))
 	}
 }
 
+// isZero reports whether v is the zero value of type t.
+func isZero(t types.Type, v value) bool {
+	switch t := t.Underlying().(type) {
+	case *types.Basic:
+		return v == zero(t)
+	case *types.Array:
+		for _, x := range v.(array) {
+			if !isZero(t.Elem(), x) {
+				return false
+			}
+		}
+		return true
+	case *types.Struct:
+		for i, x := range v.(structure) {
+			if !isZero(t.Field(i).Type(), x) {
+				return false
+			}
+		}
+		return true
+	default:
+		return isNil(v)
+	}
+}
+
+func ext۰reflect۰Value۰IsNil(fr *frame, args []value) value {
+	// Signature: func (reflect.Value) bool
+	return isNil(rV2V(args[0]))
+}
+
 func ext۰reflect۰Value۰IsValid(fr *frame, args []value) value {
 	// Signature: func (reflect.Value) bool
-	return rV2V(args[0]) != nil
+	return rV2T(args[0]).t != nil
+}
+
+func ext۰reflect۰Value۰IsZero(fr *frame, args []value) value {
+	// Signature: func (reflect.Value) bool
+	if rV2T(args[0]).t == nil {
+		panic(reflectError("reflect: call of reflect.Value.IsZero on zero Value"))
+	}
+	return isZero(rV2T(args[0]).t, rV2V(args[0]))
 }
 
 func ext۰reflect۰Value۰Set(fr *frame, args []value) value {
-	// TODO(adonovan): implement.
+	// Signature: func (v reflect.Value, x reflect.Value)
+	v := args[0]
+	addr := mustBeAssignable(v, "Set")
+	t := rV2T(v).t
+	store(t, addr, assignTo(args[1], t, "reflect.Set"))
+	return nil
+}
+
+// setBasic implements the Set methods of reflect.Value for basic
+// types: it converts x, of type xt, to the type of v, whose kind must
+// be in the range [lo, hi], and stores it in v.
+func setBasic(v value, method string, lo, hi reflect.Kind, x value, xt types.Type) {
+	addr := mustBeAssignable(v, method)
+	t := rV2T(v).t
+	if k := reflectKind(t); k < lo || k > hi {
+		panic(reflectError(fmt.Sprintf("reflect: call of reflect.Value.%s on %s Value", method, kindString(k))))
+	}
+	if lo != reflect.Bool {
+		x = conv(t, xt, x)
+	}
+	*addr = x
+}
+
+func ext۰reflect۰Value۰SetBool(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, x bool)
+	setBasic(args[0], "SetBool", reflect.Bool, reflect.Bool, args[1], types.Typ[types.Bool])
+	return nil
+}
+
+func ext۰reflect۰Value۰SetFloat(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, x float64)
+	setBasic(args[0], "SetFloat", reflect.Float32, reflect.Float64, args[1], types.Typ[types.Float64])
+	return nil
+}
+
+func ext۰reflect۰Value۰SetInt(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, x int64)
+	setBasic(args[0], "SetInt", reflect.Int, reflect.Int64, args[1], types.Typ[types.Int64])
 	return nil
 }
 
+func ext۰reflect۰Value۰SetString(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, x string)
+	setBasic(args[0], "SetString", reflect.String, reflect.String, args[1], types.Typ[types.String])
+	return nil
+}
+
+func ext۰reflect۰Value۰SetUint(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, x uint64)
+	setBasic(args[0], "SetUint", reflect.Uint, reflect.Uintptr, args[1], types.Typ[types.Uint64])
+	return nil
+}
+
+func ext۰reflect۰Value۰SetLen(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, n int)
+	v := args[0]
+	addr := mustBeAssignable(v, "SetLen")
+	mustBe(v, "SetLen", reflect.Slice)
+	s := (*addr).([]value)
+	n := args[1].(int)
+	if n < 0 || n > cap(s) {
+		panic(reflectError("reflect: slice length out of range in SetLen"))
+	}
+	*addr = s[:n]
+	return nil
+}
+
+func ext۰reflect۰Value۰SetMapIndex(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, key, elem reflect.Value)
+	v := args[0]
+	mustBe(v, "SetMapIndex", reflect.Map)
+	if rV2RO(v) != 0 {
+		panic(reflectError("reflect: reflect.Value.SetMapIndex using value obtained using unexported field"))
+	}
+	mt := rV2T(v).t.Underlying().(*types.Map)
+	k := assignTo(args[1], mt.Key(), "reflect.Value.SetMapIndex")
+	m := rV2V(v)
+	if rV2T(args[2]).t == nil {
+		switch m := m.(type) {
+		case map[value]value:
+			delete(m, k)
+		case *hashmap:
+			m.delete(k.(hashable))
+		}
+		return nil
+	}
+	e := assignTo(args[2], mt.Elem(), "reflect.Value.SetMapIndex")
+	switch m := m.(type) {
+	case map[value]value:
+		m[k] = e
+	case *hashmap:
+		if m == nil {
+			panic("assignment to entry in nil map")
+		}
+		m.insert(k.(hashable), e)
+	}
+	return nil
+}
+
+func ext۰reflect۰Value۰Slice(fr *frame, args []value) value {
+	// Signature: func (v reflect.Value, i, j int) reflect.Value
+	v := args[0]
+	i, j := args[1].(int), args[2].(int)
+	t := rV2T(v).t
+	var s []value
+	switch ut := t.Underlying().(type) {
+	case *types.Array:
+		addr := rV2Addr(v)
+		if addr == nil {
+			panic(reflectError("reflect.Value.Slice: slice of unaddressable array"))
+		}
+		s, t = (*addr).(array), types.NewSlice(ut.Elem())
+	case *types.Slice:
+		s = rV2V(v).([]value)
+	case *types.Basic:
+		if ut.Info()&types.IsString != 0 {
+			str := rV2V(v).(string)
+			if i < 0 || j < i || j > len(str) {
+				panic(reflectError("reflect.Value.Slice: string slice index out of bounds"))
+			}
+			return newReflectValue(t, str[i:j], nil, rV2RO(v).sticky())
+		}
+		mustBe(v, "Slice", reflect.Array, reflect.Slice, reflect.String)
+	default:
+		mustBe(v, "Slice", reflect.Array, reflect.Slice, reflect.String)
+	}
+	if i < 0 || j < i || j > cap(s) {
+		panic(reflectError("reflect.Value.Slice: slice index out of bounds"))
+	}
+	return newReflectValue(t, s[i:j], nil, rV2RO(v).sticky())
+}
+
+func valueInterface(v value, safe bool) value {
+	t := rV2T(v).t
+	if t == nil {
+		panic(reflectError("reflect: call of reflect.Value.Interface on zero Value"))
+	}
+	if safe && rV2RO(v) != 0 {
+		panic(reflectError("reflect.Value.Interface: cannot return value obtained from unexported field or method"))
+	}
+	x := rV2V(v)
+	if types.IsInterface(t) {
+		return x // already an interface value
+	}
+	return iface{t, x}
+}
+
 func ext۰reflect۰valueInterface(fr *frame, args []value) value {
 	// Signature: func (v reflect.Value, safe bool) interface{}
-	v := args[0].(structure)
-	return iface{rV2T(v).t, rV2V(v)}
+	return valueInterface(args[0], args[1].(bool))
 }
 
 func ext۰reflect۰error۰Error(fr *frame, args []value) value {
 	return args[0]
 }
 
-// newMethod creates a new method of the specified name, package and receiver type.
-func newMethod(pkg *ssa.Package, recvType types.Type, name string) *ssa.Function {
-	// TODO(adonovan): fix: hack: currently the only part of Signature
-	// that is needed is the "pointerness" of Recv.Type, and for
-	// now, we'll set it to always be false since we're only
-	// concerned with rtype.  Encapsulate this better.
-	sig := types.NewSignatureType(types.NewParam(token.NoPos, nil, "recv", recvType), nil, nil, nil, nil, false)
-	fn := pkg.Prog.NewFunction(name, sig, "fake reflect method")
+// newMethod creates a new method of package pkg and the specified
+// receiver type that implements the interface method m.
+func newMethod(pkg *ssa.Package, recvType types.Type, m *types.Func) *ssa.Function {
+	msig := m.Type().(*types.Signature)
+	recv := types.NewParam(token.NoPos, nil, "recv", recvType)
+	sig := types.NewSignatureType(recv, nil, nil, msig.Params(), msig.Results(), msig.Variadic())
+	fn := pkg.Prog.NewFunction(m.Name(), sig, "fake reflect method")
 	fn.Pkg = pkg
 	return fn
 }
@@ -523,51 +1491,93 @@ func initReflect(i *interpreter) {
 		Members: make(map[string]ssa.Member),
 	}
 
-	// Clobber the type-checker's notion of reflect.Value's
-	// underlying type so that it more closely matches the fake one
-	// (at least in the number of fields---we lie about the type of
-	// the rtype field).
+	// Clobber the type-checker's notion of the underlying types of
+	// reflect.Value and reflect.MapIter so that they match the
+	// interpreter's representation in the number of fields (though
+	// not in their types, which are all interface{}).
 	//
 	// We must ensure that calls to (ssa.Value).Type() return the
 	// fake type so that correct "shape" is used when allocating
 	// variables, making zero values, loading, and storing.
 	//
-	// TODO(adonovan): obviously this is a hack.  We need a cleaner
-	// way to fake the reflect package (almost---DeepEqual is fine).
-	// One approach would be not to even load its source code, but
-	// provide fake source files.  This would guarantee that no bad
-	// information leaks into other packages.
+	// The fields of both types are unexported and their methods are
+	// all implemented by the interpreter, so the clobbered types are
+	// observable only through unsafe, as by unsafe.Sizeof, and by the
+	// functions of package reflect that the interpreter does not
+	// implement, which must use a Value only through its methods, as
+	// DeepEqual does. This is a known limitation, not a planned fix:
+	// replacing package reflect wholesale with fake source files, as
+	// the interpreter's tests do, is not an option for real programs.
 	if r := i.prog.ImportedPackage("reflect"); r != nil {
-		rV := r.Pkg.Scope().Lookup("Value").Type().(*types.Named)
+		tEface := types.NewInterface(nil, nil).Complete()
+		for name, fields := range map[string][]string{
+			"Value":   {"t", "v", "addr", "ro"},
+			"MapIter": {"it"},
+		} {
+			obj := r.Pkg.Scope().Lookup(name)
+			if obj == nil {
+				continue
+			}
+			named := obj.Type().(*types.Named)
 
-		// delete bodies of the old methods
-		mset := i.prog.MethodSets.MethodSet(rV)
-		for j := 0; j < mset.Len(); j++ {
-			i.prog.MethodValue(mset.At(j)).Blocks = nil
-		}
+			// delete bodies of the old methods
+			for j := 0; j < named.NumMethods(); j++ {
+				i.prog.FuncValue(named.Method(j)).Blocks = nil
+			}
 
-		tEface := types.NewInterface(nil, nil).Complete()
-		rV.SetUnderlying(types.NewStruct([]*types.Var{
-			types.NewField(token.NoPos, r.Pkg, "t", tEface, false), // a lie
-			types.NewField(token.NoPos, r.Pkg, "v", tEface, false),
-		}, nil))
-	}
-
-	i.rtypeMethods = methodSet{
-		"Bits":      newMethod(i.reflectPackage, rtypeType, "Bits"),
-		"Elem":      newMethod(i.reflectPackage, rtypeType, "Elem"),
-		"Field":     newMethod(i.reflectPackage, rtypeType, "Field"),
-		"In":        newMethod(i.reflectPackage, rtypeType, "In"),
-		"Kind":      newMethod(i.reflectPackage, rtypeType, "Kind"),
-		"NumField":  newMethod(i.reflectPackage, rtypeType, "NumField"),
-		"NumIn":     newMethod(i.reflectPackage, rtypeType, "NumIn"),
-		"NumMethod": newMethod(i.reflectPackage, rtypeType, "NumMethod"),
-		"NumOut":    newMethod(i.reflectPackage, rtypeType, "NumOut"),
-		"Out":       newMethod(i.reflectPackage, rtypeType, "Out"),
-		"Size":      newMethod(i.reflectPackage, rtypeType, "Size"),
-		"String":    newMethod(i.reflectPackage, rtypeType, "String"),
+			vars := make([]*types.Var, len(fields))
+			for j, field := range fields {
+				vars[j] = types.NewField(token.NoPos, r.Pkg, field, tEface, false) // a lie
+			}
+			named.SetUnderlying(types.NewStruct(vars, nil))
+		}
 	}
+
+	// The code of function values implemented by the interpreter.
+	i.makeFuncStub = i.prog.NewFunction("makeFuncStub", new(types.Signature), "fake reflect function")
+	i.makeFuncStub.Pkg = i.reflectPackage
+
+	// The methods of the interpreter's implementations of reflect.Type
+	// and of the error values returned by reflect, with the signatures
+	// of the interface methods that they implement.
 	i.errorMethods = methodSet{
-		"Error": newMethod(i.reflectPackage, errorType, "Error"),
+		"Error": newMethod(i.reflectPackage, errorType, types.Universe.Lookup("error").Type().Underlying().(*types.Interface).Method(0)),
+	}
+	r := i.prog.ImportedPackage("reflect")
+	if r == nil {
+		return // no reflect.Type values
+	}
+	tType := r.Pkg.Scope().Lookup("Type").Type()
+	i.rtypeMethods = methodSet{}
+	for _, name := range []string{
+		"Align",
+		"AssignableTo",
+		"Bits",
+		"Comparable",
+		"ConvertibleTo",
+		"Elem",
+		"Field",
+		"FieldByName",
+		"Implements",
+		"In",
+		"IsVariadic",
+		"Key",
+		"Kind",
+		"Len",
+		"Method",
+		"MethodByName",
+		"Name",
+		"NumField",
+		"NumIn",
+		"NumMethod",
+		"NumOut",
+		"Out",
+		"PkgPath",
+		"Size",
+		"String",
+	} {
+		if m, _, _ := types.LookupFieldOrMethod(tType, false, nil, name); m != nil {
+			i.rtypeMethods[name] = newMethod(i.reflectPackage, rtypeType, m.(*types.Func))
+		}
 	}
 }
@@ -0,0 +1,640 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp
+
+// This file defines the deterministic scheduler, which runs the
+// goroutines of the target program one at a time.
+//
+// Each target goroutine runs on its own interpreter goroutine, but
+// only the one chosen by the scheduler runs; the others are parked,
+// waiting on their wake channel. Control passes from one goroutine to
+// another only at scheduling points: channel operations (send,
+// receive, select and close), go statements, calls to runtime.Gosched
+// and time.Sleep, and goroutine exit. At each scheduling point the
+// scheduler chooses among the goroutines that can proceed, and, for a
+// select statement, among its ready cases.
+//
+// Channels are modeled by the scheduler, not by Go channels: the
+// chan value of a target channel serves only as its identity, so that
+// the rest of the interpreter need not distinguish the two modes.
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"io"
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+)
+
+// A Schedule determines the choices made by the deterministic
+// scheduler of [InterpretSchedule] at each scheduling point that has
+// more than one alternative: which goroutine runs next, which ready
+// case of a select statement proceeds, and which blocked goroutine is
+// the partner of a channel operation.
+type Schedule struct {
+	// Choices are the choices made at the first scheduling points,
+	// each the index of an alternative. Alternative 0 is the default:
+	// the current goroutine if it can proceed and did not yield by
+	// calling runtime.Gosched or time.Sleep, otherwise the next one in
+	// order of creation that can; the first ready case; the partner
+	// that blocked first.
+	Choices []int
+
+	// Seed, if nonzero, seeds the pseudo-random choices made after
+	// Choices are exhausted. Otherwise the default alternative is
+	// chosen, so that each goroutine runs until it blocks.
+	Seed int64
+}
+
+// String returns the schedule in the form accepted by
+// [ParseSchedule]: the seed, followed by a colon and the
+// comma-separated choices, if any.
+func (sched *Schedule) String() string {
+	var buf strings.Builder
+	buf.WriteString(strconv.FormatInt(sched.Seed, 10))
+	for i, c := range sched.Choices {
+		if i == 0 {
+			buf.WriteByte(':')
+		} else {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(strconv.Itoa(c))
+	}
+	return buf.String()
+}
+
+// ParseSchedule parses a schedule in the form returned by
+// [Schedule.String], such as "42" or "0:1,0,2".
+func ParseSchedule(s string) (*Schedule, error) {
+	seed, choices, _ := strings.Cut(s, ":")
+	sched := new(Schedule)
+	var err error
+	if sched.Seed, err = strconv.ParseInt(seed, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: bad seed", s)
+	}
+	if choices != "" {
+		for _, c := range strings.Split(choices, ",") {
+			n, err := strconv.Atoi(c)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid schedule %q: bad choice %q", s, c)
+			}
+			sched.Choices = append(sched.Choices, n)
+		}
+	}
+	return sched, nil
+}
+
+// InterpretSchedule is like [Interpret], but it runs the goroutines of
+// the program one at a time, switching between them only at
+// scheduling points (channel operations, go statements, and calls to
+// runtime.Gosched and time.Sleep) as determined by sched, so that
+// each execution of a program under a given schedule is the same.
+// (The order of iteration over maps is not determined by the
+// schedule.) Calls to time.Sleep do not wait.
+//
+// If all goroutines are blocked, InterpretSchedule reports a deadlock
+// and returns 2. The report, like that of an unrecovered panic in any
+// goroutine, includes the stacks of all goroutines.
+func InterpretSchedule(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename string, args []string, sched *Schedule) (exitCode int) {
+	i := newInterpreter(mainpkg, mode, sizes, filename, args)
+	s := newScheduler(i, sched)
+	return s.run(func() int { return i.runMain(mainpkg) })
+}
+
+// Explore interprets the program once under each schedule that
+// deviates from the default schedule (see [Schedule]) at most bound
+// times, in depth-first order, until an execution fails: that is, it
+// deadlocks, panics, or exits with a nonzero status. The target
+// program's output is discarded.
+//
+// Explore returns the number of executions. If one failed, it also
+// returns its schedule, which [InterpretSchedule] will reproduce, and
+// its exit code. Each execution must terminate: Explore does not
+// detect livelocks.
+func Explore(mainpkg *ssa.Package, mode Mode, sizes types.Sizes, filename string, args []string, bound int) (runs int, failed *Schedule, exitCode int) {
+	var prefix []int
+	for {
+		i := newInterpreter(mainpkg, mode, sizes, filename, args)
+		i.stderr = io.Discard
+		s := newScheduler(i, &Schedule{Choices: prefix})
+		exitCode = s.run(func() int { return i.runMain(mainpkg) })
+		runs++
+		if exitCode != 0 {
+			// Omit the trailing default choices.
+			n := len(s.trace)
+			for n > 0 && s.trace[n-1].index == 0 {
+				n--
+			}
+			sched := &Schedule{Choices: make([]int, n)}
+			for k, c := range s.trace[:n] {
+				sched.Choices[k] = c.index
+			}
+			return runs, sched, exitCode
+		}
+		if prefix = nextPrefix(s.trace, bound); prefix == nil {
+			return runs, nil, 0
+		}
+	}
+}
+
+// A choice records a choice made by the scheduler.
+type choice struct {
+	index int // index of the chosen alternative
+	n     int // number of alternatives
+}
+
+// nextPrefix returns the choices that begin the schedule that follows
+// the one that made the specified choices, in depth-first order among
+// schedules with at most bound nonzero choices, or nil if there is none.
+func nextPrefix(trace []choice, bound int) []int {
+	deviations := 0
+	for _, c := range trace {
+		if c.index != 0 {
+			deviations++
+		}
+	}
+	for k := len(trace) - 1; k >= 0; k-- {
+		c := trace[k]
+		if c.index != 0 {
+			deviations-- // deviations in trace[:k]
+		}
+		if c.index+1 < c.n && deviations < bound {
+			prefix := make([]int, k+1)
+			for j, c := range trace[:k] {
+				prefix[j] = c.index
+			}
+			prefix[k] = c.index + 1
+			return prefix
+		}
+	}
+	return nil
+}
+
+// killedPanic is the panic by which the scheduler unwinds the stack
+// of a goroutine when the program has terminated. No deferred calls
+// of the target program run during unwinding.
+type killedPanic struct{}
+
+// A scheduler runs the goroutines of a program one at a time.
+// Its state is accessed only by the running goroutine.
+type scheduler struct {
+	i        *interpreter
+	sched    *Schedule
+	rand     *rand.Rand              // source of pseudo-random choices, or nil
+	trace    []choice                // choices made so far
+	gs       []*goroutine            // live goroutines, in order of creation
+	running  *goroutine              // the running goroutine
+	ncreated int                     // number of goroutines created
+	chans    map[chan value]*channel // state of channels, by identity
+	done     bool                    // the program has terminated
+	exitCode int                     // exit code, once done
+	wg       sync.WaitGroup          // interpreter goroutines
+}
+
+// A goroutine holds the scheduler's state of a target goroutine.
+type goroutine struct {
+	id        int
+	wake      chan struct{} // receives when the goroutine is resumed
+	fn        value         // function called by the goroutine
+	createdBy string        // location of the go statement, for stacks
+	started   bool
+	fr        *frame  // innermost frame as of the last scheduling point
+	op        *chanOp // pending channel operation, if blocked
+	stack     string  // stack of the current panic, if any
+	status    string  // reason for blocking, while op != nil
+}
+
+// A chanOp is a channel operation of a goroutine: a send, a receive,
+// or a select statement with zero or more cases.
+type chanOp struct {
+	cases       []chanCase
+	nonblocking bool // select statement with a default case
+
+	// Results, set when the operation completes.
+	chosen int    // index of the chosen case, or -1 for default
+	recv   value  // received value, or nil if the channel is closed
+	recvOk bool   // a value was received
+	err    string // panic message, if the operation failed
+}
+
+// A chanCase is a send or receive case of a channel operation.
+type chanCase struct {
+	ch   chan value // nil channels are never ready
+	send bool
+	val  value // value to send
+}
+
+// A channel holds the scheduler's state of a target channel.
+type channel struct {
+	buf    []value // buffered values
+	cap    int
+	closed bool
+}
+
+// A partner is a blocked goroutine with a case complementary to
+// another goroutine's case, with which it may communicate.
+type partner struct {
+	g *goroutine
+	k int // index of the case of g.op
+}
+
+func newScheduler(i *interpreter, sched *Schedule) *scheduler {
+	s := &scheduler{
+		i:     i,
+		sched: sched,
+		chans: make(map[chan value]*channel),
+	}
+	if sched.Seed != 0 {
+		s.rand = rand.New(rand.NewSource(sched.Seed))
+	}
+	i.sched = s
+	return s
+}
+
+// run runs the program, whose main goroutine calls main, and returns
+// its exit code once all goroutines have stopped.
+func (s *scheduler) run(main func() int) int {
+	g := s.newGoroutine(nil, "")
+	s.running = g
+	s.start(g, func() {
+		exitCode := main()
+		if !s.done {
+			s.terminate(exitCode)
+		}
+	})
+	g.wake <- struct{}{}
+	s.wg.Wait()
+	return s.exitCode
+}
+
+// choose returns the index of the alternative chosen among n.
+func (s *scheduler) choose(n int) int {
+	if n <= 1 {
+		return 0
+	}
+	var index int
+	switch k := len(s.trace); {
+	case k < len(s.sched.Choices):
+		// Clamp, in case the program's behavior depends on something
+		// other than its schedule, such as the order of map iteration.
+		index = s.sched.Choices[k]
+		if index >= n {
+			index = n - 1
+		}
+	case s.rand != nil:
+		index = s.rand.Intn(n)
+	}
+	s.trace = append(s.trace, choice{index, n})
+	return index
+}
+
+func (s *scheduler) newGoroutine(fn value, createdBy string) *goroutine {
+	s.ncreated++
+	g := &goroutine{
+		id:        s.ncreated,
+		wake:      make(chan struct{}, 1),
+		fn:        fn,
+		createdBy: createdBy,
+	}
+	s.gs = append(s.gs, g)
+	return g
+}
+
+// start starts the interpreter goroutine of g, which calls body once g
+// is first resumed.
+func (s *scheduler) start(g *goroutine, body func()) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		<-g.wake
+		if s.done {
+			return
+		}
+		g.started = true
+		body()
+	}()
+}
+
+// goStmt creates a goroutine that calls fn with arguments args, as
+// instr in frame fr does, then yields.
+func (s *scheduler) goStmt(fr *frame, instr *ssa.Go, fn value, args []value) {
+	createdBy := fmt.Sprintf("%s in goroutine %d\n\t%s", fr.fn, s.running.id, s.i.prog.Fset.Position(instr.Pos()))
+	g := s.newGoroutine(fn, createdBy)
+	s.start(g, func() {
+		defer func() {
+			switch p := recover().(type) {
+			case nil:
+				s.exit(g)
+			case killedPanic:
+				// The program has terminated.
+			case exitPanic:
+				s.terminate(int(p))
+			default:
+				if s.i.mode&DisableRecover != 0 {
+					panic(p) // let interpreter crash
+				}
+				s.i.reportPanic(p)
+				s.terminate(2)
+			}
+		}()
+		call(s.i, nil, instr.Pos(), fn, args)
+	})
+	s.yield(fr, nil, "")
+}
+
+// yield is called by the running goroutine at a scheduling point, in
+// frame fr. If op is non-nil, the goroutine performs it, blocking
+// (for the specified reason) until it can proceed. yield returns once
+// the goroutine is chosen to run.
+func (s *scheduler) yield(fr *frame, op *chanOp, status string) {
+	g := s.running
+	g.fr = fr
+	if op != nil {
+		g.op = op
+		g.status = status
+		if op.nonblocking {
+			// A select statement with a default case completes
+			// now, atomically: were it left pending, another
+			// goroutine could make its ready cases unready.
+			if s.canProceed(g) {
+				s.perform(g)
+			} else {
+				g.op = nil
+				op.chosen = -1
+			}
+		}
+	}
+	s.reschedule(g, slices.Index(s.gs, g), false)
+}
+
+// gosched is called by the running goroutine, in frame fr, when it
+// yields explicitly: the default choice is then the next goroutine.
+func (s *scheduler) gosched(fr *frame) {
+	g := s.running
+	g.fr = fr
+	s.reschedule(g, slices.Index(s.gs, g)+1, false)
+}
+
+// exit is called when goroutine g returns from its function.
+func (s *scheduler) exit(g *goroutine) {
+	k := slices.Index(s.gs, g)
+	s.gs = slices.Delete(s.gs, k, k+1)
+	s.reschedule(g, k, true)
+}
+
+// reschedule chooses the next goroutine to run among those that can
+// proceed, starting from s.gs[start], performs its pending operation,
+// if any, and switches from g to it. Unless g is exiting, it returns
+// once g is resumed.
+func (s *scheduler) reschedule(g *goroutine, start int, exiting bool) {
+	var alts []*goroutine
+	for j := range len(s.gs) {
+		if h := s.gs[(start+j)%len(s.gs)]; s.canProceed(h) {
+			alts = append(alts, h)
+		}
+	}
+	if len(alts) == 0 {
+		fmt.Fprintln(s.i.stderr, "fatal error: all goroutines are asleep - deadlock!")
+		s.dump(s.i.stderr, nil)
+		s.terminate(2)
+		if exiting {
+			return
+		}
+		panic(killedPanic{})
+	}
+	next := alts[s.choose(len(alts))]
+	if next.op != nil {
+		s.perform(next)
+	}
+	if next != g {
+		s.running = next
+		next.wake <- struct{}{}
+		if exiting {
+			return
+		}
+		<-g.wake
+		if s.done {
+			panic(killedPanic{})
+		}
+	}
+	if g.op != nil {
+		panic(fmt.Sprintf("goroutine %d resumed while blocked", g.id))
+	}
+}
+
+// terminate ends the program with the specified exit code, stopping
+// all goroutines other than the running one, which must stop itself.
+func (s *scheduler) terminate(exitCode int) {
+	s.done = true
+	s.exitCode = exitCode
+	for _, g := range s.gs {
+		if g != s.running {
+			g.wake <- struct{}{}
+		}
+	}
+}
+
+// canProceed reports whether goroutine g can run.
+func (s *scheduler) canProceed(g *goroutine) bool {
+	if g.op == nil {
+		return true
+	}
+	for _, cas := range g.op.cases {
+		if s.ready(g, cas) {
+			return true
+		}
+	}
+	return false
+}
+
+// ready reports whether case cas of goroutine g can proceed.
+func (s *scheduler) ready(g *goroutine, cas chanCase) bool {
+	if cas.ch == nil {
+		return false
+	}
+	c := s.channel(cas.ch)
+	switch {
+	case c.closed:
+		return true
+	case c.cap == 0:
+		return len(s.partners(g, cas)) > 0
+	case cas.send:
+		return len(c.buf) < c.cap
+	default:
+		return len(c.buf) > 0
+	}
+}
+
+// partners returns the blocked goroutines other than g with a case
+// complementary to cas, on an unbuffered channel, in order of creation.
+func (s *scheduler) partners(g *goroutine, cas chanCase) []partner {
+	var partners []partner
+	for _, h := range s.gs {
+		if h != g && h.op != nil {
+			for k, hcas := range h.op.cases {
+				if hcas.ch == cas.ch && hcas.send != cas.send {
+					partners = append(partners, partner{h, k})
+				}
+			}
+		}
+	}
+	return partners
+}
+
+// partner chooses a partner for case cas of goroutine g.
+func (s *scheduler) partner(g *goroutine, cas chanCase) partner {
+	partners := s.partners(g, cas)
+	return partners[s.choose(len(partners))]
+}
+
+// channel returns the state of channel ch.
+func (s *scheduler) channel(ch chan value) *channel {
+	c := s.chans[ch]
+	if c == nil {
+		c = &channel{cap: cap(ch)}
+		s.chans[ch] = c
+	}
+	return c
+}
+
+// perform performs the pending operation of goroutine g, which must
+// be able to proceed, and any complementary operation of a partner.
+func (s *scheduler) perform(g *goroutine) {
+	op := g.op
+	var ready []int
+	for k, cas := range op.cases {
+		if s.ready(g, cas) {
+			ready = append(ready, k)
+		}
+	}
+	g.op = nil
+	op.chosen = ready[s.choose(len(ready))]
+	cas := op.cases[op.chosen]
+	c := s.channel(cas.ch)
+	switch {
+	case cas.send && c.closed:
+		op.err = "send on closed channel"
+	case cas.send && c.cap == 0:
+		p := s.partner(g, cas)
+		p.g.op.chosen, p.g.op.recv, p.g.op.recvOk = p.k, cas.val, true
+		p.g.op = nil
+	case cas.send:
+		c.buf = append(c.buf, cas.val)
+	case len(c.buf) > 0:
+		op.recv, op.recvOk = c.buf[0], true
+		c.buf = c.buf[1:]
+	case c.closed:
+		// Receive the zero value.
+	default:
+		p := s.partner(g, cas)
+		op.recv, op.recvOk = p.g.op.cases[p.k].val, true
+		p.g.op.chosen = p.k
+		p.g.op = nil
+	}
+}
+
+// chanOp performs the channel operation op in frame fr, blocking for
+// the specified reason until it can proceed, and returns the index of
+// the chosen case.
+func (s *scheduler) chanOp(fr *frame, op *chanOp, status string) int {
+	s.yield(fr, op, status)
+	if op.err != "" {
+		panic(op.err)
+	}
+	return op.chosen
+}
+
+// send sends x on channel ch.
+func (s *scheduler) send(fr *frame, ch chan value, x value) {
+	status := "chan send"
+	if ch == nil {
+		status += " (nil chan)"
+	}
+	s.chanOp(fr, &chanOp{cases: []chanCase{{ch: ch, send: true, val: x}}}, status)
+}
+
+// recv receives from channel ch, returning the value, or nil if the
+// channel is closed, and whether a value was received.
+func (s *scheduler) recv(fr *frame, ch chan value) (value, bool) {
+	status := "chan receive"
+	if ch == nil {
+		status += " (nil chan)"
+	}
+	op := &chanOp{cases: []chanCase{{ch: ch}}}
+	s.chanOp(fr, op, status)
+	return op.recv, op.recvOk
+}
+
+// close closes channel ch.
+func (s *scheduler) close(fr *frame, ch chan value) {
+	s.yield(fr, nil, "")
+	if ch == nil {
+		panic("close of nil channel")
+	}
+	c := s.channel(ch)
+	if c.closed {
+		panic("close of closed channel")
+	}
+	c.closed = true
+}
+
+// dump writes the stacks of all goroutines to out, starting with the
+// running goroutine if it is panicking.
+func (s *scheduler) dump(out io.Writer, panicking *goroutine) {
+	if panicking != nil {
+		stack := panicking.stack
+		if stack == "" {
+			stack = s.stack(panicking.fr)
+		}
+		fmt.Fprintf(out, "\ngoroutine %d [running]:\n%s", panicking.id, stack)
+		s.createdBy(out, panicking)
+	}
+	for _, g := range s.gs {
+		if g == panicking {
+			continue
+		}
+		status := "runnable"
+		if g.op != nil {
+			status = g.status
+		}
+		fmt.Fprintf(out, "\ngoroutine %d [%s]:\n", g.id, status)
+		if g.started {
+			fmt.Fprint(out, s.stack(g.fr))
+		} else {
+			fmt.Fprintf(out, "%s()\n", g.fn)
+		}
+		s.createdBy(out, g)
+	}
+}
+
+// createdBy writes the location of the go statement that created
+// goroutine g, if any, to out.
+func (s *scheduler) createdBy(out io.Writer, g *goroutine) {
+	if g.createdBy != "" {
+		fmt.Fprintf(out, "created by %s\n", g.createdBy)
+	}
+}
+
+// stack returns a description of the stack of frames whose innermost
+// frame is fr.
+func (s *scheduler) stack(fr *frame) string {
+	var buf strings.Builder
+	for ; fr != nil; fr = fr.caller {
+		fmt.Fprintf(&buf, "%s()\n", fr.fn)
+		pos := fr.fn.Pos()
+		if fr.instr != nil && fr.instr.Pos().IsValid() {
+			pos = fr.instr.Pos()
+		}
+		if pos.IsValid() {
+			fmt.Fprintf(&buf, "\t%s\n", s.i.prog.Fset.Position(pos))
+		}
+	}
+	return buf.String()
+}
@@ -0,0 +1,214 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp_test
+
+import (
+	"github.com/tinygo-org/tinygo/alt_go/build"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/loader"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa/interp"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa/ssautil"
+	"github.com/tinygo-org/tinygo/x-tools/internal/testenv"
+)
+
+// loadMain loads and builds the program testdata/sched/<name>.go and
+// returns its main package and the sizes of its types.
+func loadMain(t *testing.T, goroot, name string) (*ssa.Package, types.Sizes) {
+	ctx := build.Default // copy
+	ctx.GOROOT = goroot
+	ctx.GOOS = runtime.GOOS
+	ctx.GOARCH = runtime.GOARCH
+	conf := loader.Config{Build: &ctx}
+	input := filepath.Join("testdata", "sched", name+".go")
+	if _, err := conf.FromArgs([]string{input}, true); err != nil {
+		t.Fatalf("FromArgs(%s) failed: %s", input, err)
+	}
+	conf.Import("runtime")
+	iprog, err := conf.Load()
+	if err != nil {
+		t.Fatalf("conf.Load(%s) failed: %s", input, err)
+	}
+	prog := ssautil.CreateProgram(iprog, ssa.InstantiateGenerics|ssa.SanityCheckFunctions)
+	prog.Build()
+	return prog.Package(iprog.Created[0].Pkg), types.SizesFor("gc", ctx.GOARCH)
+}
+
+// interpretSchedule runs the main package under the schedule and
+// returns its exit code and output.
+func interpretSchedule(t *testing.T, mainPkg *ssa.Package, sizes types.Sizes, sched *interp.Schedule) (int, string) {
+	restore := captureOutput(t)
+	exitCode := interp.InterpretSchedule(mainPkg, 0, sizes, "main", nil, sched)
+	return exitCode, restore()
+}
+
+func TestScheduleDefault(t *testing.T) {
+	testenv.NeedsExec(t)
+	goroot := makeGoroot(t)
+	mainPkg, sizes := loadMain(t, goroot, "pingpong")
+
+	exitCode, out := interpretSchedule(t, mainPkg, sizes, new(interp.Schedule))
+	if exitCode != 0 {
+		t.Fatalf("exit code was %d", exitCode)
+	}
+	const want = `worker 1 step 0
+worker 2 step 0
+worker 1 step 1
+worker 2 step 1
+worker 1 step 2
+worker 2 step 2
+ping 1
+ping 2
+ping 3
+`
+	if out != want {
+		t.Errorf("got output:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestScheduleSeed(t *testing.T) {
+	testenv.NeedsExec(t)
+	goroot := makeGoroot(t)
+	mainPkg, sizes := loadMain(t, goroot, "pingpong")
+
+	// The same seed yields the same execution.
+	outputs := make(map[string]bool)
+	for seed := range int64(10) {
+		sched := &interp.Schedule{Seed: seed + 1}
+		exitCode, out := interpretSchedule(t, mainPkg, sizes, sched)
+		if exitCode != 0 {
+			t.Fatalf("seed %d: exit code was %d", sched.Seed, exitCode)
+		}
+		if _, out2 := interpretSchedule(t, mainPkg, sizes, sched); out2 != out {
+			t.Errorf("seed %d: got output:\n%s\nthen:\n%s", sched.Seed, out, out2)
+		}
+		outputs[out] = true
+	}
+	if len(outputs) < 2 {
+		t.Errorf("10 seeds yielded %d distinct outputs, want several", len(outputs))
+	}
+}
+
+func TestScheduleDeadlock(t *testing.T) {
+	testenv.NeedsExec(t)
+	goroot := makeGoroot(t)
+	mainPkg, sizes := loadMain(t, goroot, "deadlock")
+
+	exitCode, out := interpretSchedule(t, mainPkg, sizes, new(interp.Schedule))
+	if exitCode != 2 {
+		t.Errorf("exit code was %d, want 2", exitCode)
+	}
+	for _, want := range []string{
+		"fatal error: all goroutines are asleep - deadlock!\n\ngoroutine 1 [chan receive]:\nmain.main()\n",
+		"deadlock.go:13:",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+	if strings.Contains(out, "goroutine 2") {
+		t.Errorf("output mentions goroutine 2, which has exited")
+	}
+}
+
+func TestSchedulePanic(t *testing.T) {
+	testenv.NeedsExec(t)
+	goroot := makeGoroot(t)
+	mainPkg, sizes := loadMain(t, goroot, "gopanic")
+
+	exitCode, out := interpretSchedule(t, mainPkg, sizes, new(interp.Schedule))
+	if exitCode != 2 {
+		t.Errorf("exit code was %d, want 2", exitCode)
+	}
+	for _, want := range []string{
+		"panic: (string, boom)\n\ngoroutine 2 [running]:\nmain.fail()\n",
+		"gopanic.go:9:",
+		"created by main.main in goroutine 1\n",
+		"goroutine 1 [runnable]:\nmain.main()\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q", want)
+		}
+	}
+}
+
+func TestExplore(t *testing.T) {
+	testenv.NeedsExec(t)
+	goroot := makeGoroot(t)
+	mainPkg, sizes := loadMain(t, goroot, "bank")
+
+	// The default schedule does not reveal the race.
+	runs, failed, _ := interp.Explore(mainPkg, 0, sizes, "main", nil, 0)
+	if runs != 1 || failed != nil {
+		t.Fatalf("Explore with bound 0: got %d runs and schedule %v, want 1 run and no failure", runs, failed)
+	}
+
+	runs, failed, exitCode := interp.Explore(mainPkg, 0, sizes, "main", nil, 2)
+	if failed == nil {
+		t.Fatalf("Explore with bound 2: no failure in %d runs", runs)
+	}
+	if exitCode != 2 {
+		t.Errorf("Explore: exit code was %d, want 2", exitCode)
+	}
+	t.Logf("failing schedule %v found after %d runs", failed, runs)
+
+	// The failing schedule reproduces the failure.
+	sched, err := interp.ParseSchedule(failed.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	exitCode, out := interpretSchedule(t, mainPkg, sizes, sched)
+	if exitCode != 2 || !strings.Contains(out, "panic: (string, negative balance)\n\ngoroutine 1 [running]:\nmain.main()\n") {
+		t.Errorf("replay of schedule %v: exit code %d, output:\n%s", sched, exitCode, out)
+	}
+}
+
+// TestExploreNonblocking checks that no schedule makes a select
+// statement with a default case block.
+func TestExploreNonblocking(t *testing.T) {
+	testenv.NeedsExec(t)
+	goroot := makeGoroot(t)
+	mainPkg, sizes := loadMain(t, goroot, "nonblocking")
+
+	runs, failed, _ := interp.Explore(mainPkg, 0, sizes, "main", nil, 3)
+	if failed != nil {
+		exitCode, out := interpretSchedule(t, mainPkg, sizes, failed)
+		t.Fatalf("schedule %v failed with exit code %d after %d runs:\n%s", failed, exitCode, runs, out)
+	}
+	if runs < 2 {
+		t.Errorf("Explore with bound 3: got %d runs, want several", runs)
+	}
+}
+
+func TestParseSchedule(t *testing.T) {
+	for _, test := range []struct {
+		in   string
+		want string // or error
+	}{
+		{"0", "0"},
+		{"42", "42"},
+		{"-1:0,1,2", "-1:0,1,2"},
+		{"0:3", "0:3"},
+		{"x", `invalid schedule "x": bad seed`},
+		{"0:1,,2", `invalid schedule "0:1,,2": bad choice ""`},
+		{"0:-1", `invalid schedule "0:-1": bad choice "-1"`},
+	} {
+		sched, err := interp.ParseSchedule(test.in)
+		var got string
+		if err != nil {
+			got = err.Error()
+		} else {
+			got = sched.String()
+		}
+		if got != test.want {
+			t.Errorf("ParseSchedule(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests of reflection-driven encoding and decoding, using the
+// encoding/json package of the test GOROOT: a small stand-in for the
+// real package that exercises the same reflect operations.
+//
+// Validate this file with 'go run' after editing.
+
+package main
+
+import "encoding/json"
+
+func assert(cond bool, msg string) {
+	if !cond {
+		panic(msg)
+	}
+}
+
+type Point struct {
+	X, Y int
+}
+
+type Shape struct {
+	Name    string            `json:"name"`
+	Points  []Point           `json:"points"`
+	Closed  bool              `json:"closed,omitempty"`
+	Scale   float64           `json:"scale"`
+	Origin  *Point            `json:"origin"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Extra   interface{}       `json:"extra"`
+	Ignored int               `json:"-"`
+	hidden  int
+}
+
+type Pair[K comparable, V any] struct {
+	Key   K `json:"k"`
+	Value V `json:"v"`
+}
+
+func marshal(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func main() {
+	// Encoding of values of basic, composite and named types.
+	assert(marshal(nil) == "null", "nil")
+	assert(marshal(42) == "42", "int")
+	assert(marshal(uint8(7)) == "7", "uint8")
+	assert(marshal(2.5) == "2.5", "float")
+	assert(marshal("a\"b\\\n\t") == `"a\"b\\\n\t"`, "string")
+	assert(marshal([]int(nil)) == "null", "nil slice")
+	assert(marshal([2]bool{true, false}) == "[true,false]", "array")
+	assert(marshal(map[string]int{"b": 2, "a": 1}) == `{"a":1,"b":2}`, "map")
+
+	s := Shape{
+		Name:    "tri",
+		Points:  []Point{{0, 0}, {1, 0}, {0, 1}},
+		Scale:   1.5,
+		Extra:   []interface{}{"x", 1},
+		Ignored: 1,
+		hidden:  2,
+	}
+	const want = `{"name":"tri","points":[{"X":0,"Y":0},{"X":1,"Y":0},{"X":0,"Y":1}],"scale":1.5,"origin":null,"extra":["x",1]}`
+	got := marshal(s)
+	assert(got == want, "struct: got "+got)
+	s.Closed = true
+	s.Origin = &Point{3, 4}
+	s.Labels = map[string]string{"color": "red"}
+	got = marshal(&s)
+	const want2 = `{"name":"tri","points":[{"X":0,"Y":0},{"X":1,"Y":0},{"X":0,"Y":1}],"closed":true,"scale":1.5,"origin":{"X":3,"Y":4},"labels":{"color":"red"},"extra":["x",1]}`
+	assert(got == want2, "struct with omitempty fields: got "+got)
+	assert(marshal(Pair[string, []int]{"k", []int{1}}) == `{"k":"k","v":[1]}`, "generic struct")
+
+	if _, err := json.Marshal(make(chan int)); err == nil {
+		panic("Marshal of chan succeeded")
+	}
+
+	// Decoding into structs, pointers, slices, maps and interfaces.
+	var s2 Shape
+	if err := json.Unmarshal([]byte(want2), &s2); err != nil {
+		panic(err)
+	}
+	assert(s2.Name == "tri" && len(s2.Points) == 3 && s2.Points[2].Y == 1, "Unmarshal points")
+	assert(s2.Closed && s2.Scale == 1.5 && s2.Origin != nil && *s2.Origin == Point{3, 4}, "Unmarshal fields")
+	assert(len(s2.Labels) == 1 && s2.Labels["color"] == "red", "Unmarshal map")
+	extra, ok := s2.Extra.([]interface{})
+	assert(ok && len(extra) == 2 && extra[0] == "x" && extra[1] == 1.0, "Unmarshal interface")
+	assert(marshal(s2) == want2, "round trip")
+
+	var p Pair[string, map[string]*Point]
+	if err := json.Unmarshal([]byte(` { "k" : "a", "v" : {"p": {"x": 1, "Y": -2}, "q": null} } `), &p); err != nil {
+		panic(err)
+	}
+	assert(p.Key == "a" && len(p.Value) == 2 && *p.Value["p"] == Point{1, -2} && p.Value["q"] == nil,
+		"Unmarshal generic struct")
+
+	var v interface{}
+	if err := json.Unmarshal([]byte(`{"a":[true,null,"s",0.25]}`), &v); err != nil {
+		panic(err)
+	}
+	a := v.(map[string]interface{})["a"].([]interface{})
+	assert(len(a) == 4 && a[0] == true && a[1] == nil && a[2] == "s" && a[3] == 0.25, "Unmarshal any")
+
+	var n int
+	if err := json.Unmarshal([]byte(`"one"`), &n); err == nil {
+		panic("Unmarshal of string into int succeeded")
+	}
+	if err := json.Unmarshal([]byte(`[1,`), &a); err == nil {
+		panic("Unmarshal of invalid JSON succeeded")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests of the interpreter's model of reflective calls: methods,
+// Call, CallSlice and MakeFunc.
+//
+// Validate this file with 'go run' after editing.
+
+package main
+
+import "reflect"
+
+func assert(cond bool, msg string) {
+	if !cond {
+		panic(msg)
+	}
+}
+
+type counter struct{ n int }
+
+func (c counter) Get() int       { return c.n }
+func (c *counter) Add(k int) int { c.n += k; return c.n }
+func (c counter) hidden()        {}
+
+type getter interface{ Get() int }
+
+func sum(base int, xs ...int) int {
+	for _, x := range xs {
+		base += x
+	}
+	return base
+}
+
+func divmod(a, b int) (int, int) { return a / b, a % b }
+
+func main() {
+	// Method sets.
+	ct := reflect.TypeOf(counter{})
+	assert(ct.NumMethod() == 1 && ct.Method(0).Name == "Get", "value method set")
+	pt := reflect.TypeOf(&counter{})
+	assert(pt.NumMethod() == 2 && pt.Method(0).Name == "Add" && pt.Method(1).Name == "Get", "pointer method set")
+	m, ok := pt.MethodByName("Add")
+	assert(ok && m.Index == 0 && m.Type.NumIn() == 2 && m.Type.In(0) == pt, "MethodByName")
+	if _, ok := ct.MethodByName("hidden"); ok {
+		panic("unexported method found")
+	}
+	gt := reflect.TypeOf((*getter)(nil)).Elem()
+	assert(gt.Kind() == reflect.Interface && gt.NumMethod() == 1, "interface method set")
+	assert(ct.Implements(gt) && pt.Implements(gt), "Implements")
+
+	// Method expressions via Type.Method.
+	c := &counter{n: 1}
+	out := m.Func.Call([]reflect.Value{reflect.ValueOf(c), reflect.ValueOf(2)})
+	assert(len(out) == 1 && out[0].Int() == 3 && c.n == 3, "Method.Func.Call")
+
+	// Method values via Value.Method.
+	add := reflect.ValueOf(c).MethodByName("Add")
+	assert(add.Type().NumIn() == 1, "method value type")
+	add.Call([]reflect.Value{reflect.ValueOf(4)})
+	assert(c.n == 7, "method value call")
+	get := reflect.ValueOf(*c).Method(0)
+	c.n = 100
+	assert(get.Call(nil)[0].Int() == 7, "method value receiver not copied")
+	f := add.Interface().(func(int) int)
+	assert(f(1) == 101, "method value as func")
+
+	// Calls of ordinary functions.
+	fv := reflect.ValueOf(divmod)
+	out = fv.Call([]reflect.Value{reflect.ValueOf(7), reflect.ValueOf(2)})
+	assert(out[0].Int() == 3 && out[1].Int() == 1, "Call with two results")
+
+	sv := reflect.ValueOf(sum)
+	assert(sv.Type().IsVariadic(), "IsVariadic")
+	out = sv.Call([]reflect.Value{reflect.ValueOf(1), reflect.ValueOf(2), reflect.ValueOf(3)})
+	assert(out[0].Int() == 6, "variadic Call")
+	out = sv.Call([]reflect.Value{reflect.ValueOf(1)})
+	assert(out[0].Int() == 1, "variadic Call with no extra args")
+	out = sv.CallSlice([]reflect.Value{reflect.ValueOf(1), reflect.ValueOf([]int{10, 20})})
+	assert(out[0].Int() == 31, "CallSlice")
+
+	// MakeFunc.
+	swap := func(in []reflect.Value) []reflect.Value {
+		return []reflect.Value{in[1], in[0]}
+	}
+	var intSwap func(int, int) (int, int)
+	fn := reflect.MakeFunc(reflect.TypeOf(intSwap), swap)
+	reflect.ValueOf(&intSwap).Elem().Set(fn)
+	a, b := intSwap(1, 2)
+	assert(a == 2 && b == 1, "MakeFunc int swap")
+
+	var strSwap func(string, string) (string, string)
+	reflect.ValueOf(&strSwap).Elem().Set(reflect.MakeFunc(reflect.TypeOf(strSwap), swap))
+	s, t := strSwap("x", "y")
+	assert(s == "y" && t == "x", "MakeFunc string swap")
+
+	// A function made by MakeFunc may itself be called reflectively,
+	// and may panic.
+	out = fn.Call([]reflect.Value{reflect.ValueOf(3), reflect.ValueOf(4)})
+	assert(out[0].Int() == 4 && out[1].Int() == 3, "Call of MakeFunc result")
+	boom := reflect.MakeFunc(reflect.TypeOf(func() {}), func([]reflect.Value) []reflect.Value {
+		panic("boom")
+	}).Interface().(func())
+	mustPanic(boom, "boom")
+
+	// Errors.
+	mustPanic(func() { fv.Call(nil) }, "reflect: Call with too few input arguments")
+	mustPanic(func() { fv.Call([]reflect.Value{reflect.ValueOf(1), reflect.ValueOf("2")}) },
+		"reflect: Call using string as type int")
+}
+
+func mustPanic(f func(), want string) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			panic("no panic; want " + want)
+		}
+		var got string
+		switch r := r.(type) {
+		case string:
+			got = r
+		case error:
+			got = r.Error()
+		}
+		assert(got == want, "got panic "+got+"; want "+want)
+	}()
+	f()
+}
@@ -0,0 +1,188 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests of the reflect operations by which fmt prints values with the
+// %v and %+v verbs. The fmt package of the test GOROOT is a stub, so
+// the printer below follows the logic of fmt's printValue; the
+// expected strings are those printed by the real fmt.
+//
+// Validate this file with 'go run' after editing.
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func assert(cond bool, msg string) {
+	if !cond {
+		panic(msg)
+	}
+}
+
+type Stringer interface {
+	String() string
+}
+
+var stringerType = reflect.TypeOf((*Stringer)(nil)).Elem()
+
+// printer formats values as fmt does for %v, or %+v if plus is set.
+type printer struct {
+	buf  strings.Builder
+	plus bool
+}
+
+func (p *printer) print(v reflect.Value, depth int) {
+	// As in fmt's handleMethods, a Stringer formats itself, unless
+	// it is reached through an unexported field.
+	if v.IsValid() && v.CanInterface() && v.Type().Implements(stringerType) {
+		if v.Kind() != reflect.Pointer || !v.IsNil() {
+			p.buf.WriteString(v.Interface().(Stringer).String())
+			return
+		}
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		p.buf.WriteString("<invalid reflect.Value>")
+	case reflect.Bool:
+		if v.Bool() {
+			p.buf.WriteString("true")
+		} else {
+			p.buf.WriteString("false")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p.buf.WriteString(strconv.Itoa(int(v.Int())))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		p.buf.WriteString(strconv.Itoa(int(v.Uint())))
+	case reflect.String:
+		p.buf.WriteString(v.String())
+	case reflect.Struct:
+		p.buf.WriteString("{")
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if i > 0 {
+				p.buf.WriteString(" ")
+			}
+			if p.plus {
+				p.buf.WriteString(t.Field(i).Name)
+				p.buf.WriteString(":")
+			}
+			p.print(v.Field(i), depth+1)
+		}
+		p.buf.WriteString("}")
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() && depth == 0 {
+			p.buf.WriteString("[]")
+			return
+		}
+		p.buf.WriteString("[")
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				p.buf.WriteString(" ")
+			}
+			p.print(v.Index(i), depth+1)
+		}
+		p.buf.WriteString("]")
+	case reflect.Map:
+		// fmt sorts the keys; these tests use only string keys.
+		var keys []string
+		iter := v.MapRange()
+		for iter.Next() {
+			keys = append(keys, iter.Key().String())
+		}
+		sort.Strings(keys)
+		p.buf.WriteString("map[")
+		for i, k := range keys {
+			if i > 0 {
+				p.buf.WriteString(" ")
+			}
+			p.buf.WriteString(k)
+			p.buf.WriteString(":")
+			p.print(v.MapIndex(reflect.ValueOf(k)), depth+1)
+		}
+		p.buf.WriteString("]")
+	case reflect.Pointer:
+		// Only at the top level is a pointer to a struct, slice or
+		// map printed as &value.
+		if depth == 0 && !v.IsNil() {
+			switch v.Elem().Kind() {
+			case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+				p.buf.WriteString("&")
+				p.print(v.Elem(), depth+1)
+				return
+			}
+		}
+		if v.IsNil() {
+			p.buf.WriteString("<nil>")
+		} else {
+			p.buf.WriteString("0x")
+		}
+	case reflect.Interface:
+		if v.IsNil() {
+			p.buf.WriteString("<nil>")
+		} else {
+			p.print(v.Elem(), depth+1)
+		}
+	default:
+		p.buf.WriteString("?" + v.Type().String())
+	}
+}
+
+// sprint returns the value x formatted as by fmt.Sprintf("%v", x), or
+// by "%+v" if plus is set.
+func sprint(x interface{}, plus bool) string {
+	p := &printer{plus: plus}
+	p.print(reflect.ValueOf(x), 0)
+	return p.buf.String()
+}
+
+type Point struct {
+	X, Y int
+}
+
+type Temp struct{ C int }
+
+func (t Temp) String() string { return strconv.Itoa(t.C) + "°C" }
+
+type Shape struct {
+	Name   string
+	Points []Point
+	Origin *Point
+	Temp   Temp
+	Any    interface{}
+	Tags   map[string]int
+	hidden bool
+	secret Temp
+}
+
+func main() {
+	assert(sprint(Point{1, 2}, false) == "{1 2}", "%v of Point")
+	assert(sprint(Point{1, 2}, true) == "{X:1 Y:2}", "%+v of Point")
+	assert(sprint(&Point{3, 4}, false) == "&{3 4}", "%v of *Point")
+	assert(sprint(Temp{21}, false) == "21°C", "%v of Stringer")
+	assert(sprint([]Point{{1, 2}, {3, 4}}, false) == "[{1 2} {3 4}]", "%v of []Point")
+
+	s := Shape{
+		Name:   "tri",
+		Points: []Point{{0, 0}, {1, 0}},
+		Temp:   Temp{5},
+		Any:    Point{7, 8},
+		Tags:   map[string]int{"b": 2, "a": 1},
+		hidden: true,
+		secret: Temp{9},
+	}
+	got := sprint(s, false)
+	const want = "{tri [{0 0} {1 0}] <nil> 5°C {7 8} map[a:1 b:2] true {9}}"
+	if got != want {
+		panic("%v of Shape: got " + got + ", want " + want)
+	}
+	got = sprint(s, true)
+	const wantPlus = "{Name:tri Points:[{X:0 Y:0} {X:1 Y:0}] Origin:<nil> Temp:5°C Any:{X:7 Y:8} Tags:map[a:1 b:2] hidden:true secret:{C:9}}"
+	if got != wantPlus {
+		panic("%+v of Shape: got " + got + ", want " + wantPlus)
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests of the reflect operations by which text/template evaluates
+// the fields, map keys and methods named in its actions. The test
+// GOROOT has no text/template package, so the evaluator below follows
+// the logic of text/template's evalField for a small subset of the
+// template language; the expected strings are those executed by the
+// real text/template.
+//
+// Validate this file with 'go run' after editing.
+
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+func assert(cond bool, msg string) {
+	if !cond {
+		panic(msg)
+	}
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// execute expands the actions of tmpl, which are of the forms
+// {{.F.G}}, {{.M "arg"}} and {{len .F}}, applied to data.
+func execute(tmpl string, data interface{}) (string, error) {
+	var buf strings.Builder
+	for {
+		i := strings.Index(tmpl, "{{")
+		if i < 0 {
+			buf.WriteString(tmpl)
+			return buf.String(), nil
+		}
+		buf.WriteString(tmpl[:i])
+		tmpl = tmpl[i+len("{{"):]
+		j := strings.Index(tmpl, "}}")
+		if j < 0 {
+			return "", errors.New("unclosed action")
+		}
+		action := tmpl[:j]
+		tmpl = tmpl[j+len("}}"):]
+
+		length := strings.HasPrefix(action, "len ")
+		if length {
+			action = action[len("len "):]
+		}
+		var args []reflect.Value
+		if k := strings.Index(action, " "); k >= 0 {
+			arg, err := strconv.Unquote(action[k+1:])
+			if err != nil {
+				return "", err
+			}
+			args = append(args, reflect.ValueOf(arg))
+			action = action[:k]
+		}
+		v, err := evalChain(reflect.ValueOf(data), action, args)
+		if err != nil {
+			return "", err
+		}
+		if length {
+			v = reflect.ValueOf(indirect(v).Len())
+		}
+		buf.WriteString(printValue(v))
+	}
+}
+
+// evalChain evaluates a chain of field names such as ".F.G" applied to
+// dot. The arguments are passed to the final element, if a method.
+func evalChain(dot reflect.Value, chain string, args []reflect.Value) (reflect.Value, error) {
+	if !strings.HasPrefix(chain, ".") {
+		return reflect.Value{}, errors.New("bad chain " + chain)
+	}
+	chain = chain[1:]
+	for {
+		name, rest := chain, ""
+		if k := strings.Index(chain, "."); k >= 0 {
+			name, rest = chain[:k], chain[k+1:]
+		}
+		var final []reflect.Value
+		if rest == "" {
+			final = args
+		}
+		var err error
+		dot, err = evalField(dot, name, final)
+		if err != nil || rest == "" {
+			return dot, err
+		}
+		chain = rest
+	}
+}
+
+// evalField evaluates the field, map key or method called name of
+// receiver, as text/template's evalField does.
+func evalField(receiver reflect.Value, name string, args []reflect.Value) (reflect.Value, error) {
+	// Methods, including those of the pointer, take precedence.
+	ptr := receiver
+	if ptr.Kind() != reflect.Interface && ptr.Kind() != reflect.Pointer && ptr.CanAddr() {
+		ptr = ptr.Addr()
+	}
+	if method := ptr.MethodByName(name); method.IsValid() {
+		typ := method.Type()
+		if typ.NumIn() != len(args) {
+			return reflect.Value{}, errors.New("wrong number of args for " + name)
+		}
+		results := method.Call(args)
+		if typ.NumOut() == 2 && typ.Out(1) == errorType && !results[1].IsNil() {
+			return reflect.Value{}, results[1].Interface().(error)
+		}
+		return results[0], nil
+	}
+	if len(args) > 0 {
+		return reflect.Value{}, errors.New(name + " is not a method but has arguments")
+	}
+	receiver = indirect(receiver)
+	switch receiver.Kind() {
+	case reflect.Struct:
+		if tField, ok := receiver.Type().FieldByName(name); ok {
+			if !tField.IsExported() {
+				return reflect.Value{}, errors.New(name + " is an unexported field")
+			}
+			return receiver.FieldByName(name), nil
+		}
+	case reflect.Map:
+		if receiver.Type().Key().Kind() == reflect.String {
+			// A missing key yields the invalid Value.
+			return receiver.MapIndex(reflect.ValueOf(name)), nil
+		}
+	}
+	return reflect.Value{}, errors.New("can't evaluate field " + name)
+}
+
+// indirect dereferences pointers and interfaces.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		v = v.Elem()
+	}
+	return v
+}
+
+// printValue formats v, a string, integer or Stringer.
+func printValue(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<no value>"
+	}
+	if s, ok := v.Interface().(interface{ String() string }); ok {
+		return s.String()
+	}
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.Itoa(int(v.Int()))
+	}
+	return "?" + v.Type().String()
+}
+
+type Point struct{ X, Y int }
+
+type Level int
+
+func (l Level) String() string { return "L" + strconv.Itoa(int(l)) }
+
+type User struct {
+	Name   string
+	Home   *Point
+	Visits []Point
+	Tags   map[string]string
+	Level  Level
+	secret string
+}
+
+func (u User) Greet(who string) string { return u.Name + " greets " + who }
+
+func (u *User) Rename(name string) (string, error) {
+	if name == "" {
+		return "", errors.New("empty name")
+	}
+	u.Name = name
+	return name, nil
+}
+
+func main() {
+	u := &User{
+		Name:   "ann",
+		Home:   &Point{3, 4},
+		Visits: []Point{{1, 1}, {2, 2}},
+		Tags:   map[string]string{"team": "go"},
+		Level:  2,
+		secret: "x",
+	}
+	for _, test := range []struct {
+		tmpl, want string
+	}{
+		{"{{.Name}} lives at {{.Home.X}},{{.Home.Y}}", "ann lives at 3,4"},
+		{"{{len .Visits}} visits", "2 visits"},
+		{"team {{.Tags.team}}, other {{.Tags.other}}.", "team go, other <no value>."},
+		{"level {{.Level}}", "level L2"},
+		{`{{.Greet "bob"}}`, "ann greets bob"},
+		{`{{.Rename "eve"}} is {{.Name}}`, "eve is eve"},
+	} {
+		got, err := execute(test.tmpl, u)
+		if err != nil {
+			panic(test.tmpl + ": " + err.Error())
+		}
+		if got != test.want {
+			panic(test.tmpl + ": got " + got + ", want " + test.want)
+		}
+	}
+
+	for _, test := range []struct {
+		tmpl, want string
+	}{
+		{"{{.secret}}", "secret is an unexported field"},
+		{"{{.Missing}}", "can't evaluate field Missing"},
+		{`{{.Rename ""}}`, "empty name"},
+	} {
+		_, err := execute(test.tmpl, u)
+		assert(err != nil && strings.Contains(err.Error(), test.want), test.tmpl+": wrong error")
+	}
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests of the interpreter's model of reflect.Value: addressability,
+// Set, maps, slices and struct fields.
+//
+// Validate this file with 'go run' after editing.
+
+package main
+
+import "reflect"
+
+func assert(cond bool, msg string) {
+	if !cond {
+		panic(msg)
+	}
+}
+
+type point struct {
+	X, Y int
+	name string
+}
+
+type tagged struct {
+	A int    `json:"a,omitempty" xml:"alpha"`
+	B string `json:"-"`
+	point
+}
+
+func main() {
+	// Set through a pointer updates the variable.
+	x := 1
+	v := reflect.ValueOf(&x).Elem()
+	assert(v.CanAddr() && v.CanSet(), "x is not settable")
+	v.SetInt(42)
+	assert(x == 42, "SetInt did not update x")
+	v.Set(reflect.ValueOf(7))
+	assert(x == 7, "Set did not update x")
+	assert(!reflect.ValueOf(x).CanSet(), "copy of x is settable")
+
+	// Fields of an addressable struct alias the struct.
+	p := point{1, 2, "p"}
+	pv := reflect.ValueOf(&p).Elem()
+	pv.Field(0).SetInt(10)
+	pv.FieldByName("Y").Set(reflect.ValueOf(20))
+	assert(p.X == 10 && p.Y == 20, "field update not observed")
+	assert(!pv.Field(2).CanSet(), "unexported field is settable")
+	assert(!pv.Field(2).CanInterface(), "unexported field can be converted to interface")
+	assert(pv.Field(2).String() == "p", "String of unexported field")
+	assert(pv.Addr().Interface().(*point) == &p, "Addr is not &p")
+
+	// Slice elements are addressable.
+	s := []string{"a", "b"}
+	sv := reflect.ValueOf(s)
+	sv.Index(1).SetString("B")
+	assert(s[1] == "B", "slice element update not observed")
+	sv = reflect.Append(sv, reflect.ValueOf("c"))
+	sv = reflect.AppendSlice(sv, reflect.ValueOf([]string{"d", "e"}))
+	assert(sv.Len() == 5 && sv.Index(4).String() == "e", "Append")
+	assert(sv.Slice(1, 3).Len() == 2 && sv.Slice(1, 3).Index(0).String() == "B", "Slice")
+
+	ms := reflect.MakeSlice(reflect.TypeOf([]point{}), 2, 4)
+	ms.Index(1).Field(1).SetInt(5)
+	assert(ms.Cap() == 4 && ms.Interface().([]point)[1].Y == 5, "MakeSlice")
+
+	// Maps.
+	m := map[string]int{"one": 1}
+	mv := reflect.ValueOf(m)
+	mv.SetMapIndex(reflect.ValueOf("two"), reflect.ValueOf(2))
+	assert(m["two"] == 2, "SetMapIndex")
+	assert(mv.MapIndex(reflect.ValueOf("one")).Int() == 1, "MapIndex")
+	assert(!mv.MapIndex(reflect.ValueOf("three")).IsValid(), "MapIndex of missing key")
+	mv.SetMapIndex(reflect.ValueOf("one"), reflect.Value{})
+	assert(len(m) == 1, "SetMapIndex with zero Value does not delete")
+	sum := 0
+	for it := mv.MapRange(); it.Next(); {
+		sum += len(it.Key().String()) * int(it.Value().Int())
+	}
+	assert(sum == 6, "MapRange")
+
+	mm := reflect.MakeMap(reflect.MapOf(reflect.TypeOf(point{}), reflect.TypeOf(0)))
+	mm.SetMapIndex(reflect.ValueOf(point{X: 1}), reflect.ValueOf(1))
+	assert(mm.Len() == 1 && mm.MapIndex(reflect.ValueOf(point{X: 1})).Int() == 1, "map with struct keys")
+
+	// Interfaces.
+	var e interface{} = 3
+	ev := reflect.ValueOf(&e).Elem()
+	assert(ev.Kind() == reflect.Interface && ev.Elem().Kind() == reflect.Int, "interface Elem")
+	ev.Set(reflect.ValueOf("three"))
+	assert(e == "three", "Set of interface variable")
+	assert(ev.Interface() == "three", "Interface of interface variable")
+
+	// New, Zero, IsZero and Convert.
+	np := reflect.New(reflect.TypeOf(point{}))
+	np.Elem().Field(0).SetInt(3)
+	assert(np.Interface().(*point).X == 3, "New")
+	assert(reflect.Zero(reflect.TypeOf(point{})).IsZero(), "Zero is not IsZero")
+	assert(!reflect.ValueOf(p).IsZero(), "p is IsZero")
+	assert(reflect.ValueOf(65).Convert(reflect.TypeOf(1.0)).Float() == 65, "Convert int to float")
+	assert(reflect.Indirect(reflect.ValueOf(&x)).Int() == 7, "Indirect")
+
+	// Struct types and tags.
+	tt := reflect.TypeOf(tagged{})
+	assert(tt.NumField() == 3 && tt.Name() == "tagged" && tt.PkgPath() == "main", "struct type")
+	a := tt.Field(0)
+	assert(a.Tag.Get("json") == "a,omitempty" && a.Tag.Get("xml") == "alpha", "Tag.Get")
+	if _, ok := a.Tag.Lookup("yaml"); ok {
+		panic("Tag.Lookup of missing key")
+	}
+	assert(tt.Field(1).Offset == reflect.TypeOf(0).Size(), "Offset")
+	assert(tt.Field(2).Anonymous && !tt.Field(2).IsExported(), "embedded field")
+	f, ok := tt.FieldByName("Y")
+	assert(ok && len(f.Index) == 2 && f.Index[0] == 2 && f.Index[1] == 1, "FieldByName of promoted field")
+
+	var tv tagged
+	reflect.ValueOf(&tv).Elem().FieldByName("X").SetInt(9)
+	assert(tv.X == 9, "update of promoted field")
+
+	// Panics.
+	mustPanic(func() { reflect.ValueOf(x).SetInt(1) }, "reflect: reflect.Value.SetInt using unaddressable value")
+	mustPanic(func() { pv.Field(2).SetString("q") }, "reflect: reflect.Value.SetString using value obtained using unexported field")
+	mustPanic(func() { pv.Field(0).SetString("q") }, "reflect: call of reflect.Value.SetString on int Value")
+}
+
+func mustPanic(f func(), want string) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			panic("no panic; want " + want)
+		}
+		var got string
+		switch r := r.(type) {
+		case string:
+			got = r
+		case error:
+			got = r.Error()
+		}
+		assert(got == want, "got panic "+got+"; want "+want)
+	}()
+	f()
+}
@@ -0,0 +1,34 @@
+package main
+
+// A check-then-act race: the balance may become negative if both
+// withdrawals check it before either updates it.
+
+import "sync"
+
+var (
+	mu      sync.Mutex
+	balance = 100
+)
+
+func withdraw(amount int, done chan<- bool) {
+	mu.Lock()
+	ok := balance >= amount
+	mu.Unlock()
+	if ok {
+		mu.Lock()
+		balance -= amount
+		mu.Unlock()
+	}
+	done <- true
+}
+
+func main() {
+	done := make(chan bool)
+	go withdraw(80, done)
+	go withdraw(80, done)
+	<-done
+	<-done
+	if balance < 0 {
+		panic("negative balance")
+	}
+}
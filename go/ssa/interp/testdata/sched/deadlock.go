@@ -0,0 +1,14 @@
+package main
+
+// A deadlock: the goroutine sends only once.
+
+func send(ch chan<- int) {
+	ch <- 1
+}
+
+func main() {
+	ch := make(chan int)
+	go send(ch)
+	<-ch
+	<-ch
+}
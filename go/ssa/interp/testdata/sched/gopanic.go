@@ -0,0 +1,17 @@
+package main
+
+// An unrecovered panic in a goroutine other than main.
+
+func fail(ch chan int) {
+	defer func() {
+		ch <- 1 // deferred calls run before the program exits
+	}()
+	panic("boom")
+}
+
+func main() {
+	ch := make(chan int)
+	go fail(ch)
+	<-ch
+	select {}
+}
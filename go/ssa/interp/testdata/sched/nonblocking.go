@@ -0,0 +1,23 @@
+package main
+
+// Two goroutines poll a channel holding one value. A select statement
+// with a default case never blocks: whichever goroutine finds the
+// value taken by the other takes its default case.
+
+func poll(ch chan int, done chan<- bool) {
+	select {
+	case <-ch:
+	default:
+	}
+	done <- true
+}
+
+func main() {
+	ch := make(chan int, 1)
+	ch <- 1
+	done := make(chan bool)
+	go poll(ch, done)
+	go poll(ch, done)
+	<-done
+	<-done
+}
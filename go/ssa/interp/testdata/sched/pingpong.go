@@ -0,0 +1,46 @@
+package main
+
+// Goroutines that yield after each step, and a select statement,
+// under the deterministic scheduler.
+
+import "runtime"
+
+func worker(id int, done chan<- int) {
+	for i := range 3 {
+		println("worker", id, "step", i)
+		runtime.Gosched()
+	}
+	done <- id
+}
+
+func main() {
+	done := make(chan int)
+	go worker(1, done)
+	go worker(2, done)
+	<-done
+	<-done
+
+	ping, pong := make(chan int), make(chan int, 1)
+	go func() {
+		for n := range ping {
+			pong <- n + 1
+		}
+		close(pong)
+	}()
+	for n := 0; n < 3; {
+		select {
+		case ping <- n:
+			n = <-pong
+			println("ping", n)
+		default:
+			runtime.Gosched()
+		}
+	}
+	close(ping)
+	if _, ok := <-pong; ok {
+		panic("pong not closed")
+	}
+	if len(pong) != 0 || cap(pong) != 1 {
+		panic("bad len or cap")
+	}
+}
@@ -0,0 +1,504 @@
+// Package json is a small reflection-based implementation of the
+// encoding and decoding of JSON values, enough for tests of the
+// interpreter's reflect package.
+package json
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func Marshal(v interface{}) ([]byte, error) {
+	var b strings.Builder
+	if err := encode(&b, reflect.ValueOf(v)); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+func encode(b *strings.Builder, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("null")
+	case reflect.Bool:
+		if v.Bool() {
+			b.WriteString("true")
+		} else {
+			b.WriteString("false")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		b.WriteString(strconv.Itoa(int(v.Int())))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		b.WriteString(strconv.Itoa(int(v.Uint())))
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		format := byte('f')
+		if f != 0 && (f < 1e-6 && f > -1e-6 || f >= 1e21 || f <= -1e21) {
+			format = 'e'
+		}
+		b.WriteString(strconv.FormatFloat(f, format, -1, v.Type().Bits()))
+	case reflect.String:
+		quote(b, v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		return encode(b, v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		return encodeArray(b, v)
+	case reflect.Array:
+		return encodeArray(b, v)
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("null")
+			return nil
+		}
+		if v.Type().Key().Kind() != reflect.String {
+			return errors.New("json: unsupported type: " + v.Type().String())
+		}
+		keys := make(map[string]reflect.Value)
+		var names []string
+		for _, k := range v.MapKeys() {
+			keys[k.String()] = k
+			names = append(names, k.String())
+		}
+		sort.Strings(names)
+		b.WriteString("{")
+		for i, name := range names {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			quote(b, name)
+			b.WriteString(":")
+			if err := encode(b, v.MapIndex(keys[name])); err != nil {
+				return err
+			}
+		}
+		b.WriteString("}")
+	case reflect.Struct:
+		b.WriteString("{")
+		first := true
+		for _, f := range fields(v.Type()) {
+			fv := v.Field(f.index)
+			if f.omitEmpty && isEmpty(fv) {
+				continue
+			}
+			if !first {
+				b.WriteString(",")
+			}
+			first = false
+			quote(b, f.name)
+			b.WriteString(":")
+			if err := encode(b, fv); err != nil {
+				return err
+			}
+		}
+		b.WriteString("}")
+	default:
+		return errors.New("json: unsupported type: " + v.Type().String())
+	}
+	return nil
+}
+
+func encodeArray(b *strings.Builder, v reflect.Value) error {
+	b.WriteString("[")
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		if err := encode(b, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	b.WriteString("]")
+	return nil
+}
+
+func quote(b *strings.Builder, s string) {
+	const hex = "0123456789abcdef"
+	b.WriteString(`"`)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteString(`\` + string(c))
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c < 0x20:
+			b.WriteString(`\u00` + string(hex[c>>4]) + string(hex[c&0xf]))
+		default:
+			b.WriteString(s[i : i+1])
+		}
+	}
+	b.WriteString(`"`)
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// A field is an encoded field of a struct.
+type field struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// fields returns the encoded fields of struct type t: its exported
+// fields, named by their json tags, if any.
+func fields(t reflect.Type) []field {
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if i := strings.Index(tag, ","); i >= 0 {
+			name, opts = tag[:i], tag[i+1:]
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields = append(fields, field{name, i, opts == "omitempty"})
+	}
+	return fields
+}
+
+func Unmarshal(data []byte, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("json: Unmarshal(non-pointer)")
+	}
+	d := &decoder{s: string(data)}
+	if err := d.value(rv.Elem()); err != nil {
+		return err
+	}
+	if d.space(); d.i < len(d.s) {
+		return d.error("after top-level value")
+	}
+	return nil
+}
+
+// A decoder decodes the JSON text s, of which it has read s[:i].
+type decoder struct {
+	s string
+	i int
+}
+
+func (d *decoder) error(context string) error {
+	if d.i >= len(d.s) {
+		return errors.New("json: unexpected end of JSON input")
+	}
+	return errors.New("json: invalid character '" + d.s[d.i:d.i+1] + "' " + context)
+}
+
+func (d *decoder) space() {
+	for d.i < len(d.s) && strings.Contains(" \t\r\n", d.s[d.i:d.i+1]) {
+		d.i++
+	}
+}
+
+func (d *decoder) accept(s string) bool {
+	if d.space(); strings.HasPrefix(d.s[d.i:], s) {
+		d.i += len(s)
+		return true
+	}
+	return false
+}
+
+// value decodes a value into v.
+func (d *decoder) value(v reflect.Value) error {
+	if d.space(); d.i == len(d.s) {
+		return d.error("looking for beginning of value")
+	}
+	if v.Kind() == reflect.Interface && v.NumMethod() == 0 {
+		x, err := d.any()
+		if err == nil {
+			if x == nil {
+				v.Set(reflect.Zero(v.Type()))
+			} else {
+				v.Set(reflect.ValueOf(x))
+			}
+		}
+		return err
+	}
+	if d.accept("null") {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return d.value(v.Elem())
+	}
+
+	switch c := d.s[d.i]; {
+	case c == '{':
+		return d.object(v)
+	case c == '[':
+		return d.array(v)
+	case c == '"':
+		s, err := d.str()
+		if err != nil {
+			return err
+		}
+		if v.Kind() != reflect.String {
+			return d.typeError("string", v)
+		}
+		v.SetString(s)
+	case d.accept("true"):
+		return d.setBool(v, true)
+	case d.accept("false"):
+		return d.setBool(v, false)
+	default:
+		lit := d.number()
+		if lit == "" {
+			return d.error("looking for beginning of value")
+		}
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n, err := strconv.Atoi(lit)
+			if err != nil {
+				return d.typeError("number "+lit, v)
+			}
+			v.SetInt(int64(n))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			n, err := strconv.Atoi(lit)
+			if err != nil || n < 0 {
+				return d.typeError("number "+lit, v)
+			}
+			v.SetUint(uint64(n))
+		case reflect.Float32, reflect.Float64:
+			f, err := parseFloat(lit)
+			if err != nil {
+				return err
+			}
+			v.SetFloat(f)
+		default:
+			return d.typeError("number", v)
+		}
+	}
+	return nil
+}
+
+func (d *decoder) setBool(v reflect.Value, b bool) error {
+	if v.Kind() != reflect.Bool {
+		return d.typeError("bool", v)
+	}
+	v.SetBool(b)
+	return nil
+}
+
+func (d *decoder) typeError(what string, v reflect.Value) error {
+	return errors.New("json: cannot unmarshal " + what + " into Go value of type " + v.Type().String())
+}
+
+// object decodes an object into v, a struct or a map with string keys.
+func (d *decoder) object(v reflect.Value) error {
+	var fs []field
+	switch v.Kind() {
+	case reflect.Struct:
+		fs = fields(v.Type())
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return d.typeError("object", v)
+		}
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+	default:
+		return d.typeError("object", v)
+	}
+	d.accept("{")
+	if d.accept("}") {
+		return nil
+	}
+	for {
+		if d.space(); d.i == len(d.s) || d.s[d.i] != '"' {
+			return d.error("looking for beginning of object key string")
+		}
+		key, err := d.str()
+		if err != nil {
+			return err
+		}
+		if !d.accept(":") {
+			return d.error("after object key")
+		}
+		if v.Kind() == reflect.Map {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := d.value(elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(reflect.ValueOf(key).Convert(v.Type().Key()), elem)
+		} else {
+			var dst reflect.Value
+			for _, f := range fs {
+				if f.name == key || !dst.IsValid() && strings.EqualFold(f.name, key) {
+					dst = v.Field(f.index)
+				}
+			}
+			if !dst.IsValid() {
+				if _, err := d.any(); err != nil { // skip unknown field
+					return err
+				}
+			} else if err := d.value(dst); err != nil {
+				return err
+			}
+		}
+		if d.accept("}") {
+			return nil
+		}
+		if !d.accept(",") {
+			return d.error("after object key:value pair")
+		}
+	}
+}
+
+// array decodes an array into v, a slice or array.
+func (d *decoder) array(v reflect.Value) error {
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return d.typeError("array", v)
+	}
+	d.accept("[")
+	n := 0
+	for !d.accept("]") {
+		if n > 0 && !d.accept(",") {
+			return d.error("after array element")
+		}
+		elem := reflect.New(v.Type().Elem()).Elem()
+		if err := d.value(elem); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Slice {
+			if n == 0 {
+				v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+			}
+			v.Set(reflect.Append(v, elem))
+		} else if n < v.Len() {
+			v.Index(n).Set(elem)
+		}
+		n++
+	}
+	if n == 0 && v.Kind() == reflect.Slice {
+		v.Set(reflect.MakeSlice(v.Type(), 0, 0))
+	}
+	for ; v.Kind() == reflect.Array && n < v.Len(); n++ {
+		v.Index(n).Set(reflect.Zero(v.Type().Elem()))
+	}
+	return nil
+}
+
+// any decodes a value of any type, as map[string]interface{},
+// []interface{}, string, float64, bool or nil.
+func (d *decoder) any() (interface{}, error) {
+	if d.space(); d.i == len(d.s) {
+		return nil, d.error("looking for beginning of value")
+	}
+	switch c := d.s[d.i]; {
+	case c == '{':
+		var m map[string]interface{}
+		err := d.object(reflect.ValueOf(&m).Elem())
+		return m, err
+	case c == '[':
+		var s []interface{}
+		err := d.array(reflect.ValueOf(&s).Elem())
+		return s, err
+	case c == '"':
+		return d.str()
+	case d.accept("true"):
+		return true, nil
+	case d.accept("false"):
+		return false, nil
+	case d.accept("null"):
+		return nil, nil
+	}
+	lit := d.number()
+	if lit == "" {
+		return nil, d.error("looking for beginning of value")
+	}
+	return parseFloat(lit)
+}
+
+// str decodes a string literal.
+func (d *decoder) str() (string, error) {
+	start := d.i
+	for d.i++; d.i < len(d.s) && d.s[d.i] != '"'; d.i++ {
+		if d.s[d.i] == '\\' {
+			d.i++
+		}
+	}
+	if d.i == len(d.s) {
+		return "", d.error("in string literal")
+	}
+	d.i++
+	s, err := strconv.Unquote(strings.Replace(d.s[start:d.i], `\/`, "/", -1))
+	if err != nil {
+		return "", errors.New("json: invalid string literal " + d.s[start:d.i])
+	}
+	return s, nil
+}
+
+// number returns the text of a number literal.
+func (d *decoder) number() string {
+	start := d.i
+	for d.i < len(d.s) && strings.Contains("+-0123456789.eE", d.s[d.i:d.i+1]) {
+		d.i++
+	}
+	return d.s[start:d.i]
+}
+
+// parseFloat returns the value of the number literal lit, which may
+// be inexact in its last digits.
+func parseFloat(lit string) (float64, error) {
+	mant, exp := lit, 0
+	if i := strings.Index(strings.ToLower(lit), "e"); i >= 0 {
+		e, err := strconv.Atoi(strings.Replace(lit[i+1:], "+", "", 1))
+		if err != nil {
+			return 0, errors.New("json: invalid number literal " + lit)
+		}
+		mant, exp = lit[:i], e
+	}
+	if i := strings.Index(mant, "."); i >= 0 {
+		exp -= len(mant) - i - 1
+		mant = mant[:i] + mant[i+1:]
+	}
+	n, err := strconv.Atoi(mant)
+	if err != nil {
+		return 0, errors.New("json: invalid number literal " + lit)
+	}
+	f := float64(n)
+	for ; exp > 0; exp-- {
+		f *= 10
+	}
+	for ; exp < 0; exp++ {
+		f /= 10
+	}
+	return f, nil
+}
@@ -104,6 +104,6 @@ func deepValueEqual(v1, v2 Value, visited map[visit]bool) bool {
 		return v1.IsNil() && v2.IsNil()
 	default:
 		// Normal equality suffices
-		return v1.Interface() == v2.Interface() // try interface comparison as a fallback.
+		return valueInterface(v1, false) == valueInterface(v2, false) // try interface comparison as a fallback.
 	}
 }
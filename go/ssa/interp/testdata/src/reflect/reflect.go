@@ -1,37 +1,194 @@
 package reflect
 
+import "strconv"
+
 type Type interface {
+	Align() int
+	Method(int) Method
+	MethodByName(string) (Method, bool)
+	NumMethod() int
+	Name() string
+	PkgPath() string
+	Size() uintptr
 	String() string
 	Kind() Kind
+	Implements(u Type) bool
+	AssignableTo(u Type) bool
+	ConvertibleTo(u Type) bool
+	Comparable() bool
+	Bits() int
+	IsVariadic() bool
 	Elem() Type
+	Field(i int) StructField
+	FieldByName(name string) (StructField, bool)
+	In(i int) Type
+	Key() Type
+	Len() int
+	NumField() int
+	NumIn() int
+	NumOut() int
+	Out(i int) Type
 }
 
-type Value struct {
+type Method struct {
+	Name    string
+	PkgPath string
+	Type    Type
+	Func    Value
+	Index   int
 }
 
-func (Value) String() string
+func (m Method) IsExported() bool { return m.PkgPath == "" }
+
+type StructField struct {
+	Name      string
+	PkgPath   string
+	Type      Type
+	Tag       StructTag
+	Offset    uintptr
+	Index     []int
+	Anonymous bool
+}
+
+func (f StructField) IsExported() bool { return f.PkgPath == "" }
+
+type StructTag string
+
+func (tag StructTag) Get(key string) string {
+	v, _ := tag.Lookup(key)
+	return v
+}
+
+func (tag StructTag) Lookup(key string) (value string, ok bool) {
+	for tag != "" {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		if tag == "" {
+			break
+		}
 
+		// Scan to colon.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+
+		// Scan quoted string to find value.
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if key == name {
+			value, err := strconv.Unquote(qvalue)
+			if err != nil {
+				break
+			}
+			return value, true
+		}
+	}
+	return "", false
+}
+
+type Value struct {
+}
+
+func (Value) Addr() Value
+func (Value) Bool() bool
+func (Value) Bytes() []byte
+func (Value) Call(in []Value) []Value
+func (Value) CallSlice(in []Value) []Value
+func (Value) CanAddr() bool
+func (Value) CanInterface() bool
+func (Value) CanSet() bool
+func (Value) Cap() int
+func (Value) Complex() complex128
+func (Value) Convert(t Type) Value
 func (Value) Elem() Value
-func (Value) Kind() Kind
+func (Value) Field(int) Value
+func (Value) FieldByName(string) Value
+func (Value) Float() float64
+func (Value) Index(i int) Value
 func (Value) Int() int64
-func (Value) IsValid() bool
+func (Value) Interface() interface{}
 func (Value) IsNil() bool
+func (Value) IsValid() bool
+func (Value) IsZero() bool
+func (Value) Kind() Kind
 func (Value) Len() int
-func (Value) Pointer() uintptr
-func (Value) Index(i int) Value
-func (Value) Type() Type
-func (Value) Field(int) Value
 func (Value) MapIndex(Value) Value
 func (Value) MapKeys() []Value
+func (Value) MapRange() *MapIter
+func (Value) Method(int) Value
+func (Value) MethodByName(string) Value
 func (Value) NumField() int
-func (Value) Interface() interface{}
+func (Value) NumMethod() int
+func (Value) Pointer() uintptr
+func (Value) Set(x Value)
+func (Value) SetBool(x bool)
+func (Value) SetFloat(x float64)
+func (Value) SetInt(x int64)
+func (Value) SetLen(n int)
+func (Value) SetMapIndex(key, elem Value)
+func (Value) SetString(x string)
+func (Value) SetUint(x uint64)
+func (Value) Slice(i, j int) Value
+func (Value) String() string
+func (Value) Type() Type
+func (Value) Uint() uint64
+
+func valueInterface(v Value, safe bool) interface{}
+
+type MapIter struct {
+}
 
+func (*MapIter) Key() Value
+func (*MapIter) Next() bool
+func (*MapIter) Reset(v Value)
+func (*MapIter) Value() Value
+
+func Append(s Value, x ...Value) Value
+func AppendSlice(s, t Value) Value
+
+func Indirect(v Value) Value {
+	if v.Kind() != Pointer {
+		return v
+	}
+	return v.Elem()
+}
+
+func MakeFunc(typ Type, fn func(args []Value) (results []Value)) Value
+func MakeMap(typ Type) Value
+func MakeSlice(typ Type, len, cap int) Value
+func MapOf(key, elem Type) Type
+func New(typ Type) Value
+func PointerTo(t Type) Type
+func PtrTo(t Type) Type { return PointerTo(t) }
 func SliceOf(Type) Type
 
 func TypeOf(interface{}) Type
 
 func ValueOf(interface{}) Value
 
+func Zero(typ Type) Value
+
 type Kind uint
 
 // Constants need to be kept in sync with the actual definitions for comparisons in tests.
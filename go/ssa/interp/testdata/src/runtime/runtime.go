@@ -17,3 +17,5 @@ type Error interface {
 }
 
 func GC()
+
+func Gosched()
@@ -4,3 +4,4 @@ func Itoa(i int) string
 func Atoi(s string) (int, error)
 
 func FormatFloat(float64, byte, int, int) string
+func Unquote(s string) (string, error)
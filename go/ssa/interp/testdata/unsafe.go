@@ -0,0 +1,102 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests of the interpreter's emulation of package unsafe.
+//
+// Validate this file with 'go run' after editing.
+
+package main
+
+import "unsafe"
+
+func assert(cond bool, msg string) {
+	if !cond {
+		panic(msg)
+	}
+}
+
+type point struct{ X, Y int32 }
+type vec struct{ A, B int32 }
+
+type header struct {
+	tag  uint8
+	n    int64
+	data [4]uint16
+}
+
+func main() {
+	// Conversion between types of the same representation aliases.
+	p := point{1, 2}
+	v := (*vec)(unsafe.Pointer(&p))
+	v.B = 20
+	assert(p.Y == 20, "alias of point as vec")
+
+	// Reinterpretation of numbers of the same size.
+	f := 1.5
+	bits := *(*uint64)(unsafe.Pointer(&f))
+	assert(bits == 0x3ff8000000000000, "float64 bits")
+	g := *(*float64)(unsafe.Pointer(&bits))
+	assert(g == 1.5, "float64 from bits")
+	i := int32(-1)
+	assert(*(*uint32)(unsafe.Pointer(&i)) == 0xffffffff, "int32 as uint32")
+
+	// string <-> []byte.
+	b := []byte("hello")
+	assert(*(*string)(unsafe.Pointer(&b)) == "hello", "[]byte as string")
+	s := "world"
+	assert(string(*(*[]byte)(unsafe.Pointer(&s))) == "world", "string as []byte")
+
+	// Sizeof, Alignof and Offsetof.
+	var h header
+	assert(unsafe.Sizeof(h) == 24, "Sizeof")
+	assert(unsafe.Alignof(h.n) == 8, "Alignof")
+	assert(unsafe.Offsetof(h.n) == 8 && unsafe.Offsetof(h.data) == 16, "Offsetof")
+	assert(generic[header]() == 24, "Sizeof of type parameter")
+
+	// Add within an array and into a struct.
+	arr := [4]int32{10, 11, 12, 13}
+	p2 := (*int32)(unsafe.Add(unsafe.Pointer(&arr[0]), 2*unsafe.Sizeof(arr[0])))
+	assert(*p2 == 12, "Add to array element")
+	*p2 = 42
+	assert(arr[2] == 42, "update through Add")
+	p1 := (*int32)(unsafe.Add(unsafe.Pointer(p2), -4))
+	assert(*p1 == 11, "negative Add")
+
+	pn := (*int64)(unsafe.Add(unsafe.Pointer(&h), unsafe.Offsetof(h.n)))
+	*pn = 7
+	assert(h.n == 7, "Add to struct field")
+	pd := (*uint16)(unsafe.Add(unsafe.Pointer(&h), unsafe.Offsetof(h.data)+2))
+	*pd = 3
+	assert(h.data[1] == 3, "Add to element of array field")
+	pt := (*int64)(unsafe.Add(unsafe.Pointer(&h.tag), unsafe.Offsetof(h.n)))
+	assert(pt == &h.n, "Add from one field to another")
+	p3 := (*uint16)(unsafe.Add(unsafe.Pointer(&h.data[3]), -6))
+	assert(p3 == &h.data[0], "Add from one element of an array field to another")
+	p4 := (*int32)(unsafe.Add(unsafe.Pointer(p1), -4))
+	assert(p4 == &arr[0], "Add to the first element of an array")
+	bs := make([]byte, 2, 4)
+	pb := (*byte)(unsafe.Add(unsafe.Pointer(&bs[0]), 3))
+	*pb = 'x'
+	assert(bs[:4][3] == 'x', "Add within the capacity of a slice")
+
+	// Slice, SliceData, String and StringData.
+	sl := unsafe.Slice(&arr[1], 3)
+	assert(len(sl) == 3 && sl[1] == 42, "Slice")
+	sl[0] = 0
+	assert(arr[1] == 0, "Slice aliases array")
+	assert(unsafe.SliceData(sl) == &arr[1], "SliceData")
+	assert(unsafe.SliceData([]int(nil)) == nil, "SliceData of nil")
+	assert(unsafe.String(&b[1], 3) == "ell", "String")
+	assert(*unsafe.StringData("abc") == 'a', "StringData")
+	assert(unsafe.String(unsafe.StringData(s), len(s)) == "world", "String of StringData")
+	assert(len(unsafe.Slice(unsafe.SliceData(bs), 4)) == 4, "Slice of SliceData")
+	assert(unsafe.Slice(&h.n, 1)[0] == 7, "Slice of a variable")
+	assert(unsafe.Slice(&h.data[1], 3)[0] == 3, "Slice of an array field")
+	assert(len(unsafe.Slice((*int)(nil), 0)) == 0, "Slice of nil")
+}
+
+func generic[T any]() uintptr {
+	var t T
+	return unsafe.Sizeof(t)
+}
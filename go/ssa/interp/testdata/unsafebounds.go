@@ -0,0 +1,62 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Tests of the interpreter's checks that unsafe operations stay
+// within the variable that contains the pointer. A compiled program
+// does not check them, so this file can't be validated with 'go run'.
+
+package main
+
+import "unsafe"
+
+func assert(cond bool, msg string) {
+	if !cond {
+		panic(msg)
+	}
+}
+
+type pair struct{ a, b int32 }
+
+func main() {
+	var s pair
+	arr := [2]int32{1, 2}
+	sl := make([]int32, 1, 2)
+
+	// Offsets beyond the containing variable.
+	mustPanic(func() { _ = unsafe.Add(unsafe.Pointer(&s.b), 4) },
+		"runtime error: unsafe.Add: offset 8 is outside the main.pair variable containing the pointer")
+	mustPanic(func() { _ = unsafe.Add(unsafe.Pointer(&arr[0]), -4) },
+		"runtime error: unsafe.Add: offset -4 is outside the [2]int32 variable containing the pointer")
+	mustPanic(func() { _ = unsafe.Add(unsafe.Pointer(&sl[0]), 8) },
+		"runtime error: unsafe.Add: offset 8 is outside the [2]int32 variable containing the pointer")
+
+	// A pointer of unknown provenance addresses only its pointee.
+	p := index(arr[:], 0)
+	mustPanic(func() { _ = unsafe.Add(unsafe.Pointer(p), 4) },
+		"runtime error: unsafe.Add: offset 4 is outside the int32 variable containing the pointer")
+
+	// Slices beyond the containing array.
+	mustPanic(func() { _ = unsafe.Slice(&arr[1], 2) },
+		"runtime error: unsafe.Slice: len 2 exceeds the 1 elements of the array containing ptr")
+	mustPanic(func() { _ = unsafe.Slice(&s.a, 2) },
+		"runtime error: unsafe.Slice: len 2 exceeds the int32 variable at ptr")
+	b := make([]byte, 3)
+	mustPanic(func() { _ = unsafe.String(&b[0], 4) },
+		"runtime error: unsafe.String: len 4 exceeds the 3 elements of the array containing ptr")
+	assert(len(unsafe.Slice(p, 1)) == 1, "Slice of a pointer of unknown provenance")
+}
+
+func index(s []int32, i int) *int32 { return &s[i] }
+
+func mustPanic(f func(), want string) {
+	defer func() {
+		r := recover()
+		if r == nil {
+			panic("no panic; want " + want)
+		}
+		got := r.(error).Error()
+		assert(got == want, "got panic "+got+"; want "+want)
+	}()
+	f()
+}
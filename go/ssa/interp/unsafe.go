@@ -0,0 +1,392 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package interp
+
+// Emulated "unsafe" operations.
+//
+// The interpreter's "boxed" value representation has no memory
+// layout, so only those unsafe operations whose effect can be
+// expressed in terms of variables are supported:
+//
+//   - conversion of unsafe.Pointer to *T, if the pointee has the
+//     representation of a T, yields an alias of the pointee;
+//   - otherwise, if the pointee is a number or bool of the same size as
+//     T, or T is string and the pointee a []byte or vice versa, it
+//     yields a pointer to a reinterpreted copy of the pointee;
+//   - unsafe.Add may move a pointer to another field or element of
+//     the variable that contains it, and unsafe.Slice and String may
+//     span the elements of the array or slice that contains it, if
+//     the pointer was computed in the same function from a pointer of
+//     known type, as by &x, &x.f or &a[i]; otherwise it may only
+//     address the variable to which it points;
+//   - unsafe.SliceData and StringData are supported.
+//
+// An operation that would address memory outside the variable panics.
+// Arithmetic on uintptr values derived from pointers is not supported.
+
+import (
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"reflect"
+	"unsafe"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/internal/typeparams"
+)
+
+// unsafeBuiltinArg returns the k'th operand of the call to unsafe
+// builtin fn being executed by caller, for operations that depend on
+// its static type.
+func unsafeBuiltinArg(caller *frame, fn *ssa.Builtin, k int) ssa.Value {
+	if caller != nil {
+		if call, ok := caller.instr.(ssa.CallInstruction); ok && call.Common().Value == fn {
+			return call.Common().Args[k]
+		}
+	}
+	panic(fmt.Sprintf("unsupported use of unsafe.%s in a go or defer statement", fn.Name()))
+}
+
+// callUnsafe interprets a call to the unsafe builtin fn with arguments
+// args; ok is false if fn is not an unsafe builtin.
+func callUnsafe(i *interpreter, caller *frame, fn *ssa.Builtin, args []value) (_ value, ok bool) {
+	switch fn.Name() {
+	case "Sizeof": // unsafe.Sizeof(x) where x's type is a type parameter
+		return uintptr(i.sizes.Sizeof(unsafeBuiltinArg(caller, fn, 0).Type())), true
+
+	case "Alignof":
+		return uintptr(i.sizes.Alignof(unsafeBuiltinArg(caller, fn, 0).Type())), true
+
+	case "Offsetof":
+		return unsafeOffsetof(i.sizes, unsafeBuiltinArg(caller, fn, 0)), true
+
+	case "Add": // unsafe.Add(ptr Pointer, len IntegerType) Pointer
+		n := asInt64(args[1])
+		if n == 0 {
+			return args[0], true
+		}
+		loc, ok := locate(caller, unsafeBuiltinArg(caller, fn, 0))
+		if !ok {
+			panic("unsafe.Add: pointer is not a conversion from a pointer of known type")
+		}
+		return unsafe.Pointer(offsetAddr(i.sizes, loc.t, loc.v, loc.off+n)), true
+
+	case "Slice": // unsafe.Slice(ptr *T, len IntegerType) []T
+		return unsafeSlice(i.sizes, caller, fn, asInt64(args[1])), true
+
+	case "SliceData": // unsafe.SliceData(slice []T) *T
+		s := args[0].([]value)
+		if cap(s) == 0 {
+			return (*value)(nil), true
+		}
+		return &s[:1][0], true
+
+	case "String": // unsafe.String(ptr *byte, len IntegerType) string
+		b := unsafeSlice(i.sizes, caller, fn, asInt64(args[1]))
+		return conv(types.Typ[types.String], types.NewSlice(types.Typ[types.Byte]), b), true
+
+	case "StringData": // unsafe.StringData(str string) *byte
+		b := conv(types.NewSlice(types.Typ[types.Byte]), types.Typ[types.String], args[0].(string)).([]value)
+		if len(b) == 0 {
+			return (*value)(nil), true
+		}
+		return &b[0], true
+	}
+	return nil, false
+}
+
+// unsafeSlice returns the slice of n variables starting at the pointer
+// operand of the call to unsafe.Slice or String being executed by fr.
+func unsafeSlice(sizes types.Sizes, fr *frame, fn *ssa.Builtin, n int64) []value {
+	ptr := unsafeBuiltinArg(fr, fn, 0)
+	elem := typeparams.CoreType(ptr.Type()).(*types.Pointer).Elem()
+	p := fr.get(ptr).(*value)
+	switch {
+	case n < 0:
+		panic(fmt.Sprintf("unsafe.%s: len out of range", fn.Name()))
+	case p == nil && n > 0:
+		panic(fmt.Sprintf("unsafe.%s: ptr is nil and len is not zero", fn.Name()))
+	case p == nil:
+		return nil
+	}
+
+	// Find the innermost array of elem that contains ptr.
+	size := sizes.Sizeof(elem)
+	loc, ok := locate(fr, ptr)
+	for ok && size > 0 {
+		if at, isArray := loc.t.Underlying().(*types.Array); isArray && loc.off%size == 0 &&
+			sizes.Sizeof(at.Elem()) == size && sameRepr(zero(elem), zero(at.Elem())) {
+			a, k := (*loc.v).(array), loc.off/size
+			if n > int64(len(a))-k {
+				panic(fmt.Sprintf("unsafe.%s: len %d exceeds the %d elements of the array containing ptr", fn.Name(), n, int64(len(a))-k))
+			}
+			return a[k : k+n : k+n]
+		}
+		loc, ok = loc.inner(sizes)
+	}
+	if n > 1 {
+		panic(fmt.Sprintf("unsafe.%s: len %d exceeds the %s variable at ptr", fn.Name(), n, elem))
+	}
+	return unsafe.Slice(p, n)
+}
+
+// unsafeOffsetof returns the offset of the field selected by the
+// operand x of unsafe.Offsetof.
+func unsafeOffsetof(sizes types.Sizes, x ssa.Value) uintptr {
+	var st types.Type
+	var field int
+	switch x := x.(type) {
+	case *ssa.Field:
+		st, field = x.X.Type(), x.Field
+	case *ssa.UnOp:
+		if fa, ok := x.X.(*ssa.FieldAddr); ok && x.Op == token.MUL {
+			st, field = typeparams.MustDeref(fa.X.Type()), fa.Field
+		}
+	}
+	if st == nil {
+		panic("unsafe.Offsetof: operand is not a field selection")
+	}
+	return uintptr(fieldOffsets(sizes, st.Underlying().(*types.Struct))[field])
+}
+
+// fieldOffsets returns the offsets of the fields of struct type st.
+func fieldOffsets(sizes types.Sizes, st *types.Struct) []int64 {
+	fields := make([]*types.Var, st.NumFields())
+	for i := range fields {
+		fields[i] = st.Field(i)
+	}
+	return sizes.Offsetsof(fields)
+}
+
+// A location is the address of the byte at offset off within the
+// variable v of type t.
+type location struct {
+	t   types.Type
+	v   *value
+	off int64
+}
+
+// locate returns the location of the pointer x, a value of frame fr,
+// within the outermost variable that contains it, as determined from
+// the instructions that computed x in fr. It returns false if x is not
+// derived from a pointer of known type.
+func locate(fr *frame, x ssa.Value) (location, bool) {
+	sizes := fr.i.sizes
+	switch x := x.(type) {
+	case *ssa.Convert:
+		if isPointer(x.X.Type()) {
+			return locate(fr, x.X)
+		}
+		return location{}, false
+
+	case *ssa.ChangeType:
+		return locate(fr, x.X)
+
+	case *ssa.Call:
+		if fn, ok := x.Call.Value.(*ssa.Builtin); ok {
+			switch args := x.Call.Args; fn.Name() {
+			case "Add":
+				loc, ok := locate(fr, args[0])
+				loc.off += asInt64(fr.get(args[1]))
+				return loc, ok
+			case "SliceData":
+				elem := typeparams.CoreType(args[0].Type()).(*types.Slice).Elem()
+				return sliceLocation(elem, fr.get(args[0]).([]value)), true
+			case "StringData":
+				// The bytes of a string are immutable, so a copy will do.
+				tBytes := types.NewSlice(types.Typ[types.Byte])
+				b := conv(tBytes, types.Typ[types.String], fr.get(args[0])).([]value)
+				return sliceLocation(types.Typ[types.Byte], b), true
+			}
+		}
+
+	case *ssa.FieldAddr:
+		st := typeparams.CoreType(typeparams.MustDeref(x.X.Type())).(*types.Struct)
+		loc, _ := locate(fr, x.X)
+		loc.off += fieldOffsets(sizes, st)[x.Field]
+		return loc, true
+
+	case *ssa.IndexAddr:
+		k := asInt64(fr.get(x.Index))
+		var loc location
+		switch t := typeparams.CoreType(x.X.Type()).(type) {
+		case *types.Pointer: // *array
+			loc, _ = locate(fr, x.X)
+			loc.off += k * sizes.Sizeof(typeparams.CoreType(t.Elem()).(*types.Array).Elem())
+		case *types.Slice:
+			loc = sliceLocation(t.Elem(), fr.get(x.X).([]value))
+			loc.off += k * sizes.Sizeof(t.Elem())
+		}
+		return loc, true
+	}
+	if ptr, ok := typeparams.CoreType(x.Type()).(*types.Pointer); ok {
+		return location{ptr.Elem(), fr.get(x).(*value), 0}, true
+	}
+	return location{}, false
+}
+
+// isPointer reports whether t is a pointer or unsafe.Pointer type.
+func isPointer(t types.Type) bool {
+	switch t := typeparams.CoreType(t).(type) {
+	case *types.Pointer:
+		return true
+	case *types.Basic:
+		return t.Kind() == types.UnsafePointer
+	}
+	return false
+}
+
+// sliceLocation returns the location of the first element of s, a
+// slice of elem, as if s were an array of cap(s) elements.
+func sliceLocation(elem types.Type, s []value) location {
+	a := array(s[:cap(s)])
+	for i := len(s); i < len(a); i++ {
+		if a[i] == nil { // spare capacity left by append
+			a[i] = zero(elem)
+		}
+	}
+	v := value(a)
+	return location{types.NewArray(elem, int64(len(a))), &v, 0}
+}
+
+// inner returns the location of the same byte within the field or
+// element of loc.v that contains it, or false if there is none.
+func (loc location) inner(sizes types.Sizes) (location, bool) {
+	switch t := loc.t.Underlying().(type) {
+	case *types.Struct:
+		for i, off := range fieldOffsets(sizes, t) {
+			if f := t.Field(i).Type(); off <= loc.off && loc.off < off+sizes.Sizeof(f) {
+				return location{f, &(*loc.v).(structure)[i], loc.off - off}, true
+			}
+		}
+	case *types.Array:
+		if size := sizes.Sizeof(t.Elem()); size > 0 && loc.off >= 0 && loc.off < t.Len()*size {
+			k := loc.off / size
+			return location{t.Elem(), &(*loc.v).(array)[k], loc.off - k*size}, true
+		}
+	}
+	return location{}, false
+}
+
+// offsetAddr returns the address of the field or element at offset n
+// within the variable p of type t, or p itself if n is zero.
+func offsetAddr(sizes types.Sizes, t types.Type, p *value, n int64) *value {
+	if p == nil {
+		panic("unsafe.Add: pointer is nil")
+	}
+	if n < 0 || n >= sizes.Sizeof(t) {
+		panic(fmt.Sprintf("unsafe.Add: offset %d is outside the %s variable containing the pointer", n, t))
+	}
+	loc := location{t, p, n}
+	for loc.off != 0 {
+		var ok bool
+		if loc, ok = loc.inner(sizes); !ok {
+			panic(fmt.Sprintf("unsafe.Add: unsupported offset %d into a variable of type %s", n, t))
+		}
+	}
+	return loc.v
+}
+
+// unsafePointerToPointer converts the unsafe.Pointer x to pointer type t.
+func unsafePointerToPointer(t types.Type, x unsafe.Pointer) value {
+	p := (*value)(x)
+	if p == nil {
+		return p
+	}
+	elem := t.Underlying().(*types.Pointer).Elem()
+	// The address of a variable is also that of its first field or
+	// element, and so on.
+	for q := p; ; {
+		if sameRepr(zero(elem), *q) {
+			return q
+		}
+		if v, ok := (*q).(structure); ok && len(v) > 0 {
+			q = &v[0]
+		} else if v, ok := (*q).(array); ok && len(v) > 0 {
+			q = &v[0]
+		} else {
+			break
+		}
+	}
+	if v, ok := reinterpret(elem, *p); ok {
+		return &v
+	}
+	panic(fmt.Sprintf("unsupported conversion of unsafe.Pointer to %s: pointee is %T", t, *p))
+}
+
+// sameRepr reports whether values x and y have the same
+// representation, so that a variable containing y may be used as one
+// of the type of x.
+func sameRepr(x, y value) bool {
+	switch x := x.(type) {
+	case structure:
+		y, ok := y.(structure)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !sameRepr(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case array:
+		y, ok := y.(array)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !sameRepr(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case *ssa.Function, *ssa.Builtin, *closure:
+		switch y.(type) {
+		case *ssa.Function, *ssa.Builtin, *closure:
+			return true
+		}
+		return false
+	}
+	return reflect.TypeOf(x) == reflect.TypeOf(y)
+}
+
+// reinterpret returns the value of type t whose memory representation
+// is that of x, if both are numbers or booleans of the same size, or
+// one is a string and the other a []byte.
+func reinterpret(t types.Type, x value) (value, bool) {
+	switch z := zero(t).(type) {
+	case string:
+		if b, ok := x.([]value); ok {
+			s := make([]byte, len(b))
+			for i := range b {
+				if s[i], ok = b[i].(byte); !ok {
+					return nil, false
+				}
+			}
+			return string(s), true
+		}
+	case []value:
+		if s, ok := x.(string); ok && reflectKind(t.Underlying().(*types.Slice).Elem()) == reflect.Uint8 {
+			for i := 0; i < len(s); i++ {
+				z = append(z, s[i])
+			}
+			return z, true
+		}
+	default:
+		src, dst := reflect.ValueOf(x), reflect.ValueOf(z)
+		if isNumeric(src.Kind()) && isNumeric(dst.Kind()) && src.Type().Size() == dst.Type().Size() {
+			p := reflect.New(src.Type())
+			p.Elem().Set(src)
+			return reflect.NewAt(dst.Type(), p.UnsafePointer()).Elem().Interface(), true
+		}
+	}
+	return nil, false
+}
+
+// isNumeric reports whether k is a numeric or boolean kind.
+func isNumeric(k reflect.Kind) bool {
+	return reflect.Bool <= k && k <= reflect.Complex128
+}
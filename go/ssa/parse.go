@@ -0,0 +1,1942 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa
+
+// This file defines ParsePackage, which reconstructs a Package from
+// its textual form.
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/constant"
+	"github.com/tinygo-org/tinygo/alt_go/parser"
+	"github.com/tinygo-org/tinygo/alt_go/scanner"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/tinygo-org/tinygo/x-tools/internal/typeparams"
+)
+
+// ParsePackage parses the text of an SSA package, creates the package
+// in prog with the specified import path, and returns it. The
+// filename is used in positions and error messages.
+//
+// The text of a package has two parts. The first is a preamble of Go
+// source: a package clause, imports, and declarations of all the
+// package-level constants, variables, types, functions and methods of
+// the package, with no function bodies. The preamble is type-checked,
+// and its imports must be packages already created in prog. The
+// second part, which starts at the first line beginning with
+// "# Name:", is a sequence of functions in the form printed by
+// [WriteFunction]:
+//
+//	# Name: example.com/p.f
+//	# Package: example.com/p
+//	func f(x int) int:
+//	0:                                                                entry P:0 S:2
+//		t0 = x < 0:int                                                     bool
+//		if t0 goto 1 else 2
+//	1:                                                              if.then P:1 S:0
+//		t1 = -x                                                             int
+//		return t1
+//	2:                                                              if.done P:1 S:0
+//		return x
+//
+// Each function begins with comment lines giving its name and,
+// optionally, its "Synthetic" provenance, "Parent" function, "Recover"
+// block, "Free variables" and "Locals", followed by its signature and
+// its blocks. A "Location" comment is ignored. Each block begins with
+// a line holding its index and optionally a comment, and each of its
+// instructions is on a line indented by a tab. Each value-defining
+// instruction names a register and ends with the type of its value,
+// which must be separated from the instruction by at least one space.
+// Registers may have any names; they are renumbered in order.
+//
+// Functions, methods and the package initializer (a function named
+// "init", with "package initializer" provenance) are identified by
+// name. Additional init functions are named init#1, init#2, and so on.
+// An anonymous function is identified by the "Parent" comment, and must
+// follow its parent and any earlier anonymous functions of the same
+// parent. Functions of the package for which no body is given have
+// only parameters, like functions loaded from export data. Synthetic
+// functions such as wrappers are created on demand, as during
+// building, so the text of any such function is skipped, except for
+// the instances of generic functions in [InstantiateGenerics] mode.
+//
+// References to functions, globals and types of other packages are
+// qualified by their package path, as printed by [WriteFunction]; the
+// referenced packages must have been created in prog. Types declared
+// within functions are not supported, nor are [DebugRef] instructions.
+// Constants are printed exactly: a string in full, and a number in
+// decimal if that is exact, or else as a fraction, such as
+// 1/3:float64, or in hexadecimal floating-point notation if it is too
+// large for a fraction.
+//
+// [WritePackageText] prints a package in this form.
+//
+// Each function is sanity-checked, and an error is returned if it is
+// not well formed. If an error is returned, prog may contain a
+// partially created package.
+func ParsePackage(prog *Program, path, filename string, src []byte) (_ *Package, err error) {
+	p := &textParser{
+		prog:     prog,
+		filename: filename,
+		funcs:    make(map[string]*Function),
+		bounds:   make(map[*types.Func]*Function),
+		thunks:   make(map[string]*Function),
+		filled:   make(map[*Function]bool),
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(*parseError)
+			if !ok {
+				panic(r)
+			}
+			err = perr
+		}
+	}()
+	return p.parsePackage(path, src), nil
+}
+
+// A parseError is an error in the text of a package.
+type parseError struct {
+	filename string
+	line     int
+	msg      string
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("%s:%d: %s", e.filename, e.line, e.msg)
+}
+
+// A textParser holds the state of ParsePackage.
+//
+// The parser is a recursive descent parser over one line of text at a
+// time, held in s. Errors are reported by panicking with a *parseError.
+type textParser struct {
+	prog     *Program
+	filename string
+	pkg      *Package
+	b        builder // builds functions created on demand
+
+	funcs  map[string]*Function      // functions of pkg by name, both absolute and relative to pkg
+	bounds map[*types.Func]*Function // bound method wrappers created so far
+	thunks map[string]*Function      // thunks created so far, by name
+	filled map[*Function]bool        // functions whose bodies were parsed
+	refs   map[*types.Package]bool   // other packages referenced by functions
+
+	// the current line
+	line int    // line number, for errors
+	s    string // text
+	i    int    // position of next character within s
+
+	// the current function
+	fn         *Function
+	tparams    *types.TypeParamList  // type parameters in scope
+	localNames map[string]bool       // names of parameters, free variables and registers
+	locals     map[string]Value      // values of parameters, free variables and registers defined so far
+	succs      map[*BasicBlock][]int // successor indices of each block
+	phiPreds   map[*BasicBlock][]int // predecessor indices given by the first phi of each block
+	fixups     []func()              // type-dependent actions, run after operands are resolved
+	replace    map[Value]Value       // instructions to replace after fixups
+	lines      map[Instruction]int   // line of each instruction, for errors
+}
+
+// A textSection is the text of one function.
+type textSection struct {
+	line      int // line number of "# Name:" comment
+	name      string
+	synthetic string
+	parent    string
+	recover   string
+	freeVars  []textLine // "name type" lines
+	locals    []textLine // "name type" lines
+	sig       textLine
+	body      []textLine
+	fn        *Function
+}
+
+// A textLine is a line of text and its line number.
+type textLine struct {
+	line int
+	text string
+}
+
+func (p *textParser) errorf(format string, args ...any) {
+	panic(&parseError{p.filename, p.line, fmt.Sprintf(format, args...)})
+}
+
+func (p *textParser) parsePackage(path string, src []byte) *Package {
+	// Split the text into the preamble and function sections.
+	lines := strings.Split(string(src), "\n")
+	var (
+		preamble bytes.Buffer
+		sections []*textSection
+		sec      *textSection
+	)
+	for i, text := range lines {
+		line := textLine{i + 1, strings.TrimRight(text, " \r")}
+		p.line = line.line
+		if strings.HasPrefix(text, "# Name: ") {
+			sec = &textSection{line: line.line, name: strings.TrimPrefix(line.text, "# Name: ")}
+			sections = append(sections, sec)
+			continue
+		}
+		if sec == nil {
+			preamble.WriteString(text)
+			preamble.WriteString("\n")
+			continue
+		}
+		p.addLine(sec, line)
+	}
+
+	// Type-check the preamble and create the package.
+	p.line = 0
+	fset := p.prog.Fset
+	f, err := parser.ParseFile(fset, p.filename, preamble.Bytes(), parser.SkipObjectResolution)
+	if err != nil {
+		if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+			panic(&parseError{p.filename, list[0].Pos.Line, list[0].Msg})
+		}
+		panic(&parseError{p.filename, 0, err.Error()})
+	}
+	// Function declarations have no bodies, which is an error only
+	// for generic functions.
+	var first *types.Error
+	conf := types.Config{
+		Importer: textImporter{p.prog},
+		Error: func(err error) {
+			if e := err.(types.Error); first == nil && !strings.HasSuffix(e.Msg, "missing function body") {
+				first = &e
+			}
+		},
+	}
+	tpkg, _ := conf.Check(path, fset, []*ast.File{f}, nil)
+	if first != nil {
+		panic(&parseError{p.filename, fset.Position(first.Pos).Line, first.Msg})
+	}
+	p.pkg = p.prog.CreatePackage(tpkg, nil, nil, true)
+	p.refs = make(map[*types.Package]bool)
+	p.b.fns = p.pkg.created
+	for _, mem := range p.pkg.objects {
+		if fn, ok := mem.(*Function); ok {
+			p.addFunc(fn)
+		}
+	}
+	p.pkg.init.build = (*builder).buildParamsOnly // unless parsed
+	p.addFunc(p.pkg.init)
+
+	// Create the functions of the sections, then parse their bodies.
+	for _, sec := range sections {
+		p.line = sec.line
+		sec.fn = p.sectionFunc(sec)
+	}
+	for _, sec := range sections {
+		if sec.fn != nil {
+			p.parseBody(sec)
+		}
+	}
+	for _, sec := range sections {
+		if fn := sec.fn; fn != nil {
+			p.line = sec.line
+			var buf bytes.Buffer
+			if !sanityCheck(fn, &buf) {
+				p.errorf("invalid function %s:\n%s", fn, &buf)
+			}
+		}
+	}
+	for _, sec := range sections {
+		if fn := sec.fn; fn != nil && fn.parent == nil {
+			fn.done()
+		}
+	}
+
+	// Record the packages on which the functions depend.
+	imports := tpkg.Imports()
+	for _, imp := range imports {
+		delete(p.refs, imp)
+	}
+	for _, q := range p.prog.AllPackages() {
+		if p.refs[q.Pkg] {
+			imports = append(imports, q.Pkg)
+		}
+	}
+	tpkg.SetImports(imports)
+
+	// Build the remaining functions and mark the parsed ones done.
+	p.b.iterate()
+	p.pkg.buildOnce.Do(func() {})
+	p.pkg.created = nil
+	p.pkg.files = nil
+	p.pkg.initVersion = nil
+	return p.pkg
+}
+
+// addLine adds a line of text to a function section.
+func (p *textParser) addLine(sec *textSection, line textLine) {
+	text := line.text
+	switch {
+	case text == "":
+		// skip
+	case sec.sig.text != "":
+		sec.body = append(sec.body, line)
+	case strings.HasPrefix(text, "func "):
+		sec.sig = line
+	case strings.HasPrefix(text, "# Synthetic: "):
+		sec.synthetic = strings.TrimPrefix(text, "# Synthetic: ")
+	case strings.HasPrefix(text, "# Parent: "):
+		sec.parent = strings.TrimPrefix(text, "# Parent: ")
+	case strings.HasPrefix(text, "# Recover: "):
+		sec.recover = strings.TrimPrefix(text, "# Recover: ")
+	case strings.HasPrefix(text, "# Package: "), strings.HasPrefix(text, "# Location: "):
+		// ignore
+	case text == "# Free variables:":
+		sec.freeVars = []textLine{}
+	case text == "# Locals:":
+		sec.locals = []textLine{}
+	default:
+		m := varLineRE.FindStringSubmatch(text)
+		if m == nil {
+			p.errorf("unexpected line %q", text)
+		}
+		v := textLine{line.line, m[1]}
+		if sec.locals != nil {
+			sec.locals = append(sec.locals, v)
+		} else if sec.freeVars != nil {
+			sec.freeVars = append(sec.freeVars, v)
+		} else {
+			p.errorf("unexpected line %q", text)
+		}
+	}
+}
+
+var (
+	varLineRE   = regexp.MustCompile(`^#\s*\d+:\t(.*)$`)
+	blockLineRE = regexp.MustCompile(`^(\d+):(.*?)(?:\s*P:\d+ S:\d+)?$`)
+	registerRE  = regexp.MustCompile(`^\t([\pL_][\pL\pN_]*) = `)
+	anonNameRE  = regexp.MustCompile(`^(.*)\$(\d+)$`)
+	initNameRE  = regexp.MustCompile(`^init#(\d+)$`)
+)
+
+// addFunc records a function of the package by name.
+func (p *textParser) addFunc(fn *Function) {
+	p.funcs[fn.String()] = fn
+	p.funcs[fn.RelString(p.pkg.Pkg)] = fn
+}
+
+// A textImporter imports the packages of a program.
+type textImporter struct{ prog *Program }
+
+func (imp textImporter) Import(path string) (*types.Package, error) {
+	if path == "unsafe" {
+		return types.Unsafe, nil
+	}
+	if pkg := imp.prog.packageByPath(path); pkg != nil {
+		return pkg, nil
+	}
+	return nil, fmt.Errorf("package %q has not been created", path)
+}
+
+// packageByPath returns the created package with the specified path,
+// or nil if there is none.
+func (prog *Program) packageByPath(path string) *types.Package {
+	if p := prog.ImportedPackage(path); p != nil {
+		return p.Pkg
+	}
+	for pkg := range prog.packages {
+		if pkg.Path() == path {
+			return pkg
+		}
+	}
+	return nil
+}
+
+// sectionFunc returns the function whose body is given by sec,
+// creating it if necessary, or nil if sec is to be skipped.
+func (p *textParser) sectionFunc(sec *textSection) *Function {
+	if sec.sig.text == "" {
+		p.errorf("function %s has no signature", sec.name)
+	}
+
+	var fn *Function
+	switch {
+	case sec.parent != "":
+		fn = p.createAnon(sec)
+
+	case sec.synthetic == "package initializer":
+		fn = p.pkg.init
+
+	case p.funcs[sec.name] != nil && sec.synthetic == "":
+		fn = p.funcs[sec.name]
+
+	case strings.HasPrefix(sec.synthetic, "instance of "):
+		if p.prog.mode&InstantiateGenerics == 0 {
+			p.errorf("instance %s requires InstantiateGenerics mode", sec.name)
+		}
+		p.s, p.i = sec.name, 0
+		fn, _ = p.memberRef().(*Function)
+		if fn == nil || !p.atEnd() {
+			p.errorf("invalid instance %s", sec.name)
+		}
+		p.addFunc(fn)
+
+	case sec.synthetic != "":
+		return nil // created on demand
+
+	default:
+		name, ok := strings.CutPrefix(sec.name, p.pkg.Pkg.Path()+".")
+		m := initNameRE.FindStringSubmatch(name)
+		if !ok || m == nil {
+			p.errorf("function %s is not declared", sec.name)
+		}
+		if n, _ := strconv.Atoi(m[1]); n != int(p.pkg.ninit)+1 {
+			p.errorf("function %s is out of order", name)
+		}
+		p.pkg.ninit++
+		obj := types.NewFunc(token.NoPos, p.pkg.Pkg, "init", new(types.Signature))
+		fn = createFunction(p.prog, obj, name, nil, nil, "")
+		fn.Pkg = p.pkg
+		p.pkg.Members[name] = fn
+		p.pkg.objects[obj] = fn
+		p.pkg.created = append(p.pkg.created, fn)
+		p.b.enqueue(fn)
+		p.addFunc(fn)
+	}
+
+	if fn.Blocks != nil || p.filled[fn] {
+		p.errorf("function %s is defined more than once", fn)
+	}
+	p.filled[fn] = true
+	fn.Synthetic = sec.synthetic
+	fn.Params = nil
+	fn.build = nil // the body is built by parsing it
+
+	// Check the signature of functions that were not created from it.
+	if fn.parent == nil {
+		var buf bytes.Buffer
+		writeSignature(&buf, fn.relPkg(), fn.Name(), fn.Signature)
+		if got := strings.TrimSuffix(sec.sig.text, ":"); got != buf.String() {
+			p.line = sec.sig.line
+			p.errorf("signature %q of %s does not match its declaration %q", got, fn, buf.String())
+		}
+	}
+	return fn
+}
+
+// createAnon creates the anonymous function whose text is sec.
+func (p *textParser) createAnon(sec *textSection) *Function {
+	m := anonNameRE.FindStringSubmatch(sec.name)
+	if m == nil {
+		p.errorf("invalid name %s of anonymous function", sec.name)
+	}
+	parent := p.funcs[m[1]]
+	if parent == nil || !p.filled[parent] {
+		p.errorf("parent %s of %s must precede it", m[1], sec.name)
+	}
+	if n, _ := strconv.Atoi(m[2]); n != len(parent.AnonFuncs)+1 {
+		p.errorf("anonymous function %s is out of order", sec.name)
+	}
+
+	fn := &Function{
+		name:       fmt.Sprintf("%s$%d", parent.Name(), 1+len(parent.AnonFuncs)),
+		parent:     parent,
+		anonIdx:    int32(len(parent.AnonFuncs)),
+		Pkg:        parent.Pkg,
+		Prog:       p.prog,
+		typeparams: parent.typeparams,
+		typeargs:   parent.typeargs,
+	}
+	parent.AnonFuncs = append(parent.AnonFuncs, fn)
+	p.setFunc(fn)
+
+	// Parse the signature: func name(params) results:
+	p.line, p.s, p.i = sec.sig.line, strings.TrimSuffix(sec.sig.text, ":"), 0
+	p.expect("func " + fn.Name())
+	fn.Signature = p.signature(nil)
+	p.expectEnd()
+
+	for _, v := range sec.freeVars {
+		p.line, p.s, p.i = v.line, v.text, 0
+		name := p.name()
+		p.expect(" ")
+		fn.FreeVars = append(fn.FreeVars, &FreeVar{name: name, typ: p.typ(), parent: fn})
+		p.expectEnd()
+	}
+	p.addFunc(fn)
+	return fn
+}
+
+// setFunc makes fn the current function, whose type parameters are in
+// scope.
+func (p *textParser) setFunc(fn *Function) {
+	p.fn = fn
+	p.tparams = fn.typeparams
+	if fn.typeargs != nil {
+		p.tparams = nil // instance
+	}
+}
+
+// parseBody parses the body of the function of sec.
+func (p *textParser) parseBody(sec *textSection) {
+	fn := sec.fn
+	p.setFunc(fn)
+	p.localNames = make(map[string]bool)
+	p.locals = make(map[string]Value)
+	p.succs = make(map[*BasicBlock][]int)
+	p.phiPreds = make(map[*BasicBlock][]int)
+	p.fixups = nil
+	p.replace = make(map[Value]Value)
+	p.lines = make(map[Instruction]int)
+
+	// Declare the parameters and free variables.
+	if recv := fn.Signature.Recv(); recv != nil {
+		fn.addParamVar(recv)
+	}
+	params := fn.Signature.Params()
+	for i := range params.Len() {
+		fn.addParamVar(params.At(i))
+	}
+	for _, v := range fn.Params {
+		p.define(v.Name(), v)
+	}
+	for _, v := range fn.FreeVars {
+		p.define(v.Name(), v)
+	}
+	if len(fn.FreeVars) > 0 && fn.parent == nil {
+		p.errorf("function %s has free variables", fn)
+	}
+
+	if len(sec.body) == 1 && sec.body[0].text == "\t(external)" {
+		return
+	}
+	if len(sec.body) == 0 {
+		p.errorf("function %s has no body", fn)
+	}
+	for _, line := range sec.body {
+		if m := registerRE.FindStringSubmatch(line.text); m != nil {
+			p.localNames[m[1]] = true
+		}
+	}
+
+	// Parse the blocks.
+	var block *BasicBlock
+	for _, line := range sec.body {
+		p.line, p.s, p.i = line.line, line.text, 0
+		if m := blockLineRE.FindStringSubmatch(line.text); m != nil {
+			if index, _ := strconv.Atoi(m[1]); index != len(fn.Blocks) {
+				p.errorf("block %d is out of order", index)
+			}
+			block = fn.newBasicBlock(strings.TrimSpace(m[2]))
+			continue
+		}
+		if block == nil || !p.accept("\t") {
+			p.errorf("unexpected line %q", line.text)
+		}
+		instr := p.instr(block)
+		instr.setBlock(block)
+		block.Instrs = append(block.Instrs, instr)
+		p.lines[instr] = line.line
+	}
+
+	// Resolve the operands, then complete the instructions.
+	var rands []*Value
+	for _, b := range fn.Blocks {
+		for _, instr := range b.Instrs {
+			p.line = p.lines[instr]
+			for _, rand := range instr.Operands(rands[:0]) {
+				if u, ok := (*rand).(*unresolved); ok {
+					v, ok := p.locals[u.name]
+					if !ok {
+						p.errorf("undefined: %s", u.name)
+					}
+					*rand = v
+				}
+			}
+		}
+	}
+	for _, fixup := range p.fixups {
+		fixup()
+	}
+	if len(p.replace) > 0 {
+		for _, b := range fn.Blocks {
+			for i, instr := range b.Instrs {
+				if v, ok := instr.(Value); ok && p.replace[v] != nil {
+					b.Instrs[i] = p.replace[v].(Instruction)
+					instr = b.Instrs[i]
+				}
+				for _, rand := range instr.Operands(rands[:0]) {
+					if v := p.replace[*rand]; v != nil {
+						*rand = v
+					}
+				}
+			}
+		}
+	}
+	p.buildCFG(sec)
+
+	// Record the locals and the recover block.
+	for _, l := range sec.locals {
+		p.line = l.line
+		name, _, _ := strings.Cut(l.text, " ")
+		alloc, ok := p.locals[name].(*Alloc)
+		if !ok || alloc.Heap || alloc.Parent() != fn {
+			p.errorf("%s is not a local Alloc", name)
+		}
+		fn.Locals = append(fn.Locals, alloc)
+	}
+	if sec.recover != "" {
+		index, err := strconv.Atoi(sec.recover)
+		if err != nil || index < 0 || index >= len(fn.Blocks) {
+			p.line = sec.line
+			p.errorf("invalid recover block %s", sec.recover)
+		}
+		fn.Recover = fn.Blocks[index]
+	}
+
+	buildReferrers(fn)
+	buildDomTree(fn)
+	numberRegisters(fn)
+}
+
+// buildCFG sets the predecessors and successors of each block of the
+// current function. The order of the predecessors of a block is that
+// of the edges of its phis, if any; otherwise it is the order of the
+// blocks.
+func (p *textParser) buildCFG(sec *textSection) {
+	fn := p.fn
+	p.line = sec.line
+	block := func(index int) *BasicBlock {
+		if index < 0 || index >= len(fn.Blocks) {
+			p.errorf("invalid block %d", index)
+		}
+		return fn.Blocks[index]
+	}
+	for _, b := range fn.Blocks {
+		if len(p.succs[b]) > 0 {
+			p.line = p.lines[b.Instrs[len(b.Instrs)-1]]
+		}
+		for _, index := range p.succs[b] {
+			succ := block(index)
+			b.Succs = append(b.Succs, succ)
+			succ.Preds = append(succ.Preds, b)
+		}
+	}
+	for _, b := range fn.Blocks {
+		indices, ok := p.phiPreds[b]
+		if !ok {
+			continue
+		}
+		p.line = p.lines[b.Instrs[0]]
+		var preds []*BasicBlock
+		for _, index := range indices {
+			preds = append(preds, block(index))
+		}
+		for _, pred := range b.Preds {
+			i := 0
+			for i < len(preds) && preds[i] != pred {
+				i++
+			}
+			if i == len(preds) || len(preds) != len(b.Preds) {
+				p.errorf("phi edges of block %d do not match its predecessors", b.Index)
+			}
+		}
+		b.Preds = preds
+	}
+}
+
+// define defines a local value.
+func (p *textParser) define(name string, v Value) {
+	if _, ok := p.locals[name]; ok {
+		p.errorf("%s is defined more than once", name)
+	}
+	p.localNames[name] = true
+	p.locals[name] = v
+}
+
+// An unresolved value is a reference to a local value of the current
+// function, which may not yet be defined.
+type unresolved struct{ name string }
+
+func (u *unresolved) Name() string              { return u.name }
+func (u *unresolved) String() string            { return u.name }
+func (u *unresolved) Type() types.Type          { return nil }
+func (u *unresolved) Parent() *Function         { return nil }
+func (u *unresolved) Referrers() *[]Instruction { return nil }
+func (u *unresolved) Pos() token.Pos            { return token.NoPos }
+
+// fixup records an action to complete an instruction on the
+// specified line once its operands are resolved.
+func (p *textParser) fixup(line int, action func()) {
+	p.fixups = append(p.fixups, func() {
+		p.line = line
+		action()
+	})
+}
+
+// -- instructions --
+
+// instr parses the instruction on the current line.
+func (p *textParser) instr(block *BasicBlock) Instruction {
+	if m := registerRE.FindStringSubmatch("\t" + p.rest()); m != nil {
+		return p.valueLine(m[1])
+	}
+
+	switch {
+	case p.keyword("jump"):
+		p.succs[block] = []int{p.int()}
+		p.expectEnd()
+		return new(Jump)
+
+	case p.keyword("if"):
+		v := &If{Cond: p.operand()}
+		p.expect(" goto ")
+		t := p.int()
+		p.expect(" else ")
+		p.succs[block] = []int{t, p.int()}
+		p.expectEnd()
+		return v
+
+	case p.accept("return"):
+		v := new(Return)
+		for p.accept(" ") || (len(v.Results) > 0 && p.accept(", ")) {
+			v.Results = append(v.Results, p.operand())
+		}
+		p.expectEnd()
+		return v
+
+	case p.accept("rundefers"):
+		p.expectEnd()
+		return new(RunDefers)
+
+	case p.keyword("panic"):
+		v := &Panic{X: p.operand()}
+		p.expectEnd()
+		return v
+
+	case p.keyword("go"):
+		v := new(Go)
+		p.call(&v.Call, nil)
+		p.expectEnd()
+		return v
+
+	case p.keyword("defer"):
+		v := new(Defer)
+		if p.accept("[") {
+			v.DeferStack = p.operand()
+			p.expect("] ")
+		}
+		p.call(&v.Call, nil)
+		p.expectEnd()
+		return v
+
+	case p.keyword("send"):
+		v := &Send{Chan: p.operand()}
+		p.expect(" <- ")
+		v.X = p.operand()
+		p.expectEnd()
+		return v
+
+	case p.peek(";"):
+		p.errorf("DebugRef instructions are not supported")
+
+	case p.accept("*"):
+		v := &Store{Addr: p.operand()}
+		p.expect(" = ")
+		v.Val = p.operand()
+		p.expectEnd()
+		return v
+	}
+
+	v := &MapUpdate{Map: p.operand()}
+	p.expect("[")
+	v.Key = p.operand()
+	p.expect("] = ")
+	v.Value = p.operand()
+	p.expectEnd()
+	return v
+}
+
+// valueLine parses the value-defining instruction on the current
+// line, which defines the named register.
+//
+// The type of the value follows the instruction, separated by a run
+// of spaces. Since both may contain spaces, valueLine tries each run,
+// preferring longer runs (which separate right-aligned types) and then
+// later ones, until the rest of the line is a type and the part before
+// it is an instruction.
+func (p *textParser) valueLine(name string) Instruction {
+	start := p.i + len(name) + len(" = ")
+	text := p.s
+	type run struct{ start, end int }
+	var long, short []run
+	for i := len(text) - 1; i > start; i-- {
+		if text[i] != ' ' {
+			continue
+		}
+		j := i
+		for j > start && text[j-1] == ' ' {
+			j--
+		}
+		if i-j+1 > 1 {
+			long = append(long, run{j, i + 1})
+		} else {
+			short = append(short, run{j, i + 1})
+		}
+		i = j
+	}
+
+	var first *parseError
+	for _, r := range append(long, short...) {
+		var instr Instruction
+		err := p.try(func() {
+			// Parse the type, then the instruction.
+			p.s, p.i = text[r.end:], 0
+			typ := p.typ()
+			p.expectEnd()
+			p.s, p.i = text[:r.start], start
+			instr = p.value(typ)
+			p.expectEnd()
+		})
+		if err == nil {
+			p.s, p.i = text, len(text)
+			p.define(name, instr.(Value))
+			return instr
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	if first == nil {
+		p.errorf("value has no type")
+	}
+	panic(first)
+}
+
+// try calls f, returning the error it reports, if any. On error, it
+// restores the current line.
+func (p *textParser) try(f func()) (err *parseError) {
+	s, i, nfixups := p.s, p.i, len(p.fixups)
+	defer func() {
+		if r := recover(); r != nil {
+			perr, ok := r.(*parseError)
+			if !ok {
+				panic(r)
+			}
+			p.s, p.i, p.fixups = s, i, p.fixups[:nfixups]
+			err = perr
+		}
+	}()
+	f()
+	return nil
+}
+
+// value parses a value-defining instruction whose value has the
+// specified type.
+func (p *textParser) value(typ types.Type) Instruction {
+	line, start := p.line, p.i
+	var v interface {
+		Instruction
+		Value
+		setType(types.Type)
+	}
+	switch {
+	case p.keyword("local"), p.keyword("new"):
+		alloc := &Alloc{Heap: strings.HasPrefix(p.s[start:], "new ")}
+		p.typ()
+		p.expect(" (")
+		if !strings.HasSuffix(p.s, ")") || p.i > len(p.s)-1 {
+			p.errorf("invalid Alloc")
+		}
+		alloc.Comment = p.s[p.i : len(p.s)-1]
+		p.i = len(p.s)
+		if _, ok := typeparams.CoreType(typ).(*types.Pointer); !ok {
+			p.errorf("type of Alloc is not a pointer")
+		}
+		v = alloc
+
+	case p.keyword("phi"):
+		phi := new(Phi)
+		p.expect("[")
+		var preds []int
+		for !p.accept("]") {
+			if len(phi.Edges) > 0 {
+				p.expect(", ")
+			}
+			preds = append(preds, p.int())
+			p.expect(": ")
+			phi.Edges = append(phi.Edges, p.operand())
+		}
+		if p.accept(" #") {
+			phi.Comment = p.rest()
+			p.i = len(p.s)
+		}
+		block := p.fn.Blocks[len(p.fn.Blocks)-1]
+		if _, ok := p.phiPreds[block]; !ok {
+			p.phiPreds[block] = preds
+		} else if !slices.Equal(p.phiPreds[block], preds) {
+			p.errorf("phi edges of block %d do not match", block.Index)
+		}
+		v = phi
+
+	case p.keyword("changetype"):
+		c := &ChangeType{}
+		c.X = p.conv()
+		v = c
+
+	case p.keyword("convert"):
+		c := &Convert{}
+		c.X = p.conv()
+		v = c
+
+	case p.keyword("change interface"):
+		c := &ChangeInterface{}
+		c.X = p.conv()
+		v = c
+
+	case p.keyword("slice to array pointer"):
+		c := &SliceToArrayPointer{}
+		c.X = p.conv()
+		v = c
+
+	case p.keyword("multiconvert"):
+		c := &MultiConvert{}
+		c.X = p.conv()
+		p.expect(" [")
+		if !strings.HasSuffix(p.s, "]") {
+			p.errorf("invalid multiconvert")
+		}
+		p.i = len(p.s)
+		p.fixup(line, func() {
+			c.from = c.X.Type()
+			c.to = typ
+		})
+		v = c
+
+	case p.keyword("make"):
+		if p.accept("closure ") {
+			c := &MakeClosure{Fn: p.operand()}
+			if _, ok := c.Fn.(*Function); !ok {
+				p.errorf("closure of non-function %s", c.Fn.Name())
+			}
+			if p.accept(" [") {
+				c.Bindings = []Value{}
+				for !p.accept("]") {
+					if len(c.Bindings) > 0 {
+						p.expect(", ")
+					}
+					c.Bindings = append(c.Bindings, p.operand())
+				}
+			}
+			v = c
+			break
+		}
+		tstart := p.i
+		t := p.typ()
+		if p.peek(" <- ") {
+			p.i = tstart // reparse as a conversion
+			c := &MakeInterface{}
+			c.X = p.conv()
+			v = c
+			break
+		}
+		switch typeparams.CoreType(t).(type) {
+		case *types.Slice:
+			c := &MakeSlice{}
+			p.expect(" ")
+			c.Len = p.operand()
+			p.expect(" ")
+			c.Cap = p.operand()
+			v = c
+		case *types.Map:
+			c := &MakeMap{}
+			if p.accept(" ") && !p.atEnd() {
+				c.Reserve = p.operand()
+			}
+			v = c
+		case *types.Chan:
+			c := &MakeChan{}
+			p.expect(" ")
+			c.Size = p.operand()
+			v = c
+		default:
+			p.errorf("invalid make of %s", t)
+		}
+
+	case p.keyword("slice"):
+		s := &Slice{X: p.operand()}
+		p.expect("[")
+		if !p.peek(":") {
+			s.Low = p.operand()
+		}
+		p.expect(":")
+		if !p.peek("]") && !p.peek(":") {
+			s.High = p.operand()
+		}
+		if p.accept(":") {
+			s.Max = p.operand()
+		}
+		p.expect("]")
+		v = s
+
+	case p.keyword("range"):
+		v = &Range{X: p.operand()}
+
+	case p.keyword("next"):
+		n := &Next{Iter: p.operand()}
+		p.fixup(line, func() {
+			rng, ok := n.Iter.(*Range)
+			if !ok {
+				p.errorf("operand of next is not a range")
+			}
+			n.IsString = isBasic(typeparams.CoreType(rng.X.Type()))
+		})
+		v = n
+
+	case p.keyword("typeassert"), p.peek("typeassert,ok "):
+		a := &TypeAssert{CommaOk: p.accept("typeassert,ok ")}
+		a.X = p.operand()
+		p.expect(".(")
+		a.AssertedType = p.typ()
+		p.expect(")")
+		v = a
+
+	case p.keyword("extract"):
+		e := &Extract{Tuple: p.operand()}
+		p.expect(" #")
+		e.Index = p.int()
+		v = e
+
+	case p.keyword("select"):
+		s := new(Select)
+		if p.accept("non") {
+			p.expect("blocking [")
+		} else {
+			p.expect("blocking [")
+			s.Blocking = true
+		}
+		for !p.accept("]") {
+			if len(s.States) > 0 {
+				p.expect(", ")
+			}
+			st := &SelectState{Dir: types.RecvOnly}
+			if p.accept("<-") {
+				st.Chan = p.operand()
+			} else {
+				st.Dir = types.SendOnly
+				st.Chan = p.operand()
+				p.expect("<-")
+				st.Send = p.operand()
+			}
+			s.States = append(s.States, st)
+		}
+		v = s
+
+	case p.keyword("invoke"):
+		c := new(Call)
+		p.i -= len("invoke ")
+		p.call(&c.Call, typ)
+		v = c
+
+	case p.accept("&"):
+		x := p.operand()
+		if p.accept(".") {
+			f := &FieldAddr{X: x}
+			f.Field = p.field()
+			v = f
+		} else {
+			p.expect("[")
+			a := &IndexAddr{X: x, Index: p.operand()}
+			p.expect("]")
+			v = a
+		}
+
+	default:
+		// A unary operation, or an operation on a leading operand.
+		var u *UnOp
+		if p.try(func() {
+			op, ok := unOps[p.s[p.i]]
+			if p.accept("<-") {
+				op, ok = token.ARROW, true
+			} else if ok {
+				p.i++
+			}
+			if !ok {
+				p.errorf("not a unary operation")
+			}
+			u = &UnOp{Op: op, X: p.operand(), CommaOk: p.accept(",ok")}
+			p.expectEnd()
+		}) == nil {
+			v = u
+			break
+		}
+
+		x := p.operand()
+		switch {
+		case p.peek("("):
+			c := new(Call)
+			p.callArgs(&c.Call, x, typ)
+			v = c
+		case p.accept("."):
+			f := &Field{X: x}
+			f.Field = p.field()
+			v = f
+		case p.accept("["):
+			index := p.operand()
+			p.expect("]")
+			if p.accept(",ok") {
+				v = &Lookup{X: x, Index: index, CommaOk: true}
+				break
+			}
+			// Index or Lookup, depending on the type of x.
+			idx := &Index{X: x, Index: index}
+			idx.setType(typ)
+			p.fixup(line, func() {
+				if _, ok := typeparams.CoreType(idx.X.Type()).(*types.Map); ok {
+					l := &Lookup{X: idx.X, Index: idx.Index}
+					l.setType(typ)
+					p.replace[idx] = l
+				}
+			})
+			v = idx
+		default:
+			p.expect(" ")
+			op, _, _ := strings.Cut(p.rest(), " ")
+			tok, ok := binOps[op]
+			if !ok {
+				p.errorf("unexpected %q", p.rest())
+			}
+			p.i += len(op)
+			p.expect(" ")
+			v = &BinOp{Op: tok, X: x, Y: p.operand()}
+		}
+	}
+	v.setType(typ)
+	return v
+}
+
+// unOps and binOps map operator strings to tokens.
+var (
+	unOps = map[byte]token.Token{
+		'!': token.NOT,
+		'-': token.SUB,
+		'^': token.XOR,
+		'*': token.MUL,
+	}
+	binOps = func() map[string]token.Token {
+		ops := make(map[string]token.Token)
+		for _, tok := range []token.Token{
+			token.ADD, token.SUB, token.MUL, token.QUO, token.REM,
+			token.AND, token.OR, token.XOR, token.SHL, token.SHR, token.AND_NOT,
+			token.EQL, token.NEQ, token.LSS, token.LEQ, token.GTR, token.GEQ,
+		} {
+			ops[tok.String()] = tok
+		}
+		return ops
+	}()
+)
+
+// conv parses the rest of a conversion, "T <- U (x)", and returns x.
+func (p *textParser) conv() Value {
+	p.typ()
+	p.expect(" <- ")
+	p.typ()
+	p.expect(" (")
+	x := p.operand()
+	p.expect(")")
+	return x
+}
+
+// field parses the rest of a field selection, "f [#i]", and returns i.
+func (p *textParser) field() int {
+	i := strings.Index(p.s[p.i:], " [#")
+	if i < 0 {
+		p.errorf("invalid field selection")
+	}
+	p.i += i + len(" [#")
+	index := p.int()
+	p.expect("]")
+	return index
+}
+
+// call parses a call, "f(args)" or "invoke x.m(args)", into c. The
+// type of the result is typ, or nil for go and defer.
+func (p *textParser) call(c *CallCommon, typ types.Type) {
+	if p.accept("invoke ") {
+		recv := p.operand()
+		p.expect(".")
+		name := p.name()
+		p.fixup(p.line, func() {
+			if iface, ok := c.Value.Type().Underlying().(*types.Interface); ok {
+				for i := range iface.NumMethods() {
+					if m := iface.Method(i); m.Name() == name {
+						c.Method = m
+					}
+				}
+			}
+			if c.Method == nil {
+				p.errorf("invalid invoke of %s.%s", c.Value.Name(), name)
+			}
+		})
+		p.callArgs(c, recv, typ)
+		return
+	}
+	p.callArgs(c, p.operand(), typ)
+}
+
+// callArgs parses the arguments of a call, "(args)", of the specified
+// function value into c.
+func (p *textParser) callArgs(c *CallCommon, fn Value, typ types.Type) {
+	c.Value = fn
+	p.expect("(")
+	variadic := false
+	for !p.accept(")") {
+		if len(c.Args) > 0 {
+			p.expect(", ")
+		}
+		c.Args = append(c.Args, p.operand())
+		if p.accept("...") {
+			variadic = true
+			p.expect(")")
+			break
+		}
+	}
+
+	// The signature of a builtin depends on the call.
+	if b, ok := fn.(*Builtin); ok && b.sig == nil {
+		p.fixup(p.line, func() {
+			var params []*types.Var
+			for _, arg := range c.Args {
+				params = append(params, anonVar(arg.Type()))
+			}
+			var results *types.Tuple
+			if tuple, ok := typ.(*types.Tuple); ok {
+				results = tuple
+			} else if typ != nil {
+				results = types.NewTuple(anonVar(typ))
+			}
+			b.sig = types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), results, variadic)
+		})
+	}
+}
+
+// -- operands --
+
+// operand parses an operand of an instruction.
+func (p *textParser) operand() Value {
+	rest := p.rest()
+	if rest == "" {
+		p.errorf("missing operand")
+	}
+	switch c := rest[0]; {
+	case c == '"' || c == '[' || isDigit(c) || strings.HasPrefix(rest, "*new("):
+		return p.constant()
+	case (c == '-' || c == '+' || c == '(') && len(rest) > 1 && (isDigit(rest[1]) || rest[1] == '-' || rest[1] == '.'):
+		return p.constant()
+	case c == '(':
+		return p.memberRef()
+	case strings.HasPrefix(rest, "ssa:"):
+		p.i += len("ssa:")
+		name := "ssa:" + p.name()
+		if name == vDeferStack.Name() {
+			return vDeferStack
+		}
+		return &Builtin{name: name}
+	}
+
+	start := p.i
+	name := p.name()
+	if name == "" {
+		p.errorf("unexpected %q", rest)
+	}
+	if p.localNames[name] {
+		if v := p.locals[name]; v != nil {
+			return v
+		}
+		return &unresolved{name}
+	}
+	if (name == "true" || name == "false" || name == "nil") && p.peek(":") {
+		p.i = start
+		return p.constant()
+	}
+
+	// The zero value of a struct or array type?
+	p.i = start
+	if p.try(func() {
+		p.typ()
+		if !p.peek("{}:") {
+			p.errorf("not a composite zero value")
+		}
+	}) == nil {
+		p.i = start
+		return p.constant()
+	}
+
+	return p.memberRef()
+}
+
+// memberRef parses a reference to a function or global, or to a
+// builtin function.
+func (p *textParser) memberRef() Value {
+	start := p.i
+
+	// Method: (T).m, (T).m$bound, (T).m$thunk, (T).m[targs]
+	if p.accept("(") {
+		recv := p.typ()
+		p.expect(").")
+		name := p.name()
+		if fn := p.funcs[p.s[start:p.i]]; fn != nil {
+			return fn
+		}
+		hasTargs := p.peek("[")
+		if hasTargs {
+			p.skipBrackets()
+			if p.peek("$") {
+				return p.anonRef(start)
+			}
+		}
+		return p.method(recv, name, hasTargs)
+	}
+
+	// [path.]name, possibly followed by type arguments.
+	end := p.i
+	for end < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[end:])
+		if !isNameRune(r) && !strings.ContainsRune("./-~+", r) {
+			break
+		}
+		end += size
+	}
+	for tok := p.s[start:end]; tok != ""; {
+		if v := p.member(tok); v != nil {
+			p.i = start + len(tok)
+			if fn, ok := v.(*Function); ok && fn.generic != nil && p.accept("[") {
+				var targs []types.Type
+				for !p.accept("]") {
+					if len(targs) > 0 {
+						p.expect(" ")
+					}
+					targs = append(targs, p.typ())
+				}
+				if p.peek("$") {
+					return p.anonRef(start)
+				}
+				return fn.instance(targs, &p.b)
+			}
+			return v
+		}
+		dot := strings.LastIndexByte(tok, '.')
+		if dot < 0 {
+			break
+		}
+		tok = tok[:dot]
+	}
+
+	// Builtin?
+	name := p.name()
+	if obj, ok := types.Universe.Lookup(name).(*types.Builtin); ok {
+		return &Builtin{name: obj.Name()}
+	}
+	if obj, ok := types.Unsafe.Scope().Lookup(name).(*types.Builtin); ok {
+		return &Builtin{name: obj.Name()}
+	}
+	p.errorf("undefined: %s", p.s[start:end])
+	panic("unreachable")
+}
+
+// anonRef returns the parsed anonymous function of an instance, whose
+// name starts at start and continues at the current "$".
+func (p *textParser) anonRef(start int) *Function {
+	p.name()
+	name := p.s[start:p.i]
+	fn := p.funcs[name]
+	if fn == nil {
+		p.errorf("undefined: %s", name)
+	}
+	return fn
+}
+
+// member returns the function or global denoted by a possibly
+// qualified name, or nil if there is none.
+func (p *textParser) member(name string) Value {
+	if fn := p.funcs[name]; fn != nil {
+		return fn
+	}
+	pkg := p.pkg
+	if slash := strings.LastIndexByte(name, '/'); slash >= 0 || strings.Contains(name, ".") {
+		pkg = nil
+		for i := max(slash, 0); i < len(name); i++ {
+			if name[i] != '.' {
+				continue
+			}
+			if tpkg := p.prog.packageByPath(name[:i]); tpkg != nil {
+				pkg = p.prog.packages[tpkg]
+				name = name[i+1:]
+				break
+			}
+		}
+		if pkg == nil {
+			return nil
+		}
+		if pkg != p.pkg {
+			p.refs[pkg.Pkg] = true
+		}
+	}
+	switch mem := pkg.Members[name].(type) {
+	case *Function:
+		return mem
+	case *Global:
+		return mem
+	}
+	return nil
+}
+
+// method returns the function denoted by method name of type recv,
+// which may have a $bound or $thunk suffix, and type arguments.
+func (p *textParser) method(recv types.Type, name string, hasTargs bool) *Function {
+	base := strings.TrimSuffix(strings.TrimSuffix(name, "$bound"), "$thunk")
+	var sel *types.Selection
+	mset := p.prog.MethodSets.MethodSet(recv)
+	for i := range mset.Len() {
+		if s := mset.At(i); s.Obj().Name() == base {
+			sel = s
+		}
+	}
+	if sel == nil {
+		p.errorf("type %s has no method %s", recv, base)
+	}
+	obj := sel.Obj().(*types.Func)
+	if pkg := obj.Pkg(); pkg != nil && pkg != p.pkg.Pkg {
+		p.refs[pkg] = true
+	}
+
+	switch {
+	case strings.HasSuffix(name, "$bound"):
+		fn := p.bounds[obj]
+		if fn == nil {
+			fn = createBound(p.prog, obj)
+			p.b.enqueue(fn)
+			p.bounds[obj] = fn
+		}
+		return fn
+
+	case strings.HasSuffix(name, "$thunk"):
+		key := types.TypeString(recv, nil) + "." + name
+		fn := p.thunks[key]
+		if fn == nil {
+			msig := sel.Type().(*types.Signature)
+			params := []*types.Var{anonVar(recv)}
+			for i := range msig.Params().Len() {
+				params = append(params, msig.Params().At(i))
+			}
+			fn = createThunk(p.prog, &selection{
+				kind:     types.MethodExpr,
+				recv:     recv,
+				typ:      types.NewSignatureType(nil, nil, nil, types.NewTuple(params...), msig.Results(), msig.Variadic()),
+				obj:      obj,
+				index:    sel.Index(),
+				indirect: sel.Indirect(),
+			})
+			p.b.enqueue(fn)
+			p.thunks[key] = fn
+		}
+		return fn
+
+	case !types.IsInterface(recv) && !p.prog.isParameterized(recv):
+		return p.prog.MethodValue(sel)
+
+	case hasTargs:
+		return p.prog.objectMethod(obj, &p.b)
+	}
+	if fn := p.prog.FuncValue(obj.Origin()); fn != nil {
+		return fn
+	}
+	p.errorf("invalid reference to method %s of %s", name, recv)
+	panic("unreachable")
+}
+
+// constant parses a constant, "value:type".
+func (p *textParser) constant() *Const {
+	rest := p.rest()
+	var lit string
+	switch {
+	case rest[0] == '"':
+		q, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			p.errorf("invalid string constant")
+		}
+		lit = q
+	case rest[0] == '(':
+		end := strings.IndexByte(rest, ')')
+		if end < 0 {
+			p.errorf("invalid complex constant")
+		}
+		lit = rest[:end+1]
+	default:
+		// Scan to the colon, skipping brackets and strings.
+		depth := 0
+		for i := 0; i < len(rest) && lit == ""; i++ {
+			switch rest[i] {
+			case '(', '[', '{':
+				depth++
+			case ')', ']', '}':
+				depth--
+			case '"', '`':
+				q, err := strconv.QuotedPrefix(rest[i:])
+				if err == nil {
+					i += len(q) - 1
+				}
+			case ':':
+				if depth == 0 {
+					lit = rest[:i]
+				}
+			}
+		}
+	}
+	p.i += len(lit)
+	p.expect(":")
+	typ := p.typ()
+
+	var val constant.Value
+	switch {
+	case lit == "nil" || strings.HasSuffix(lit, "{}") || strings.HasPrefix(lit, "*new("):
+		// zero value
+	case lit == "true" || lit == "false":
+		val = constant.MakeBool(lit == "true")
+	case lit[0] == '"':
+		s, _ := strconv.Unquote(lit)
+		val = constant.MakeString(s)
+	case lit[0] == '(':
+		re, im, ok := strings.Cut(lit[1:len(lit)-1], " + ")
+		if !ok || !strings.HasSuffix(im, "i") {
+			p.errorf("invalid complex constant %s", lit)
+		}
+		val = constant.BinaryOp(numberLit(re), token.ADD, constant.MakeImag(numberLit(strings.TrimSuffix(im, "i"))))
+	default:
+		val = numberLit(lit)
+	}
+	if val != nil && val.Kind() == constant.Unknown {
+		p.errorf("invalid constant %s", lit)
+	}
+
+	if val != nil && val.Kind() != constant.Bool && val.Kind() != constant.String {
+		if t, ok := typeparams.CoreType(typ).(*types.Basic); ok {
+			switch info := t.Info(); {
+			case info&types.IsInteger != 0:
+				val = constant.ToInt(val)
+			case info&types.IsFloat != 0:
+				val = constant.ToFloat(val)
+			case info&types.IsComplex != 0:
+				val = constant.ToComplex(val)
+			}
+		}
+	}
+	return NewConst(val, typ)
+}
+
+// numberLit returns the value of a possibly signed numeric literal,
+// or of a fraction of two such literals.
+func numberLit(lit string) constant.Value {
+	if num, den, ok := strings.Cut(lit, "/"); ok {
+		return constant.BinaryOp(numberLit(num), token.QUO, numberLit(den))
+	}
+	neg := strings.HasPrefix(lit, "-")
+	lit = strings.TrimLeft(lit, "+-")
+	tok := token.INT
+	if strings.HasPrefix(lit, "0x") {
+		if strings.ContainsAny(lit, "pP") {
+			tok = token.FLOAT
+		}
+	} else if strings.ContainsAny(lit, ".eE") {
+		tok = token.FLOAT
+	}
+	val := constant.MakeFromLiteral(lit, tok, 0)
+	if neg {
+		val = constant.UnaryOp(token.SUB, val, 0)
+	}
+	return val
+}
+
+// -- types --
+
+// typ parses a type, in the form printed by types.TypeString relative
+// to the package of the current function.
+func (p *textParser) typ() types.Type {
+	switch {
+	case p.accept("*"):
+		elem := p.typ()
+		if elem == tDeferStack.Elem() {
+			return tDeferStack
+		}
+		return types.NewPointer(elem)
+
+	case p.accept("[]"):
+		return types.NewSlice(p.typ())
+
+	case p.accept("["):
+		n := p.int()
+		p.expect("]")
+		return types.NewArray(p.typ(), int64(n))
+
+	case p.accept("map["):
+		key := p.typ()
+		p.expect("]")
+		return types.NewMap(key, p.typ())
+
+	case p.accept("chan<- "):
+		return types.NewChan(types.SendOnly, p.typ())
+
+	case p.accept("<-chan "):
+		return types.NewChan(types.RecvOnly, p.typ())
+
+	case p.accept("chan "):
+		if p.accept("(") {
+			elem := p.typ()
+			p.expect(")")
+			return types.NewChan(types.SendRecv, elem)
+		}
+		return types.NewChan(types.SendRecv, p.typ())
+
+	case p.accept("func("):
+		p.i--
+		return p.signature(nil)
+
+	case p.accept("struct{"):
+		var fields []*types.Var
+		var tags []string
+		for !p.accept("}") {
+			if len(fields) > 0 {
+				p.expect("; ")
+			}
+			// Embedded field, or name and type?
+			var field *types.Var
+			start := p.i
+			name := p.name()
+			if name != "" && p.accept(" ") && !p.peek("\"") {
+				field = types.NewField(token.NoPos, p.pkg.Pkg, name, p.typ(), false)
+			} else {
+				p.i = start
+				t := p.typ()
+				field = types.NewField(token.NoPos, p.pkg.Pkg, embeddedName(t), t, true)
+			}
+			tag := ""
+			if p.accept(" ") {
+				q, err := strconv.QuotedPrefix(p.rest())
+				if err != nil {
+					p.errorf("invalid struct tag")
+				}
+				p.i += len(q)
+				tag, _ = strconv.Unquote(q)
+			}
+			fields = append(fields, field)
+			tags = append(tags, tag)
+		}
+		return types.NewStruct(fields, tags)
+
+	case p.accept("interface{"):
+		var methods []*types.Func
+		var embeddeds []types.Type
+		for !p.accept("}") {
+			if len(methods)+len(embeddeds) > 0 {
+				p.expect("; ")
+			}
+			start := p.i
+			name := p.name()
+			if name != "" && p.peek("(") {
+				sig := p.signature(nil)
+				methods = append(methods, types.NewFunc(token.NoPos, p.pkg.Pkg, name, sig))
+				continue
+			}
+			p.i = start
+			var terms []*types.Term
+			for len(terms) == 0 || p.accept(" | ") {
+				tilde := p.accept("~")
+				terms = append(terms, types.NewTerm(tilde, p.typ()))
+			}
+			if len(terms) == 1 && !terms[0].Tilde() {
+				embeddeds = append(embeddeds, terms[0].Type())
+			} else {
+				embeddeds = append(embeddeds, types.NewUnion(terms))
+			}
+		}
+		return types.NewInterfaceType(methods, embeddeds).Complete()
+
+	case p.peek("("):
+		return p.tuple()
+
+	case p.accept("untyped "):
+		name := p.name()
+		for _, t := range types.Typ {
+			if t.Name() == "untyped "+name {
+				return t
+			}
+		}
+		p.errorf("invalid type untyped %s", name)
+	}
+	return p.namedType()
+}
+
+// namedType parses a possibly qualified type name, and its type
+// arguments, if any.
+func (p *textParser) namedType() types.Type {
+	start := p.i
+	end := p.i
+	for end < len(p.s) {
+		r, size := utf8.DecodeRuneInString(p.s[end:])
+		if !isNameRune(r) && !strings.ContainsRune("./-~+", r) {
+			break
+		}
+		end += size
+	}
+	qname := p.s[start:end]
+	if qname == "" {
+		p.errorf("missing type")
+	}
+	p.i = end
+
+	var obj types.Object
+	if dot := strings.LastIndexByte(qname, '.'); dot >= 0 {
+		path, name := qname[:dot], qname[dot+1:]
+		var pkg *types.Package
+		if path == "unsafe" {
+			pkg = types.Unsafe
+		} else {
+			pkg = p.prog.packageByPath(path)
+		}
+		if pkg == nil {
+			p.errorf("package %q has not been created", path)
+		}
+		if pkg != p.pkg.Pkg && pkg != types.Unsafe {
+			p.refs[pkg] = true
+		}
+		obj = pkg.Scope().Lookup(name)
+	} else {
+		for i := range p.tparams.Len() {
+			if tparam := p.tparams.At(i); tparam.Obj().Name() == qname {
+				return tparam
+			}
+		}
+		obj = p.pkg.Pkg.Scope().Lookup(qname)
+		if obj == nil {
+			obj = types.Universe.Lookup(qname)
+		}
+		switch qname {
+		case "iter":
+			if obj == nil {
+				return tRangeIter
+			}
+		case "deferStack":
+			if obj == nil {
+				return tDeferStack.Elem()
+			}
+		case "invalid":
+			if obj == nil && p.accept(" type") {
+				return types.Typ[types.Invalid]
+			}
+		}
+	}
+	tname, ok := obj.(*types.TypeName)
+	if !ok {
+		p.errorf("%s is not a type", qname)
+	}
+	if !p.accept("[") {
+		return tname.Type()
+	}
+	var targs []types.Type
+	for !p.accept("]") {
+		if len(targs) > 0 {
+			p.expect(", ")
+		}
+		targs = append(targs, p.typ())
+	}
+	t, err := types.Instantiate(p.prog.ctxt, tname.Type(), targs, false)
+	if err != nil {
+		p.errorf("%v", err)
+	}
+	return t
+}
+
+// signature parses the rest of a function type: "(params) results".
+func (p *textParser) signature(recv *types.Var) *types.Signature {
+	params, variadic := p.params()
+	var results *types.Tuple
+	if p.peek(" (") {
+		p.i++
+		results, _ = p.params()
+	} else if p.peek(" ") {
+		// A result type, unless the space separates something else.
+		start := p.i
+		if p.try(func() {
+			p.i++
+			results = types.NewTuple(anonVar(p.typ()))
+		}) != nil {
+			p.i = start
+		}
+	}
+	return types.NewSignatureType(recv, nil, nil, params, results, variadic)
+}
+
+// tuple parses a tuple type.
+func (p *textParser) tuple() *types.Tuple {
+	t, variadic := p.params()
+	if variadic {
+		p.errorf("invalid tuple")
+	}
+	return t
+}
+
+// params parses a parenthesized list of variables, each with a name
+// or not, reporting whether the last has the form ...T.
+func (p *textParser) params() (*types.Tuple, bool) {
+	p.expect("(")
+	var vars []*types.Var
+	variadic := false
+	for !p.accept(")") {
+		if len(vars) > 0 {
+			p.expect(", ")
+		}
+		if variadic {
+			p.errorf("invalid variadic parameter")
+		}
+		// Name and type, or type?
+		name := ""
+		start := p.i
+		if n := p.name(); n != "" && p.accept(" ") && !p.peek("|") {
+			name = n
+		} else {
+			p.i = start
+		}
+		variadic = p.accept("...")
+		t := p.typ()
+		if variadic {
+			t = types.NewSlice(t)
+		}
+		vars = append(vars, types.NewParam(token.NoPos, p.pkg.Pkg, name, t))
+	}
+	return types.NewTuple(vars...), variadic
+}
+
+// embeddedName returns the name of an embedded field of type t.
+func embeddedName(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	switch t := types.Unalias(t).(type) {
+	case *types.Named:
+		return t.Obj().Name()
+	case *types.Basic:
+		return t.Name()
+	}
+	return "?"
+}
+
+// -- lexical helpers --
+
+// rest returns the rest of the current line.
+func (p *textParser) rest() string { return p.s[p.i:] }
+
+// atEnd reports whether the current line is fully parsed.
+func (p *textParser) atEnd() bool { return p.i == len(p.s) }
+
+// expectEnd reports an error unless the current line is fully parsed.
+func (p *textParser) expectEnd() {
+	if !p.atEnd() {
+		p.errorf("unexpected %q", p.rest())
+	}
+}
+
+// peek reports whether the rest of the line begins with s.
+func (p *textParser) peek(s string) bool { return strings.HasPrefix(p.rest(), s) }
+
+// accept consumes s if the rest of the line begins with it.
+func (p *textParser) accept(s string) bool {
+	if p.peek(s) {
+		p.i += len(s)
+		return true
+	}
+	return false
+}
+
+// expect consumes s, or reports an error.
+func (p *textParser) expect(s string) {
+	if !p.accept(s) {
+		p.errorf("got %q, want %q", p.rest(), s)
+	}
+}
+
+// keyword consumes the keyword kw and the space that follows it if
+// the rest of the line begins with them, and they are not the operand
+// of a binary operation (which could be a parameter named kw).
+func (p *textParser) keyword(kw string) bool {
+	if !p.peek(kw + " ") {
+		return false
+	}
+	rest := p.s[p.i+len(kw)+1:]
+	if op, _, ok := strings.Cut(rest, " "); ok && binOps[op] != 0 {
+		return false
+	}
+	p.i += len(kw) + 1
+	return true
+}
+
+// int parses a decimal integer.
+func (p *textParser) int() int {
+	start := p.i
+	if p.peek("-") {
+		p.i++
+	}
+	for !p.atEnd() && isDigit(p.s[p.i]) {
+		p.i++
+	}
+	n, err := strconv.Atoi(p.s[start:p.i])
+	if err != nil {
+		p.errorf("invalid integer %q", p.s[start:p.i])
+	}
+	return n
+}
+
+// name parses a name, which may be empty. Names of values, unlike Go
+// identifiers, may contain '$' and '#', as in f$1 and init#1.
+func (p *textParser) name() string {
+	start := p.i
+	for !p.atEnd() {
+		r, size := utf8.DecodeRuneInString(p.rest())
+		if !isNameRune(r) {
+			break
+		}
+		p.i += size
+	}
+	return p.s[start:p.i]
+}
+
+// skipBrackets skips a bracketed list, such as type arguments.
+func (p *textParser) skipBrackets() {
+	depth := 0
+	for !p.atEnd() {
+		switch p.s[p.i] {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		}
+		p.i++
+		if depth == 0 {
+			return
+		}
+	}
+	p.errorf("unbalanced brackets")
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || r == '$' || r == '#' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
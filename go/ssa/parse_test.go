@@ -0,0 +1,213 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ssa_test
+
+import (
+	"bytes"
+	"flag"
+	"github.com/tinygo-org/tinygo/alt_go/constant"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/packages"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa"
+	"github.com/tinygo-org/tinygo/x-tools/go/ssa/ssautil"
+	"github.com/tinygo-org/tinygo/x-tools/internal/diff"
+	"github.com/tinygo-org/tinygo/x-tools/internal/testfiles"
+	"github.com/tinygo-org/tinygo/x-tools/txtar"
+)
+
+var update = flag.Bool("update", false, "update the golden SSA in testdata/text")
+
+// TestParseGolden checks the text of package example.com/p of each
+// testdata/text/*.txtar archive both ways: the text printed by
+// WritePackageText must match the archive's p.ssa file, and the
+// package parsed from that file must print the same text.
+//
+// Archives whose comment mentions InstantiateGenerics are built in
+// that mode.
+func TestParseGolden(t *testing.T) {
+	files, err := filepath.Glob("testdata/text/*.txtar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			ar, err := txtar.ParseFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mode := ssa.SanityCheckFunctions
+			if strings.Contains(string(ar.Comment), "InstantiateGenerics") {
+				mode |= ssa.InstantiateGenerics
+			}
+
+			// Build the package from source and print it.
+			pkgs := testfiles.LoadPackages(t, ar, "./p")
+			_, spkgs := ssautil.Packages(pkgs, mode)
+			spkgs[0].Build()
+			var buf bytes.Buffer
+			ssa.WritePackageText(&buf, spkgs[0])
+			got := buf.String()
+
+			var golden *txtar.File
+			for i := range ar.Files {
+				if ar.Files[i].Name == "p.ssa" {
+					golden = &ar.Files[i]
+				}
+			}
+			if golden == nil {
+				t.Fatal("no p.ssa file")
+			}
+			if *update {
+				golden.Data = []byte(got)
+				if err := os.WriteFile(file, txtar.Format(ar), 0666); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want := string(golden.Data)
+			if got != want {
+				t.Errorf("built package does not match p.ssa:\n%s", diff.Unified("p.ssa", "got", want, got))
+			}
+
+			// Parse the golden text in a new program containing the
+			// dependencies of the package, without bodies, and print it.
+			prog2 := ssa.NewProgram(token.NewFileSet(), mode)
+			var deps []*packages.Package
+			for _, dep := range pkgs[0].Imports {
+				deps = append(deps, dep)
+			}
+			packages.Visit(deps, nil, func(p *packages.Package) {
+				prog2.CreatePackage(p.Types, nil, nil, true)
+			})
+			p, err := ssa.ParsePackage(prog2, "example.com/p", "p.ssa", golden.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			buf.Reset()
+			ssa.WritePackageText(&buf, p)
+			if got := buf.String(); got != want {
+				t.Errorf("parsed package does not match p.ssa:\n%s", diff.Unified("p.ssa", "got", want, got))
+			}
+
+			// The text is exact, so the constants must be too.
+			built, parsed := constsOf(spkgs[0]), constsOf(p)
+			if len(built) != len(parsed) {
+				t.Fatalf("parsed package has %d constants, want %d", len(parsed), len(built))
+			}
+			for i, c := range built {
+				c2 := parsed[i]
+				if (c.Value == nil) != (c2.Value == nil) ||
+					c.Value != nil && (c.Value.Kind() != c2.Value.Kind() || !constant.Compare(c.Value, token.EQL, c2.Value)) {
+					t.Errorf("parsed constant %s, want %s", c2.Value.ExactString(), c.Value.ExactString())
+				}
+			}
+		})
+	}
+}
+
+// constsOf returns the constant operands of the package-level functions
+// of pkg and their anonymous functions, in order.
+func constsOf(pkg *ssa.Package) []*ssa.Const {
+	var names []string
+	for name, mem := range pkg.Members {
+		if _, ok := mem.(*ssa.Function); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var consts []*ssa.Const
+	var visit func(fn *ssa.Function)
+	visit = func(fn *ssa.Function) {
+		for _, b := range fn.Blocks {
+			for _, instr := range b.Instrs {
+				for _, op := range instr.Operands(nil) {
+					if c, ok := (*op).(*ssa.Const); ok {
+						consts = append(consts, c)
+					}
+				}
+			}
+		}
+		for _, anon := range fn.AnonFuncs {
+			visit(anon)
+		}
+	}
+	for _, name := range names {
+		visit(pkg.Func(name))
+	}
+	return consts
+}
+
+// TestParseSnippet parses a function written by hand, with arbitrary
+// register names and no block comments.
+func TestParseSnippet(t *testing.T) {
+	const src = `package p
+
+func max(x, y int) int
+
+# Name: example.com/p.max
+func max(x int, y int) int:
+0:                                                                       P:0 S:2
+	less = x < y                                                           bool
+	if less goto 1 else 2
+1:                                                                       P:1 S:1
+	jump 2
+2:                                                                       P:2 S:0
+	m = phi [0: x, 1: y]                                                    int
+	return m
+`
+	prog := ssa.NewProgram(token.NewFileSet(), ssa.SanityCheckFunctions)
+	p, err := ssa.ParsePackage(prog, "example.com/p", "p.ssa", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fn := p.Func("max")
+	if len(fn.Blocks) != 3 || len(fn.Params) != 2 {
+		t.Fatalf("got %d blocks and %d params, want 3 and 2", len(fn.Blocks), len(fn.Params))
+	}
+	phi, ok := fn.Blocks[2].Instrs[0].(*ssa.Phi)
+	if !ok {
+		t.Fatalf("got %T, want *ssa.Phi", fn.Blocks[2].Instrs[0])
+	}
+	if phi.Name() != "t1" || phi.Edges[0] != fn.Params[0] || phi.Edges[1] != fn.Params[1] {
+		t.Errorf("got %s = %s, want t1 = phi [0: x, 1: y]", phi.Name(), phi)
+	}
+	if refs := *fn.Params[1].Referrers(); len(refs) != 2 {
+		t.Errorf("y has %d referrers, want 2", len(refs))
+	}
+	if idom := fn.Blocks[2].Idom(); idom != fn.Blocks[0] {
+		t.Errorf("idom of block 2 is %v, want 0", idom)
+	}
+	if types.Identical(phi.Type(), types.Typ[types.Int]) != true {
+		t.Errorf("phi has type %s, want int", phi.Type())
+	}
+}
+
+// TestParseErrors checks the errors reported for invalid text.
+func TestParseErrors(t *testing.T) {
+	for _, test := range []struct {
+		body, want string
+	}{
+		{"\tt0 = x + y int\n\treturn t0", "p.ssa:6: undefined: y"},
+		{"\tt0 = x + 1:int\n\treturn t0", "p.ssa:6: 1 is not a type"},
+		{"\tt0 = x +++ 1:int int\n\treturn t0", `p.ssa:6: unexpected "+++ 1:int"`},
+		{"\tjump 1", "p.ssa:6: invalid block 1"},
+		{"\treturn", "p.ssa:3: invalid function"},
+		{"\t; x @ 1:1 is x\n\treturn x", "p.ssa:6: DebugRef instructions are not supported"},
+	} {
+		// The body starts on line 6.
+		src := "package p\nfunc f(x int) int\n# Name: example.com/p.f\nfunc f(x int) int:\n0:\n" + test.body + "\n"
+		prog := ssa.NewProgram(token.NewFileSet(), 0)
+		_, err := ssa.ParsePackage(prog, "example.com/p", "p.ssa", []byte(src))
+		if err == nil || !strings.HasPrefix(err.Error(), test.want) {
+			t.Errorf("parsing %q: got error %v, want %s", test.body, err, test.want)
+		}
+	}
+}
@@ -10,13 +10,18 @@ package ssa
 import (
 	"bytes"
 	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/constant"
 	"github.com/tinygo-org/tinygo/alt_go/types"
 	"io"
+	"math/big"
 	"reflect"
 	"sort"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/tinygo-org/tinygo/x-tools/go/types/typeutil"
+	"github.com/tinygo-org/tinygo/x-tools/internal/aliases"
 	"github.com/tinygo-org/tinygo/x-tools/internal/typeparams"
 )
 
@@ -39,6 +44,32 @@ func relName(v Value, i Instruction) string {
 	return v.Name()
 }
 
+// A namer returns the name of operand v of instruction i, as printed
+// by the format method of i. String uses relName; the text written by
+// WritePackageText uses exactName.
+type namer func(v Value, i Instruction) string
+
+// exactName is like relName, but prints constants exactly.
+func exactName(v Value, i Instruction) string {
+	if c, ok := v.(*Const); ok {
+		var from *types.Package
+		if i != nil {
+			from = i.Parent().relPkg()
+		}
+		return c.exactString(from)
+	}
+	return relName(v, i)
+}
+
+// instrString returns instr.String(), but with constants printed
+// exactly if exact is set.
+func instrString(instr Instruction, exact bool) string {
+	if instr, ok := instr.(interface{ format(namer) string }); ok && exact {
+		return instr.format(exactName)
+	}
+	return instr.String()
+}
+
 func relType(t types.Type, from *types.Package) string {
 	return types.TypeString(t, types.RelativeTo(from))
 }
@@ -90,7 +121,8 @@ func (v *Alloc) String() string {
 	return fmt.Sprintf("%s %s (%s)", op, relType(typeparams.MustDeref(v.Type()), from), v.Comment)
 }
 
-func (v *Phi) String() string {
+func (v *Phi) String() string { return v.format(relName) }
+func (v *Phi) format(name namer) string {
 	var b bytes.Buffer
 	b.WriteString("phi [")
 	for i, edge := range v.Edges {
@@ -109,7 +141,7 @@ func (v *Phi) String() string {
 		fmt.Fprintf(&b, "%d: ", block)
 		edgeVal := "<nil>" // be robust
 		if edge != nil {
-			edgeVal = relName(edge, v)
+			edgeVal = name(edge, v)
 		}
 		b.WriteString(edgeVal)
 	}
@@ -121,20 +153,20 @@ func (v *Phi) String() string {
 	return b.String()
 }
 
-func printCall(v *CallCommon, prefix string, instr Instruction) string {
+func printCall(v *CallCommon, prefix string, instr Instruction, name namer) string {
 	var b bytes.Buffer
 	b.WriteString(prefix)
 	if !v.IsInvoke() {
-		b.WriteString(relName(v.Value, instr))
+		b.WriteString(name(v.Value, instr))
 	} else {
-		fmt.Fprintf(&b, "invoke %s.%s", relName(v.Value, instr), v.Method.Name())
+		fmt.Fprintf(&b, "invoke %s.%s", name(v.Value, instr), v.Method.Name())
 	}
 	b.WriteString("(")
 	for i, arg := range v.Args {
 		if i > 0 {
 			b.WriteString(", ")
 		}
-		b.WriteString(relName(arg, instr))
+		b.WriteString(name(arg, instr))
 	}
 	if v.Signature().Variadic() {
 		b.WriteString("...")
@@ -144,41 +176,64 @@ func printCall(v *CallCommon, prefix string, instr Instruction) string {
 }
 
 func (c *CallCommon) String() string {
-	return printCall(c, "", nil)
+	return printCall(c, "", nil, relName)
 }
 
-func (v *Call) String() string {
-	return printCall(&v.Call, "", v)
+func (v *Call) String() string { return v.format(relName) }
+func (v *Call) format(name namer) string {
+	return printCall(&v.Call, "", v, name)
 }
 
-func (v *BinOp) String() string {
-	return fmt.Sprintf("%s %s %s", relName(v.X, v), v.Op.String(), relName(v.Y, v))
+func (v *BinOp) String() string { return v.format(relName) }
+func (v *BinOp) format(name namer) string {
+	return fmt.Sprintf("%s %s %s", name(v.X, v), v.Op.String(), name(v.Y, v))
 }
 
-func (v *UnOp) String() string {
-	return fmt.Sprintf("%s%s%s", v.Op, relName(v.X, v), commaOk(v.CommaOk))
+func (v *UnOp) String() string { return v.format(relName) }
+func (v *UnOp) format(name namer) string {
+	return fmt.Sprintf("%s%s%s", v.Op, name(v.X, v), commaOk(v.CommaOk))
 }
 
-func printConv(prefix string, v, x Value) string {
+func printConv(prefix string, v, x Value, name namer) string {
 	from := v.Parent().relPkg()
 	return fmt.Sprintf("%s %s <- %s (%s)",
 		prefix,
 		relType(v.Type(), from),
 		relType(x.Type(), from),
-		relName(x, v.(Instruction)))
+		name(x, v.(Instruction)))
 }
 
-func (v *ChangeType) String() string          { return printConv("changetype", v, v.X) }
-func (v *Convert) String() string             { return printConv("convert", v, v.X) }
-func (v *ChangeInterface) String() string     { return printConv("change interface", v, v.X) }
-func (v *SliceToArrayPointer) String() string { return printConv("slice to array pointer", v, v.X) }
-func (v *MakeInterface) String() string       { return printConv("make", v, v.X) }
+func (v *ChangeType) String() string { return v.format(relName) }
+func (v *ChangeType) format(name namer) string {
+	return printConv("changetype", v, v.X, name)
+}
+
+func (v *Convert) String() string { return v.format(relName) }
+func (v *Convert) format(name namer) string {
+	return printConv("convert", v, v.X, name)
+}
+
+func (v *ChangeInterface) String() string { return v.format(relName) }
+func (v *ChangeInterface) format(name namer) string {
+	return printConv("change interface", v, v.X, name)
+}
+
+func (v *SliceToArrayPointer) String() string { return v.format(relName) }
+func (v *SliceToArrayPointer) format(name namer) string {
+	return printConv("slice to array pointer", v, v.X, name)
+}
+
+func (v *MakeInterface) String() string { return v.format(relName) }
+func (v *MakeInterface) format(name namer) string {
+	return printConv("make", v, v.X, name)
+}
 
-func (v *MultiConvert) String() string {
+func (v *MultiConvert) String() string { return v.format(relName) }
+func (v *MultiConvert) format(name namer) string {
 	from := v.Parent().relPkg()
 
 	var b strings.Builder
-	b.WriteString(printConv("multiconvert", v, v.X))
+	b.WriteString(printConv("multiconvert", v, v.X, name))
 	b.WriteString(" [")
 	for i, s := range termListOf(v.from) {
 		for j, d := range termListOf(v.to) {
@@ -192,109 +247,123 @@ func (v *MultiConvert) String() string {
 	return b.String()
 }
 
-func (v *MakeClosure) String() string {
+func (v *MakeClosure) String() string { return v.format(relName) }
+func (v *MakeClosure) format(name namer) string {
 	var b bytes.Buffer
-	fmt.Fprintf(&b, "make closure %s", relName(v.Fn, v))
+	fmt.Fprintf(&b, "make closure %s", name(v.Fn, v))
 	if v.Bindings != nil {
 		b.WriteString(" [")
 		for i, c := range v.Bindings {
 			if i > 0 {
 				b.WriteString(", ")
 			}
-			b.WriteString(relName(c, v))
+			b.WriteString(name(c, v))
 		}
 		b.WriteString("]")
 	}
 	return b.String()
 }
 
-func (v *MakeSlice) String() string {
+func (v *MakeSlice) String() string { return v.format(relName) }
+func (v *MakeSlice) format(name namer) string {
 	from := v.Parent().relPkg()
 	return fmt.Sprintf("make %s %s %s",
 		relType(v.Type(), from),
-		relName(v.Len, v),
-		relName(v.Cap, v))
+		name(v.Len, v),
+		name(v.Cap, v))
 }
 
-func (v *Slice) String() string {
+func (v *Slice) String() string { return v.format(relName) }
+func (v *Slice) format(name namer) string {
 	var b bytes.Buffer
 	b.WriteString("slice ")
-	b.WriteString(relName(v.X, v))
+	b.WriteString(name(v.X, v))
 	b.WriteString("[")
 	if v.Low != nil {
-		b.WriteString(relName(v.Low, v))
+		b.WriteString(name(v.Low, v))
 	}
 	b.WriteString(":")
 	if v.High != nil {
-		b.WriteString(relName(v.High, v))
+		b.WriteString(name(v.High, v))
 	}
 	if v.Max != nil {
 		b.WriteString(":")
-		b.WriteString(relName(v.Max, v))
+		b.WriteString(name(v.Max, v))
 	}
 	b.WriteString("]")
 	return b.String()
 }
 
-func (v *MakeMap) String() string {
+func (v *MakeMap) String() string { return v.format(relName) }
+func (v *MakeMap) format(name namer) string {
 	res := ""
 	if v.Reserve != nil {
-		res = relName(v.Reserve, v)
+		res = name(v.Reserve, v)
 	}
 	from := v.Parent().relPkg()
 	return fmt.Sprintf("make %s %s", relType(v.Type(), from), res)
 }
 
-func (v *MakeChan) String() string {
+func (v *MakeChan) String() string { return v.format(relName) }
+func (v *MakeChan) format(name namer) string {
 	from := v.Parent().relPkg()
-	return fmt.Sprintf("make %s %s", relType(v.Type(), from), relName(v.Size, v))
+	return fmt.Sprintf("make %s %s", relType(v.Type(), from), name(v.Size, v))
 }
 
-func (v *FieldAddr) String() string {
+func (v *FieldAddr) String() string { return v.format(relName) }
+func (v *FieldAddr) format(name namer) string {
 	// Be robust against a bad index.
-	name := "?"
+	field := "?"
 	if fld := fieldOf(typeparams.MustDeref(v.X.Type()), v.Field); fld != nil {
-		name = fld.Name()
+		field = fld.Name()
 	}
-	return fmt.Sprintf("&%s.%s [#%d]", relName(v.X, v), name, v.Field)
+	return fmt.Sprintf("&%s.%s [#%d]", name(v.X, v), field, v.Field)
 }
 
-func (v *Field) String() string {
+func (v *Field) String() string { return v.format(relName) }
+func (v *Field) format(name namer) string {
 	// Be robust against a bad index.
-	name := "?"
+	field := "?"
 	if fld := fieldOf(v.X.Type(), v.Field); fld != nil {
-		name = fld.Name()
+		field = fld.Name()
 	}
-	return fmt.Sprintf("%s.%s [#%d]", relName(v.X, v), name, v.Field)
+	return fmt.Sprintf("%s.%s [#%d]", name(v.X, v), field, v.Field)
 }
 
-func (v *IndexAddr) String() string {
-	return fmt.Sprintf("&%s[%s]", relName(v.X, v), relName(v.Index, v))
+func (v *IndexAddr) String() string { return v.format(relName) }
+func (v *IndexAddr) format(name namer) string {
+	return fmt.Sprintf("&%s[%s]", name(v.X, v), name(v.Index, v))
 }
 
-func (v *Index) String() string {
-	return fmt.Sprintf("%s[%s]", relName(v.X, v), relName(v.Index, v))
+func (v *Index) String() string { return v.format(relName) }
+func (v *Index) format(name namer) string {
+	return fmt.Sprintf("%s[%s]", name(v.X, v), name(v.Index, v))
 }
 
-func (v *Lookup) String() string {
-	return fmt.Sprintf("%s[%s]%s", relName(v.X, v), relName(v.Index, v), commaOk(v.CommaOk))
+func (v *Lookup) String() string { return v.format(relName) }
+func (v *Lookup) format(name namer) string {
+	return fmt.Sprintf("%s[%s]%s", name(v.X, v), name(v.Index, v), commaOk(v.CommaOk))
 }
 
-func (v *Range) String() string {
-	return "range " + relName(v.X, v)
+func (v *Range) String() string { return v.format(relName) }
+func (v *Range) format(name namer) string {
+	return "range " + name(v.X, v)
 }
 
-func (v *Next) String() string {
-	return "next " + relName(v.Iter, v)
+func (v *Next) String() string { return v.format(relName) }
+func (v *Next) format(name namer) string {
+	return "next " + name(v.Iter, v)
 }
 
-func (v *TypeAssert) String() string {
+func (v *TypeAssert) String() string { return v.format(relName) }
+func (v *TypeAssert) format(name namer) string {
 	from := v.Parent().relPkg()
-	return fmt.Sprintf("typeassert%s %s.(%s)", commaOk(v.CommaOk), relName(v.X, v), relType(v.AssertedType, from))
+	return fmt.Sprintf("typeassert%s %s.(%s)", commaOk(v.CommaOk), name(v.X, v), relType(v.AssertedType, from))
 }
 
-func (v *Extract) String() string {
-	return fmt.Sprintf("extract %s #%d", relName(v.Tuple, v), v.Index)
+func (v *Extract) String() string { return v.format(relName) }
+func (v *Extract) format(name namer) string {
+	return fmt.Sprintf("extract %s #%d", name(v.Tuple, v), v.Index)
 }
 
 func (s *Jump) String() string {
@@ -306,25 +375,29 @@ func (s *Jump) String() string {
 	return fmt.Sprintf("jump %d", block)
 }
 
-func (s *If) String() string {
+func (s *If) String() string { return s.format(relName) }
+func (s *If) format(name namer) string {
 	// Be robust against malformed CFG.
 	tblock, fblock := -1, -1
 	if s.block != nil && len(s.block.Succs) == 2 {
 		tblock = s.block.Succs[0].Index
 		fblock = s.block.Succs[1].Index
 	}
-	return fmt.Sprintf("if %s goto %d else %d", relName(s.Cond, s), tblock, fblock)
+	return fmt.Sprintf("if %s goto %d else %d", name(s.Cond, s), tblock, fblock)
 }
 
-func (s *Go) String() string {
-	return printCall(&s.Call, "go ", s)
+func (s *Go) String() string { return s.format(relName) }
+func (s *Go) format(name namer) string {
+	return printCall(&s.Call, "go ", s, name)
 }
 
-func (s *Panic) String() string {
-	return "panic " + relName(s.X, s)
+func (s *Panic) String() string { return s.format(relName) }
+func (s *Panic) format(name namer) string {
+	return "panic " + name(s.X, s)
 }
 
-func (s *Return) String() string {
+func (s *Return) String() string { return s.format(relName) }
+func (s *Return) format(name namer) string {
 	var b bytes.Buffer
 	b.WriteString("return")
 	for i, r := range s.Results {
@@ -333,7 +406,7 @@ func (s *Return) String() string {
 		} else {
 			b.WriteString(", ")
 		}
-		b.WriteString(relName(r, s))
+		b.WriteString(name(r, s))
 	}
 	return b.String()
 }
@@ -342,20 +415,23 @@ func (*RunDefers) String() string {
 	return "rundefers"
 }
 
-func (s *Send) String() string {
-	return fmt.Sprintf("send %s <- %s", relName(s.Chan, s), relName(s.X, s))
+func (s *Send) String() string { return s.format(relName) }
+func (s *Send) format(name namer) string {
+	return fmt.Sprintf("send %s <- %s", name(s.Chan, s), name(s.X, s))
 }
 
-func (s *Defer) String() string {
+func (s *Defer) String() string { return s.format(relName) }
+func (s *Defer) format(name namer) string {
 	prefix := "defer "
 	if s.DeferStack != nil {
-		prefix += "[" + relName(s.DeferStack, s) + "] "
+		prefix += "[" + name(s.DeferStack, s) + "] "
 	}
-	c := printCall(&s.Call, prefix, s)
+	c := printCall(&s.Call, prefix, s, name)
 	return c
 }
 
-func (s *Select) String() string {
+func (s *Select) String() string { return s.format(relName) }
+func (s *Select) format(name namer) string {
 	var b bytes.Buffer
 	for i, st := range s.States {
 		if i > 0 {
@@ -363,11 +439,11 @@ func (s *Select) String() string {
 		}
 		if st.Dir == types.RecvOnly {
 			b.WriteString("<-")
-			b.WriteString(relName(st.Chan, s))
+			b.WriteString(name(st.Chan, s))
 		} else {
-			b.WriteString(relName(st.Chan, s))
+			b.WriteString(name(st.Chan, s))
 			b.WriteString("<-")
-			b.WriteString(relName(st.Send, s))
+			b.WriteString(name(st.Send, s))
 		}
 	}
 	non := ""
@@ -377,12 +453,14 @@ func (s *Select) String() string {
 	return fmt.Sprintf("select %sblocking [%s]", non, b.String())
 }
 
-func (s *Store) String() string {
-	return fmt.Sprintf("*%s = %s", relName(s.Addr, s), relName(s.Val, s))
+func (s *Store) String() string { return s.format(relName) }
+func (s *Store) format(name namer) string {
+	return fmt.Sprintf("*%s = %s", name(s.Addr, s), name(s.Val, s))
 }
 
-func (s *MapUpdate) String() string {
-	return fmt.Sprintf("%s[%s] = %s", relName(s.Map, s), relName(s.Key, s), relName(s.Value, s))
+func (s *MapUpdate) String() string { return s.format(relName) }
+func (s *MapUpdate) format(name namer) string {
+	return fmt.Sprintf("%s[%s] = %s", name(s.Map, s), name(s.Key, s), name(s.Value, s))
 }
 
 func (s *DebugRef) String() string {
@@ -454,6 +532,199 @@ func WritePackage(buf *bytes.Buffer, p *Package) {
 	fmt.Fprintf(buf, "\n")
 }
 
+// WritePackageText writes to buf the text of package p in the form
+// read by [ParsePackage]: a preamble of Go declarations of the
+// package-level objects of p, followed by the functions of p in the
+// form printed by [WriteFunction], but without their locations.
+//
+// The functions are the package-level functions and methods of p, its
+// package initializer, their anonymous functions, and any instances
+// of its generic functions built in [InstantiateGenerics] mode.
+// Wrappers and other synthetic functions, which are created on demand,
+// are omitted.
+func WritePackageText(buf *bytes.Buffer, p *Package) {
+	writePreamble(buf, p.Pkg)
+
+	var fns []*Function
+	for _, mem := range p.Members {
+		switch mem := mem.(type) {
+		case *Function:
+			fns = append(fns, mem)
+		case *Type:
+			if named, ok := types.Unalias(mem.Type()).(*types.Named); ok && !mem.object.IsAlias() {
+				for i := range named.NumMethods() {
+					if fn, ok := p.objects[named.Method(i)].(*Function); ok {
+						fns = append(fns, fn)
+					}
+				}
+			}
+		}
+	}
+	for _, fn := range fns {
+		if fn.generic != nil {
+			fn.generic.instancesMu.Lock()
+			for _, inst := range fn.generic.instances {
+				if strings.HasPrefix(inst.Synthetic, "instance of ") {
+					fns = append(fns, inst)
+				}
+			}
+			fn.generic.instancesMu.Unlock()
+		}
+	}
+	sort.Slice(fns, func(i, j int) bool { return fns[i].String() < fns[j].String() })
+
+	var write func(fn *Function)
+	write = func(fn *Function) {
+		buf.WriteString("\n")
+		writeFunction(buf, fn, true)
+		for _, anon := range fn.AnonFuncs {
+			write(anon)
+		}
+	}
+	for _, fn := range fns {
+		write(fn)
+	}
+}
+
+// writePreamble writes to buf the package clause of pkg, followed by
+// its imports and a declaration of each of its package-level objects.
+// Functions and methods are declared without bodies, and constants
+// with their exact values.
+func writePreamble(buf *bytes.Buffer, pkg *types.Package) {
+	// Choose a distinct name for each imported package.
+	scope := pkg.Scope()
+	names := make(map[*types.Package]string)
+	used := make(map[string]bool)
+	qualifier := func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		name, ok := names[other]
+		if !ok {
+			name = other.Name()
+			for i := 1; used[name] || scope.Lookup(name) != nil; i++ {
+				name = fmt.Sprintf("%s%d", other.Name(), i)
+			}
+			names[other] = name
+			used[name] = true
+		}
+		return name
+	}
+
+	var decls bytes.Buffer
+	for _, name := range scope.Names() {
+		if name == "_" {
+			continue
+		}
+		switch obj := scope.Lookup(name).(type) {
+		case *types.Const:
+			fmt.Fprintf(&decls, "const %s", name)
+			if t, ok := obj.Type().(*types.Basic); !ok || t.Info()&types.IsUntyped == 0 {
+				fmt.Fprintf(&decls, " %s", types.TypeString(obj.Type(), qualifier))
+			}
+			fmt.Fprintf(&decls, " = %s\n", constLit(obj))
+
+		case *types.Var:
+			fmt.Fprintf(&decls, "var %s %s\n", name, types.TypeString(obj.Type(), qualifier))
+
+		case *types.Func:
+			fmt.Fprintf(&decls, "func %s", name)
+			types.WriteSignature(&decls, obj.Type().(*types.Signature), qualifier)
+			decls.WriteString("\n")
+
+		case *types.TypeName:
+			if obj.IsAlias() {
+				fmt.Fprintf(&decls, "type %s = %s\n", name, types.TypeString(aliases.Rhs(obj.Type().(*types.Alias)), qualifier))
+				continue
+			}
+			named := obj.Type().(*types.Named)
+			fmt.Fprintf(&decls, "type %s %s\n",
+				types.TypeString(named, qualifier),
+				types.TypeString(named.Underlying(), qualifier))
+			for i := range named.NumMethods() {
+				meth := named.Method(i)
+				sig := meth.Type().(*types.Signature)
+				decls.WriteString("func (")
+				if recv := sig.Recv(); recv.Name() != "" && recv.Name() != "_" {
+					fmt.Fprintf(&decls, "%s ", recv.Name())
+				}
+				fmt.Fprintf(&decls, "%s) %s", types.TypeString(sig.Recv().Type(), qualifier), meth.Name())
+				types.WriteSignature(&decls, sig, qualifier)
+				decls.WriteString("\n")
+			}
+		}
+	}
+
+	fmt.Fprintf(buf, "package %s\n", pkg.Name())
+	if len(names) > 0 {
+		var imports []*types.Package
+		for imp := range names {
+			imports = append(imports, imp)
+		}
+		sort.Slice(imports, func(i, j int) bool { return imports[i].Path() < imports[j].Path() })
+		buf.WriteString("\nimport (\n")
+		for _, imp := range imports {
+			buf.WriteString("\t")
+			if name := names[imp]; name != imp.Name() {
+				fmt.Fprintf(buf, "%s ", name)
+			}
+			fmt.Fprintf(buf, "%q\n", imp.Path())
+		}
+		buf.WriteString(")\n")
+	}
+	if decls.Len() > 0 {
+		buf.WriteString("\n")
+		buf.Write(decls.Bytes())
+	}
+}
+
+// constLit returns a constant expression for the value of c,
+// suitable for its declaration.
+func constLit(c *types.Const) string {
+	v := c.Val()
+	switch v.Kind() {
+	case constant.Int:
+		if t, ok := c.Type().(*types.Basic); ok && t.Kind() == types.UntypedRune {
+			if r, ok := constant.Int64Val(v); ok && utf8.ValidRune(rune(r)) {
+				return strconv.QuoteRune(rune(r))
+			}
+		}
+	case constant.Float:
+		return floatLit(v)
+	case constant.Complex:
+		return fmt.Sprintf("complex(%s, %s)", floatLit(constant.Real(v)), floatLit(constant.Imag(v)))
+	}
+	return v.ExactString()
+}
+
+// floatLit returns an untyped float constant expression for the value
+// of v, which must be of kind Int or Float. A value represented as a
+// floating-point number, as by export data, is printed as a fraction
+// if it is small enough to be represented as one.
+func floatLit(v constant.Value) string {
+	s := exactFloat(v)
+	if num, den, ok := strings.Cut(s, "/"); ok {
+		return fmt.Sprintf("(%s.0 / %s)", num, den)
+	}
+	if !strings.ContainsAny(s, ".eEp") {
+		s += ".0"
+	}
+	return s
+}
+
+// exactFloat returns the exact value of v, which must be of kind Int
+// or Float, as printed by its ExactString method, but as a fraction
+// if it is represented as a floating-point number small enough to be
+// represented as one.
+func exactFloat(v constant.Value) string {
+	if f, ok := constant.Val(v).(*big.Float); ok {
+		if r, _ := f.Rat(nil); r != nil {
+			v = constant.Make(r)
+		}
+	}
+	return v.ExactString()
+}
+
 func commaOk(x bool) string {
 	if x {
 		return ",ok"
@@ -111,6 +111,46 @@ const AnalysisProgressTitle = "Analyzing Dependencies"
 //
 // Notifications of progress may be sent to the optional reporter.
 func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Package, reporter *progress.Tracker) ([]*Diagnostic, error) {
+	return s.analyze(ctx, pkgs, reporter, false)
+}
+
+// AnalyzeProgram applies the whole-program phase (RunProgram) of each
+// enabled analyzer that has one to the packages in the pkgs map, which
+// form the program, and returns its diagnostics. The diagnostics
+// reported by the Run functions of these analyzers are not included:
+// [Snapshot.Analyze] returns them.
+//
+// Unlike the results of Analyze, those of AnalyzeProgram are not
+// cached: the Run functions of the whole-program analyzers are applied
+// afresh to every package of the program, which is type-checked from
+// syntax within a single realm so that the RunProgram function may
+// relate the types and objects of different packages.
+//
+// Notifications of progress may be sent to the optional reporter.
+func (s *Snapshot) AnalyzeProgram(ctx context.Context, pkgs map[PackageID]*metadata.Package, reporter *progress.Tracker) ([]*Diagnostic, error) {
+	return s.analyze(ctx, pkgs, reporter, true)
+}
+
+// ProgramAnalyzersEnabled reports whether any enabled analyzer has a
+// whole-program phase, for which [Snapshot.AnalyzeProgram] is needed.
+func (s *Snapshot) ProgramAnalyzersEnabled() bool {
+	for _, a := range analyzers(s.Options().Staticcheck) {
+		if a.Analyzer().RunProgram != nil && s.analyzerEnabled(a) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzerEnabled reports whether the analyzer is enabled in this
+// snapshot's options.
+func (s *Snapshot) analyzerEnabled(a *settings.Analyzer) bool {
+	enabled, ok := s.Options().Analyses[a.Analyzer().Name]
+	return enabled || !ok && a.EnabledByDefault()
+}
+
+// analyze implements Analyze and, if program is set, AnalyzeProgram.
+func (s *Snapshot) analyze(ctx context.Context, pkgs map[PackageID]*metadata.Package, reporter *progress.Tracker, program bool) ([]*Diagnostic, error) {
 	start := time.Now() // for progress reporting
 
 	var tagStr string // sorted comma-separated list of PackageIDs
@@ -130,12 +170,19 @@ func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Pac
 	analyzers := analyzers(s.Options().Staticcheck)
 	toSrc := make(map[*analysis.Analyzer]*settings.Analyzer)
 	var enabledAnalyzers []*analysis.Analyzer // enabled subset + transitive requirements
+	var programAnalyzers []*analysis.Analyzer // enabled analyzers with a whole-program phase
 	for _, a := range analyzers {
-		if enabled, ok := s.Options().Analyses[a.Analyzer().Name]; enabled || !ok && a.EnabledByDefault() {
+		if s.analyzerEnabled(a) {
 			toSrc[a.Analyzer()] = a
 			enabledAnalyzers = append(enabledAnalyzers, a.Analyzer())
+			if a.Analyzer().RunProgram != nil {
+				programAnalyzers = append(programAnalyzers, a.Analyzer())
+			}
 		}
 	}
+	if program && len(programAnalyzers) == 0 {
+		return nil, nil
+	}
 	sort.Slice(enabledAnalyzers, func(i, j int) bool {
 		return enabledAnalyzers[i].Name < enabledAnalyzers[j].Name
 	})
@@ -166,8 +213,28 @@ func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Pac
 	}
 	facty = requiredAnalyzers(facty)
 
-	batch, release := s.acquireTypeChecking()
-	defer release()
+	// The whole-program phase needs the Run results of its analyzers
+	// on the root packages, which it alone can relate, so these are
+	// type-checked in a batch of their own, forming a single realm.
+	// Root nodes run the whole-program analyzers and the facty set,
+	// which their dependents among the roots may need.
+	rootAnalyzers := enabledAnalyzers
+	var batch *typeCheckBatch
+	if program {
+		sort.Slice(programAnalyzers, func(i, j int) bool {
+			return programAnalyzers[i].Name < programAnalyzers[j].Name
+		})
+		rootAnalyzers = requiredAnalyzers(append(slices.Clip(programAnalyzers), facty...))
+		realm := make(map[PackageID]bool)
+		for id := range pkgs {
+			realm[id] = true
+		}
+		batch = newRealmTypeCheckBatch(s.view.parseCache, s.view.typ == GoPackagesDriverView, realm)
+	} else {
+		var release func()
+		batch, release = s.acquireTypeChecking()
+		defer release()
+	}
 
 	ids := moremaps.KeySlice(pkgs)
 	handles, err := s.getPackageHandles(ctx, ids)
@@ -243,7 +310,8 @@ func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Pac
 		if err != nil {
 			return nil, err
 		}
-		root.analyzers = enabledAnalyzers
+		root.analyzers = rootAnalyzers
+		root.keep = program
 		roots = append(roots, root)
 	}
 
@@ -315,30 +383,45 @@ func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Pac
 			limiter <- unit{}
 			defer func() { <-limiter }()
 
-			// Check to see if we already have a valid cache key. If not, compute it.
-			//
-			// The snapshot field that memoizes keys depends on whether this key is
-			// for the analysis result including all enabled analyzer, or just facty analyzers.
-			var keys *persistent.Map[PackageID, file.Hash]
-			if _, root := pkgs[an.ph.mp.ID]; root {
-				keys = s.fullAnalysisKeys
+			var (
+				summary *analyzeSummary
+				err     error
+			)
+			if an.keep {
+				// The results of the whole-program analyzers
+				// are needed, so the cache cannot be used.
+				summary, err = an.run(ctx)
+			} else if program {
+				// The summaries of root nodes differ from those
+				// of Analyze, so the memoized keys of their
+				// dependents do not apply.
+				summary, err = an.runCached(ctx, an.cacheKey())
 			} else {
-				keys = s.factyAnalysisKeys
-			}
-
-			// As keys is referenced by a snapshot field, it's guarded by s.mu.
-			s.mu.Lock()
-			key, keyFound := keys.Get(an.ph.mp.ID)
-			s.mu.Unlock()
+				// Check to see if we already have a valid cache key. If not, compute it.
+				//
+				// The snapshot field that memoizes keys depends on whether this key is
+				// for the analysis result including all enabled analyzer, or just facty analyzers.
+				var keys *persistent.Map[PackageID, file.Hash]
+				if _, root := pkgs[an.ph.mp.ID]; root {
+					keys = s.fullAnalysisKeys
+				} else {
+					keys = s.factyAnalysisKeys
+				}
 
-			if !keyFound {
-				key = an.cacheKey()
+				// As keys is referenced by a snapshot field, it's guarded by s.mu.
 				s.mu.Lock()
-				keys.Set(an.ph.mp.ID, key, nil)
+				key, keyFound := keys.Get(an.ph.mp.ID)
 				s.mu.Unlock()
-			}
 
-			summary, err := an.runCached(ctx, key)
+				if !keyFound {
+					key = an.cacheKey()
+					s.mu.Lock()
+					keys.Set(an.ph.mp.ID, key, nil)
+					s.mu.Unlock()
+				}
+
+				summary, err = an.runCached(ctx, key)
+			}
 			if err != nil {
 				return err // cancelled, or failed to produce a package
 			}
@@ -383,6 +466,10 @@ func (s *Snapshot) Analyze(ctx context.Context, pkgs map[PackageID]*metadata.Pac
 		}
 	}
 
+	if program {
+		return runPrograms(ctx, programAnalyzers, toSrc, roots), nil
+	}
+
 	// Report diagnostics only from enabled actions that succeeded.
 	// Errors from creating or analyzing packages are ignored.
 	// Diagnostics are reported in the order of the analyzers argument.
@@ -472,6 +559,11 @@ type analysisNode struct {
 	summary         *analyzeSummary               // serializable result of analyzing this package
 	stableNames     map[*analysis.Analyzer]string // cross-process stable names for Analyzers
 
+	// If keep is set, run retains in actions the actions of the
+	// analyzers, including their results, for the whole-program phase.
+	keep    bool
+	actions map[*analysis.Analyzer]*action
+
 	summaryHashOnce sync.Once
 	_summaryHash    file.Hash // memoized hash of data affecting dependents
 }
@@ -730,6 +822,13 @@ func (an *analysisNode) run(ctx context.Context) (*analyzeSummary, error) {
 		return nil, err // cancelled
 	}
 
+	if an.keep {
+		an.actions = make(map[*analysis.Analyzer]*action)
+		for _, root := range roots {
+			an.actions[root.a] = root
+		}
+	}
+
 	// Return summaries only for the requested actions.
 	summaries := make(map[string]*actionSummary)
 	for _, root := range roots {
@@ -885,7 +984,8 @@ type action struct {
 	vdeps      map[PackageID]*analysisNode // vertical dependencies
 
 	// results of action.exec():
-	result  any // result of Run function, of type a.ResultType
+	result  any        // result of Run function, of type a.ResultType
+	facts   *facts.Set // facts of the package and its dependencies
 	summary *actionSummary
 	err     error
 }
@@ -1028,93 +1128,6 @@ func (act *action) exec(ctx context.Context) (any, *actionSummary, error) {
 		factFilter[reflect.TypeOf(f)] = true
 	}
 
-	// posToLocation converts from token.Pos to protocol form.
-	posToLocation := func(start, end token.Pos) (protocol.Location, error) {
-		tokFile := apkg.pkg.FileSet().File(start)
-
-		// Find existing mapper by file name.
-		// (Don't require an exact token.File match
-		// as the analyzer may have re-parsed the file.)
-		var (
-			mapper *protocol.Mapper
-			fixed  bool
-		)
-		for _, p := range apkg.pkg.CompiledGoFiles() {
-			if p.Tok.Name() == tokFile.Name() {
-				mapper = p.Mapper
-				fixed = p.Fixed() // suppress some assertions after parser recovery
-				break
-			}
-		}
-		if mapper == nil {
-			// The start position was not among the package's parsed
-			// Go files, indicating that the analyzer added new files
-			// to the FileSet.
-			//
-			// For example, the cgocall analyzer re-parses and
-			// type-checks some of the files in a special environment;
-			// and asmdecl and other low-level runtime analyzers call
-			// ReadFile to parse non-Go files.
-			// (This is a supported feature, documented at go/analysis.)
-			//
-			// In principle these files could be:
-			//
-			// - OtherFiles (non-Go files such as asm).
-			//   However, we set Pass.OtherFiles=[] because
-			//   gopls won't service "diagnose" requests
-			//   for non-Go files, so there's no point
-			//   reporting diagnostics in them.
-			//
-			// - IgnoredFiles (files tagged for other configs).
-			//   However, we set Pass.IgnoredFiles=[] because,
-			//   in most cases, zero-config gopls should create
-			//   another view that covers these files.
-			//
-			// - Referents of //line directives, as in cgo packages.
-			//   The file names in this case are not known a priori.
-			//   gopls generally tries to avoid honoring line directives,
-			//   but analyzers such as cgocall may honor them.
-			//
-			// In short, it's unclear how this can be reached
-			// other than due to an analyzer bug.
-			return protocol.Location{}, bug.Errorf("diagnostic location is not among files of package: %s", tokFile.Name())
-		}
-		// Inv: mapper != nil
-
-		if end == token.NoPos {
-			end = start
-		}
-
-		// debugging #64547
-		fileStart := token.Pos(tokFile.Base())
-		fileEnd := fileStart + token.Pos(tokFile.Size())
-		if start < fileStart {
-			if !fixed {
-				bug.Reportf("start < start of file")
-			}
-			start = fileStart
-		}
-		if end < start {
-			// This can happen if End is zero (#66683)
-			// or a small positive displacement from zero
-			// due to recursive Node.End() computation.
-			// This usually arises from poor parser recovery
-			// of an incomplete term at EOF.
-			if !fixed {
-				bug.Reportf("end < start of file")
-			}
-			end = fileEnd
-		}
-		if end > fileEnd+1 {
-			if !fixed {
-				bug.Reportf("end > end of file + 1")
-			}
-			end = fileEnd
-		}
-
-		return mapper.PosLocation(tokFile, start, end)
-	}
-
 	// Now run the (pkg, analyzer) action.
 	var diagnostics []gobDiagnostic
 
@@ -1135,7 +1148,7 @@ func (act *action) exec(ctx context.Context) (any, *actionSummary, error) {
 				bug.Reportf("invalid SuggestedFixes: %v", err)
 				d.SuggestedFixes = nil
 			}
-			diagnostic, err := toGobDiagnostic(posToLocation, analyzer, d)
+			diagnostic, err := toGobDiagnostic(apkg.posToLocation, analyzer, d)
 			if err != nil {
 				// Don't bug.Report here: these errors all originate in
 				// posToLocation, and we can more accurately discriminate
@@ -1231,6 +1244,7 @@ func (act *action) exec(ctx context.Context) (any, *actionSummary, error) {
 		panic(fmt.Sprintf("%v: Pass.ExportPackageFact(%T) called after Run", act, fact))
 	}
 
+	act.facts = factset
 	factsdata := factset.Encode()
 	return result, &actionSummary{
 		Diagnostics: diagnostics,
@@ -1239,6 +1253,93 @@ func (act *action) exec(ctx context.Context) (any, *actionSummary, error) {
 	}, nil
 }
 
+// posToLocation converts from token.Pos to protocol form.
+func (apkg *analysisPackage) posToLocation(start, end token.Pos) (protocol.Location, error) {
+	tokFile := apkg.pkg.FileSet().File(start)
+
+	// Find existing mapper by file name.
+	// (Don't require an exact token.File match
+	// as the analyzer may have re-parsed the file.)
+	var (
+		mapper *protocol.Mapper
+		fixed  bool
+	)
+	for _, p := range apkg.pkg.CompiledGoFiles() {
+		if p.Tok.Name() == tokFile.Name() {
+			mapper = p.Mapper
+			fixed = p.Fixed() // suppress some assertions after parser recovery
+			break
+		}
+	}
+	if mapper == nil {
+		// The start position was not among the package's parsed
+		// Go files, indicating that the analyzer added new files
+		// to the FileSet.
+		//
+		// For example, the cgocall analyzer re-parses and
+		// type-checks some of the files in a special environment;
+		// and asmdecl and other low-level runtime analyzers call
+		// ReadFile to parse non-Go files.
+		// (This is a supported feature, documented at go/analysis.)
+		//
+		// In principle these files could be:
+		//
+		// - OtherFiles (non-Go files such as asm).
+		//   However, we set Pass.OtherFiles=[] because
+		//   gopls won't service "diagnose" requests
+		//   for non-Go files, so there's no point
+		//   reporting diagnostics in them.
+		//
+		// - IgnoredFiles (files tagged for other configs).
+		//   However, we set Pass.IgnoredFiles=[] because,
+		//   in most cases, zero-config gopls should create
+		//   another view that covers these files.
+		//
+		// - Referents of //line directives, as in cgo packages.
+		//   The file names in this case are not known a priori.
+		//   gopls generally tries to avoid honoring line directives,
+		//   but analyzers such as cgocall may honor them.
+		//
+		// In short, it's unclear how this can be reached
+		// other than due to an analyzer bug.
+		return protocol.Location{}, bug.Errorf("diagnostic location is not among files of package: %s", tokFile.Name())
+	}
+	// Inv: mapper != nil
+
+	if end == token.NoPos {
+		end = start
+	}
+
+	// debugging #64547
+	fileStart := token.Pos(tokFile.Base())
+	fileEnd := fileStart + token.Pos(tokFile.Size())
+	if start < fileStart {
+		if !fixed {
+			bug.Reportf("start < start of file")
+		}
+		start = fileStart
+	}
+	if end < start {
+		// This can happen if End is zero (#66683)
+		// or a small positive displacement from zero
+		// due to recursive Node.End() computation.
+		// This usually arises from poor parser recovery
+		// of an incomplete term at EOF.
+		if !fixed {
+			bug.Reportf("end < start of file")
+		}
+		end = fileEnd
+	}
+	if end > fileEnd+1 {
+		if !fixed {
+			bug.Reportf("end > end of file + 1")
+		}
+		end = fileEnd
+	}
+
+	return mapper.PosLocation(tokFile, start, end)
+}
+
 var (
 	analyzerRunTimesMu sync.Mutex
 	analyzerRunTimes   = make(map[*analysis.Analyzer]time.Duration)
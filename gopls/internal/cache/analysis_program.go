@@ -0,0 +1,205 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+// This file defines the whole-program phase of gopls' analysis driver,
+// in which the RunProgram function of each analyzer is applied to the
+// packages of a program, typically the workspace packages.
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/settings"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/bug"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/moremaps"
+	"github.com/tinygo-org/tinygo/x-tools/internal/analysisinternal"
+	"github.com/tinygo-org/tinygo/x-tools/internal/event"
+)
+
+// runPrograms applies the RunProgram function of each of the analyzers
+// to the root nodes, whose run method has retained their actions, and
+// returns the resulting diagnostics.
+//
+// Unlike drivers based on go/analysis/checker, which hold the syntax of
+// every package in memory, gopls provides only the root packages in
+// ProgramPass.Packages, not their dependencies.
+//
+// A whole-program analysis is skipped if the action of its analyzer
+// failed on any root package; if it fails, the error is logged.
+func runPrograms(ctx context.Context, analyzers []*analysis.Analyzer, toSrc map[*analysis.Analyzer]*settings.Analyzer, roots []*analysisNode) []*Diagnostic {
+	// Order the root packages so that each follows its dependencies.
+	var (
+		ordered []*analysisNode
+		visited = make(map[*analysisNode]bool)
+		visit   func(an *analysisNode)
+	)
+	visit = func(an *analysisNode) {
+		if !visited[an] {
+			visited[an] = true
+			for _, succ := range moremaps.Sorted(an.succs) {
+				visit(succ)
+			}
+			if an.keep && an.actions != nil {
+				ordered = append(ordered, an)
+			}
+		}
+	}
+	slices.SortFunc(roots, func(x, y *analysisNode) int {
+		return strings.Compare(string(x.ph.mp.ID), string(y.ph.mp.ID))
+	})
+	for _, root := range roots {
+		visit(root)
+	}
+	if len(ordered) == 0 {
+		return nil
+	}
+
+	var diagnostics []*Diagnostic
+	for _, a := range analyzers {
+		var (
+			actions []*action // actions of a, in the order of the packages
+			failed  bool
+		)
+		for _, an := range ordered {
+			act := an.actions[a]
+			actions = append(actions, act)
+			failed = failed || act == nil || act.err != nil
+		}
+		if failed {
+			continue
+		}
+		diags, err := runProgram(ctx, a, toSrc[a], actions)
+		if err != nil {
+			event.Error(ctx, fmt.Sprintf("whole-program analysis %s failed", a.Name), err)
+			continue
+		}
+		diagnostics = append(diagnostics, diags...)
+	}
+	return diagnostics
+}
+
+// runProgram applies the RunProgram function of analyzer a to the
+// packages of its actions, which belong to a single realm.
+func runProgram(ctx context.Context, a *analysis.Analyzer, srcAnalyzer *settings.Analyzer, actions []*action) (diagnostics []*Diagnostic, err error) {
+	fset := actions[0].pkg.pkg.FileSet()
+
+	pkgOf := make(map[*token.File]*analysisPackage)
+	for _, act := range actions {
+		for _, p := range act.pkg.pkg.CompiledGoFiles() {
+			pkgOf[p.Tok] = act.pkg
+		}
+	}
+
+	factFilter := make(map[reflect.Type]bool)
+	for _, f := range a.FactTypes {
+		factFilter[reflect.TypeOf(f)] = true
+	}
+
+	pass := &analysis.ProgramPass{
+		Analyzer: a,
+		Fset:     fset,
+		Report: func(d analysis.Diagnostic) {
+			// Assert that SuggestedFixes are well formed.
+			if err := analysisinternal.ValidateFixes(fset, a, d.SuggestedFixes); err != nil {
+				bug.Reportf("invalid SuggestedFixes: %v", err)
+				d.SuggestedFixes = nil
+			}
+			apkg := pkgOf[fset.File(d.Pos)]
+			if apkg == nil {
+				return // not in a package of the program
+			}
+			diagnostic, err := toGobDiagnostic(apkg.posToLocation, a, d)
+			if err != nil {
+				event.Error(ctx, fmt.Sprintf("internal error converting diagnostic from analyzer %q", a.Name), err)
+				return
+			}
+			diagnostics = append(diagnostics, toSourceDiagnostic(srcAnalyzer, &diagnostic))
+		},
+		ReadFile: func(filename string) ([]byte, error) {
+			for _, act := range actions {
+				for _, p := range act.pkg.pkg.CompiledGoFiles() {
+					if p.URI.Path() == filename {
+						h, err := act.fsource.ReadFile(ctx, p.URI)
+						if err != nil {
+							return nil, err
+						}
+						content, err := h.Content()
+						if err != nil {
+							return nil, err // file doesn't exist
+						}
+						return slices.Clone(content), nil // follow ownership of os.ReadFile
+					}
+				}
+			}
+			return nil, fmt.Errorf("ProgramPass.ReadFile: %s is not among the files of the program", filename)
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			type key struct {
+				obj types.Object
+				t   reflect.Type
+			}
+			seen := make(map[key]bool)
+			var facts []analysis.ObjectFact
+			for _, act := range actions {
+				for _, f := range act.facts.AllObjectFacts(factFilter) {
+					if k := (key{f.Object, reflect.TypeOf(f.Fact)}); !seen[k] {
+						seen[k] = true
+						facts = append(facts, f)
+					}
+				}
+			}
+			return facts
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			type key struct {
+				pkg *types.Package
+				t   reflect.Type
+			}
+			seen := make(map[key]bool)
+			var facts []analysis.PackageFact
+			for _, act := range actions {
+				for _, f := range act.facts.AllPackageFacts(factFilter) {
+					if k := (key{f.Package, reflect.TypeOf(f.Fact)}); !seen[k] {
+						seen[k] = true
+						facts = append(facts, f)
+					}
+				}
+			}
+			return facts
+		},
+	}
+	for _, act := range actions {
+		pass.Packages = append(pass.Packages, &analysis.PackageResult{
+			Pkg:       act.pkg.pkg.Types(),
+			Files:     act.pkg.files,
+			TypesInfo: act.pkg.pkg.TypesInfo(),
+			Root:      true,
+			Result:    act.result,
+		})
+	}
+
+	// Recover from panics (only) within the analyzer logic,
+	// as action.exec does.
+	defer func() {
+		if r := recover(); r != nil {
+			if bug.PanicOnBugs {
+				panic(r)
+			}
+			diagnostics, err = nil, fmt.Errorf("whole-program analysis %s panicked: %v", a.Name, r)
+		}
+	}()
+	if err := a.RunProgram(pass); err != nil {
+		return nil, err
+	}
+	return diagnostics, nil
+}
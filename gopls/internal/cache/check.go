@@ -69,6 +69,13 @@ type typeCheckBatch struct {
 	syntaxPackages   *futureCache[PackageID, *Package]       // transient cache of in-progress syntax futures
 	importPackages   *futureCache[PackageID, *types.Package] // persistent cache of imports
 	gopackagesdriver bool                                    // for bug reporting: were packages loaded with a driver?
+
+	// If realm is non-nil, the batch type-checks the packages it
+	// contains from syntax within a single realm of token.Pos and
+	// types.Object values: every package uses the batch's FileSet,
+	// and importers of these packages use the types of their syntax
+	// packages, not of their export data. See [Snapshot.AnalyzeProgram].
+	realm map[PackageID]bool
 }
 
 // addHandles is called by each goroutine joining the type check batch, to
@@ -251,6 +258,16 @@ func newTypeCheckBatch(parseCache *parseCache, gopackagesdriver bool) *typeCheck
 	}
 }
 
+// newRealmTypeCheckBatch creates a new type checking batch whose realm
+// is the given set of packages. Their syntax packages are persisted, so
+// that each is type-checked once, whether for import or for its own sake.
+func newRealmTypeCheckBatch(parseCache *parseCache, gopackagesdriver bool, realm map[PackageID]bool) *typeCheckBatch {
+	b := newTypeCheckBatch(parseCache, gopackagesdriver)
+	b.syntaxPackages = newFutureCache[PackageID, *Package](true)
+	b.realm = realm
+	return b
+}
+
 // query executes a traversal of package information in the given typeCheckBatch.
 // For each package in importIDs, the package will be loaded "for import" (sans
 // syntax).
@@ -294,6 +311,14 @@ func (b *typeCheckBatch) getImportPackage(ctx context.Context, id PackageID) (pk
 	return b.importPackages.get(ctx, id, func(ctx context.Context) (*types.Package, error) {
 		ph := b.getHandle(id)
 
+		if b.realm[id] {
+			pkg, err := b.getPackage(ctx, ph)
+			if err != nil {
+				return nil, err
+			}
+			return pkg.Types(), nil
+		}
+
 		// "unsafe" cannot be imported or type-checked.
 		//
 		// We check PkgPath, not id, as the structure of the ID
@@ -354,7 +379,7 @@ func (b *typeCheckBatch) getPackage(ctx context.Context, ph *packageHandle) (*Pa
 		// Record imports of this package to avoid redundant work in typesConfig.
 		imports := make(map[PackagePath]*types.Package)
 		fset := b.fset
-		if ph.state >= validImports {
+		if ph.state >= validImports && b.realm == nil {
 			for _, imp := range ph.pkgData.imports {
 				imports[PackagePath(imp.Path())] = imp
 			}
@@ -166,6 +166,46 @@ func (index *Index) Search(key Key, method *types.Func) []Result {
 	return results
 }
 
+// A TypeRelation is a set of directions of the "implements" relation
+// between a query type and a candidate type.
+type TypeRelation int8
+
+const (
+	Subtype   TypeRelation = 1 << iota // candidate implements the query type
+	Supertype                          // query type implements the candidate
+)
+
+// A TypeResult reports a package-level type found by [Index.Related].
+type TypeResult struct {
+	Location    Location // location of the type's name
+	PkgPath     string   // path of declaring package
+	Name        string   // name of the type
+	IsInterface bool     // the type is an interface
+}
+
+// Related reports each type in the index that is related to the
+// type that produced the search key by one of the relations in rel.
+//
+// Unlike Search, Related reports interface/interface pairs, such as
+// an interface and the interfaces it embeds, since they form part of
+// the type hierarchy. An interface is related to itself, so callers
+// should not search the index of the package declaring the query type.
+func (index *Index) Related(key Key, rel TypeRelation) []TypeResult {
+	var results []TypeResult
+	for _, candidate := range index.pkg.MethodSets {
+		if rel&Subtype != 0 && implements(candidate, key.mset) ||
+			rel&Supertype != 0 && implements(key.mset, candidate) {
+			results = append(results, TypeResult{
+				Location:    index.location(candidate.Posn),
+				PkgPath:     index.pkg.Strings[index.pkg.PkgPath],
+				Name:        index.pkg.Strings[candidate.Name],
+				IsInterface: candidate.IsInterface,
+			})
+		}
+	}
+	return results
+}
+
 // implements reports whether x implements y.
 func implements(x, y *gobMethodSet) bool {
 	if !y.IsInterface {
@@ -227,6 +267,7 @@ type indexBuilder struct {
 // build adds to the index all package-level named types of the specified package.
 func (b *indexBuilder) build(fset *token.FileSet, pkg *types.Package) *Index {
 	_ = b.string("") // 0 => ""
+	b.PkgPath = b.string(pkg.Path())
 
 	objectPos := func(obj types.Object) gobPosition {
 		posn := safetoken.StartPosition(fset, obj.Pos())
@@ -286,6 +327,7 @@ func (b *indexBuilder) build(fset *token.FileSet, pkg *types.Package) *Index {
 		if tname, ok := scope.Lookup(name).(*types.TypeName); ok && !tname.IsAlias() {
 			if mset := methodSetInfo(tname.Type(), setIndexInfo); mset.Mask != 0 {
 				mset.Posn = objectPos(tname)
+				mset.Name = b.string(tname.Name())
 				// Only record types with non-trivial method sets.
 				b.MethodSets = append(b.MethodSets, mset)
 			}
@@ -365,12 +407,14 @@ var packageCodec = frob.CodecFor[gobPackage]()
 // A gobPackage records the method set of each package-level type for a single package.
 type gobPackage struct {
 	Strings    []string // index of strings used by gobPosition.File, gobMethod.{Pkg,Object}Path
+	PkgPath    int      // path of the package, index into Strings
 	MethodSets []*gobMethodSet
 }
 
 // A gobMethodSet records the method set of a single type.
 type gobMethodSet struct {
 	Posn        gobPosition
+	Name        int // name of the type, index into gobPackage.Strings
 	IsInterface bool
 	Tricky      bool   // at least one method is tricky; fingerprint must be parsed + unified
 	Mask        uint64 // mask with 1 bit from each of methods[*].sum
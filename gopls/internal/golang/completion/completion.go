@@ -110,19 +110,28 @@ type CompletionItem struct {
 	// from which this candidate was derived is a slice.
 	// (Used to complete append() calls.)
 	isSlice bool
+
+	// deferred, if non-nil, holds the information needed to compute
+	// the properties of the item that were deferred to a
+	// completionItem/resolve request. See [CompletionItem.Resolve].
+	deferred *deferredProperties
 }
 
 // completionOptions holds completion specific configuration.
 type completionOptions struct {
 	unimported            bool
 	documentation         bool
-	fullDocumentation     bool
 	placeholders          bool
 	snippets              bool
 	postfix               bool
 	matcher               settings.Matcher
 	budget                time.Duration
 	completeFunctionCalls bool
+
+	// Properties that the client can resolve lazily, and whose
+	// computation is therefore deferred.
+	deferDocumentation bool
+	deferImportEdits   bool
 }
 
 // Snippet is a convenience returns the snippet if available, otherwise
@@ -623,12 +632,13 @@ func Completion(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, p
 			matcher:               opts.Matcher,
 			unimported:            opts.CompleteUnimported,
 			documentation:         opts.CompletionDocumentation && opts.HoverKind != settings.NoDocumentation,
-			fullDocumentation:     opts.HoverKind == settings.FullDocumentation,
 			placeholders:          opts.UsePlaceholders,
 			budget:                opts.CompletionBudget,
 			snippets:              opts.InsertTextFormat == protocol.SnippetTextFormat,
 			postfix:               opts.ExperimentalPostfixCompletions,
 			completeFunctionCalls: opts.CompleteFunctionCalls,
+			deferDocumentation:    slices.Contains(opts.CompletionResolveOptions, "documentation"),
+			deferImportEdits:      slices.Contains(opts.CompletionResolveOptions, "additionalTextEdits"),
 		},
 		// default to a matcher that always matches
 		matcher:            prefixMatcher(""),
@@ -1410,12 +1420,26 @@ func (c *completer) selector(ctx context.Context, sel *ast.SelectorExpr) error {
 				item.Kind = protocol.ClassCompletion
 			}
 
+			var deferred deferredProperties
 			if needImport {
 				imp := &importInfo{importPath: path}
 				if imports.ImportPathToAssumedName(path) != string(mp.Name) {
 					imp.name = string(mp.Name)
 				}
-				item.AdditionalTextEdits, _ = c.importEdits(imp)
+				if c.opts.deferImportEdits {
+					deferred.imp = imp
+				} else {
+					item.AdditionalTextEdits, _ = c.importEdits(imp)
+				}
+			}
+			// The documentation and signature of the member are
+			// too costly to compute for every candidate, but may
+			// be computed when the client resolves the item.
+			if c.opts.documentation && c.opts.deferDocumentation {
+				deferred.member = &memberDecl{uri: uri, pkgPath: mp.PkgPath}
+			}
+			if deferred != (deferredProperties{}) {
+				item.deferred = &deferred
 			}
 
 			// For functions, add a parameter snippet.
@@ -5,17 +5,26 @@
 package completion
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"github.com/tinygo-org/tinygo/alt_go/ast"
 	"github.com/tinygo-org/tinygo/alt_go/doc"
+	"github.com/tinygo-org/tinygo/alt_go/printer"
+	"github.com/tinygo-org/tinygo/alt_go/token"
 	"github.com/tinygo-org/tinygo/alt_go/types"
+	"slices"
 	"strings"
 
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/metadata"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/parsego"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/golang"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/golang/completion/snippet"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/settings"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/safetoken"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/typesutil"
 	internalastutil "github.com/tinygo-org/tinygo/x-tools/internal/astutil"
@@ -181,15 +190,19 @@ Suffixes:
 	}
 
 	// If this candidate needs an additional import statement,
-	// add the additional text edits needed.
+	// add the additional text edits needed, unless the client
+	// will resolve them lazily.
+	var deferred *deferredProperties
 	if cand.imp != nil {
-		addlEdits, err := c.importEdits(cand.imp)
-
-		if err != nil {
-			return CompletionItem{}, err
+		if c.opts.deferImportEdits {
+			deferred = &deferredProperties{imp: cand.imp}
+		} else {
+			addlEdits, err := c.importEdits(cand.imp)
+			if err != nil {
+				return CompletionItem{}, err
+			}
+			protocolEdits = append(protocolEdits, addlEdits...)
 		}
-
-		protocolEdits = append(protocolEdits, addlEdits...)
 		if kind != protocol.ModuleCompletion {
 			if detail != "" {
 				detail += " "
@@ -239,6 +252,7 @@ Suffixes:
 		Depth:               len(cand.path),
 		snippet:             &snip,
 		isSlice:             isSlice(obj),
+		deferred:            deferred,
 	}
 	// If the user doesn't want documentation for completion items.
 	if !c.opts.documentation {
@@ -252,25 +266,167 @@ Suffixes:
 		return item, nil
 	}
 
+	// If the client will resolve the documentation lazily, we still
+	// need the doc comment now to report deprecation, if the client
+	// displays it.
+	opts := c.snapshot.Options()
+	if c.opts.deferDocumentation {
+		if item.deferred == nil {
+			item.deferred = new(deferredProperties)
+		}
+		item.deferred.obj, item.deferred.fset = obj, c.pkg.FileSet()
+		if !opts.CompletionTags && !opts.CompletionDeprecated {
+			return item, nil
+		}
+	}
+
 	comment, err := golang.HoverDocForObject(ctx, c.snapshot, c.pkg.FileSet(), obj)
 	if err != nil {
 		event.Error(ctx, fmt.Sprintf("failed to find Hover for %q", obj.Name()), err)
 		return item, nil
 	}
-	if c.opts.fullDocumentation {
-		item.Documentation = comment.Text()
-	} else {
-		item.Documentation = doc.Synopsis(comment.Text())
+	item.setDocumentation(opts, comment, !c.opts.deferDocumentation)
+
+	return item, nil
+}
+
+// setDocumentation sets the deprecation of the item and, if
+// withText is set, its documentation, from the object's doc comment.
+func (item *CompletionItem) setDocumentation(opts *settings.Options, comment *ast.CommentGroup, withText bool) {
+	if withText {
+		if opts.HoverKind == settings.FullDocumentation {
+			item.Documentation = comment.Text()
+		} else {
+			item.Documentation = doc.Synopsis(comment.Text())
+		}
 	}
 	if internalastutil.Deprecation(comment) != "" {
-		if c.snapshot.Options().CompletionTags {
+		if opts.CompletionTags {
 			item.Tags = []protocol.CompletionItemTag{protocol.ComplDeprecated}
-		} else if c.snapshot.Options().CompletionDeprecated {
+		} else if opts.CompletionDeprecated {
 			item.Deprecated = true
 		}
 	}
+}
 
-	return item, nil
+// deferredProperties holds the information needed to compute the
+// properties of a completion item that were deferred to a
+// completionItem/resolve request.
+type deferredProperties struct {
+	imp    *importInfo    // if non-nil, the import to add
+	obj    types.Object   // if non-nil, the object to document
+	fset   *token.FileSet // FileSet for obj.Pos()
+	member *memberDecl    // if non-nil, the unimported package member to document
+}
+
+// A memberDecl identifies the declaration of a package-level member
+// of an unimported package, which has the name of the item's label.
+type memberDecl struct {
+	uri     protocol.DocumentURI // the declaring file
+	pkgPath metadata.PackagePath
+}
+
+// Deferred reports whether the computation of some properties of the
+// item (its documentation, or the edits to import its package) was
+// deferred until the item is resolved by [CompletionItem.Resolve].
+func (item *CompletionItem) Deferred() bool {
+	return item.deferred != nil
+}
+
+// Resolve computes the properties of the item whose computation was
+// deferred by [Completion] because the client can resolve them lazily.
+// The snapshot must contain the same version of the file fh in which
+// completion was requested.
+//
+// Resolve does not modify the receiver, which may be shared; it
+// returns a new item.
+func (item *CompletionItem) Resolve(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) (CompletionItem, error) {
+	resolved := *item
+	resolved.deferred = nil
+	d := item.deferred
+	if d == nil {
+		return resolved, nil
+	}
+	if d.imp != nil {
+		src, err := fh.Content()
+		if err != nil {
+			return CompletionItem{}, err
+		}
+		edits, err := importEdits(snapshot.Options().Local, src, d.imp)
+		if err != nil {
+			return CompletionItem{}, err
+		}
+		resolved.AdditionalTextEdits = slices.Concat(edits, item.AdditionalTextEdits)
+	}
+	if d.obj != nil {
+		comment, err := golang.HoverDocForObject(ctx, snapshot, d.fset, d.obj)
+		if err != nil {
+			return CompletionItem{}, fmt.Errorf("failed to find Hover for %q: %v", d.obj.Name(), err)
+		}
+		resolved.setDocumentation(snapshot.Options(), comment, true)
+	}
+	if d.member != nil {
+		if err := resolved.resolveMember(ctx, snapshot, d.member); err != nil {
+			return CompletionItem{}, err
+		}
+	}
+	return resolved, nil
+}
+
+// resolveMember sets the documentation of an item for a member of an
+// unimported package, and, for a function, its signature, which are
+// not available from the quick parse used to find the candidate.
+func (item *CompletionItem) resolveMember(ctx context.Context, snapshot *cache.Snapshot, member *memberDecl) error {
+	fh, err := snapshot.ReadFile(ctx, member.uri)
+	if err != nil {
+		return err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return err
+	}
+
+	var (
+		comment *ast.CommentGroup
+		fn      *ast.FuncDecl
+	)
+	for _, decl := range pgf.File.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil && decl.Name.Name == item.Label {
+				comment, fn = decl.Doc, decl
+			}
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				var (
+					names         []*ast.Ident
+					doc, trailing *ast.CommentGroup
+				)
+				switch spec := spec.(type) {
+				case *ast.ValueSpec:
+					names, doc, trailing = spec.Names, spec.Doc, spec.Comment
+				case *ast.TypeSpec:
+					names, doc, trailing = []*ast.Ident{spec.Name}, spec.Doc, spec.Comment
+				}
+				for _, name := range names {
+					if name.Name == item.Label {
+						comment = cmp.Or(doc, decl.Doc, trailing)
+					}
+				}
+			}
+		}
+	}
+
+	if fn != nil {
+		var buf strings.Builder
+		if err := printer.Fprint(&buf, token.NewFileSet(), fn.Type); err == nil {
+			item.Detail = fmt.Sprintf("%s (from %q)", buf.String(), member.pkgPath)
+		}
+	}
+	if comment != nil {
+		item.setDocumentation(snapshot.Options(), comment, true)
+	}
+	return nil
 }
 
 // conversionEdits represents the string edits needed to make a type conversion
@@ -316,7 +472,13 @@ func (c *completer) importEdits(imp *importInfo) ([]protocol.TextEdit, error) {
 		return nil, err
 	}
 
-	return golang.ComputeImportFixEdits(c.snapshot.Options().Local, pgf.Src, &imports.ImportFix{
+	return importEdits(c.snapshot.Options().Local, pgf.Src, imp)
+}
+
+// importEdits produces the text edits necessary to add the given
+// import to the file with the given content.
+func importEdits(localPrefix string, src []byte, imp *importInfo) ([]protocol.TextEdit, error) {
+	return golang.ComputeImportFixEdits(localPrefix, src, &imports.ImportFix{
 		StmtInfo: imports.ImportInfo{
 			ImportPath: imp.importPath,
 			Name:       imp.name,
@@ -63,6 +63,24 @@ func Analyze(ctx context.Context, snapshot *cache.Snapshot, pkgIDs map[PackageID
 	return moremaps.Group(analysisDiagnostics, byURI), nil
 }
 
+// AnalyzeProgram reports the diagnostics of the whole-program phase of
+// go/analysis-framework analyzers, applied to the program formed by the
+// specified packages.
+//
+// If the provided tracker is non-nil, it may be used to provide notifications
+// of the ongoing analysis pass.
+func AnalyzeProgram(ctx context.Context, snapshot *cache.Snapshot, pkgIDs map[PackageID]*metadata.Package, tracker *progress.Tracker) (map[protocol.DocumentURI][]*cache.Diagnostic, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	analysisDiagnostics, err := snapshot.AnalyzeProgram(ctx, pkgIDs, tracker)
+	if err != nil {
+		return nil, err
+	}
+	return moremaps.Group(analysisDiagnostics, byURI), nil
+}
+
 // byURI is used for grouping diagnostics.
 func byURI(d *cache.Diagnostic) protocol.DocumentURI { return d.URI }
 
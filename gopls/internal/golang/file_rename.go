@@ -0,0 +1,403 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+// This file defines RenameFiles, which computes the edits needed to
+// keep the workspace consistent when files or directories are renamed
+// or moved (LSP workspace/willRenameFiles).
+
+import (
+	"context"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/metadata"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/parsego"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/pathutil"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/safetoken"
+	"github.com/tinygo-org/tinygo/x-tools/internal/diff"
+	"github.com/tinygo-org/tinygo/x-tools/internal/event"
+)
+
+// RenameFiles returns the edits required to keep the workspace
+// consistent after the specified files and directories are renamed.
+// The edits apply to the files under their old names, since the client
+// applies them before it performs the renamings.
+//
+// It updates:
+//   - the importers of each package whose files are all moved to
+//     another directory of the same module, to use its new import path;
+//   - the package clauses of such a package, if its name matched its
+//     old directory, to match the new one (along with references to it
+//     in importers, as when renaming a package);
+//   - the package clause of each Go file moved without the rest of its
+//     package, to match the package of its new directory;
+//   - go:embed patterns, and "testdata" string literals in tests, that
+//     refer to files renamed within the same package directory.
+func RenameFiles(ctx context.Context, snapshot *cache.Snapshot, renames []protocol.FileRename) (map[protocol.DocumentURI][]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "golang.RenameFiles")
+	defer done()
+
+	var moves fileMoves
+	for _, r := range renames {
+		oldName := protocol.DocumentURI(r.OldURI).Path()
+		newName := protocol.DocumentURI(r.NewURI).Path()
+		if oldName != "" && newName != "" {
+			moves = append(moves, [2]string{oldName, newName})
+		}
+	}
+
+	allMetadata, err := snapshot.AllMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make(map[protocol.DocumentURI][]diff.Edit)
+	moved, err := movePackages(ctx, snapshot, allMetadata, moves, edits)
+	if err != nil {
+		return nil, err
+	}
+	if err := moveFiles(ctx, snapshot, allMetadata, moves, moved, edits); err != nil {
+		return nil, err
+	}
+	if err := renameEmbeds(ctx, snapshot, allMetadata, moves, edits); err != nil {
+		return nil, err
+	}
+	return toProtocolEdits(ctx, snapshot, edits)
+}
+
+// fileMoves is a list of (old, new) pairs of file or directory names.
+type fileMoves [][2]string
+
+// lookup returns the new name of the named file, and whether it is
+// affected by one of the moves.
+func (moves fileMoves) lookup(name string) (string, bool) {
+	for _, m := range moves {
+		if pathutil.InDir(m[0], name) {
+			rel, err := filepath.Rel(m[0], name)
+			if err != nil {
+				continue
+			}
+			return filepath.Join(m[1], rel), true
+		}
+	}
+	return "", false
+}
+
+// movePackages computes the edits for each package whose Go files are
+// all moved to the same new directory: its importers must use its new
+// import path and, if its name matched the old directory, its package
+// clauses and the references to it must use the new directory name.
+//
+// Edits are written into the edits map. The result is the set of
+// moved packages.
+func movePackages(ctx context.Context, snapshot *cache.Snapshot, allMetadata []*metadata.Package, moves fileMoves, edits map[protocol.DocumentURI][]diff.Edit) (map[PackagePath]bool, error) {
+	type pkgMove struct {
+		newPath ImportPath
+		newName PackageName
+	}
+	var (
+		moved    = make(map[PackagePath]bool)
+		pkgMoves = make(map[PackagePath]pkgMove)
+	)
+	for _, mp := range allMetadata {
+		if mp.ForTest != "" || len(mp.GoFiles) == 0 || mp.Module == nil {
+			continue // consider only the primary variants of packages in modules
+		}
+		oldDir := mp.GoFiles[0].DirPath()
+		newDir := ""
+		for _, uri := range mp.GoFiles {
+			newName, ok := moves.lookup(uri.Path())
+			if !ok || newDir != "" && filepath.Dir(newName) != newDir {
+				newDir = ""
+				break
+			}
+			newDir = filepath.Dir(newName)
+		}
+		if newDir == "" || newDir == oldDir {
+			continue // not moved, or only in part
+		}
+		moved[mp.PkgPath] = true
+
+		// The import path of the package is relative to its
+		// module directory, which may itself have moved.
+		modDir := mp.Module.Dir
+		if modDir == oldDir {
+			continue // the import path of the module's root package is given by go.mod
+		}
+		if newModDir, ok := moves.lookup(modDir); ok {
+			modDir = newModDir
+		}
+		rel, err := filepath.Rel(modDir, newDir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue // moved outside its module: the new import path is unknown
+		}
+		newPath := path.Join(mp.Module.Path, filepath.ToSlash(rel))
+
+		newName := mp.Name
+		if base := filepath.Base(newDir); string(mp.Name) == filepath.Base(oldDir) &&
+			isValidIdentifier(base) && !strings.HasSuffix(base, "_test") {
+			newName = PackageName(base)
+		}
+		if PackagePath(newPath) != mp.PkgPath || newName != mp.Name {
+			pkgMoves[mp.PkgPath] = pkgMove{ImportPath(newPath), newName}
+		}
+	}
+
+	// As in renamePackage, update package clauses and imports of
+	// all variants of each moved package, and the package clauses
+	// of its x_test package.
+	for _, mp := range allMetadata {
+		if mp.IsIntermediateTestVariant() {
+			continue // for renaming, these variants are redundant
+		}
+		if mv, ok := pkgMoves[mp.PkgPath]; ok {
+			if mv.newName != mp.Name {
+				if err := renamePackageClause(ctx, mp, snapshot, mv.newName, edits); err != nil {
+					return nil, err
+				}
+			}
+			if err := renameImports(ctx, snapshot, mp, mv.newPath, mv.newName, edits); err != nil {
+				return nil, err
+			}
+		} else if mp.PkgPath == mp.ForTest+"_test" {
+			if mv, ok := pkgMoves[mp.ForTest]; ok && mv.newName+"_test" != mp.Name {
+				if err := renamePackageClause(ctx, mp, snapshot, mv.newName+"_test", edits); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return moved, nil
+}
+
+// moveFiles computes edits to the package clause of each Go file that
+// is moved to another directory without the rest of its package, so
+// that it matches the package in that directory or, if there is none,
+// the name of the directory.
+//
+// Edits are written into the edits map.
+func moveFiles(ctx context.Context, snapshot *cache.Snapshot, allMetadata []*metadata.Package, moves fileMoves, moved map[PackagePath]bool, edits map[protocol.DocumentURI][]diff.Edit) error {
+	var (
+		dirPkgs = make(map[string]PackageName) // directory -> name of the package that remains in it
+		files   []protocol.DocumentURI         // Go files moved apart from their package
+		seen    = make(map[protocol.DocumentURI]bool)
+	)
+	for _, mp := range allMetadata {
+		if mp.IsIntermediateTestVariant() {
+			continue
+		}
+		for _, uri := range mp.GoFiles {
+			if _, ok := moves.lookup(uri.Path()); !ok {
+				if mp.ForTest == "" {
+					dirPkgs[uri.DirPath()] = mp.Name
+				}
+			} else if !moved[mp.PkgPath] && !moved[mp.ForTest] && !seen[uri] {
+				seen[uri] = true
+				files = append(files, uri)
+			}
+		}
+	}
+
+	for _, uri := range files {
+		newName, _ := moves.lookup(uri.Path())
+		newDir := filepath.Dir(newName)
+		if newDir == uri.DirPath() {
+			continue // renamed within its directory
+		}
+
+		fh, err := snapshot.ReadFile(ctx, uri)
+		if err != nil {
+			return err
+		}
+		pgf, err := snapshot.ParseGo(ctx, fh, parsego.Header)
+		if err != nil {
+			return err
+		}
+		if pgf.File.Name == nil {
+			continue // no package declaration
+		}
+		oldPkgName := pgf.File.Name.Name
+
+		pkgName, ok := dirPkgs[newDir]
+		if !ok {
+			base := filepath.Base(newDir)
+			if oldPkgName == "main" || !isValidIdentifier(base) {
+				continue // a command may live in any directory
+			}
+			pkgName = PackageName(base)
+		}
+		if strings.HasSuffix(oldPkgName, "_test") && strings.HasSuffix(newName, "_test.go") {
+			pkgName += "_test" // an external test file stays external
+		}
+		if string(pkgName) == oldPkgName {
+			continue
+		}
+		edit, err := posEdit(pgf.Tok, pgf.File.Name.Pos(), pgf.File.Name.End(), string(pkgName))
+		if err != nil {
+			return err
+		}
+		edits[uri] = append(edits[uri], edit)
+	}
+	return nil
+}
+
+// renameEmbeds computes edits to the go:embed patterns that refer to
+// files or directories renamed within the same package directory, and
+// to string literals in the package's tests that refer to renamed
+// files beneath its testdata directory.
+//
+// Only literal file names and their directory prefixes are updated;
+// wildcard patterns and file names built by concatenation are not.
+//
+// Edits are written into the edits map.
+func renameEmbeds(ctx context.Context, snapshot *cache.Snapshot, allMetadata []*metadata.Package, moves fileMoves, edits map[protocol.DocumentURI][]diff.Edit) error {
+	dirFiles := make(map[string][]protocol.DocumentURI) // package directory -> Go files
+	seen := make(map[protocol.DocumentURI]bool)
+	for _, mp := range allMetadata {
+		for _, uri := range mp.GoFiles {
+			if !seen[uri] {
+				seen[uri] = true
+				dirFiles[uri.DirPath()] = append(dirFiles[uri.DirPath()], uri)
+			}
+		}
+	}
+
+	for _, m := range moves {
+		oldName, newName := m[0], m[1]
+		for dir, uris := range dirFiles {
+			if dir == oldName || !pathutil.InDir(dir, oldName) || !pathutil.InDir(dir, newName) {
+				continue // not a renaming within this package directory
+			}
+			oldRel, err1 := filepath.Rel(dir, oldName)
+			newRel, err2 := filepath.Rel(dir, newName)
+			if err1 != nil || err2 != nil || newRel == "." {
+				continue
+			}
+			oldRel, newRel = filepath.ToSlash(oldRel), filepath.ToSlash(newRel)
+			for _, uri := range uris {
+				if _, ok := moves.lookup(uri.Path()); ok {
+					continue // the referring file is itself moved
+				}
+				fileEdits, err := renameFileReferences(ctx, snapshot, uri, oldRel, newRel)
+				if err != nil {
+					return err
+				}
+				edits[uri] = append(edits[uri], fileEdits...)
+			}
+		}
+	}
+	return nil
+}
+
+// renameFileReferences returns the edits to the specified Go file that
+// update its references to the relative file name oldRel, or files
+// beneath it, to newRel.
+func renameFileReferences(ctx context.Context, snapshot *cache.Snapshot, uri protocol.DocumentURI, oldRel, newRel string) ([]diff.Edit, error) {
+	fh, err := snapshot.ReadFile(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []diff.Edit
+
+	// go:embed directives
+	for _, cg := range pgf.File.Comments {
+		for _, c := range cg.List {
+			args, ok := strings.CutPrefix(c.Text, "//go:embed")
+			if !ok || args != "" && args[0] != ' ' && args[0] != '\t' {
+				continue
+			}
+			offset, err := safetoken.Offset(pgf.Tok, c.Pos())
+			if err != nil {
+				return nil, err
+			}
+			patterns, err := parseGoEmbed(args, offset+len("//go:embed"))
+			if err != nil {
+				continue // ill-formed directive
+			}
+			for _, p := range patterns {
+				pattern, prefix := p.pattern, ""
+				if rest, ok := strings.CutPrefix(pattern, "all:"); ok {
+					pattern, prefix = rest, "all:"
+				}
+				if renamed, ok := renameRelPath(pattern, oldRel, newRel); ok {
+					edits = append(edits, diff.Edit{
+						Start: p.startOffset,
+						End:   p.endOffset,
+						New:   quoteEmbedPattern(pgf.Src[p.startOffset], prefix+renamed),
+					})
+				}
+			}
+		}
+	}
+
+	// testdata file names in tests
+	if strings.HasSuffix(uri.Path(), "_test.go") && strings.HasPrefix(oldRel, "testdata/") {
+		for cur := range pgf.Cursor.Preorder((*ast.BasicLit)(nil)) {
+			lit := cur.Node().(*ast.BasicLit)
+			if lit.Kind != token.STRING {
+				continue
+			}
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				continue
+			}
+			renamed, ok := renameRelPath(value, oldRel, newRel)
+			if !ok {
+				continue
+			}
+			text := strconv.Quote(renamed)
+			if lit.Value[0] == '`' && !strings.Contains(renamed, "`") {
+				text = "`" + renamed + "`"
+			}
+			edit, err := posEdit(pgf.Tok, lit.Pos(), lit.End(), text)
+			if err != nil {
+				return nil, err
+			}
+			edits = append(edits, edit)
+		}
+	}
+
+	return edits, nil
+}
+
+// renameRelPath returns the slash-separated relative file name name
+// with its prefix oldRel replaced by newRel, if name is oldRel or lies
+// beneath it.
+func renameRelPath(name, oldRel, newRel string) (string, bool) {
+	if name == oldRel {
+		return newRel, true
+	}
+	if rest, ok := strings.CutPrefix(name, oldRel+"/"); ok {
+		return newRel + "/" + rest, true
+	}
+	return "", false
+}
+
+// quoteEmbedPattern returns the go:embed pattern in the form of a
+// pattern whose first byte is quote: a Go string literal if quote is a
+// quotation mark or backquote (or if the pattern contains spaces), or
+// the pattern itself.
+func quoteEmbedPattern(quote byte, pattern string) string {
+	switch {
+	case quote == '`' && !strings.Contains(pattern, "`"):
+		return "`" + pattern + "`"
+	case quote == '"' || quote == '`' || strings.ContainsAny(pattern, " \t"):
+		return strconv.Quote(pattern)
+	default:
+		return pattern
+	}
+}
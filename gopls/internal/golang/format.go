@@ -17,6 +17,7 @@ import (
 	"strings"
 	"text/scanner"
 
+	"github.com/tinygo-org/tinygo/x-tools/go/ast/astutil"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/parsego"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
@@ -45,15 +46,169 @@ func Format(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]pr
 		return nil, fmt.Errorf("can't format %q: file is generated", fh.URI().Path())
 	}
 
+	formatted, err := formatFile(ctx, snapshot, fh, pgf)
+	if err != nil {
+		return nil, err
+	}
+	return computeTextEdits(ctx, pgf, formatted)
+}
+
+// RangeFormat formats the specified ranges of a file, returning edits
+// only within them.
+//
+// Each range is first expanded to the complete enclosing syntax
+// (statements, declarations, specs, and fields) and then to whole
+// lines, since a fragment of a statement cannot be formatted in
+// isolation. The file as a whole is formatted exactly as by [Format],
+// and only the resulting edits that lie within an expanded range are
+// reported, so the formatting of the ranges is consistent with that
+// of the whole file and the rest of the file is left untouched.
+func RangeFormat(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rngs []protocol.Range) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "golang.RangeFormat")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generated files shouldn't be edited. So, don't format them.
+	if ast.IsGenerated(pgf.File) {
+		return nil, fmt.Errorf("can't format %q: file is generated", fh.URI().Path())
+	}
+
+	spans := make([]formatSpan, 0, len(rngs))
+	for _, rng := range rngs {
+		start, end, err := expandFormatRange(pgf, rng)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, formatSpan{start, end})
+	}
+	return formatSpans(ctx, snapshot, fh, pgf, spans)
+}
+
+// A formatSpan is a span of a file, in byte offsets, to be formatted.
+type formatSpan struct{ start, end int }
+
+// formatSpans formats the file as a whole, exactly as by [Format], but
+// returns only the edits that lie entirely within one of the spans.
+func formatSpans(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pgf *parsego.File, spans []formatSpan) ([]protocol.TextEdit, error) {
+	formatted, err := formatFile(ctx, snapshot, fh, pgf)
+	if err != nil {
+		return nil, err
+	}
+
+	// Discard edits that are not entirely within some span.
+	edits := diff.Strings(string(pgf.Src), formatted)
+	inRange := edits[:0]
+	for _, edit := range edits {
+		for _, sp := range spans {
+			if sp.start <= edit.Start && edit.End <= sp.end {
+				inRange = append(inRange, edit)
+				break
+			}
+		}
+	}
+	return protocol.EditsFromDiffEdits(pgf.Mapper, inRange)
+}
+
+// expandFormatRange expands the range to the complete syntax that
+// encloses it, and then to whole lines, returning byte offsets.
+func expandFormatRange(pgf *parsego.File, rng protocol.Range) (int, int, error) {
+	start, end, err := pgf.Mapper.RangeOffsets(rng)
+	if err != nil {
+		return 0, 0, err
+	}
+	if pgf.ParseErr == nil {
+		startPos, endPos, err := pgf.RangePos(rng)
+		if err != nil {
+			return 0, 0, err
+		}
+		if nodeStart, nodeEnd, ok := enclosingFormatInterval(pgf.File, startPos, endPos); ok {
+			nstart, nend, err := safetoken.Offsets(pgf.Tok, nodeStart, nodeEnd)
+			if err != nil {
+				return 0, 0, err
+			}
+			start, end = min(start, nstart), max(end, nend)
+		}
+	}
+
+	// Expand to whole lines.
+	start = bytes.LastIndexByte(pgf.Src[:start], '\n') + 1
+	if end > start && pgf.Src[end-1] != '\n' {
+		if i := bytes.IndexByte(pgf.Src[end:], '\n'); i >= 0 {
+			end += i + 1
+		} else {
+			end = len(pgf.Src)
+		}
+	}
+	return start, end, nil
+}
+
+// enclosingFormatInterval returns the extent of the smallest syntax
+// that encloses the interval [start, end) and can be formatted as a
+// unit: a statement, declaration, spec, or field. If the interval
+// spans several elements of a list of statements or declarations,
+// the result spans just those elements, not the entire list.
+// It returns false if the interval contains no such syntax, for
+// example if it lies entirely within comments between declarations.
+func enclosingFormatInterval(f *ast.File, start, end token.Pos) (token.Pos, token.Pos, bool) {
+	path, _ := astutil.PathEnclosingInterval(f, start, end)
+	for _, n := range path {
+		switch n.(type) {
+		case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause, *ast.File:
+			// Use the elements of the list that intersect the interval.
+			var first, last ast.Node
+			ast.Inspect(n, func(child ast.Node) bool {
+				if child == n {
+					return true
+				}
+				if child != nil && isFormatUnit(child) && child.Pos() < end && start < child.End() {
+					if first == nil {
+						first = child
+					}
+					last = child
+				}
+				return false
+			})
+			if first != nil {
+				return first.Pos(), last.End(), true
+			}
+			if _, ok := n.(*ast.File); ok {
+				return token.NoPos, token.NoPos, false
+			}
+			return n.Pos(), n.End(), true
+		default:
+			if isFormatUnit(n) {
+				return n.Pos(), n.End(), true
+			}
+		}
+	}
+	return token.NoPos, token.NoPos, false
+}
+
+// isFormatUnit reports whether n is a statement, declaration, spec, or field.
+func isFormatUnit(n ast.Node) bool {
+	switch n.(type) {
+	case ast.Stmt, ast.Decl, ast.Spec, *ast.Field:
+		return true
+	}
+	return false
+}
+
+// formatFile returns the formatted content of the file, using gofmt
+// and any additional formatters enabled by the options.
+func formatFile(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pgf *parsego.File) (string, error) {
 	// Even if this file has parse errors, it might still be possible to format it.
 	// Using format.Node on an AST with errors may result in code being modified.
 	// Attempt to format the source of this file instead.
 	if pgf.ParseErr != nil {
 		formatted, err := formatSource(ctx, fh)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
-		return computeTextEdits(ctx, pgf, string(formatted))
+		return string(formatted), nil
 	}
 
 	// format.Node changes slightly from one release to another, so the version
@@ -63,7 +218,7 @@ func Format(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]pr
 	buf := &bytes.Buffer{}
 	fset := tokeninternal.FileSetFor(pgf.Tok)
 	if err := format.Node(buf, fset, pgf.File); err != nil {
-		return nil, err
+		return "", err
 	}
 	formatted := buf.String()
 
@@ -90,11 +245,11 @@ func Format(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]pr
 		}
 		b, err := gofumptFormat.Source(buf.Bytes(), opts)
 		if err != nil {
-			return nil, err
+			return "", err
 		}
 		formatted = string(b)
 	}
-	return computeTextEdits(ctx, pgf, formatted)
+	return formatted, nil
 }
 
 func formatSource(ctx context.Context, fh file.Handle) ([]byte, error) {
@@ -5,10 +5,14 @@
 package golang
 
 import (
+	"context"
+	"github.com/tinygo-org/tinygo/alt_go/token"
 	"strings"
 	"testing"
 
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/parsego"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/test/compare"
+	"github.com/tinygo-org/tinygo/x-tools/internal/diff"
 )
 
 func TestImportPrefix(t *testing.T) {
@@ -73,3 +77,78 @@ Hi description
 		}
 	}
 }
+
+func TestContinueComment(t *testing.T) {
+	// In each input, | marks the cursor after the newline.
+	for _, tt := range []struct {
+		name, input, want string
+	}{
+		{
+			"doc comment",
+			"package a\n\n// F does x.\n|\nfunc F() {}\n",
+			"package a\n\n// F does x.\n// \nfunc F() {}\n",
+		},
+		{
+			"middle of doc comment",
+			"package a\n\ntype T struct {\n\t// a\n\t|\n\t// b\n\tF int\n}\n",
+			"package a\n\ntype T struct {\n\t// a\n\t// \n\t// b\n\tF int\n}\n",
+		},
+		{
+			"code block",
+			"package a\n\n// Example:\n//\n//\tx := 1\n|\nfunc F() {}\n",
+			"package a\n\n// Example:\n//\n//\tx := 1\n//\t\nfunc F() {}\n",
+		},
+		{
+			"list item",
+			"package a\n\n// Items:\n//   - first\n|\nvar V int\n",
+			"package a\n\n// Items:\n//   - first\n//     \nvar V int\n",
+		},
+		{
+			"split comment",
+			"package a\n\nfunc f() {\n\t// hello\n\t|world\n}\n",
+			"package a\n\nfunc f() {\n\t// hello\n\t// world\n}\n",
+		},
+		{
+			"ordinary comment",
+			"package a\n\nfunc f() {\n\t// x\n\t|\n\treturn\n}\n",
+			"",
+		},
+		{
+			"directive",
+			"package a\n\n//go:noinline\n|\nfunc f() {}\n",
+			"",
+		},
+		{
+			"raw string",
+			"package a\n\nvar s = `\n// x\n|\n`\n",
+			"",
+		},
+		{
+			"already continued",
+			"package a\n\n// F does x.\n// |\nfunc F() {}\n",
+			"",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			offset := strings.Index(tt.input, "|")
+			src := tt.input[:offset] + tt.input[offset+1:]
+			pgf, _ := parsego.Parse(context.Background(), token.NewFileSet(), "file://a.go", []byte(src), parsego.Full, false)
+			edit, ok := continueComment(pgf, offset)
+			if !ok {
+				if tt.want != "" {
+					t.Errorf("continueComment: no edit, want %q", tt.want)
+				}
+				return
+			}
+			got, err := diff.Apply(src, []diff.Edit{edit})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == "" {
+				t.Errorf("continueComment: got %q, want no edit", got)
+			} else if d := compare.Text(tt.want, got); d != "" {
+				t.Errorf("continueComment: unexpected result (-want +got):\n%s", d)
+			}
+		})
+	}
+}
@@ -107,35 +107,9 @@ func implementations(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 	// If the resulting object has a position, we can expand the search to types
 	// in the declaring package(s). In this case, we must re-type check these
 	// packages in the same realm.
-	var (
-		declOffset int
-		declURI    protocol.DocumentURI
-		localPkgs  []*cache.Package
-	)
-	if obj.Pos().IsValid() { // no local package for error or error.Error
-		declPosn := safetoken.StartPosition(pkg.FileSet(), obj.Pos())
-		declOffset = declPosn.Offset
-		// Type-check the declaring package (incl. variants) for use
-		// by the "local" search, which uses type information to
-		// enumerate all types within the package that satisfy the
-		// query type, even those defined local to a function.
-		declURI = protocol.URIFromPath(declPosn.Filename)
-		declMPs, err := snapshot.MetadataForFile(ctx, declURI)
-		if err != nil {
-			return nil, err
-		}
-		metadata.RemoveIntermediateTestVariants(&declMPs)
-		if len(declMPs) == 0 {
-			return nil, fmt.Errorf("no packages for file %s", declURI)
-		}
-		ids := make([]PackageID, len(declMPs))
-		for i, mp := range declMPs {
-			ids[i] = mp.ID
-		}
-		localPkgs, err = snapshot.TypeCheck(ctx, ids...)
-		if err != nil {
-			return nil, err
-		}
+	declURI, declOffset, localPkgs, err := declaringPackages(ctx, snapshot, pkg.FileSet(), obj)
+	if err != nil {
+		return nil, err
 	}
 
 	pkg = nil // no longer used
@@ -178,22 +152,14 @@ func implementations(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 	// so that we can overlap index lookup with typechecking.
 	// I suspect a number of algorithms on the result of TypeCheck could
 	// be optimized by being applied as soon as each package is available.
-	globalMetas, err := snapshot.AllMetadata(ctx)
-	if err != nil {
-		return nil, err
-	}
-	metadata.RemoveIntermediateTestVariants(&globalMetas)
-	globalIDs := make([]PackageID, 0, len(globalMetas))
-
 	var pkgPath PackagePath
 	if obj.Pkg() != nil { // nil for error
 		pkgPath = PackagePath(obj.Pkg().Path())
 	}
-	for _, mp := range globalMetas {
-		if mp.PkgPath == pkgPath {
-			continue // declaring package is handled by local implementation
-		}
-		globalIDs = append(globalIDs, mp.ID)
+	// The declaring package is handled by the local implementation.
+	globalIDs, err := otherPackageIDs(ctx, snapshot, pkgPath)
+	if err != nil {
+		return nil, err
 	}
 	indexes, err := snapshot.MethodSets(ctx, globalIDs...)
 	if err != nil {
@@ -215,28 +181,11 @@ func implementations(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 		declPkg := localPkg
 		group.Go(func() error {
 			pkgID := declPkg.Metadata().ID
-			declFile, err := declPkg.File(declURI)
-			if err != nil {
-				return err // "can't happen"
-			}
-
-			// Find declaration of corresponding object
-			// in this package based on (URI, offset).
-			pos, err := safetoken.Pos(declFile.Tok, declOffset)
+			// Shadow obj, queryType, and queryMethod in this package.
+			obj, err := declaredObjectAt(declPkg, declURI, declOffset) // may be nil
 			if err != nil {
-				return err // also "can't happen"
+				return err
 			}
-			// TODO(adonovan): simplify: use objectsAt?
-			path := pathEnclosingObjNode(declFile.File, pos)
-			if path == nil {
-				return ErrNoIdentFound // checked earlier
-			}
-			id, ok := path[0].(*ast.Ident)
-			if !ok {
-				return ErrNoIdentFound // checked earlier
-			}
-			// Shadow obj, queryType, and queryMethod in this package.
-			obj := declPkg.TypesInfo().ObjectOf(id) // may be nil
 			queryType, queryMethod := typeOrMethod(obj)
 			if queryType == nil {
 				return fmt.Errorf("querying method sets in package %q: %v", pkgID, err)
@@ -279,6 +228,80 @@ func implementations(ctx context.Context, snapshot *cache.Snapshot, fh file.Hand
 	return locs, nil
 }
 
+// declaringPackages type-checks the packages (including test
+// variants) that declare obj, for use by a "local" search, which uses
+// type information to enumerate all types within the package,
+// even those defined local to a function. It returns the URI and
+// offset of the declaration, for use with [declaredObjectAt].
+//
+// The result is empty if obj has no position, such as error or error.Error.
+func declaringPackages(ctx context.Context, snapshot *cache.Snapshot, fset *token.FileSet, obj types.Object) (protocol.DocumentURI, int, []*cache.Package, error) {
+	if !obj.Pos().IsValid() {
+		return "", 0, nil, nil
+	}
+	declPosn := safetoken.StartPosition(fset, obj.Pos())
+	declURI := protocol.URIFromPath(declPosn.Filename)
+	declMPs, err := snapshot.MetadataForFile(ctx, declURI)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	metadata.RemoveIntermediateTestVariants(&declMPs)
+	if len(declMPs) == 0 {
+		return "", 0, nil, fmt.Errorf("no packages for file %s", declURI)
+	}
+	ids := make([]PackageID, len(declMPs))
+	for i, mp := range declMPs {
+		ids[i] = mp.ID
+	}
+	localPkgs, err := snapshot.TypeCheck(ctx, ids...)
+	if err != nil {
+		return "", 0, nil, err
+	}
+	return declURI, declPosn.Offset, localPkgs, nil
+}
+
+// declaredObjectAt returns the object denoted by the identifier at
+// the specified offset of a file in pkg, which must be one of the
+// packages returned by [declaringPackages]. The result may be nil.
+func declaredObjectAt(pkg *cache.Package, declURI protocol.DocumentURI, declOffset int) (types.Object, error) {
+	declFile, err := pkg.File(declURI)
+	if err != nil {
+		return nil, err // "can't happen"
+	}
+	pos, err := safetoken.Pos(declFile.Tok, declOffset)
+	if err != nil {
+		return nil, err // also "can't happen"
+	}
+	// TODO(adonovan): simplify: use objectsAt?
+	path := pathEnclosingObjNode(declFile.File, pos)
+	if path == nil {
+		return nil, ErrNoIdentFound // checked earlier
+	}
+	id, ok := path[0].(*ast.Ident)
+	if !ok {
+		return nil, ErrNoIdentFound // checked earlier
+	}
+	return pkg.TypesInfo().ObjectOf(id), nil
+}
+
+// otherPackageIDs returns the IDs of all packages in the forward
+// transitive closure of the workspace, other than those whose path
+// is pkgPath, excluding intermediate test variants.
+func otherPackageIDs(ctx context.Context, snapshot *cache.Snapshot, pkgPath PackagePath) ([]PackageID, error) {
+	globalMetas, err := snapshot.AllMetadata(ctx)
+	if err != nil {
+		return nil, err
+	}
+	metadata.RemoveIntermediateTestVariants(&globalMetas)
+	globalIDs := make([]PackageID, 0, len(globalMetas))
+	for _, mp := range globalMetas {
+		if mp.PkgPath != pkgPath {
+			globalIDs = append(globalIDs, mp.ID)
+		}
+	}
+	return globalIDs, nil
+}
+
 // offsetToLocation converts an offset-based position to a protocol.Location,
 // which requires reading the file.
 func offsetToLocation(ctx context.Context, snapshot *cache.Snapshot, filename string, start, end int) (protocol.Location, error) {
@@ -480,7 +503,18 @@ func concreteImplementsIntf(msets *typeutil.MethodSetCache, x, y types.Type) boo
 	if xiface {
 		x, y = y, x
 	}
-	// Inv: y is an interface type.
+	return implementsIntf(msets, x, y)
+}
+
+// implementsIntf reports whether type x, which may itself be an
+// interface, implements interface type y.
+//
+// If one or both types are generic, the result indicates whether the
+// interface may be implemented under some instantiation.
+func implementsIntf(msets *typeutil.MethodSetCache, x, y types.Type) bool {
+	if !types.IsInterface(y) {
+		return false
+	}
 
 	// For each interface method of y, check that x has it too.
 	// It is not necessary to compute x's complete method set.
@@ -0,0 +1,142 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/ast/astutil"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/safetoken"
+	"github.com/tinygo-org/tinygo/x-tools/internal/event"
+)
+
+// InlineValue returns the inline values to display in the specified
+// range of the file while a debugger is stopped at the given location.
+//
+// The values are the references, in the innermost function enclosing
+// the stopped location and up to the end of its last line, to the
+// variables that are visible there: locals, parameters, named results,
+// and variables captured by a closure. Each reference to such a
+// variable is reported as a variable lookup, and each selection of a
+// field from one (such as x.f.g) as an evaluatable expression. A
+// reference to a variable that is not yet declared, or is shadowed, at
+// the stopped location is not reported, since its value is not
+// available to the debugger.
+func InlineValue(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, rng, stopped protocol.Range) ([]protocol.InlineValue, error) {
+	ctx, done := event.Start(ctx, "golang.InlineValue")
+	defer done()
+
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	start, end, err := pgf.RangePos(rng)
+	if err != nil {
+		return nil, err
+	}
+	stopStart, stopEnd, err := pgf.RangePos(stopped)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the innermost function enclosing the stopped location.
+	var fn ast.Node
+	path, _ := astutil.PathEnclosingInterval(pgf.File, stopStart, stopStart)
+	for _, n := range path {
+		if _, ok := n.(*ast.FuncLit); ok {
+			fn = n
+			break
+		}
+		if _, ok := n.(*ast.FuncDecl); ok {
+			fn = n
+			break
+		}
+	}
+	if fn == nil {
+		return nil, nil // not stopped in a function
+	}
+	// Values are displayed up to the end of the stopped line.
+	stopLineEnd := token.Pos(pgf.Tok.Base() + pgf.Tok.Size())
+	if line := safetoken.Line(pgf.Tok, stopEnd); line < pgf.Tok.LineCount() {
+		stopLineEnd = pgf.Tok.LineStart(line + 1)
+	}
+	start = max(start, fn.Pos())
+	end = min(end, stopLineEnd, fn.End())
+
+	info := pkg.TypesInfo()
+	scope := pkg.Types().Scope().Innermost(stopStart)
+	if scope == nil {
+		return nil, nil
+	}
+
+	// visible reports whether id refers to a local variable that is
+	// visible (and not shadowed) at the stopped location.
+	visible := func(id *ast.Ident) bool {
+		v, ok := info.Uses[id].(*types.Var)
+		if !ok {
+			v, ok = info.Defs[id].(*types.Var)
+		}
+		if !ok || v.Name() == "_" || v.IsField() || v.Parent() == nil || v.Parent() == pkg.Types().Scope() {
+			return false // not a local variable
+		}
+		_, obj := scope.LookupParent(v.Name(), stopStart)
+		return obj == v
+	}
+
+	var values []protocol.InlineValue
+	add := func(n ast.Node, value func(protocol.Range) any) {
+		if rng, err := pgf.NodeRange(n); err == nil {
+			values = append(values, protocol.InlineValue{Value: value(rng)})
+		}
+	}
+	ast.Inspect(fn, func(n ast.Node) bool {
+		if n == nil || n.End() <= start || n.Pos() >= end {
+			return false // outside the range
+		}
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return n == fn // skip nested functions
+		case *ast.SelectorExpr:
+			if n.Pos() >= start && n.End() <= end && isFieldSelection(info, n, visible) {
+				add(n, func(rng protocol.Range) any {
+					return protocol.InlineValueEvaluatableExpression{Range: rng}
+				})
+				return false
+			}
+		case *ast.Ident:
+			if n.Pos() >= start && n.End() <= end && visible(n) {
+				add(n, func(rng protocol.Range) any {
+					return protocol.InlineValueVariableLookup{Range: rng, CaseSensitiveLookup: true}
+				})
+			}
+		}
+		return true
+	})
+	return values, nil
+}
+
+// isFieldSelection reports whether sel is a chain of field selections
+// x.f.g whose operand x is a variable identifier satisfying pred.
+func isFieldSelection(info *types.Info, sel *ast.SelectorExpr, pred func(*ast.Ident) bool) bool {
+	for {
+		if s, ok := info.Selections[sel]; !ok || s.Kind() != types.FieldVal {
+			return false
+		}
+		switch x := ast.Unparen(sel.X).(type) {
+		case *ast.SelectorExpr:
+			sel = x
+		case *ast.Ident:
+			return pred(x)
+		default:
+			return false
+		}
+	}
+}
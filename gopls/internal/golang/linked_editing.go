@@ -0,0 +1,249 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/ast/astutil"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/internal/event"
+)
+
+// LinkedEditingRange returns the ranges that an editor may edit
+// simultaneously with the identifier at the given position, so that
+// simple renamings take effect as the user types, without a request
+// to Rename. It returns nil if there are no such ranges.
+//
+// Two kinds of identifiers have linked ranges:
+//   - a local constant or variable (including a parameter or result)
+//     is linked with all its references in the enclosing function;
+//   - the name of a struct field is linked with the key of its json
+//     and yaml struct tags, if they are identical to the name.
+//
+// Unlike Rename, it performs no checks for conflicts.
+func LinkedEditingRange(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, position protocol.Position) (*protocol.LinkedEditingRanges, error) {
+	ctx, done := event.Start(ctx, "golang.LinkedEditingRange")
+	defer done()
+
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	pos, err := pgf.PositionPos(position)
+	if err != nil {
+		return nil, err
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, pos, pos)
+	if len(path) == 0 {
+		return nil, nil
+	}
+	// As in Highlight, prefer an identifier that ends at pos.
+	if _, ok := path[0].(*ast.Ident); !ok {
+		if p, _ := astutil.PathEnclosingInterval(pgf.File, pos-1, pos-1); len(p) > 0 {
+			if _, ok := p[0].(*ast.Ident); ok {
+				path = p
+			}
+		}
+	}
+
+	var spans [][2]token.Pos
+	switch n := path[0].(type) {
+	case *ast.Ident:
+		if field, ok := enclosingStructField(path); ok {
+			spans = linkedFieldTagSpans(field, n)
+		} else {
+			spans = linkedLocalSpans(pkg.TypesInfo(), path, n)
+		}
+	case *ast.BasicLit:
+		if field, ok := enclosingStructField(path); ok && field.Tag == n {
+			spans = linkedFieldTagSpans(field, nil)
+			if !spansContain(spans, pos) {
+				spans = nil // cursor is not on the linked key
+			}
+		}
+	}
+	if len(spans) < 2 {
+		return nil, nil // nothing to link
+	}
+
+	result := &protocol.LinkedEditingRanges{}
+	for _, span := range spans {
+		rng, err := pgf.PosRange(span[0], span[1])
+		if err != nil {
+			return nil, err
+		}
+		result.Ranges = append(result.Ranges, rng)
+	}
+	return result, nil
+}
+
+// enclosingStructField returns the field of a struct type of which
+// path[0] is a name or the tag.
+func enclosingStructField(path []ast.Node) (*ast.Field, bool) {
+	if len(path) < 4 {
+		return nil, false
+	}
+	field, ok := path[1].(*ast.Field)
+	if !ok {
+		return nil, false
+	}
+	_, ok = path[3].(*ast.StructType) // path[2] is the FieldList
+	return field, ok
+}
+
+// linkedLocalSpans returns the spans of the declaration and references
+// of the local constant or variable denoted by id, whose enclosing path
+// is given.
+func linkedLocalSpans(info *types.Info, path []ast.Node, id *ast.Ident) [][2]token.Pos {
+	obj := info.ObjectOf(id)
+	switch obj.(type) {
+	case *types.Var, *types.Const:
+	default:
+		return nil
+	}
+	if obj.Parent() == nil || obj.Parent() == obj.Pkg().Scope() || obj.Parent() == types.Universe {
+		return nil // not local (or a struct field)
+	}
+	if info.Implicits != nil {
+		for _, implicit := range info.Implicits {
+			if implicit == obj {
+				return nil // e.g. a type-switch variable, which has several objects
+			}
+		}
+	}
+
+	// A local object's declaration and references are
+	// confined to the outermost enclosing function.
+	var body ast.Node
+	for _, n := range path {
+		switch n.(type) {
+		case *ast.FuncDecl, *ast.FuncLit:
+			body = n
+		}
+	}
+	if body == nil {
+		return nil
+	}
+
+	var spans [][2]token.Pos
+	ast.Inspect(body, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok && info.ObjectOf(id) == obj {
+			spans = append(spans, [2]token.Pos{id.Pos(), id.End()})
+		}
+		return true
+	})
+	return spans
+}
+
+// linkedFieldTagSpans returns the spans of the name of a struct field
+// and of each key in its json and yaml tags that is identical to the
+// name. The field must have a single name; if id is non-nil, it must
+// be that name.
+func linkedFieldTagSpans(field *ast.Field, id *ast.Ident) [][2]token.Pos {
+	if len(field.Names) != 1 || id != nil && id != field.Names[0] {
+		return nil // embedded field, or not the field's name
+	}
+	name := field.Names[0]
+	if field.Tag == nil || !strings.HasPrefix(field.Tag.Value, "`") {
+		return nil // no tag, or an interpreted string literal whose offsets are not simple
+	}
+
+	spans := [][2]token.Pos{{name.Pos(), name.End()}}
+	tag := field.Tag.Value[1 : len(field.Tag.Value)-1]
+	base := field.Tag.Pos() + 1 // position of tag[0]
+	for _, kv := range structTagValues(tag) {
+		if kv.key != "json" && kv.key != "yaml" {
+			continue
+		}
+		// The key is the part of the value before the first comma.
+		value := tag[kv.start:kv.end]
+		if i := strings.IndexByte(value, ','); i >= 0 {
+			value = value[:i]
+		}
+		if value == name.Name {
+			start := base + token.Pos(kv.start)
+			spans = append(spans, [2]token.Pos{start, start + token.Pos(len(value))})
+		}
+	}
+	return spans
+}
+
+// A structTagValue records the key of a conventional struct tag
+// key:"value" pair and the offsets of its value within the tag,
+// excluding quotation marks.
+type structTagValue struct {
+	key        string
+	start, end int
+}
+
+// structTagValues returns the key/value pairs of a conventional struct
+// tag, following the syntax of [reflect.StructTag.Lookup]. It returns
+// only values that contain no escape sequences, so that their offsets
+// within the tag correspond to their text.
+func structTagValues(tag string) []structTagValue {
+	var (
+		values []structTagValue
+		offset int
+	)
+	for {
+		// Skip leading space.
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag, offset = tag[i:], offset+i
+		if tag == "" {
+			break
+		}
+
+		// Scan to colon. A space, a quote or a control character is a syntax error.
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		key := tag[:i]
+		tag, offset = tag[i+1:], offset+i+1
+
+		// Scan quoted string to find value.
+		i = 1
+		escaped := false
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+				escaped = true
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		if !escaped {
+			values = append(values, structTagValue{key, offset + 1, offset + i})
+		}
+		tag, offset = tag[i+1:], offset+i+1
+	}
+	return values
+}
+
+// spansContain reports whether pos lies within (or at the end of)
+// one of the spans.
+func spansContain(spans [][2]token.Pos, pos token.Pos) bool {
+	for _, span := range spans {
+		if span[0] <= pos && pos <= span[1] {
+			return true
+		}
+	}
+	return false
+}
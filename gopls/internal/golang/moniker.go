@@ -0,0 +1,142 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/types/objectpath"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/metadata"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/internal/event"
+	"github.com/tinygo-org/tinygo/x-tools/internal/stdlib"
+)
+
+// MonikerScheme is the scheme of the monikers reported by gopls.
+const MonikerScheme = "go"
+
+// Moniker returns the moniker of the exported symbol referred to at
+// the given position, or nil if there is none.
+//
+// A moniker identifies a symbol independent of the workspace, so that
+// an indexer can link references to it across repositories. Its
+// identifier has the form
+//
+//	module@version package objectpath
+//
+// where module and version identify the module that declares the
+// package (the version is omitted if unknown, as for workspace
+// modules; the standard library is module "std" at the Go version of
+// the view), and objectpath is the [objectpath.Path] of the symbol
+// within the package. A reference to an imported package has a
+// moniker with no object path.
+//
+// The moniker kind is "export" for a symbol declared in the package of
+// the file, and "import" for one declared in another package.
+func Moniker(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp protocol.Position) ([]protocol.Moniker, error) {
+	ctx, done := event.Start(ctx, "golang.Moniker")
+	defer done()
+
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	pos, err := pgf.PositionPos(pp)
+	if err != nil {
+		return nil, err
+	}
+	_, obj, _ := referencedObject(pkg, pgf, pos)
+	for _, spec := range pgf.File.Imports {
+		// As in importDefinition, accept a query immediately after an ImportSpec.
+		if spec.Path.Pos() <= pos && pos <= spec.Path.End() {
+			if pkgName := pkg.TypesInfo().PkgNameOf(spec); pkgName != nil {
+				obj = pkgName
+			}
+		}
+	}
+	if obj == nil || obj.Pkg() == nil {
+		return nil, nil // no object, or built-in
+	}
+
+	var (
+		declPkg *types.Package // package of the symbol
+		path    objectpath.Path
+	)
+	if pkgName, ok := obj.(*types.PkgName); ok {
+		declPkg = pkgName.Imported()
+	} else {
+		if !obj.Exported() {
+			return nil, nil
+		}
+		path, err = objectpath.For(obj)
+		if err != nil {
+			return nil, nil // not addressable from the package scope, e.g. local
+		}
+		declPkg = obj.Pkg()
+	}
+
+	// Find the module of the declaring package.
+	mp := pkg.Metadata()
+	if declPkg != pkg.Types() {
+		mp = snapshot.Metadata(mp.DepsByPkgPath[PackagePath(declPkg.Path())])
+		if mp == nil {
+			// Not a direct dependency: search by declaring file.
+			if declFile := pkg.FileSet().File(obj.Pos()); declFile != nil {
+				mp = findFileInDeps(snapshot, pkg.Metadata(), protocol.URIFromPath(declFile.Name()))
+			}
+		}
+	}
+	identifier := monikerIdentifier(snapshot, mp, declPkg.Path(), path)
+
+	kind := protocol.Import
+	if declPkg == pkg.Types() {
+		kind = protocol.Export
+	}
+	return []protocol.Moniker{{
+		Scheme:     MonikerScheme,
+		Identifier: identifier,
+		Unique:     protocol.Scheme,
+		Kind:       &kind,
+	}}, nil
+}
+
+// monikerIdentifier returns the moniker identifier of the object with
+// the given path (empty for the package itself) in the package with
+// the given path, whose metadata (if known) is mp.
+func monikerIdentifier(snapshot *cache.Snapshot, mp *metadata.Package, pkgPath string, path objectpath.Path) string {
+	var module, version string
+	switch {
+	case mp != nil && mp.Module != nil:
+		module, version = mp.Module.Path, mp.Module.Version
+		if r := mp.Module.Replace; r != nil && r.Version != "" {
+			module, version = r.Path, r.Version
+		}
+	case stdlib.PackageSymbols[pkgPath] != nil || pkgPath == "unsafe":
+		module = "std"
+		if v := snapshot.GoVersionString(); strings.HasPrefix(v, "go") {
+			version = v
+		}
+	}
+
+	var b strings.Builder
+	if module != "" {
+		b.WriteString(module)
+		if version != "" {
+			b.WriteString("@")
+			b.WriteString(version)
+		}
+		b.WriteString(" ")
+	}
+	b.WriteString(pkgPath)
+	if path != "" {
+		b.WriteString(" ")
+		b.WriteString(string(path))
+	}
+	return b.String()
+}
@@ -0,0 +1,237 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"bytes"
+	"context"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"regexp"
+	"strings"
+
+	"github.com/tinygo-org/tinygo/x-tools/go/ast/astutil"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/parsego"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/util/safetoken"
+	"github.com/tinygo-org/tinygo/x-tools/internal/diff"
+	"github.com/tinygo-org/tinygo/x-tools/internal/event"
+)
+
+// OnTypeFormat returns the edits to make after the character ch was
+// typed, ending at the given position:
+//
+//   - after a closing brace, it formats the statement or declaration
+//     that the brace completes, as by [RangeFormat];
+//   - after a newline that splits a line comment, or ends a line of a
+//     doc comment, it continues the comment on the new line, with the
+//     indentation of the comment and, within a doc comment, that of a
+//     code block or list item.
+//
+// The edits are limited to the enclosing statement, or to the new
+// line. Nothing is reported for a file that does not parse, or is
+// generated, since the user is likely to be in the middle of an edit.
+func OnTypeFormat(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp protocol.Position, ch string) ([]protocol.TextEdit, error) {
+	ctx, done := event.Start(ctx, "golang.OnTypeFormat")
+	defer done()
+
+	pgf, err := snapshot.ParseGo(ctx, fh, parsego.Full)
+	if err != nil {
+		return nil, err
+	}
+	if ast.IsGenerated(pgf.File) {
+		return nil, nil
+	}
+	offset, err := pgf.Mapper.PositionOffset(pp)
+	if err != nil {
+		return nil, err
+	}
+
+	switch ch {
+	case "}":
+		if pgf.ParseErr != nil || offset == 0 || pgf.Src[offset-1] != '}' {
+			return nil, nil
+		}
+		start, end, ok := closedStmtInterval(pgf, offset)
+		if !ok {
+			return nil, nil
+		}
+		rng, err := pgf.PosRange(start, end)
+		if err != nil {
+			return nil, err
+		}
+		spanStart, spanEnd, err := expandFormatRange(pgf, rng)
+		if err != nil {
+			return nil, err
+		}
+		return formatSpans(ctx, snapshot, fh, pgf, []formatSpan{{spanStart, spanEnd}})
+
+	case "\n":
+		edit, ok := continueComment(pgf, offset)
+		if !ok {
+			return nil, nil
+		}
+		return protocol.EditsFromDiffEdits(pgf.Mapper, []diff.Edit{edit})
+	}
+	return nil, nil
+}
+
+// closedStmtInterval returns the extent of the innermost statement,
+// declaration, spec, or field containing the syntax closed by the
+// brace just before offset.
+func closedStmtInterval(pgf *parsego.File, offset int) (token.Pos, token.Pos, bool) {
+	end, err := safetoken.Pos(pgf.Tok, offset)
+	if err != nil {
+		return token.NoPos, token.NoPos, false
+	}
+	path, _ := astutil.PathEnclosingInterval(pgf.File, end-1, end)
+	for i, n := range path {
+		if n.End() != end {
+			continue // not closed by the brace
+		}
+		for _, n := range path[i:] {
+			if block, ok := n.(*ast.BlockStmt); ok && !isStmtListElem(path, block) {
+				continue // the body of a function or statement
+			}
+			if isFormatUnit(n) {
+				return n.Pos(), n.End(), true
+			}
+		}
+		break
+	}
+	return token.NoPos, token.NoPos, false
+}
+
+// isStmtListElem reports whether the block, which is in path, is an
+// element of a list of statements, rather than the body of a function
+// or statement.
+func isStmtListElem(path []ast.Node, block *ast.BlockStmt) bool {
+	for i, n := range path {
+		if n == block && i+1 < len(path) {
+			switch path[i+1].(type) {
+			case *ast.BlockStmt, *ast.CaseClause, *ast.CommClause, *ast.LabeledStmt:
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listMarkerRx matches the marker of an item of a doc comment list.
+var listMarkerRx = regexp.MustCompile(`^([-*+•]|[0-9]+[.)]) `)
+
+// continueComment returns the edit that continues the line comment on
+// the line before the new line containing offset, if that line is a
+// line comment that was split by the newline, or a line of a doc
+// comment.
+func continueComment(pgf *parsego.File, offset int) (diff.Edit, bool) {
+	src := pgf.Src
+	lineStart := bytes.LastIndexByte(src[:offset], '\n') + 1
+	if lineStart == 0 {
+		return diff.Edit{}, false // no previous line
+	}
+	prevStart := bytes.LastIndexByte(src[:lineStart-1], '\n') + 1
+	prevLine := strings.TrimSuffix(string(src[prevStart:lineStart-1]), "\r")
+	rest := strings.TrimLeft(prevLine, " \t")
+	indent := prevLine[:len(prevLine)-len(rest)]
+	if !strings.HasPrefix(rest, "//") {
+		return diff.Edit{}, false
+	}
+
+	// Find the comment, which must begin the line, and its group.
+	var group *ast.CommentGroup
+	slash := prevStart + len(indent)
+	for _, cg := range pgf.File.Comments {
+		for _, c := range cg.List {
+			if offset, err := safetoken.Offset(pgf.Tok, c.Slash); err == nil && offset == slash {
+				group = cg
+			}
+		}
+	}
+	if group == nil {
+		return diff.Edit{}, false // e.g. within a raw string literal
+	}
+	text := rest[len("//"):]
+	if text != "" && text[0] != ' ' && text[0] != '\t' {
+		return diff.Edit{}, false // a directive such as //go:build
+	}
+
+	// Continue the comment only if the newline split it, or if it
+	// is a doc comment; a newline at the end of an ordinary comment
+	// likely ends it.
+	line := src[lineStart:]
+	if i := bytes.IndexByte(line, '\n'); i >= 0 {
+		line = line[:i]
+	}
+	lineText := bytes.TrimLeft(line, " \t")
+	if bytes.HasPrefix(lineText, []byte("//")) {
+		return diff.Edit{}, false // already continued, e.g. by the editor
+	}
+	split := len(bytes.TrimSpace(lineText)) > 0
+	if !split {
+		// The new line separates the comment from whatever follows,
+		// so the comment is a doc comment if what follows the new
+		// line is a documented node or the rest of its doc comment.
+		next := lineStart + len(line) + 1
+		if next < len(src) {
+			next += len(src[next:]) - len(bytes.TrimLeft(src[next:], " \t"))
+		}
+		if !isDocComment(pgf, group, next) {
+			return diff.Edit{}, false
+		}
+	}
+
+	// Preserve the indentation of a code block or list item.
+	docIndent := " "
+	if body := strings.TrimLeft(text, " \t"); body != "" {
+		docIndent = text[:len(text)-len(body)]
+		if m := listMarkerRx.FindString(body); m != "" {
+			docIndent += strings.Repeat(" ", len([]rune(m)))
+		}
+	}
+
+	curIndent := len(line) - len(lineText)
+	return diff.Edit{Start: lineStart, End: lineStart + curIndent, New: indent + "//" + docIndent}, true
+}
+
+// isDocComment reports whether the comment group is the doc comment
+// of the file or of a declaration, spec, or field, or would be but for
+// a blank line before the given offset of the start of the following
+// node or comment group.
+func isDocComment(pgf *parsego.File, group *ast.CommentGroup, next int) bool {
+	at := func(pos token.Pos) bool {
+		offset, err := safetoken.Offset(pgf.Tok, pos)
+		return err == nil && offset == next
+	}
+	found := false
+	ast.Inspect(pgf.File, func(n ast.Node) bool {
+		var doc *ast.CommentGroup
+		switch n := n.(type) {
+		case *ast.File:
+			doc = n.Doc
+		case *ast.FuncDecl:
+			doc = n.Doc
+		case *ast.GenDecl:
+			doc = n.Doc
+		case *ast.TypeSpec:
+			doc = n.Doc
+		case *ast.ValueSpec:
+			doc = n.Doc
+		case *ast.ImportSpec:
+			doc = n.Doc
+		case *ast.Field:
+			doc = n.Doc
+		default:
+			return !found
+		}
+		if doc == group || doc != nil && at(doc.Pos()) || doc == nil && at(n.Pos()) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -422,7 +422,15 @@ func Rename(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp pro
 		return nil, false, err
 	}
 
-	// Convert edits to protocol form.
+	result, err := toProtocolEdits(ctx, snapshot, editMap)
+	if err != nil {
+		return nil, false, err
+	}
+	return result, inPackageName, nil
+}
+
+// toProtocolEdits converts a map of renaming edits to protocol form.
+func toProtocolEdits(ctx context.Context, snapshot *cache.Snapshot, editMap map[protocol.DocumentURI][]diff.Edit) (map[protocol.DocumentURI][]protocol.TextEdit, error) {
 	result := make(map[protocol.DocumentURI][]protocol.TextEdit)
 	for uri, edits := range editMap {
 		// Sort and de-duplicate edits.
@@ -454,21 +462,21 @@ func Rename(ctx context.Context, snapshot *cache.Snapshot, f file.Handle, pp pro
 		// vendor/k8s.io/kubectl -> ../../staging/src/k8s.io/kubectl.
 		fh, err := snapshot.ReadFile(ctx, uri)
 		if err != nil {
-			return nil, false, err
+			return nil, err
 		}
 		data, err := fh.Content()
 		if err != nil {
-			return nil, false, err
+			return nil, err
 		}
 		m := protocol.NewMapper(uri, data)
 		textedits, err := protocol.EditsFromDiffEdits(m, edits)
 		if err != nil {
-			return nil, false, err
+			return nil, err
 		}
 		result[uri] = textedits
 	}
 
-	return result, inPackageName, nil
+	return result, nil
 }
 
 // renameOrdinary renames an ordinary (non-package) name throughout the workspace.
@@ -0,0 +1,317 @@
+// Copyright 2025 The Go Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package golang
+
+import (
+	"context"
+	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/token"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	"github.com/tinygo-org/tinygo/x-tools/go/types/typeutil"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/methodsets"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/internal/event"
+)
+
+// This file defines the LSP type hierarchy operators
+// (prepareTypeHierarchy, supertypes, subtypes).
+//
+// The hierarchy is based on the "implements" relation between method
+// sets, using the same local and global algorithms as the
+// Implementation query. Unlike Implementation, it reports
+// interface/interface pairs, such as an interface and the interfaces
+// it embeds. Since Go has no inheritance, the supertypes of a concrete
+// type are just the interfaces it implements, including by means of
+// methods promoted from embedded fields, and a concrete type has no
+// subtypes.
+
+// PrepareTypeHierarchy returns the TypeHierarchyItem for the named
+// type referred to at the given position, if any.
+func PrepareTypeHierarchy(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, pp protocol.Position) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "golang.PrepareTypeHierarchy")
+	defer done()
+
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, err
+	}
+	pos, err := pgf.PositionPos(pp)
+	if err != nil {
+		return nil, err
+	}
+
+	_, obj, _ := referencedObject(pkg, pgf, pos)
+	tname, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, nil
+	}
+
+	var loc protocol.Location
+	if isBuiltin(tname) {
+		builtin, id, err := builtinDecl(ctx, snapshot, tname)
+		if err != nil {
+			return nil, err
+		}
+		loc, err = builtin.NodeLocation(id)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		loc, err = mapPosition(ctx, pkg.FileSet(), snapshot, tname.Pos(), adjustedObjEnd(tname))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pkgPath PackagePath
+	if tname.Pkg() != nil {
+		pkgPath = PackagePath(tname.Pkg().Path())
+	}
+	item := typeHierarchyItem(pkgPath, tname.Name(), types.IsInterface(tname.Type()), loc)
+	return []protocol.TypeHierarchyItem{item}, nil
+}
+
+// Supertypes returns the TypeHierarchyItems for the interfaces
+// implemented by the type denoted by item.
+func Supertypes(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "golang.Supertypes")
+	defer done()
+
+	return relatedTypes(ctx, snapshot, fh, item, methodsets.Supertype)
+}
+
+// Subtypes returns the TypeHierarchyItems for the types that
+// implement the interface denoted by item.
+func Subtypes(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+	ctx, done := event.Start(ctx, "golang.Subtypes")
+	defer done()
+
+	return relatedTypes(ctx, snapshot, fh, item, methodsets.Subtype)
+}
+
+// relatedTypes is the common implementation of Supertypes and
+// Subtypes. It returns a new sorted array of items for the types
+// related to the type denoted by item by the relation rel.
+func relatedTypes(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, item protocol.TypeHierarchyItem, rel methodsets.TypeRelation) ([]protocol.TypeHierarchyItem, error) {
+	tname, fset, err := typeHierarchyObj(ctx, snapshot, fh, item)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute the method-set fingerprint used as a key to the global search.
+	key, hasMethods := methodsets.KeyOf(tname.Type())
+	if !hasMethods {
+		// A type with no methods has no interesting supertypes,
+		// and every type is a subtype of an empty interface.
+		return nil, nil
+	}
+
+	// As with Implementation, the declaring packages are searched
+	// by the local algorithm and all others by the global one.
+	var (
+		declURI    protocol.DocumentURI
+		declOffset int
+		localPkgs  []*cache.Package
+		pkgPath    PackagePath
+	)
+	if tname.Pkg() != nil { // nil for error
+		declURI, declOffset, localPkgs, err = declaringPackages(ctx, snapshot, fset, tname)
+		if err != nil {
+			return nil, err
+		}
+		pkgPath = PackagePath(tname.Pkg().Path())
+	}
+	globalIDs, err := otherPackageIDs(ctx, snapshot, pkgPath)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := snapshot.MethodSets(ctx, globalIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("querying method sets: %v", err)
+	}
+
+	// Search local and global packages in parallel.
+	var (
+		group   errgroup.Group
+		itemsMu sync.Mutex
+		items   []protocol.TypeHierarchyItem
+	)
+	addItems := func(more ...protocol.TypeHierarchyItem) {
+		itemsMu.Lock()
+		items = append(items, more...)
+		itemsMu.Unlock()
+	}
+	// local search
+	for _, localPkg := range localPkgs {
+		declPkg := localPkg
+		group.Go(func() error {
+			obj, err := declaredObjectAt(declPkg, declURI, declOffset)
+			if err != nil {
+				return err
+			}
+			tname, ok := obj.(*types.TypeName)
+			if !ok {
+				return fmt.Errorf("querying type hierarchy in package %q: not a type", declPkg.Metadata().ID)
+			}
+			localItems, err := localRelatedTypes(ctx, snapshot, declPkg, tname, rel)
+			if err != nil {
+				return fmt.Errorf("querying local type hierarchy %q: %v", declPkg.Metadata().ID, err)
+			}
+			addItems(localItems...)
+			return nil
+		})
+	}
+	// global search
+	for _, index := range indexes {
+		group.Go(func() error {
+			for _, res := range index.Related(key, rel) {
+				// Map offsets to protocol.Locations in parallel (may involve I/O).
+				group.Go(func() error {
+					loc, err := offsetToLocation(ctx, snapshot, res.Location.Filename, res.Location.Start, res.Location.End)
+					if err != nil {
+						return err
+					}
+					addItems(typeHierarchyItem(PackagePath(res.PkgPath), res.Name, res.IsInterface, loc))
+					return nil
+				})
+			}
+			return nil
+		})
+	}
+	// Special case: the error type belongs to no package, so it
+	// appears in neither search. Report it among the supertypes.
+	if rel&methodsets.Supertype != 0 && tname.Pkg() != nil &&
+		types.Implements(methodsets.EnsurePointer(tname.Type()), errorInterfaceType) {
+		group.Go(func() error {
+			loc, err := errorLocation(ctx, snapshot)
+			if err != nil {
+				return err
+			}
+			addItems(typeHierarchyItem("", "error", true, loc))
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	// Sort and de-duplicate items.
+	// (Test variants of the declaring package yield duplicates.)
+	compare := func(x, y protocol.TypeHierarchyItem) int {
+		if d := strings.Compare(x.Detail, y.Detail); d != 0 {
+			return d
+		}
+		if d := strings.Compare(x.Name, y.Name); d != 0 {
+			return d
+		}
+		return protocol.CompareLocation(
+			protocol.Location{URI: x.URI, Range: x.Range},
+			protocol.Location{URI: y.URI, Range: y.Range})
+	}
+	slices.SortFunc(items, compare)
+	items = slices.CompactFunc(items, func(x, y protocol.TypeHierarchyItem) bool {
+		return compare(x, y) == 0
+	})
+	return items, nil
+}
+
+// typeHierarchyObj returns the named type denoted by a
+// TypeHierarchyItem previously returned by this package,
+// and the file set of its position (nil for built-in types).
+func typeHierarchyObj(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle, item protocol.TypeHierarchyItem) (*types.TypeName, *token.FileSet, error) {
+	// The built-in error type is declared in a file
+	// that belongs to no package.
+	if builtin, err := snapshot.BuiltinFile(ctx); err == nil && builtin.URI == fh.URI() {
+		if tname, ok := types.Universe.Lookup(item.Name).(*types.TypeName); ok {
+			return tname, nil, nil
+		}
+		return nil, nil, fmt.Errorf("%s is not a built-in type", item.Name)
+	}
+
+	pkg, pgf, err := NarrowestPackageForFile(ctx, snapshot, fh.URI())
+	if err != nil {
+		return nil, nil, err
+	}
+	pos, err := pgf.PositionPos(item.Range.Start)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, obj, _ := referencedObject(pkg, pgf, pos)
+	tname, ok := obj.(*types.TypeName)
+	if !ok {
+		return nil, nil, fmt.Errorf("no type named %s at %v", item.Name, item.Range.Start)
+	}
+	return tname, pkg.FileSet(), nil
+}
+
+// localRelatedTypes searches within pkg for declarations of all types
+// related to the query type by rel, and returns a new unordered array
+// of items for them.
+//
+// Unlike the global search, its results include types that are local
+// to a function body.
+func localRelatedTypes(ctx context.Context, snapshot *cache.Snapshot, pkg *cache.Package, query *types.TypeName, rel methodsets.TypeRelation) ([]protocol.TypeHierarchyItem, error) {
+	queryType := methodsets.EnsurePointer(query.Type())
+
+	var msets typeutil.MethodSetCache
+
+	// Scan through all type declarations in the syntax.
+	var items []protocol.TypeHierarchyItem
+	for _, pgf := range pkg.CompiledGoFiles() {
+		for cur := range pgf.Cursor.Preorder((*ast.TypeSpec)(nil)) {
+			spec := cur.Node().(*ast.TypeSpec)
+			def, ok := pkg.TypesInfo().Defs[spec.Name].(*types.TypeName)
+			if !ok || def.IsAlias() || def == query {
+				continue // skip type aliases, and the query itself
+			}
+			candidateType := methodsets.EnsurePointer(def.Type())
+
+			// Ignore types with empty method sets.
+			// (No point reporting that every type satisfies 'any'.)
+			if msets.MethodSet(candidateType).Len() == 0 {
+				continue
+			}
+
+			if rel&methodsets.Subtype != 0 && implementsIntf(&msets, candidateType, queryType) ||
+				rel&methodsets.Supertype != 0 && implementsIntf(&msets, queryType, candidateType) {
+				loc, err := pgf.NodeLocation(spec.Name)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, typeHierarchyItem(PackagePath(pkg.Types().Path()), def.Name(), types.IsInterface(def.Type()), loc))
+			}
+		}
+	}
+	return items, nil
+}
+
+// typeHierarchyItem returns the TypeHierarchyItem for the named type
+// declared at loc. An empty pkgPath indicates a built-in type.
+func typeHierarchyItem(pkgPath PackagePath, name string, isInterface bool, loc protocol.Location) protocol.TypeHierarchyItem {
+	kind := protocol.Class
+	if isInterface {
+		kind = protocol.Interface
+	}
+	if pkgPath == "" {
+		pkgPath = "builtin"
+	}
+	return protocol.TypeHierarchyItem{
+		Name:           name,
+		Kind:           kind,
+		Detail:         fmt.Sprintf("%s • %s", pkgPath, filepath.Base(loc.URI.Path())),
+		URI:            loc.URI,
+		Range:          loc.Range,
+		SelectionRange: loc.Range,
+	}
+}
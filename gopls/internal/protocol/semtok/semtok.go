@@ -5,7 +5,10 @@
 // The semtok package provides an encoder for LSP's semantic tokens.
 package semtok
 
-import "sort"
+import (
+	"slices"
+	"sort"
+)
 
 // A Token provides the extent and semantics of a token.
 type Token struct {
@@ -201,3 +204,25 @@ func Encode(
 	}
 	return x[:j]
 }
+
+// Diff returns a single replacement that transforms the encoded
+// tokens prev into next: the elements prev[start:start+deleteCount]
+// are to be replaced by insert. The replacement is minimal with
+// respect to the common prefix and suffix of whole tokens, which
+// suffices for typical edits, since the relative encoding confines
+// the effect of a change to the tokens it touches. Identical inputs
+// yield deleteCount == 0 and an empty insert.
+func Diff(prev, next []uint32) (start, deleteCount int, insert []uint32) {
+	// Compare whole tokens of five elements.
+	const n = 5
+	prefix := 0
+	for prefix+n <= min(len(prev), len(next)) && slices.Equal(prev[prefix:prefix+n], next[prefix:prefix+n]) {
+		prefix += n
+	}
+	suffix := 0
+	for prefix+suffix+n <= min(len(prev), len(next)) &&
+		slices.Equal(prev[len(prev)-suffix-n:len(prev)-suffix], next[len(next)-suffix-n:len(next)-suffix]) {
+		suffix += n
+	}
+	return prefix, len(prev) - prefix - suffix, next[prefix : len(next)-suffix]
+}
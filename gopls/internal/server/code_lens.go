@@ -7,6 +7,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sort"
 
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
@@ -41,6 +42,7 @@ func (s *server) CodeLens(ctx context.Context, params *protocol.CodeLensParams)
 		// Unsupported file kind for a code lens.
 		return nil, nil
 	}
+	resolve := slices.Contains(snapshot.Options().CodeLensResolveOptions, "command")
 	var lenses []protocol.CodeLens
 	for kind, lensFunc := range lensFuncs {
 		if !snapshot.Options().Codelenses[kind] {
@@ -53,6 +55,18 @@ func (s *server) CodeLens(ctx context.Context, params *protocol.CodeLensParams)
 			event.Error(ctx, fmt.Sprintf("code lens %s failed", kind), err)
 			continue
 		}
+		if resolve {
+			// Identify each lens for ResolveCodeLens.
+			for i := range added {
+				data := codeLensData{URI: fh.URI(), Source: kind}
+				for _, prev := range added[:i] {
+					if prev.Range == added[i].Range {
+						data.Index++
+					}
+				}
+				added[i].Data = data
+			}
+		}
 		lenses = append(lenses, added...)
 	}
 	sort.Slice(lenses, func(i, j int) bool {
@@ -62,5 +76,67 @@ func (s *server) CodeLens(ctx context.Context, params *protocol.CodeLensParams)
 		}
 		return a.Command.Command < b.Command.Command
 	})
+
+	// If the client can resolve commands lazily, send only the
+	// ranges of the lenses; the commands are computed by
+	// ResolveCodeLens for the lenses that are visible.
+	if resolve {
+		for i := range lenses {
+			lenses[i].Command = nil
+		}
+	}
 	return lenses, nil
 }
+
+// codeLensData is the Data of a code lens whose command is computed by
+// ResolveCodeLens. Together with its range, it identifies the lens
+// among those reported by its source: Index counts the earlier lenses
+// of the source with the same range.
+type codeLensData struct {
+	URI    protocol.DocumentURI    `json:"uri"`
+	Source settings.CodeLensSource `json:"source"`
+	Index  int                     `json:"index,omitempty"`
+}
+
+// ResolveCodeLens computes the command of a code lens that was
+// deferred by CodeLens.
+func (s *server) ResolveCodeLens(ctx context.Context, lens *protocol.CodeLens) (*protocol.CodeLens, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.resolveCodeLens")
+	defer done()
+
+	var data codeLensData
+	if lens.Command != nil || lens.Data == nil || unmarshalData(lens.Data, &data) != nil {
+		return lens, nil // nothing to resolve
+	}
+	fh, snapshot, release, err := s.fileOf(ctx, data.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	var lensFunc cache.CodeLensSourceFunc
+	switch snapshot.FileKind(fh) {
+	case file.Mod:
+		lensFunc = mod.CodeLensSources()[data.Source]
+	case file.Go:
+		lensFunc = golang.CodeLensSources()[data.Source]
+	}
+	if lensFunc == nil {
+		return nil, fmt.Errorf("no code lens source %q for %s", data.Source, data.URI)
+	}
+	lenses, err := lensFunc(ctx, snapshot, fh)
+	if err != nil {
+		return nil, err
+	}
+	index := data.Index
+	for _, l := range lenses {
+		if l.Range == lens.Range {
+			if index == 0 {
+				lens.Command, lens.Data = l.Command, nil
+				return lens, nil
+			}
+			index--
+		}
+	}
+	return nil, fmt.Errorf("code lens at %v in %s is no longer valid", lens.Range, data.URI)
+}
@@ -7,6 +7,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
@@ -73,7 +74,14 @@ func (s *server) Completion(ctx context.Context, params *protocol.CompletionPara
 	options := snapshot.Options()
 	incompleteResults := options.DeepCompletion || options.Matcher == settings.Fuzzy
 
-	items, err := toProtocolCompletionItems(candidates, surrounding, options)
+	// If the client can resolve some properties of the items lazily,
+	// save the candidates for completionItem/resolve.
+	var resolveID uint64
+	if snapshot.FileKind(fh) == file.Go && len(options.CompletionResolveOptions) > 0 {
+		resolveID = s.saveCompletionCandidates(fh.URI(), fh.Version(), candidates)
+	}
+
+	items, err := toProtocolCompletionItems(candidates, surrounding, options, resolveID)
 	if err != nil {
 		return nil, err
 	}
@@ -102,9 +110,92 @@ func (s *server) saveLastCompletion(uri protocol.DocumentURI, version int32, ite
 	s.efficacyItems = items
 }
 
+// saveCompletionCandidates records the candidates of a completion
+// request in the given version of a file, for use by
+// ResolveCompletionItem, and returns the ID that identifies them.
+func (s *server) saveCompletionCandidates(uri protocol.DocumentURI, version int32, candidates []completion.CompletionItem) uint64 {
+	s.completionMu.Lock()
+	defer s.completionMu.Unlock()
+	s.completionID++
+	s.completionURI = uri
+	s.completionVersion = version
+	s.completionCandidates = candidates
+	return s.completionID
+}
+
+// completionItemData is the Data of a protocol completion item whose
+// properties may be resolved lazily. It identifies the corresponding
+// candidate of a completion request.
+type completionItemData struct {
+	ID    uint64 `json:"id"`    // the completion request; see saveCompletionCandidates
+	Index int    `json:"index"` // index of the candidate
+}
+
+func (s *server) ResolveCompletionItem(ctx context.Context, item *protocol.CompletionItem) (*protocol.CompletionItem, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.resolveCompletionItem")
+	defer done()
+
+	var data completionItemData
+	if item.Data == nil || unmarshalData(item.Data, &data) != nil {
+		return item, nil // not resolvable
+	}
+	s.completionMu.Lock()
+	uri, version, candidates := s.completionURI, s.completionVersion, s.completionCandidates
+	current := data.ID == s.completionID && 0 <= data.Index && data.Index < len(candidates)
+	s.completionMu.Unlock()
+	if !current {
+		return item, nil // superseded by a later completion request
+	}
+
+	fh, snapshot, release, err := s.fileOf(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	if fh.Version() != version {
+		return item, nil // the file has changed since completion
+	}
+
+	candidate, err := candidates[data.Index].Resolve(ctx, snapshot, fh)
+	if err != nil {
+		return nil, err
+	}
+	options := snapshot.Options()
+	if item.Detail == "" {
+		item.Detail = candidate.Detail
+	}
+	if item.Documentation == nil && candidate.Documentation != "" {
+		item.Documentation = completionDocumentation(candidate.Documentation, options)
+	}
+	if len(item.AdditionalTextEdits) == 0 {
+		item.AdditionalTextEdits = candidate.AdditionalTextEdits
+	}
+	item.Tags = protocol.NonNilSlice(candidate.Tags)
+	item.Deprecated = candidate.Deprecated
+	return item, nil
+}
+
+// completionDocumentation returns the documentation of a completion
+// item, in the format preferred by the client.
+func completionDocumentation(doc string, options *settings.Options) *protocol.Or_CompletionItem_documentation {
+	if options.PreferredContentFormat != protocol.Markdown {
+		return &protocol.Or_CompletionItem_documentation{Value: doc}
+	}
+	return &protocol.Or_CompletionItem_documentation{
+		Value: protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: golang.DocCommentToMarkdown(doc, options),
+		},
+	}
+}
+
 // toProtocolCompletionItems converts the candidates to the protocol completion items,
 // the candidates must be sorted based on score as it will be respected by client side.
-func toProtocolCompletionItems(candidates []completion.CompletionItem, surrounding *completion.Selection, options *settings.Options) ([]protocol.CompletionItem, error) {
+//
+// If resolveID is nonzero, it identifies the saved candidates (see
+// saveCompletionCandidates), and the properties that the client can
+// resolve lazily are omitted from the items.
+func toProtocolCompletionItems(candidates []completion.CompletionItem, surrounding *completion.Selection, options *settings.Options, resolveID uint64) ([]protocol.CompletionItem, error) {
 	replaceRng, err := surrounding.Range()
 	if err != nil {
 		return nil, err
@@ -142,14 +233,9 @@ func toProtocolCompletionItems(candidates []completion.CompletionItem, surroundi
 			continue
 		}
 
-		doc := &protocol.Or_CompletionItem_documentation{
-			Value: protocol.MarkupContent{
-				Kind:  protocol.Markdown,
-				Value: golang.DocCommentToMarkdown(candidate.Documentation, options),
-			},
-		}
-		if options.PreferredContentFormat != protocol.Markdown {
-			doc.Value = candidate.Documentation
+		var doc *protocol.Or_CompletionItem_documentation
+		if candidate.Documentation != "" || !slices.Contains(options.CompletionResolveOptions, "documentation") {
+			doc = completionDocumentation(candidate.Documentation, options)
 		}
 		var edits *protocol.Or_CompletionItem_textEdit
 		if options.InsertReplaceSupported {
@@ -197,6 +283,12 @@ func toProtocolCompletionItems(candidates []completion.CompletionItem, surroundi
 			Tags:          protocol.NonNilSlice(candidate.Tags),
 			Deprecated:    candidate.Deprecated,
 		}
+		if resolveID != 0 {
+			item.Data = completionItemData{ID: resolveID, Index: i}
+			if slices.Contains(options.CompletionResolveOptions, "detail") {
+				item.Detail = ""
+			}
+		}
 		items = append(items, item)
 	}
 	return items, nil
@@ -76,6 +76,150 @@ func (s *server) Diagnostic(ctx context.Context, params *protocol.DocumentDiagno
 	}, nil
 }
 
+// DiagnosticWorkspace implements the workspace/diagnostic LSP request,
+// reporting diagnostics for all files of the workspace.
+//
+// Each document report has a result ID that identifies its set of
+// diagnostics. A document whose previous result ID (as provided by the
+// client) is unchanged has an "unchanged" report, and a document with
+// a previous result ID but no diagnostics has an empty "full" report,
+// so that the client clears its diagnostics.
+//
+// If the client provides a partial result token, the reports for each
+// view are streamed as they are computed, and the response is empty.
+func (s *server) DiagnosticWorkspace(ctx context.Context, params *protocol.WorkspaceDiagnosticParams) (*protocol.WorkspaceDiagnosticReport, error) {
+	ctx, done := event.Start(ctx, "server.DiagnosticWorkspace")
+	defer done()
+
+	jsonrpc2.Async(ctx) // allow asynchronous collection of diagnostics
+
+	previous := make(map[protocol.DocumentURI]string)
+	for _, id := range params.PreviousResultIds {
+		previous[id.URI] = id.Value
+	}
+
+	report := &protocol.WorkspaceDiagnosticReport{
+		Items: []protocol.WorkspaceDocumentDiagnosticReport{},
+	}
+	reported := make(map[protocol.DocumentURI]bool)
+	// send reports the diagnostics of the files not yet reported.
+	send := func(diagnostics diagMap, snapshot *cache.Snapshot) error {
+		var items []protocol.WorkspaceDocumentDiagnosticReport
+		for uri, diags := range moremaps.Sorted(diagnostics) {
+			if reported[uri] {
+				continue
+			}
+			reported[uri] = true
+			var version int32
+			if snapshot != nil && snapshot.IsOpen(uri) {
+				if fh, err := snapshot.ReadFile(ctx, uri); err == nil {
+					version = fh.Version()
+				}
+			}
+			items = append(items, workspaceDocumentReport(uri, version, diags, previous[uri]))
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		if token := params.PartialResultToken; token != nil {
+			return s.client.Progress(ctx, &protocol.ProgressParams{
+				Token: *token,
+				Value: protocol.WorkspaceDiagnosticReportPartialResult{Items: items},
+			})
+		}
+		report.Items = append(report.Items, items...)
+		return nil
+	}
+
+	views := s.session.Views()
+	for _, view := range views {
+		snapshot, release, err := view.Snapshot()
+		if err != nil {
+			continue // view is shut down
+		}
+		diagnostics, err := s.diagnose(ctx, snapshot)
+		if err != nil {
+			release()
+			return nil, err
+		}
+		// As when publishing, only report a file's diagnostics from
+		// the views relevant to it (see golang/go#66425).
+		for uri := range diagnostics {
+			relevant, err := cache.RelevantViews(ctx, s.session, uri, views)
+			if err == nil && len(relevant) > 0 && !slices.Contains(relevant, view) {
+				delete(diagnostics, uri)
+			}
+		}
+		err = send(diagnostics, snapshot)
+		release()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Report diagnostics for orphaned files, and clear the
+	// diagnostics of files that no longer have any.
+	diagnostics, err := s.session.OrphanedFileDiagnostics(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for uri := range previous {
+		if _, ok := diagnostics[uri]; !ok {
+			diagnostics[uri] = nil
+		}
+	}
+	if err := send(diagnostics, nil); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// workspaceDocumentReport returns the workspace diagnostic report for
+// the given diagnostics of a file, whose previous result ID is
+// previousID (or empty, if unknown).
+func workspaceDocumentReport(uri protocol.DocumentURI, version int32, diagnostics []*cache.Diagnostic, previousID string) protocol.WorkspaceDocumentDiagnosticReport {
+	// De-dup diagnostics by hash, and derive the result ID from the set.
+	var (
+		hash   file.Hash
+		seen   = make(map[file.Hash]bool)
+		unique []*cache.Diagnostic
+	)
+	for _, diag := range diagnostics {
+		h := diag.Hash()
+		if !seen[h] {
+			seen[h] = true
+			hash.XORWith(h)
+			unique = append(unique, diag)
+		}
+	}
+	resultID := hash.String()
+
+	if resultID == previousID {
+		return protocol.WorkspaceDocumentDiagnosticReport{
+			Value: protocol.WorkspaceUnchangedDocumentDiagnosticReport{
+				URI:     uri,
+				Version: version,
+				UnchangedDocumentDiagnosticReport: protocol.UnchangedDocumentDiagnosticReport{
+					Kind:     string(protocol.DiagnosticUnchanged),
+					ResultID: resultID,
+				},
+			},
+		}
+	}
+	sortDiagnostics(unique)
+	return protocol.WorkspaceDocumentDiagnosticReport{
+		Value: protocol.WorkspaceFullDocumentDiagnosticReport{
+			URI:     uri,
+			Version: version,
+			FullDocumentDiagnosticReport: protocol.FullDocumentDiagnosticReport{
+				Kind:     string(protocol.DiagnosticFull),
+				ResultID: resultID,
+				Items:    toProtocolDiagnostics(unique),
+			},
+		},
+	}
+}
+
 // fileDiagnostics holds the current state of published diagnostics for a file.
 type fileDiagnostics struct {
 	publishedHash file.Hash // hash of the last set of diagnostics published for this URI
@@ -454,6 +598,11 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 
 		// secondary index, used to eliminate narrower packages.
 		toAnalyzeWidest = make(map[golang.PackagePath]*metadata.Package)
+
+		// The program to which whole-program analyzers are applied
+		// comprises the widest package of each workspace package path,
+		// whether or not it has open files.
+		toAnalyzeProgram = make(map[golang.PackagePath]*metadata.Package)
 	)
 	for _, mp := range workspacePkgs {
 		var hasNonIgnored, hasOpenFile bool
@@ -467,6 +616,9 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 		}
 		if hasNonIgnored {
 			toDiagnose[mp.ID] = mp
+			if prev, ok := toAnalyzeProgram[mp.PkgPath]; !ok || len(prev.CompiledGoFiles) < len(mp.CompiledGoFiles) {
+				toAnalyzeProgram[mp.PkgPath] = mp
+			}
 			if hasOpenFile {
 				if prev, ok := toAnalyzeWidest[mp.PkgPath]; ok {
 					if len(prev.CompiledGoFiles) >= len(mp.CompiledGoFiles) {
@@ -513,6 +665,23 @@ func (s *server) diagnose(ctx context.Context, snapshot *cache.Snapshot) (diagMa
 		// if err is non-nil (though as of today it's OK).
 		analysisDiags, err = golang.Analyze(ctx, snapshot, toAnalyze, s.progress)
 
+		// Apply the whole-program phase of analyzers that have one
+		// to the workspace packages.
+		if err == nil && snapshot.ProgramAnalyzersEnabled() {
+			program := make(map[metadata.PackageID]*metadata.Package)
+			for _, mp := range toAnalyzeProgram {
+				program[mp.ID] = mp
+			}
+			var programDiags diagMap
+			programDiags, err = golang.AnalyzeProgram(ctx, snapshot, program, s.progress)
+			for uri, diags := range programDiags {
+				if analysisDiags == nil {
+					analysisDiags = make(diagMap)
+				}
+				analysisDiags[uri] = append(analysisDiags[uri], diags...)
+			}
+		}
+
 		// Filter out Hint diagnostics for closed files.
 		// VS Code already omits Hint diagnostics in the Problems tab, but other
 		// clients do not. This filter makes the visibility of Hints more similar
@@ -121,7 +121,7 @@ func (s *server) Initialize(ctx context.Context, params *protocol.ParamInitializ
 		diagnosticProvider = &protocol.Or_ServerCapabilities_diagnosticProvider{
 			Value: protocol.DiagnosticOptions{
 				InterFileDependencies: true,
-				WorkspaceDiagnostics:  false, // we don't support workspace/diagnostic
+				WorkspaceDiagnostics:  true,
 			},
 		}
 	}
@@ -144,31 +144,43 @@ func (s *server) Initialize(ctx context.Context, params *protocol.ParamInitializ
 		Capabilities: protocol.ServerCapabilities{
 			CallHierarchyProvider: &protocol.Or_ServerCapabilities_callHierarchyProvider{Value: true},
 			CodeActionProvider:    codeActionProvider,
-			CodeLensProvider:      &protocol.CodeLensOptions{}, // must be non-nil to enable the code lens capability
+			CodeLensProvider:      &protocol.CodeLensOptions{ResolveProvider: true},
 			CompletionProvider: &protocol.CompletionOptions{
 				TriggerCharacters: []string{"."},
+				ResolveProvider:   true,
 			},
 			DefinitionProvider:         &protocol.Or_ServerCapabilities_definitionProvider{Value: true},
 			TypeDefinitionProvider:     &protocol.Or_ServerCapabilities_typeDefinitionProvider{Value: true},
+			TypeHierarchyProvider:      &protocol.Or_ServerCapabilities_typeHierarchyProvider{Value: true},
 			ImplementationProvider:     &protocol.Or_ServerCapabilities_implementationProvider{Value: true},
 			DocumentFormattingProvider: &protocol.Or_ServerCapabilities_documentFormattingProvider{Value: true},
-			DocumentSymbolProvider:     &protocol.Or_ServerCapabilities_documentSymbolProvider{Value: true},
-			WorkspaceSymbolProvider:    &protocol.Or_ServerCapabilities_workspaceSymbolProvider{Value: true},
+			DocumentRangeFormattingProvider: &protocol.Or_ServerCapabilities_documentRangeFormattingProvider{
+				Value: protocol.DocumentRangeFormattingOptions{RangesSupport: true},
+			},
+			DocumentOnTypeFormattingProvider: &protocol.DocumentOnTypeFormattingOptions{
+				FirstTriggerCharacter: "}",
+				MoreTriggerCharacter:  []string{"\n"},
+			},
+			DocumentSymbolProvider:  &protocol.Or_ServerCapabilities_documentSymbolProvider{Value: true},
+			WorkspaceSymbolProvider: &protocol.Or_ServerCapabilities_workspaceSymbolProvider{Value: true},
 			ExecuteCommandProvider: &protocol.ExecuteCommandOptions{
 				Commands: protocol.NonNilSlice(options.SupportedCommands),
 			},
-			FoldingRangeProvider:      &protocol.Or_ServerCapabilities_foldingRangeProvider{Value: true},
-			HoverProvider:             &protocol.Or_ServerCapabilities_hoverProvider{Value: true},
-			DocumentHighlightProvider: &protocol.Or_ServerCapabilities_documentHighlightProvider{Value: true},
-			DocumentLinkProvider:      &protocol.DocumentLinkOptions{},
-			InlayHintProvider:         protocol.InlayHintOptions{},
-			DiagnosticProvider:        diagnosticProvider,
-			ReferencesProvider:        &protocol.Or_ServerCapabilities_referencesProvider{Value: true},
-			RenameProvider:            renameOpts,
-			SelectionRangeProvider:    &protocol.Or_ServerCapabilities_selectionRangeProvider{Value: true},
+			FoldingRangeProvider:       &protocol.Or_ServerCapabilities_foldingRangeProvider{Value: true},
+			HoverProvider:              &protocol.Or_ServerCapabilities_hoverProvider{Value: true},
+			DocumentHighlightProvider:  &protocol.Or_ServerCapabilities_documentHighlightProvider{Value: true},
+			DocumentLinkProvider:       &protocol.DocumentLinkOptions{ResolveProvider: true},
+			InlayHintProvider:          protocol.InlayHintOptions{},
+			InlineValueProvider:        &protocol.Or_ServerCapabilities_inlineValueProvider{Value: true},
+			LinkedEditingRangeProvider: &protocol.Or_ServerCapabilities_linkedEditingRangeProvider{Value: true},
+			MonikerProvider:            &protocol.Or_ServerCapabilities_monikerProvider{Value: true},
+			DiagnosticProvider:         diagnosticProvider,
+			ReferencesProvider:         &protocol.Or_ServerCapabilities_referencesProvider{Value: true},
+			RenameProvider:             renameOpts,
+			SelectionRangeProvider:     &protocol.Or_ServerCapabilities_selectionRangeProvider{Value: true},
 			SemanticTokensProvider: protocol.SemanticTokensOptions{
 				Range: &protocol.Or_SemanticTokensOptions_range{Value: true},
-				Full:  &protocol.Or_SemanticTokensOptions_full{Value: true},
+				Full:  &protocol.Or_SemanticTokensOptions_full{Value: protocol.SemanticTokensFullDelta{Delta: true}},
 				Legend: protocol.SemanticTokensLegend{
 					TokenTypes:     moreslices.ConvertStrings[string](semtok.TokenTypes),
 					TokenModifiers: moreslices.ConvertStrings[string](semtok.TokenModifiers),
@@ -197,6 +209,20 @@ func (s *server) Initialize(ctx context.Context, params *protocol.ParamInitializ
 							Pattern: protocol.FileOperationPattern{Glob: "**/*.go"},
 						}},
 					},
+					// Renaming any file or directory may affect Go packages,
+					// either directly or through go:embed and testdata references.
+					WillRename: &protocol.FileOperationRegistrationOptions{
+						Filters: []protocol.FileOperationFilter{{
+							Scheme:  "file",
+							Pattern: protocol.FileOperationPattern{Glob: "**"},
+						}},
+					},
+					DidRename: &protocol.FileOperationRegistrationOptions{
+						Filters: []protocol.FileOperationFilter{{
+							Scheme:  "file",
+							Pattern: protocol.FileOperationPattern{Glob: "**"},
+						}},
+					},
 				},
 			},
 		},
@@ -632,6 +658,17 @@ func (s *server) fileOf(ctx context.Context, uri protocol.DocumentURI) (file.Han
 	return fh, snapshot, release, nil
 }
 
+// unmarshalData decodes the Data field of a completion item, code lens,
+// or document link, which the client returns to the server as
+// arbitrary JSON, into the value pointed to by ptr.
+func unmarshalData(data any, ptr any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, ptr)
+}
+
 // Shutdown implements the 'shutdown' LSP handler. It releases resources
 // associated with the server and waits for all ongoing work to complete.
 func (s *server) Shutdown(ctx context.Context) error {
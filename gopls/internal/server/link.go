@@ -118,17 +118,15 @@ func goLinks(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]p
 	var links []protocol.DocumentLink
 
 	// Create links for import specs.
+	//
+	// Their targets may require the package metadata, so for clients
+	// that resolve links they are computed by ResolveDocumentLink.
 	if snapshot.Options().ImportShortcut.ShowLinks() {
-
-		// If links are to pkg.go.dev, append module version suffixes.
-		// This requires the import map from the package metadata. Ignore errors.
+		resolve := snapshot.Options().DocumentLinkResolveSupported
 		var depsByImpPath map[golang.ImportPath]golang.PackageID
-		if strings.ToLower(snapshot.Options().LinkTarget) == "pkg.go.dev" {
-			if meta, err := golang.NarrowestMetadataForFile(ctx, snapshot, fh.URI()); err == nil {
-				depsByImpPath = meta.DepsByImpPath
-			}
+		if !resolve {
+			depsByImpPath = importMap(ctx, snapshot, fh)
 		}
-
 		for _, imp := range pgf.File.Imports {
 			importPath := metadata.UnquoteImportPath(imp)
 			if importPath == "" {
@@ -139,24 +137,23 @@ func goLinks(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]p
 				continue
 			}
 
-			urlPath := string(importPath)
-
-			// For pkg.go.dev, append module version suffix to package import path.
-			if mp := snapshot.Metadata(depsByImpPath[importPath]); mp != nil && mp.Module != nil && mp.Module.Path != "" && mp.Module.Version != "" {
-				urlPath = strings.Replace(urlPath, mp.Module.Path, mp.Module.Path+"@"+mp.Module.Version, 1)
-			}
-
 			start, end, err := safetoken.Offsets(pgf.Tok, imp.Path.Pos(), imp.Path.End())
 			if err != nil {
 				return nil, err
 			}
-			targetURL := cache.BuildLink(snapshot.Options().LinkTarget, urlPath, "")
 			// Account for the quotation marks in the positions.
-			l, err := toProtocolLink(pgf.Mapper, targetURL, start+len(`"`), end-len(`"`))
+			rng, err := pgf.Mapper.OffsetRange(start+len(`"`), end-len(`"`))
 			if err != nil {
 				return nil, err
 			}
-			links = append(links, l)
+			link := protocol.DocumentLink{Range: rng}
+			if resolve {
+				link.Data = importLinkData{URI: fh.URI(), ImportPath: importPath}
+			} else {
+				target := importLinkTarget(snapshot, depsByImpPath, importPath)
+				link.Target = &target
+			}
+			links = append(links, link)
 		}
 	}
 
@@ -203,6 +200,56 @@ func goLinks(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) ([]p
 	return links, nil
 }
 
+// importLinkData is the Data of the link for an import spec, whose
+// target is computed by ResolveDocumentLink.
+type importLinkData struct {
+	URI        protocol.DocumentURI `json:"uri"`        // the importing file
+	ImportPath golang.ImportPath    `json:"importPath"` // the import path
+}
+
+func (s *server) ResolveDocumentLink(ctx context.Context, link *protocol.DocumentLink) (*protocol.DocumentLink, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.resolveDocumentLink")
+	defer done()
+
+	var data importLinkData
+	if link.Target != nil || link.Data == nil || unmarshalData(link.Data, &data) != nil {
+		return link, nil // nothing to resolve
+	}
+	fh, snapshot, release, err := s.fileOf(ctx, data.URI)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	target := importLinkTarget(snapshot, importMap(ctx, snapshot, fh), data.ImportPath)
+	link.Target, link.Data = &target, nil
+	return link, nil
+}
+
+// importMap returns the import map of the package of the specified
+// Go file if links are to pkg.go.dev, whose link targets include the
+// module version of the imported package. It ignores errors.
+func importMap(ctx context.Context, snapshot *cache.Snapshot, fh file.Handle) map[golang.ImportPath]golang.PackageID {
+	if strings.ToLower(snapshot.Options().LinkTarget) == "pkg.go.dev" {
+		if meta, err := golang.NarrowestMetadataForFile(ctx, snapshot, fh.URI()); err == nil {
+			return meta.DepsByImpPath
+		}
+	}
+	return nil
+}
+
+// importLinkTarget returns the target of the link for an import of
+// the given package, using the import map returned by importMap.
+func importLinkTarget(snapshot *cache.Snapshot, depsByImpPath map[golang.ImportPath]golang.PackageID, importPath golang.ImportPath) string {
+	urlPath := string(importPath)
+
+	// For pkg.go.dev, append module version suffix to package import path.
+	if mp := snapshot.Metadata(depsByImpPath[importPath]); mp != nil && mp.Module != nil && mp.Module.Path != "" && mp.Module.Version != "" {
+		urlPath = strings.Replace(urlPath, mp.Module.Path, mp.Module.Path+"@"+mp.Module.Version, 1)
+	}
+	return cache.BuildLink(snapshot.Options().LinkTarget, urlPath, "")
+}
+
 // acceptedSchemes controls the schemes that URLs must have to be shown to the
 // user. Other schemes can't be opened by LSP clients, so linkifying them is
 // distracting. See golang/go#43990.
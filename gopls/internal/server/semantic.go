@@ -6,17 +6,69 @@ package server
 
 import (
 	"context"
+	"strconv"
 
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/golang"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/label"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol/semtok"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/template"
 	"github.com/tinygo-org/tinygo/x-tools/internal/event"
 )
 
 func (s *server) SemanticTokensFull(ctx context.Context, params *protocol.SemanticTokensParams) (*protocol.SemanticTokens, error) {
-	return s.semanticTokens(ctx, params.TextDocument, nil)
+	tokens, err := s.semanticTokens(ctx, params.TextDocument, nil)
+	if err != nil {
+		return nil, err
+	}
+	s.swapSemanticTokens(params.TextDocument.URI, tokens, "")
+	return tokens, nil
+}
+
+// SemanticTokensFullDelta returns the semantic tokens of the entire
+// document as a delta against the previous result identified by
+// params.PreviousResultID, or in full (as a *protocol.SemanticTokens)
+// if that result is no longer available.
+func (s *server) SemanticTokensFullDelta(ctx context.Context, params *protocol.SemanticTokensDeltaParams) (any, error) {
+	tokens, err := s.semanticTokens(ctx, params.TextDocument, nil)
+	if err != nil {
+		return nil, err
+	}
+	prev, ok := s.swapSemanticTokens(params.TextDocument.URI, tokens, params.PreviousResultID)
+	if !ok {
+		return tokens, nil
+	}
+	delta := &protocol.SemanticTokensDelta{
+		ResultID: tokens.ResultID,
+		Edits:    []protocol.SemanticTokensEdit{}, // must be non-nil for JSON
+	}
+	if start, deleteCount, insert := semtok.Diff(prev, tokens.Data); deleteCount > 0 || len(insert) > 0 {
+		delta.Edits = append(delta.Edits, protocol.SemanticTokensEdit{
+			Start:       uint32(start),
+			DeleteCount: uint32(deleteCount),
+			Data:        insert,
+		})
+	}
+	return delta, nil
+}
+
+// swapSemanticTokens records tokens, a full result for the document,
+// as the basis for future delta requests, and assigns its ResultID.
+// If prevID identifies the previously recorded result for the
+// document, swapSemanticTokens returns its data.
+func (s *server) swapSemanticTokens(uri protocol.DocumentURI, tokens *protocol.SemanticTokens, prevID string) ([]uint32, bool) {
+	s.semanticTokensMu.Lock()
+	defer s.semanticTokensMu.Unlock()
+
+	prev, ok := s.semanticTokensResults[uri]
+	s.lastSemanticTokensID++
+	tokens.ResultID = strconv.FormatUint(s.lastSemanticTokensID, 10)
+	s.semanticTokensResults[uri] = tokens
+	if ok && prevID != "" && prev.ResultID == prevID {
+		return prev.Data, true
+	}
+	return nil, false
 }
 
 func (s *server) SemanticTokensRange(ctx context.Context, params *protocol.SemanticTokensRangeParams) (*protocol.SemanticTokens, error) {
@@ -25,6 +25,7 @@ import (
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache/metadata"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/golang"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/golang/completion"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/progress"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/settings"
@@ -40,15 +41,16 @@ func New(session *cache.Session, client protocol.ClientCloser, options *settings
 	// upgrade, it means that one or more new methods need new
 	// stub declarations in unimplemented.go.
 	return &server{
-		diagnostics:         make(map[protocol.DocumentURI]*fileDiagnostics),
-		watchedGlobPatterns: nil, // empty
-		changedFiles:        make(map[protocol.DocumentURI]unit),
-		session:             session,
-		client:              client,
-		diagnosticsSema:     make(chan unit, concurrentAnalyses),
-		progress:            progress.NewTracker(client),
-		options:             options,
-		viewsToDiagnose:     make(map[*cache.View]uint64),
+		diagnostics:           make(map[protocol.DocumentURI]*fileDiagnostics),
+		watchedGlobPatterns:   nil, // empty
+		changedFiles:          make(map[protocol.DocumentURI]unit),
+		session:               session,
+		client:                client,
+		diagnosticsSema:       make(chan unit, concurrentAnalyses),
+		semanticTokensResults: make(map[protocol.DocumentURI]*protocol.SemanticTokens),
+		progress:              progress.NewTracker(client),
+		options:               options,
+		viewsToDiagnose:       make(map[*cache.View]uint64),
 	}
 }
 
@@ -112,6 +114,12 @@ type server struct {
 	// expensive.
 	diagnosticsSema chan unit
 
+	// The most recent full semantic tokens result for each open
+	// document, the basis for semanticTokens/full/delta requests.
+	semanticTokensMu      sync.Mutex
+	semanticTokensResults map[protocol.DocumentURI]*protocol.SemanticTokens
+	lastSemanticTokensID  uint64
+
 	progress *progress.Tracker
 
 	// When the workspace fails to load, we show its status through a progress
@@ -135,6 +143,14 @@ type server struct {
 	efficacyItems   []protocol.CompletionItem
 	efficacyPos     protocol.Position
 
+	// The candidates of the most recent Go completion request, whose
+	// deferred properties are computed by completionItem/resolve.
+	completionMu         sync.Mutex
+	completionID         uint64 // identifies the request; zero before the first
+	completionURI        protocol.DocumentURI
+	completionVersion    int32
+	completionCandidates []completion.CompletionItem
+
 	// Web server (for package documentation, etc) associated with this
 	// LSP server. Opened on demand, and closed during LSP Shutdown.
 	webOnce sync.Once
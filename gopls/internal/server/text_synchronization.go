@@ -207,6 +207,10 @@ func (s *server) DidClose(ctx context.Context, params *protocol.DidCloseTextDocu
 	ctx, done := event.Start(ctx, "lsp.Server.didClose", label.URI.Of(params.TextDocument.URI))
 	defer done()
 
+	s.semanticTokensMu.Lock()
+	delete(s.semanticTokensResults, params.TextDocument.URI)
+	s.semanticTokensMu.Unlock()
+
 	return s.didModifyFiles(ctx, []file.Modification{
 		{
 			URI:     params.TextDocument.URI,
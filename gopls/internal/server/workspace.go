@@ -7,12 +7,15 @@ package server
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/file"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/golang"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/golang/completion"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/settings"
@@ -169,3 +172,130 @@ func (s *server) DidCreateFiles(ctx context.Context, params *protocol.CreateFile
 
 	return applyChanges(ctx, s.client, allChanges)
 }
+
+// WillCreateFiles implements the workspace/willCreateFiles request.
+// Gopls has no edits to make before a file is created: the package
+// clause of a new Go file is added by DidCreateFiles.
+func (s *server) WillCreateFiles(ctx context.Context, params *protocol.CreateFilesParams) (*protocol.WorkspaceEdit, error) {
+	return nil, nil
+}
+
+// WillDeleteFiles implements the workspace/willDeleteFiles request.
+// Gopls has no edits to make before a file is deleted: any references
+// to the deleted file are reported as diagnostics.
+func (s *server) WillDeleteFiles(ctx context.Context, params *protocol.DeleteFilesParams) (*protocol.WorkspaceEdit, error) {
+	return nil, nil
+}
+
+// WillRenameFiles implements the workspace/willRenameFiles request.
+// It returns the edits that update import paths, package clauses, and
+// references to embedded files affected by the renaming of the
+// specified files and directories.
+func (s *server) WillRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) (*protocol.WorkspaceEdit, error) {
+	ctx, done := event.Start(ctx, "lsp.Server.willRenameFiles")
+	defer done()
+
+	// Compute the edits for each view against its own snapshot, as the
+	// renamed files may belong to different views.
+	type group struct {
+		snapshot *cache.Snapshot
+		renames  []protocol.FileRename
+	}
+	var groups []*group
+	byView := make(map[*cache.View]*group)
+	for _, rename := range params.Files {
+		uri, err := protocol.ParseDocumentURI(rename.OldURI)
+		if err != nil {
+			return nil, err
+		}
+		snapshot, release, err := s.session.SnapshotOf(ctx, uri)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		g := byView[snapshot.View()]
+		if g == nil {
+			g = &group{snapshot: snapshot}
+			byView[snapshot.View()] = g
+			groups = append(groups, g)
+		}
+		g.renames = append(g.renames, rename)
+	}
+
+	// A file may be edited by several groups, for example when it
+	// imports packages renamed in different views, so the edits of
+	// all groups are merged per file, without duplicates.
+	var (
+		uris    []protocol.DocumentURI
+		handles = make(map[protocol.DocumentURI]file.Handle)
+		merged  = make(map[protocol.DocumentURI][]protocol.TextEdit)
+	)
+	for _, g := range groups {
+		edits, err := golang.RenameFiles(ctx, g.snapshot, g.renames)
+		if err != nil {
+			return nil, err
+		}
+		for uri, e := range edits {
+			if _, ok := handles[uri]; !ok {
+				fh, err := g.snapshot.ReadFile(ctx, uri)
+				if err != nil {
+					return nil, err
+				}
+				handles[uri] = fh
+				uris = append(uris, uri)
+			}
+			for _, edit := range e {
+				if !slices.Contains(merged[uri], edit) {
+					merged[uri] = append(merged[uri], edit)
+				}
+			}
+		}
+	}
+	var changes []protocol.DocumentChange
+	for _, uri := range uris {
+		changes = append(changes, protocol.DocumentChangeEdit(handles[uri], merged[uri]))
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	return protocol.NewWorkspaceEdit(changes...), nil
+}
+
+// DidRenameFiles implements the workspace/didRenameFiles notification,
+// which is treated like the deletion of the old files and the creation
+// of the new ones on disk, including those within renamed directories.
+func (s *server) DidRenameFiles(ctx context.Context, params *protocol.RenameFilesParams) error {
+	ctx, done := event.Start(ctx, "lsp.Server.didRenameFiles")
+	defer done()
+
+	var modifications []file.Modification
+	for _, rename := range params.Files {
+		oldURI, err := protocol.ParseDocumentURI(rename.OldURI)
+		if err != nil {
+			return err
+		}
+		newURI, err := protocol.ParseDocumentURI(rename.NewURI)
+		if err != nil {
+			return err
+		}
+		// A renamed directory is expanded to the files known to be
+		// within it, each of which is moved to the new directory.
+		deleted := s.session.ExpandModificationsToDirectories(ctx, []file.Modification{
+			{URI: oldURI, Action: file.Delete, OnDisk: true},
+		})
+		for _, del := range deleted {
+			created := newURI
+			if del.URI != oldURI {
+				rel, err := filepath.Rel(oldURI.Path(), del.URI.Path())
+				if err != nil {
+					continue
+				}
+				created = protocol.URIFromPath(filepath.Join(newURI.Path(), rel))
+			}
+			modifications = append(modifications,
+				del,
+				file.Modification{URI: created, Action: file.Create, OnDisk: true})
+		}
+	}
+	return s.didModifyFiles(ctx, modifications, FromDidChangeWatchedFiles)
+}
@@ -231,3 +231,11 @@ func init() {
 		DefaultAnalyzers[analyzer.analyzer.Name] = analyzer
 	}
 }
+
+// RegisterAnalyzer adds to DefaultAnalyzers an analyzer that is
+// disabled by default. It allows tests to exercise analyzers, such as
+// those with a whole-program phase, that gopls does not provide.
+// It must be called before any session is created.
+func RegisterAnalyzer(a *analysis.Analyzer) {
+	DefaultAnalyzers[a.Name] = &Analyzer{analyzer: a, nonDefault: true}
+}
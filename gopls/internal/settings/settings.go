@@ -80,6 +80,9 @@ type ClientOptions struct {
 	CompletionDeprecated                       bool
 	SupportedResourceOperations                []protocol.ResourceOperationKind
 	CodeActionResolveOptions                   []string
+	CompletionResolveOptions                   []string
+	CodeLensResolveOptions                     []string
+	DocumentLinkResolveSupported               bool
 	ShowDocumentSupported                      bool
 	// SupportedWorkDoneProgressFormats specifies the formats supported by the
 	// client for handling workdone progress metadata.
@@ -971,6 +974,15 @@ func (o *Options) ForClientCapabilities(clientInfo *protocol.ClientInfo, caps pr
 		o.CodeActionResolveOptions = caps.TextDocument.CodeAction.ResolveSupport.Properties
 	}
 
+	// Check which properties of completion items and code lenses the
+	// client can resolve lazily.
+	if rs := caps.TextDocument.Completion.CompletionItem.ResolveSupport; rs != nil {
+		o.CompletionResolveOptions = rs.Properties
+	}
+	if cl := caps.TextDocument.CodeLens; cl != nil && cl.ResolveSupport != nil {
+		o.CodeLensResolveOptions = cl.ResolveSupport.Properties
+	}
+
 	// Client experimental capabilities.
 	if experimental, ok := caps.Experimental.(map[string]any); ok {
 		if formats, ok := experimental["progressMessageStyles"].([]any); ok {
@@ -979,6 +991,12 @@ func (o *Options) ForClientCapabilities(clientInfo *protocol.ClientInfo, caps pr
 				o.SupportedWorkDoneProgressFormats[WorkDoneProgressStyle(f.(string))] = true
 			}
 		}
+		// LSP has no capability for resolving document links, so
+		// clients that send documentLink/resolve for links without a
+		// target must say so explicitly.
+		if supported, ok := experimental["documentLinkResolveSupport"].(bool); ok {
+			o.DocumentLinkResolveSupported = supported
+		}
 	}
 }
 
@@ -7,6 +7,7 @@ package codelens
 import (
 	"fmt"
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/server"
@@ -74,6 +75,54 @@ const (
 	}
 }
 
+// TestResolveCodeLens checks that, for a client that can resolve the
+// commands of code lenses, gopls reports only their ranges and computes
+// each command in codeLens/resolve.
+func TestResolveCodeLens(t *testing.T) {
+	const workspace = `
+-- go.mod --
+module codelens.test
+
+go 1.12
+-- lib.go --
+package lib
+
+//` + `go:generate stringer -type=Number
+`
+	const capabilities = `{ "textDocument": { "codeLens": {
+		"resolveSupport": { "properties": ["command"] }
+	} } }`
+	WithOptions(
+		CapabilitiesJSON([]byte(capabilities)),
+	).Run(t, workspace, func(t *testing.T, env *Env) {
+		env.OpenFile("lib.go")
+		lenses := env.CodeLens("lib.go")
+		if len(lenses) != 2 {
+			t.Fatalf("got %d code lenses, want 2", len(lenses))
+		}
+		var titles []string
+		for _, lens := range lenses {
+			if lens.Command != nil {
+				t.Errorf("CodeLens returned lens with command %q; want none", lens.Command.Title)
+			}
+			resolved, err := env.Editor.Server.ResolveCodeLens(env.Ctx, &lens)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resolved.Command == nil {
+				t.Fatalf("ResolveCodeLens returned lens without command")
+			}
+			titles = append(titles, resolved.Command.Title)
+		}
+		// The two go:generate lenses share a range, but each is
+		// resolved to its own command.
+		slices.Sort(titles)
+		if want := []string{"run go generate", "run go generate ./..."}; !slices.Equal(titles, want) {
+			t.Errorf("resolved code lenses have titles %q, want %q", titles, want)
+		}
+	})
+}
+
 const proxyWithLatest = `
 -- golang.org/x/hello@v1.3.3/go.mod --
 module golang.org/x/hello
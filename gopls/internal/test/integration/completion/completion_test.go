@@ -5,6 +5,7 @@
 package completion
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
@@ -518,6 +519,78 @@ func doit() {
 	})
 }
 
+func TestCompletionResolve(t *testing.T) {
+	// Package a puts math in the workspace, so that its members
+	// are completed from syntax, with deferred documentation.
+	const src = `
+-- go.mod --
+module mod.com
+
+go 1.19
+
+-- a/a.go --
+package a
+
+import "math"
+
+var _ = math.Pi
+
+-- main.go --
+package main
+
+func main() {
+	_ = math.Sqr
+}
+`
+	const capabilities = `{ "textDocument": { "completion": { "completionItem": {
+		"resolveSupport": { "properties": ["documentation", "detail", "additionalTextEdits"] }
+	} } } }`
+	WithOptions(
+		CapabilitiesJSON([]byte(capabilities)),
+	).Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+		env.Await(env.DoneWithOpen())
+		loc := env.RegexpSearch("main.go", `math.Sqr()`)
+		completions := env.Completion(loc)
+		if len(completions.Items) == 0 {
+			t.Fatalf("no completion items")
+		}
+		item := completions.Items[0]
+		if item.Label != "Sqrt" {
+			t.Fatalf("first completion item is %q, want Sqrt", item.Label)
+		}
+
+		// The deferred properties are absent from the list...
+		if item.Documentation != nil || item.Detail != "" || len(item.AdditionalTextEdits) > 0 {
+			t.Errorf("Completion returned item with documentation %v, detail %q, edits %v; want none", item.Documentation, item.Detail, item.AdditionalTextEdits)
+		}
+		if item.Data == nil {
+			t.Fatalf("Completion returned item without data")
+		}
+
+		// ...and present once the item is resolved.
+		resolved, err := env.Editor.Server.ResolveCompletionItem(env.Ctx, &item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc, err := json.Marshal(resolved.Documentation)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(doc), "Sqrt returns the square root of x") {
+			t.Errorf("resolved documentation = %s, want the doc comment of math.Sqrt", doc)
+		}
+		if want := "func(x float64) float64"; !strings.Contains(resolved.Detail, want) {
+			t.Errorf("resolved detail = %q, want %q", resolved.Detail, want)
+		}
+		env.AcceptCompletion(loc, *resolved)
+		env.Await(env.DoneWithChange())
+		if got := env.BufferText("main.go"); !strings.Contains(got, `import "math"`) {
+			t.Errorf("accepting resolved completion did not add import:\n%s", got)
+		}
+	})
+}
+
 func TestUnimportedCompletion_VSCodeIssue1489(t *testing.T) {
 	const src = `
 -- go.mod --
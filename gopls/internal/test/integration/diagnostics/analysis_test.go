@@ -6,10 +6,16 @@ package diagnostics
 
 import (
 	"fmt"
+	"github.com/tinygo-org/tinygo/alt_go/ast"
+	"github.com/tinygo-org/tinygo/alt_go/types"
+	"reflect"
 	"testing"
 
+	"github.com/tinygo-org/tinygo/x-tools/go/analysis"
+	"github.com/tinygo-org/tinygo/x-tools/go/types/typeutil"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/cache"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
+	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/settings"
 	. "github.com/tinygo-org/tinygo/x-tools/gopls/internal/test/integration"
 )
 
@@ -154,3 +160,90 @@ var y interface{}
 		)
 	})
 }
+
+// uncalledAnalyzer reports exported functions that are not called
+// anywhere in the program. Its Run function returns the set of
+// functions called by each package, which its RunProgram function
+// relates to the functions declared by the others.
+var uncalledAnalyzer = &analysis.Analyzer{
+	Name:       "uncalled",
+	Doc:        "reports exported functions that are never called",
+	ResultType: reflect.TypeOf(map[*types.Func]bool(nil)),
+	Run: func(pass *analysis.Pass) (any, error) {
+		called := make(map[*types.Func]bool)
+		for _, file := range pass.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if fn, ok := typeutil.Callee(pass.TypesInfo, call).(*types.Func); ok {
+						called[fn] = true
+					}
+				}
+				return true
+			})
+		}
+		return called, nil
+	},
+	RunProgram: func(pass *analysis.ProgramPass) error {
+		called := make(map[*types.Func]bool)
+		for _, pkg := range pass.Packages {
+			for fn := range pkg.Result.(map[*types.Func]bool) {
+				called[fn] = true
+			}
+		}
+		for _, pkg := range pass.Packages {
+			for _, file := range pkg.Files {
+				for _, decl := range file.Decls {
+					if decl, ok := decl.(*ast.FuncDecl); ok && decl.Name.IsExported() && decl.Recv == nil {
+						if fn := pkg.TypesInfo.Defs[decl.Name].(*types.Func); !called[fn] && fn.Name() != "main" {
+							pass.Reportf(decl.Name.Pos(), "%s is never called", fn.Name())
+						}
+					}
+				}
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	settings.RegisterAnalyzer(uncalledAnalyzer)
+}
+
+// TestProgramAnalyzer checks that gopls applies the whole-program phase
+// of an analyzer to the workspace packages, including those without
+// open files, and that their types and objects are commensurable.
+func TestProgramAnalyzer(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.20
+-- a/a.go --
+package a
+
+func F() {}
+
+func G() {}
+-- b/b.go --
+package b
+
+import "mod.com/a"
+
+func H() { a.F() }
+`
+	WithOptions(
+		Settings{"analyses": map[string]any{"uncalled": true}},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.OpenFile("b/b.go")
+		env.AfterChange(
+			Diagnostics(env.AtRegexp("a/a.go", "G"), WithMessage("G is never called")),
+			Diagnostics(env.AtRegexp("b/b.go", "H"), WithMessage("H is never called")),
+			NoDiagnostics(env.AtRegexp("a/a.go", "F")),
+		)
+		env.RegexpReplace("b/b.go", `a\.F\(\)`, "a.F(); a.G()")
+		env.AfterChange(
+			NoDiagnostics(ForFile("a/a.go")),
+			Diagnostics(env.AtRegexp("b/b.go", "H"), WithMessage("H is never called")),
+		)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"os/exec"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/protocol"
 	"github.com/tinygo-org/tinygo/x-tools/gopls/internal/server"
 	. "github.com/tinygo-org/tinygo/x-tools/gopls/internal/test/integration"
@@ -159,6 +160,53 @@ func TestDiagnosticClearingOnEdit(t *testing.T) {
 	})
 }
 
+func TestWorkspacePullDiagnostics(t *testing.T) {
+	WithOptions(
+		// In forwarded mode, partial results may be relayed after the
+		// response to the request.
+		Modes(Default),
+		Settings{
+			"pullDiagnostics": true,
+		},
+	).Run(t, badPackage, func(t *testing.T, env *Env) {
+		// summarize returns the kind and number of diagnostics of each
+		// report, by file, and the result IDs.
+		summarize := func(reports []protocol.WorkspaceDocumentDiagnosticReport) (map[string]string, []protocol.PreviousResultID) {
+			got := make(map[string]string)
+			var ids []protocol.PreviousResultID
+			for _, r := range reports {
+				// Any report decodes as a full report.
+				report := r.Value.(protocol.WorkspaceFullDocumentDiagnosticReport)
+				path := env.Sandbox.Workdir.URIToPath(report.URI)
+				got[path] = fmt.Sprintf("%s %d", report.Kind, len(report.Items))
+				ids = append(ids, protocol.PreviousResultID{URI: report.URI, Value: report.ResultID})
+			}
+			return got, ids
+		}
+
+		got, ids := summarize(env.WorkspaceDiagnostics(nil, false))
+		want := map[string]string{"a.go": "full 1", "b.go": "full 1"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("workspace/diagnostic: unexpected reports (-want +got):\n%s", diff)
+		}
+
+		// With the previous result IDs, the reports are unchanged.
+		got, _ = summarize(env.WorkspaceDiagnostics(ids, false))
+		want = map[string]string{"a.go": "unchanged 0", "b.go": "unchanged 0"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("workspace/diagnostic with previous IDs: unexpected reports (-want +got):\n%s", diff)
+		}
+
+		// Once the error is fixed, the (streamed) reports are empty.
+		env.OpenFile("b.go")
+		env.RegexpReplace("b.go", "(a) = 2", "b")
+		got, _ = summarize(env.WorkspaceDiagnostics(ids, true))
+		want = map[string]string{"a.go": "full 0", "b.go": "full 0"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("workspace/diagnostic after fix: unexpected reports (-want +got):\n%s", diff)
+		}
+	})
+}
 func TestDiagnosticClearingOnDelete_Issue37049(t *testing.T) {
 	Run(t, badPackage, func(t *testing.T, env *Env) {
 		env.OpenFile("a.go")
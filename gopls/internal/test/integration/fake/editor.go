@@ -53,6 +53,11 @@ type Editor struct {
 	serverCapabilities protocol.ServerCapabilities
 	semTokOpts         protocol.SemanticTokensOptions
 
+	// partialResults holds the handlers of the partial results of
+	// pending requests, by partial result token.
+	partialResults   map[string]func(json.RawMessage) error
+	nextPartialToken int
+
 	// Call metrics for the purpose of expectations. This is done in an ad-hoc
 	// manner for now. Perhaps in the future we should do something more
 	// systematic. Guarded with a separate mutex as calls may need to be accessed
@@ -173,7 +178,7 @@ func (e *Editor) Connect(ctx context.Context, connector servertest.Connector, ho
 	if e.config.MaxMessageDelay > 0 {
 		handler = DelayedHandler(e.config.MaxMessageDelay, handler)
 	}
-	conn.Go(bgCtx, protocol.Handlers(handler))
+	conn.Go(bgCtx, e.partialResultHandler(protocol.Handlers(handler)))
 
 	if err := e.initialize(ctx); err != nil {
 		return nil, err
@@ -182,6 +187,32 @@ func (e *Editor) Connect(ctx context.Context, connector servertest.Connector, ho
 	return e, nil
 }
 
+// partialResultHandler returns a handler that delivers partial results
+// (progress notifications for a partial result token of a pending
+// request) synchronously, so that, unlike other messages, which the
+// handler handles asynchronously, they are delivered before the
+// response to the request.
+func (e *Editor) partialResultHandler(handler jsonrpc2.Handler) jsonrpc2.Handler {
+	return func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
+		if req.Method() == "$/progress" {
+			var params struct {
+				Token any             `json:"token"`
+				Value json.RawMessage `json:"value"`
+			}
+			if err := json.Unmarshal(req.Params(), &params); err == nil {
+				token, _ := params.Token.(string)
+				e.mu.Lock()
+				h, ok := e.partialResults[token]
+				e.mu.Unlock()
+				if ok {
+					return reply(ctx, nil, h(params.Value))
+				}
+			}
+		}
+		return handler(ctx, reply, req)
+	}
+}
+
 // DelayedHandler waits [0, maxDelay) before handling each message.
 func DelayedHandler(maxDelay time.Duration, handler jsonrpc2.Handler) jsonrpc2.Handler {
 	return func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) error {
@@ -1087,6 +1118,66 @@ func (e *Editor) Diagnostics(ctx context.Context, path string) ([]protocol.Diagn
 	return report.Items, nil
 }
 
+// WorkspaceDiagnostics returns the reports of a workspace/diagnostic
+// request with the given previous result IDs. If partial is set, the
+// request asks for partial results, and the reports are those
+// delivered as partial results; it is an error if the response has
+// reports too.
+func (e *Editor) WorkspaceDiagnostics(ctx context.Context, previous []protocol.PreviousResultID, partial bool) ([]protocol.WorkspaceDocumentDiagnosticReport, error) {
+	if e.Server == nil {
+		return nil, errors.New("not connected")
+	}
+	params := &protocol.WorkspaceDiagnosticParams{
+		PreviousResultIds: previous,
+	}
+	var (
+		mu       sync.Mutex
+		streamed []protocol.WorkspaceDocumentDiagnosticReport
+	)
+	if partial {
+		e.mu.Lock()
+		e.nextPartialToken++
+		token := fmt.Sprintf("partial-%d", e.nextPartialToken)
+		if e.partialResults == nil {
+			e.partialResults = make(map[string]func(json.RawMessage) error)
+		}
+		e.partialResults[token] = func(value json.RawMessage) error {
+			var result protocol.WorkspaceDiagnosticReportPartialResult
+			if err := json.Unmarshal(value, &result); err != nil {
+				return err
+			}
+			mu.Lock()
+			streamed = append(streamed, result.Items...)
+			mu.Unlock()
+			return nil
+		}
+		e.mu.Unlock()
+		defer func() {
+			e.mu.Lock()
+			delete(e.partialResults, token)
+			e.mu.Unlock()
+		}()
+		var tok protocol.ProgressToken = token
+		params.PartialResultToken = &tok
+	}
+	report, err := e.Server.DiagnosticWorkspace(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, errors.New("DiagnosticWorkspace returned no report")
+	}
+	if !partial {
+		return report.Items, nil
+	}
+	if len(report.Items) > 0 {
+		return nil, fmt.Errorf("got %d reports in response to a request for partial results", len(report.Items))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	return streamed, nil
+}
+
 // GetQuickFixes returns the available quick fix code actions.
 func (e *Editor) GetQuickFixes(ctx context.Context, loc protocol.Location, diagnostics []protocol.Diagnostic) ([]protocol.CodeAction, error) {
 	return e.CodeActions(ctx, loc, diagnostics, protocol.QuickFix, protocol.SourceFixAll)
@@ -1499,6 +1590,48 @@ func (e *Editor) RenameFile(ctx context.Context, oldPath, newPath string) error
 	return nil
 }
 
+// MoveFile renames a file or directory as an editor's file explorer
+// would: it sends a workspace/willRenameFiles request and applies the
+// resulting edits, performs the renaming, then sends a
+// workspace/didRenameFiles notification.
+func (e *Editor) MoveFile(ctx context.Context, oldPath, newPath string) error {
+	return e.MoveFiles(ctx, [2]string{oldPath, newPath})
+}
+
+// MoveFiles is like MoveFile, but renames several files or directories,
+// each an (old, new) pair of paths, in a single operation.
+func (e *Editor) MoveFiles(ctx context.Context, moves ...[2]string) error {
+	params := &protocol.RenameFilesParams{}
+	for _, m := range moves {
+		params.Files = append(params.Files, protocol.FileRename{
+			OldURI: string(e.sandbox.Workdir.URI(m[0])),
+			NewURI: string(e.sandbox.Workdir.URI(m[1])),
+		})
+	}
+	if e.Server != nil {
+		wsedit, err := e.Server.WillRenameFiles(ctx, params)
+		if err != nil {
+			return fmt.Errorf("WillRenameFiles: %w", err)
+		}
+		if wsedit != nil {
+			if err := e.applyWorkspaceEdit(ctx, wsedit); err != nil {
+				return err
+			}
+		}
+	}
+	for _, m := range moves {
+		if err := e.RenameFile(ctx, m[0], m[1]); err != nil {
+			return err
+		}
+	}
+	if e.Server != nil {
+		if err := e.Server.DidRenameFiles(ctx, params); err != nil {
+			return fmt.Errorf("DidRenameFiles: %w", err)
+		}
+	}
+	return nil
+}
+
 // renameBuffers renames in-memory buffers affected by the renaming of
 // oldPath->newPath, returning the resulting text documents that must be closed
 // and opened over the LSP.
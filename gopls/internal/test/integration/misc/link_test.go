@@ -92,3 +92,59 @@ const Hello = "Hello"
 		}
 	})
 }
+
+// TestResolveDocumentLink checks that, for a client that declares it
+// resolves document links, the targets of import links are computed by
+// documentLink/resolve.
+func TestResolveDocumentLink(t *testing.T) {
+	const program = `
+-- go.mod --
+module mod.test
+
+go 1.12
+
+require import.test v1.2.3
+-- main.go --
+package main
+
+import "import.test/pkg"
+
+func main() {
+	println(pkg.Hello)
+}`
+
+	const proxy = `
+-- import.test@v1.2.3/go.mod --
+module import.test
+
+go 1.12
+-- import.test@v1.2.3/pkg/const.go --
+package pkg
+
+const Hello = "Hello"
+`
+	const capabilities = `{ "experimental": { "documentLinkResolveSupport": true } }`
+	WithOptions(
+		ProxyFiles(proxy),
+		WriteGoSum("."),
+		CapabilitiesJSON([]byte(capabilities)),
+	).Run(t, program, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+
+		links := env.DocumentLink("main.go")
+		if len(links) != 1 {
+			t.Fatalf("documentLink: got links %+v for main.go, want one link", links)
+		}
+		if links[0].Target != nil || links[0].Data == nil {
+			t.Fatalf("documentLink: got link %+v, want a link with data and no target", links[0])
+		}
+		resolved, err := env.Editor.Server.ResolveDocumentLink(env.Ctx, &links[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		const pkgLink = "https://pkg.go.dev/import.test@v1.2.3/pkg"
+		if resolved.Target == nil || *resolved.Target != pkgLink {
+			t.Errorf("documentLink/resolve: got link %+v, want target %q", resolved, pkgLink)
+		}
+	})
+}
@@ -919,3 +919,223 @@ func checkTestdata(t *testing.T, env *Env) {
 		}
 	}
 }
+
+// This test checks that moving a package directory in the editor
+// updates the import paths of its importers, and its package name.
+func TestMoveFiles_Package(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- lib/lib.go --
+package lib
+
+func F() int { return 1 }
+-- lib/lib_test.go --
+package lib_test
+
+import (
+	"testing"
+
+	"mod.com/lib"
+)
+
+func TestF(t *testing.T) { _ = lib.F() }
+-- main.go --
+package main
+
+import "mod.com/lib"
+
+func main() { _ = lib.F() }
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.MoveFile("lib", "util")
+		env.AfterChange(NoDiagnostics())
+
+		checkBuffer(t, env, "main.go", `import "mod.com/util"`, `util.F()`)
+		checkBuffer(t, env, "util/lib.go", "package util\n")
+		checkBuffer(t, env, "util/lib_test.go", "package util_test\n", `"mod.com/util"`, "util.F()")
+	})
+}
+
+// This test checks that a single renaming of files in different views
+// updates the importers in each view.
+func TestMoveFiles_Views(t *testing.T) {
+	const files = `
+-- a/go.mod --
+module a.com
+
+go 1.18
+-- a/lib/lib.go --
+package lib
+
+func F() int { return 1 }
+-- a/main.go --
+package main
+
+import "a.com/lib"
+
+func main() { _ = lib.F() }
+-- b/go.mod --
+module b.com
+
+go 1.18
+-- b/lib/lib.go --
+package lib
+
+func G() int { return 2 }
+-- b/main.go --
+package main
+
+import "b.com/lib"
+
+func main() { _ = lib.G() }
+`
+	WithOptions(
+		WorkspaceFolders("a", "b"),
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.MoveFiles([2]string{"a/lib", "a/util"}, [2]string{"b/lib", "b/util"})
+		env.AfterChange(NoDiagnostics())
+
+		checkBuffer(t, env, "a/main.go", `import "a.com/util"`, `util.F()`)
+		checkBuffer(t, env, "b/main.go", `import "b.com/util"`, `util.G()`)
+		checkBuffer(t, env, "b/util/lib.go", "package util\n")
+	})
+}
+
+// This test checks that a file belonging to two views receives the
+// edits computed in each of them when packages of both are renamed.
+func TestMoveFiles_SharedImporter(t *testing.T) {
+	// The build constraint puts b/lib in the windows view only, while
+	// b/main.go belongs to both views.
+	const files = `
+-- go.work --
+go 1.18
+
+use (
+	./a
+	./b
+)
+-- a/go.mod --
+module a.com
+
+go 1.18
+-- a/lib/lib.go --
+package lib
+
+func F() int { return 1 }
+-- b/go.mod --
+module b.com
+
+go 1.18
+-- b/lib/lib.go --
+//go:build windows
+
+package lib
+
+func G() int { return 2 }
+-- b/main.go --
+package main
+
+import (
+	alib "a.com/lib"
+	"b.com/lib"
+)
+
+func main() { _ = alib.F() + lib.G() }
+`
+	WithOptions(
+		WorkspaceFolders(".", "b"),
+		FolderSettings{"b": {"env": map[string]string{"GOOS": "windows"}}},
+	).Run(t, files, func(t *testing.T, env *Env) {
+		env.MoveFiles([2]string{"a/lib/lib.go", "a/util/lib.go"}, [2]string{"b/lib/lib.go", "b/util/lib.go"})
+
+		checkBuffer(t, env, "b/main.go", `alib "a.com/util"`, `"b.com/util"`, `util.G()`)
+	})
+}
+
+// This test checks that moving a single file to the directory of
+// another package updates its package clause.
+func TestMoveFiles_File(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- a/a.go --
+package a
+
+const A = 1
+-- a/extra.go --
+package a
+
+const Extra = 2
+-- b/b.go --
+package b
+
+const B = A
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.MoveFile("a/extra.go", "b/extra.go")
+		checkBuffer(t, env, "b/extra.go", "package b\n")
+	})
+}
+
+// This test checks that renaming an embedded file or a testdata file
+// updates the go:embed patterns and test file names that refer to it.
+func TestMoveFiles_Embed(t *testing.T) {
+	const files = `
+-- go.mod --
+module mod.com
+
+go 1.18
+-- p/p.go --
+package p
+
+import _ "embed"
+
+//go:embed static/hello.txt
+var hello string
+
+//go:embed "testdata/in.txt"
+var in string
+-- p/static/hello.txt --
+hello
+-- p/testdata/in.txt --
+in
+-- p/p_test.go --
+package p
+
+import (
+	"os"
+	"testing"
+)
+
+func TestP(t *testing.T) {
+	_, _ = os.ReadFile("testdata/in.txt")
+	_ = "static/hello.txt" // not testdata: unchanged
+}
+`
+	Run(t, files, func(t *testing.T, env *Env) {
+		env.MoveFile("p/static", "p/assets")
+		env.MoveFile("p/testdata/in.txt", "p/testdata/input.txt")
+		env.AfterChange(NoDiagnostics(ForFile("p/p.go")))
+
+		checkBuffer(t, env, "p/p.go", "//go:embed assets/hello.txt\n", `//go:embed "testdata/input.txt"`)
+		checkBuffer(t, env, "p/p_test.go", `os.ReadFile("testdata/input.txt")`, `"static/hello.txt"`)
+	})
+}
+
+// checkBuffer reports an error if the editor buffer of the named file
+// (which it opens) does not contain each of the wanted substrings.
+func checkBuffer(t *testing.T, env *Env, name string, want ...string) {
+	t.Helper()
+	env.OpenFile(name)
+	got := env.BufferText(name)
+	for _, w := range want {
+		if !strings.Contains(got, w) {
+			t.Errorf("%s does not contain %q:\n%s", name, w, got)
+		}
+	}
+}
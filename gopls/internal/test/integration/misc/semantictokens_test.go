@@ -5,7 +5,9 @@
 package misc
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 
@@ -236,3 +238,93 @@ const bad = `
 		}
 	})
 }
+
+// TestSemanticTokensDelta checks that applying the edits of a
+// semanticTokens/full/delta response to the previous result yields
+// the same tokens as a full request.
+func TestSemanticTokensDelta(t *testing.T) {
+	const src = `
+-- go.mod --
+module example.com
+
+go 1.21
+-- main.go --
+package main
+
+func f(x int) int {
+	return x
+}
+
+func g() {}
+`
+	WithOptions(
+		Modes(Default),
+		Settings{"semanticTokens": true},
+	).Run(t, src, func(t *testing.T, env *Env) {
+		env.OpenFile("main.go")
+		doc := env.Editor.TextDocumentIdentifier("main.go")
+		full, err := env.Editor.Server.SemanticTokensFull(env.Ctx, &protocol.SemanticTokensParams{TextDocument: doc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if full.ResultID == "" {
+			t.Fatal("SemanticTokensFull returned no ResultID")
+		}
+
+		// An unchanged document yields an empty delta.
+		delta := semanticTokensDelta(t, env, full.ResultID)
+		if delta.Edits == nil {
+			t.Fatalf("SemanticTokensFullDelta returned full tokens, want delta")
+		}
+		if len(delta.Edits) != 0 {
+			t.Errorf("SemanticTokensFullDelta of unchanged file returned edits %v", delta.Edits)
+		}
+
+		env.RegexpReplace("main.go", "return x", "y := x\n\treturn y")
+		delta = semanticTokensDelta(t, env, delta.ResultID)
+		if delta.Edits == nil {
+			t.Fatalf("SemanticTokensFullDelta returned full tokens, want delta")
+		}
+		got := full.Data
+		for _, edit := range delta.Edits {
+			got = slices.Replace(slices.Clone(got), int(edit.Start), int(edit.Start+edit.DeleteCount), edit.Data...)
+		}
+
+		want, err := env.Editor.Server.SemanticTokensFull(env.Ctx, &protocol.SemanticTokensParams{TextDocument: doc})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want.Data, got); diff != "" {
+			t.Errorf("delta-updated tokens do not match full tokens (-want +got):\n%s", diff)
+		}
+
+		// A stale result ID yields the full tokens.
+		if delta := semanticTokensDelta(t, env, full.ResultID); delta.Edits != nil {
+			t.Errorf("SemanticTokensFullDelta with stale ID returned edits %v, want full tokens", delta.Edits)
+		}
+	})
+}
+
+// semanticTokensDelta makes a semanticTokens/full/delta request for
+// main.go. The response is either a SemanticTokensDelta or, if
+// prevID is stale, a SemanticTokens, which has no "edits" field.
+func semanticTokensDelta(t *testing.T, env *Env, prevID string) *protocol.SemanticTokensDelta {
+	t.Helper()
+	res, err := env.Editor.Server.SemanticTokensFullDelta(env.Ctx, &protocol.SemanticTokensDeltaParams{
+		TextDocument:     env.Editor.TextDocumentIdentifier("main.go"),
+		PreviousResultID: prevID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The client decodes the result as a generic JSON value.
+	data, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var delta protocol.SemanticTokensDelta
+	if err := json.Unmarshal(data, &delta); err != nil {
+		t.Fatal(err)
+	}
+	return &delta
+}
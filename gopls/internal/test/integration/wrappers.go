@@ -256,6 +256,18 @@ func (e *Env) Diagnostics(name string) []protocol.Diagnostic {
 	return diags
 }
 
+// WorkspaceDiagnostics returns the reports of a workspace/diagnostic
+// request, calling t.Fatal on any error. See
+// [fake.Editor.WorkspaceDiagnostics].
+func (e *Env) WorkspaceDiagnostics(previous []protocol.PreviousResultID, partial bool) []protocol.WorkspaceDocumentDiagnosticReport {
+	e.TB.Helper()
+	reports, err := e.Editor.WorkspaceDiagnostics(e.Ctx, previous, partial)
+	if err != nil {
+		e.TB.Fatal(err)
+	}
+	return reports
+}
+
 // GetQuickFixes returns the available quick fix code actions, calling t.Fatal
 // on any error.
 func (e *Env) GetQuickFixes(path string, diagnostics []protocol.Diagnostic) []protocol.CodeAction {
@@ -511,6 +523,22 @@ func (e *Env) RenameFile(oldPath, newPath string) {
 	}
 }
 
+// MoveFile wraps Editor.MoveFile, calling t.Fatal on any error.
+func (e *Env) MoveFile(oldPath, newPath string) {
+	e.TB.Helper()
+	if err := e.Editor.MoveFile(e.Ctx, oldPath, newPath); err != nil {
+		e.TB.Fatal(err)
+	}
+}
+
+// MoveFiles wraps Editor.MoveFiles, calling t.Fatal on any error.
+func (e *Env) MoveFiles(moves ...[2]string) {
+	e.TB.Helper()
+	if err := e.Editor.MoveFiles(e.Ctx, moves...); err != nil {
+		e.TB.Fatal(err)
+	}
+}
+
 // SignatureHelp wraps Editor.SignatureHelp, calling t.Fatal on error
 func (e *Env) SignatureHelp(loc protocol.Location) *protocol.SignatureHelp {
 	e.TB.Helper()
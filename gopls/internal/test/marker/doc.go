@@ -223,6 +223,30 @@ Here is the list of supported action markers:
     (These locations are the declarations of the functions enclosing
     the calls, not the calls themselves.)
 
+  - inlinevalues(stopped location, want ...location): makes a
+    textDocument/inlineValue request for the portion of the file up to the
+    end of the line of the stopped location, as if a debugger had stopped
+    there, and
+    checks that the set of ranges of the resulting inline values matches
+    want.
+
+  - linkedediting(src location, want ...location): makes a
+    textDocument/linkedEditingRange request at the src location, and
+    checks that the set of resulting ranges matches want. If want is
+    empty, the request must return no ranges.
+
+  - moniker(src location, want stringMatcher): makes a
+    textDocument/moniker request at the src location, and checks that the
+    result is a single moniker whose kind and identifier, separated by a
+    space, match want. If want is the empty string, the request must
+    return no monikers.
+
+  - ontypeformat(src location, ch string, golden): makes a
+    textDocument/onTypeFormatting request as if the character ch had just
+    been typed at src, which ends at the position of the request, and
+    compares the result of applying the edits to the current document
+    against the named golden file.
+
   - outgoingcalls(src location, want ...location): makes a
     callHierarchy/outgoingCalls query at the src location, and checks that
     the set of call.To locations matches want.
@@ -244,6 +268,11 @@ Here is the list of supported action markers:
     (Failures in the computation to offer a fix do not generally result
     in LSP errors, so this marker is not appropriate for testing them.)
 
+  - rangeformat(golden, ...location): performs a textDocument/rangeFormatting
+    request (or textDocument/rangesFormatting, if more than one location is
+    given) for the given ranges of the current document, and compares the
+    result against the named golden file, as for the format marker.
+
   - rank(location, ...string OR completionItem): executes a
     textDocument/completion request at the given location, and verifies that
     each expected completion item occurs in the results, in the expected order.
@@ -276,6 +305,14 @@ Here is the list of supported action markers:
     case the item's label is used). It checks that the resulting snippet
     matches the provided snippet.
 
+  - subtypes(src location, want ...location): makes a
+    textDocument/prepareTypeHierarchy query at the src location followed by
+    a typeHierarchy/subtypes query on the resulting item, and checks that
+    the set of item locations matches want.
+
+  - supertypes(src location, want ...location): like subtypes, but makes a
+    typeHierarchy/supertypes query.
+
   - symbol(golden): makes a textDocument/documentSymbol request
     for the enclosing file, formats the response with one symbol
     per line, sorts it, and compares against the named golden file.
@@ -587,8 +587,13 @@ var actionMarkerFuncs = map[string]func(marker){
 	"implementation":   actionMarkerFunc(implementationMarker, "err"),
 	"incomingcalls":    actionMarkerFunc(incomingCallsMarker),
 	"inlayhints":       actionMarkerFunc(inlayhintsMarker),
+	"inlinevalues":     actionMarkerFunc(inlineValuesMarker),
+	"linkedediting":    actionMarkerFunc(linkedEditingMarker),
+	"moniker":          actionMarkerFunc(monikerMarker),
+	"ontypeformat":     actionMarkerFunc(onTypeFormatMarker),
 	"outgoingcalls":    actionMarkerFunc(outgoingCallsMarker),
 	"preparerename":    actionMarkerFunc(prepareRenameMarker, "span"),
+	"rangeformat":      actionMarkerFunc(rangeFormatMarker),
 	"rank":             actionMarkerFunc(rankMarker),
 	"refs":             actionMarkerFunc(refsMarker),
 	"rename":           actionMarkerFunc(renameMarker),
@@ -596,6 +601,8 @@ var actionMarkerFuncs = map[string]func(marker){
 	"selectionrange":   actionMarkerFunc(selectionRangeMarker),
 	"signature":        actionMarkerFunc(signatureMarker),
 	"snippet":          actionMarkerFunc(snippetMarker),
+	"subtypes":         actionMarkerFunc(subtypesMarker),
+	"supertypes":       actionMarkerFunc(supertypesMarker),
 	"quickfix":         actionMarkerFunc(quickfixMarker),
 	"quickfixerr":      actionMarkerFunc(quickfixErrMarker),
 	"symbol":           actionMarkerFunc(symbolMarker),
@@ -1736,6 +1743,74 @@ func formatMarker(mark marker, golden *Golden) {
 	compareGolden(mark, got, golden)
 }
 
+// rangeFormatMarker implements the @rangeformat marker.
+// onTypeFormatMarker implements the @ontypeformat marker.
+func onTypeFormatMarker(mark marker, src protocol.Location, ch string, golden *Golden) {
+	edits, err := mark.server().OnTypeFormatting(mark.ctx(), &protocol.DocumentOnTypeFormattingParams{
+		TextDocument: mark.document(),
+		Position:     src.Range.End,
+		Ch:           ch,
+	})
+	if err != nil {
+		mark.errorf("OnTypeFormatting failed: %v", err)
+		return
+	}
+	env := mark.run.env
+	filename := mark.path()
+	mapper, err := env.Editor.Mapper(filename)
+	if err != nil {
+		mark.errorf("Editor.Mapper(%s) failed: %v", filename, err)
+		return
+	}
+	got, _, err := protocol.ApplyEdits(mapper, edits)
+	if err != nil {
+		mark.errorf("ApplyProtocolEdits failed: %v", err)
+		return
+	}
+	compareGolden(mark, got, golden)
+}
+
+func rangeFormatMarker(mark marker, golden *Golden, locs ...protocol.Location) {
+	var (
+		edits []protocol.TextEdit
+		err   error
+	)
+	if len(locs) == 1 {
+		edits, err = mark.server().RangeFormatting(mark.ctx(), &protocol.DocumentRangeFormattingParams{
+			TextDocument: mark.document(),
+			Range:        locs[0].Range,
+		})
+	} else {
+		var rngs []protocol.Range
+		for _, loc := range locs {
+			rngs = append(rngs, loc.Range)
+		}
+		edits, err = mark.server().RangesFormatting(mark.ctx(), &protocol.DocumentRangesFormattingParams{
+			TextDocument: mark.document(),
+			Ranges:       rngs,
+		})
+	}
+	var got []byte
+	if err != nil {
+		got = []byte(err.Error() + "\n") // all golden content is newline terminated
+	} else {
+		env := mark.run.env
+		filename := mark.path()
+		mapper, err := env.Editor.Mapper(filename)
+		if err != nil {
+			mark.errorf("Editor.Mapper(%s) failed: %v", filename, err)
+		}
+
+		got, _, err = protocol.ApplyEdits(mapper, edits)
+		if err != nil {
+			mark.errorf("ApplyProtocolEdits failed: %v", err)
+			return
+		}
+	}
+
+	compareGolden(mark, got, golden)
+}
+
 func highlightLocationMarker(mark marker, loc protocol.Location, kindName expect.Identifier) protocol.DocumentHighlight {
 	var kind protocol.DocumentHighlightKind
 	switch kindName {
@@ -1794,6 +1869,84 @@ func highlightMarker(mark marker, src protocol.DocumentHighlight, dsts ...protoc
 	}
 }
 
+// inlineValuesMarker implements the @inlinevalues marker.
+func inlineValuesMarker(mark marker, stopped protocol.Location, want ...protocol.Location) {
+	values, err := mark.server().InlineValue(mark.ctx(), &protocol.InlineValueParams{
+		TextDocument: mark.document(),
+		Range:        protocol.Range{End: protocol.Position{Line: stopped.Range.End.Line + 1}},
+		Context:      protocol.InlineValueContext{StoppedLocation: stopped.Range},
+	})
+	if err != nil {
+		mark.errorf("InlineValue failed: %v", err)
+		return
+	}
+	var got []protocol.Location
+	for _, v := range values {
+		var rng protocol.Range
+		switch v := v.Value.(type) {
+		case protocol.InlineValueText:
+			rng = v.Range
+		case protocol.InlineValueVariableLookup:
+			rng = v.Range
+		case protocol.InlineValueEvaluatableExpression:
+			rng = v.Range
+		default:
+			mark.errorf("unexpected inline value %T", v)
+		}
+		got = append(got, protocol.Location{URI: stopped.URI, Range: rng})
+	}
+	if err := compareLocations(mark, got, want); err != nil {
+		mark.errorf("inlineValue: %v", err)
+	}
+}
+
+// linkedEditingMarker implements the @linkedediting marker.
+func linkedEditingMarker(mark marker, src protocol.Location, want ...protocol.Location) {
+	got, err := mark.server().LinkedEditingRange(mark.ctx(), &protocol.LinkedEditingRangeParams{
+		TextDocumentPositionParams: protocol.LocationTextDocumentPositionParams(src),
+	})
+	if err != nil {
+		mark.errorf("LinkedEditingRange failed: %v", err)
+		return
+	}
+	var gotLocs []protocol.Location
+	if got != nil {
+		for _, rng := range got.Ranges {
+			gotLocs = append(gotLocs, protocol.Location{URI: src.URI, Range: rng})
+		}
+	}
+	if err := compareLocations(mark, gotLocs, want); err != nil {
+		mark.errorf("linkedEditingRange: %v", err)
+	}
+}
+
+// monikerMarker implements the @moniker marker.
+func monikerMarker(mark marker, src protocol.Location, want stringMatcher) {
+	monikers, err := mark.server().Moniker(mark.ctx(), &protocol.MonikerParams{
+		TextDocumentPositionParams: protocol.LocationTextDocumentPositionParams(src),
+	})
+	if err != nil {
+		mark.errorf("Moniker failed: %v", err)
+		return
+	}
+	if want.empty() {
+		if len(monikers) > 0 {
+			mark.errorf("Moniker returned %v, want none", monikers)
+		}
+		return
+	}
+	if len(monikers) != 1 {
+		mark.errorf("Moniker returned %d monikers, want 1", len(monikers))
+		return
+	}
+	m := monikers[0]
+	if m.Scheme != "go" || m.Unique != protocol.Scheme || m.Kind == nil {
+		mark.errorf("Moniker returned %+v, want scheme \"go\", uniqueness %q and a kind", m, protocol.Scheme)
+		return
+	}
+	want.check(mark, fmt.Sprintf("%s %s", *m.Kind, m.Identifier))
+}
+
 func hoverMarker(mark marker, src, dst protocol.Location, sc stringMatcher) {
 	content, gotDst := mark.run.env.Hover(src)
 	if gotDst != dst {
@@ -2469,6 +2622,48 @@ func callHierarchy(mark marker, src protocol.Location, getCalls callHierarchyFun
 	}
 }
 
+func subtypesMarker(mark marker, src protocol.Location, want ...protocol.Location) {
+	getTypes := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		return mark.server().Subtypes(mark.ctx(), &protocol.TypeHierarchySubtypesParams{Item: item})
+	}
+	typeHierarchy(mark, src, getTypes, want)
+}
+
+func supertypesMarker(mark marker, src protocol.Location, want ...protocol.Location) {
+	getTypes := func(item protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error) {
+		return mark.server().Supertypes(mark.ctx(), &protocol.TypeHierarchySupertypesParams{Item: item})
+	}
+	typeHierarchy(mark, src, getTypes, want)
+}
+
+type typeHierarchyFunc = func(protocol.TypeHierarchyItem) ([]protocol.TypeHierarchyItem, error)
+
+func typeHierarchy(mark marker, src protocol.Location, getTypes typeHierarchyFunc, want []protocol.Location) {
+	items, err := mark.server().PrepareTypeHierarchy(mark.ctx(), &protocol.TypeHierarchyPrepareParams{
+		TextDocumentPositionParams: protocol.LocationTextDocumentPositionParams(src),
+	})
+	if err != nil {
+		mark.errorf("PrepareTypeHierarchy failed: %v", err)
+		return
+	}
+	if nitems := len(items); nitems != 1 {
+		mark.errorf("PrepareTypeHierarchy returned %d items, want exactly 1", nitems)
+		return
+	}
+	related, err := getTypes(items[0])
+	if err != nil {
+		mark.errorf("type hierarchy failed: %v", err)
+		return
+	}
+	var got []protocol.Location
+	for _, item := range related {
+		got = append(got, protocol.Location{URI: item.URI, Range: item.Range})
+	}
+	if err := compareLocations(mark, got, want); err != nil {
+		mark.errorf("type hierarchy: %v", err)
+	}
+}
+
 func inlayhintsMarker(mark marker, g *Golden) {
 	hints := mark.run.env.InlayHints(mark.path())
 
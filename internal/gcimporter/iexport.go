@@ -281,9 +281,20 @@ func IExportShallow(fset *token.FileSet, pkg *types.Package, reportf ReportFunc)
 	// fact iexportCommon doesn't even check for I/O errors.
 	// TODO(adonovan): handle I/O errors properly.
 	// TODO(adonovan): use byte slices throughout, avoiding copying.
-	const bundle, shallow = false, true
+	const bundle, shallow, all = false, true, false
 	var out bytes.Buffer
-	err := iexportCommon(&out, fset, bundle, shallow, iexportVersion, []*types.Package{pkg}, reportf)
+	err := iexportCommon(&out, fset, bundle, shallow, all, iexportVersion, []*types.Package{pkg}, reportf)
+	return out.Bytes(), err
+}
+
+// IExportShallowAll is like [IExportShallow], but it encodes all the
+// package-level declarations of pkg, not just the exported ones and
+// those on which they depend, so that the imported package has a
+// complete scope.
+func IExportShallowAll(fset *token.FileSet, pkg *types.Package, reportf ReportFunc) ([]byte, error) {
+	const bundle, shallow, all = false, true, true
+	var out bytes.Buffer
+	err := iexportCommon(&out, fset, bundle, shallow, all, iexportVersion, []*types.Package{pkg}, reportf)
 	return out.Bytes(), err
 }
 
@@ -322,17 +333,17 @@ const bundleVersion = 0
 // The package path of the top-level package will not be recorded,
 // so that calls to IImportData can override with a provided package path.
 func IExportData(out io.Writer, fset *token.FileSet, pkg *types.Package) error {
-	const bundle, shallow = false, false
-	return iexportCommon(out, fset, bundle, shallow, iexportVersion, []*types.Package{pkg}, nil)
+	const bundle, shallow, all = false, false, false
+	return iexportCommon(out, fset, bundle, shallow, all, iexportVersion, []*types.Package{pkg}, nil)
 }
 
 // IExportBundle writes an indexed export bundle for pkgs to out.
 func IExportBundle(out io.Writer, fset *token.FileSet, pkgs []*types.Package) error {
-	const bundle, shallow = true, false
-	return iexportCommon(out, fset, bundle, shallow, iexportVersion, pkgs, nil)
+	const bundle, shallow, all = true, false, false
+	return iexportCommon(out, fset, bundle, shallow, all, iexportVersion, pkgs, nil)
 }
 
-func iexportCommon(out io.Writer, fset *token.FileSet, bundle, shallow bool, version int, pkgs []*types.Package, reportf ReportFunc) (err error) {
+func iexportCommon(out io.Writer, fset *token.FileSet, bundle, shallow, all bool, version int, pkgs []*types.Package, reportf ReportFunc) (err error) {
 	if !debug {
 		defer func() {
 			if e := recover(); e != nil {
@@ -374,11 +385,11 @@ func iexportCommon(out io.Writer, fset *token.FileSet, bundle, shallow bool, ver
 		panic(internalErrorf("too many predeclared types: %d > %d", len(p.typIndex), predeclReserved))
 	}
 
-	// Initialize work queue with exported declarations.
+	// Initialize work queue with exported (or all) declarations.
 	for _, pkg := range pkgs {
 		scope := pkg.Scope()
 		for _, name := range scope.Names() {
-			if token.IsExported(name) {
+			if all || token.IsExported(name) {
 				p.pushDecl(scope.Lookup(name))
 			}
 		}